@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/whotypes/leetbot/internal/data"
+	"github.com/whotypes/leetbot/internal/health"
+	"github.com/whotypes/leetbot/internal/logging"
+	"github.com/whotypes/leetbot/internal/metrics"
+)
+
+// gatewayStatus tracks whether the session's gateway connection is up
+type gatewayStatus struct {
+	connected atomic.Bool
+}
+
+// track follows the session's connect, ready and disconnect events
+func (g *gatewayStatus) track(s *discordgo.Session) {
+	s.AddHandler(func(_ *discordgo.Session, _ *discordgo.Ready) { g.connected.Store(true) })
+	s.AddHandler(func(_ *discordgo.Session, _ *discordgo.Resumed) { g.connected.Store(true) })
+	s.AddHandler(func(_ *discordgo.Session, _ *discordgo.Disconnect) { g.connected.Store(false) })
+}
+
+// check is a readiness check that fails while the gateway is down
+func (g *gatewayStatus) check() error {
+	if !g.connected.Load() {
+		return errors.New("discord gateway not connected")
+	}
+	return nil
+}
+
+// startAdminServer serves operational endpoints on addr, which is kept off
+// the public internet, and returns a function that shuts it down
+func startAdminServer(addr string, problemsData func() *data.ProblemsByCompany, gateway *gatewayStatus) func() {
+	checker := health.NewChecker()
+	checker.Add("data", health.DataLoaded(problemsData))
+	checker.Add("gateway", gateway.check)
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", metrics.Handler())
+	mux.HandleFunc("GET /healthz", health.Healthz)
+	mux.HandleFunc("GET /readyz", checker.Readyz)
+	mux.HandleFunc("GET /api/info", health.InfoHandler(problemsData))
+
+	srv := &http.Server{
+		Addr:         addr,
+		Handler:      mux,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		slog.Info("admin server starting", "addr", addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("admin server failed", logging.Err(err))
+		}
+	}()
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(ctx); err != nil {
+			slog.Error("admin server shutdown failed", logging.Err(err))
+		}
+	}
+}
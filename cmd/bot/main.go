@@ -1,8 +1,7 @@
 package main
 
 import (
-	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
@@ -12,32 +11,66 @@ import (
 	"github.com/whotypes/leetbot/internal/config"
 	"github.com/whotypes/leetbot/internal/data"
 	"github.com/whotypes/leetbot/internal/discord"
+	"github.com/whotypes/leetbot/internal/logging"
+	"github.com/whotypes/leetbot/internal/ratelimit"
 )
 
 func main() {
-	cfg, err := config.Load()
-	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
-	}
+	cfg := config.MustLoad("leetbot", os.Args[1:])
+	logging.Setup(cfg.Log.Options())
 
 	if err := cfg.Validate(); err != nil {
-		log.Fatalf("Invalid configuration: %v", err)
+		fatal("invalid configuration", err)
 	}
+	discord.Configure(cfg.Bot)
 
-	fmt.Printf("Starting Leetbot with prefix '%s'...\n", cfg.BotPrefix)
-	fmt.Println("Loading problems data...")
-	problemsData, err := data.LoadAllProblems()
+	slog.Info("starting leetbot", "prefix", cfg.BotPrefix)
+	problemsData, err := data.LoadWithOverlays(cfg.Data.Overlays, cfg.Data.Strict)
 	if err != nil {
-		log.Fatalf("Failed to load problems data: %v", err)
+		fatal("failed to load problems data", err)
 	}
 
-	fmt.Printf("Loaded data for %d companies\n", len(problemsData.GetAvailableCompanies()))
+	version := problemsData.Version()
+	slog.Info("loaded problems data",
+		"companies", len(problemsData.GetAvailableCompanies()),
+		"dataset_version", version.Version,
+		"unique_problems", version.UniqueProblems)
 
 	handler := discord.NewHandler(problemsData, cfg.BotPrefix)
 
+	// bot.rate_limits overrides individual limits, e.g. query.user=3/30s
+	rateLimits, err := ratelimit.ParseCommandLimits(cfg.Bot.RateLimits)
+	if err != nil {
+		fatal("invalid bot.rate_limits", err)
+	}
+	handler.SetRateLimits(rateLimits)
+	slog.Debug("rate limits", "limits", rateLimits.String())
+
+	// load the previous snapshot for /dataset changelog if one is configured
+	if snapshotsDir := cfg.Bot.SnapshotDir; snapshotsDir != "" {
+		previous, err := data.LatestSnapshotBefore(snapshotsDir, version)
+		if err != nil {
+			slog.Warn("failed to load dataset snapshots", "dir", snapshotsDir, logging.Err(err))
+		} else if previous != nil {
+			slog.Info("comparing against dataset snapshot", "dataset_version", previous.Version().Version)
+			handler.SetPreviousDataset(previous)
+		}
+	}
+
 	dg, err := discordgo.New("Bot " + cfg.DiscordToken)
 	if err != nil {
-		log.Fatalf("Failed to create Discord session: %v", err)
+		fatal("failed to create discord session", err)
+	}
+
+	discord.InstrumentSession(dg)
+
+	gateway := &gatewayStatus{}
+	gateway.track(dg)
+
+	// metrics and health checks are served only when an admin address is configured
+	if addr := cfg.Bot.AdminAddr; addr != "" {
+		stop := startAdminServer(addr, func() *data.ProblemsByCompany { return problemsData }, gateway)
+		defer stop()
 	}
 
 	// Set the session in the handler for restart functionality
@@ -47,6 +80,11 @@ func main() {
 	reconnectChan := make(chan discord.RestartRequest)
 	handler.SetReconnectChannel(reconnectChan)
 
+	// SIGHUP and !reload config apply config changes without reconnecting
+	reloader := &configReloader{args: os.Args[1:], handler: handler, current: cfg}
+	handler.SetConfigReloader(reloader.reload)
+	reloader.watchSignals()
+
 	dg.AddHandler(handler.HandleMessage)
 
 	dg.AddHandler(func(s *discordgo.Session, i *discordgo.InteractionCreate) {
@@ -56,7 +94,7 @@ func main() {
 		switch i.Type {
 		case discordgo.InteractionMessageComponent:
 			// let the paginator handle button clicks
-			discord.PaginatorManager.OnInteractionCreate(s, i)
+			handler.HandleComponent(s, i)
 		case discordgo.InteractionApplicationCommand:
 			handler.HandleSlashCommand(s, i)
 		case discordgo.InteractionApplicationCommandAutocomplete:
@@ -69,61 +107,26 @@ func main() {
 		// Update handler's session reference on ready
 		handler.SetSession(s)
 
-		log.Printf("Leetbot logged in as: %v#%v", s.State.User.Username, s.State.User.Discriminator)
+		slog.Info("logged in", "username", s.State.User.Username, "discriminator", s.State.User.Discriminator)
 
-		commands := discord.GetSlashCommands(problemsData)
-
-		log.Println("Clearing old slash commands...")
-		// Get all currently registered commands
-		registeredCommands, err := s.ApplicationCommands(s.State.User.ID, "")
-		if err != nil {
-			log.Printf("Warning: failed to get registered commands: %v", err)
-		} else {
-			// Delete commands that are no longer in our code
-			for _, registeredCmd := range registeredCommands {
-				// Check if this command should still exist
-				shouldExist := false
-				for _, currentCmd := range commands {
-					if registeredCmd.Name == currentCmd.Name {
-						shouldExist = true
-						break
-					}
-				}
-
-				if !shouldExist {
-					log.Printf("Removing old command: /%v", registeredCmd.Name)
-					err := s.ApplicationCommandDelete(s.State.User.ID, "", registeredCmd.ID)
-					if err != nil {
-						log.Printf("Failed to delete command '%v': %v", registeredCmd.Name, err)
-					}
-				}
-			}
+		// only scopes whose commands changed are overwritten, so reconnects are cheap
+		if err := handler.SyncCommands(s); err != nil {
+			slog.Error("syncing slash commands failed", logging.Err(err))
 		}
-
-		log.Println("Registering slash commands...")
-		for _, v := range commands {
-			_, err := s.ApplicationCommandCreate(s.State.User.ID, "", v)
-			if err != nil {
-				log.Printf("Cannot create '%v' command: %v", v.Name, err)
-			} else {
-				log.Printf("✓ Registered command: /%v", v.Name)
-			}
-		}
-		log.Printf("Finished registering slash commands")
 	})
 
 	dg.Identify.Intents = discordgo.IntentsGuildMessages | discordgo.IntentsMessageContent
 
 	err = dg.Open()
 	if err != nil {
-		log.Fatalf("Failed to open Discord connection: %v", err)
+		fatal("failed to open discord connection", err)
 	}
 	defer dg.Close()
 
 	// start a goroutine to handle reconnection signals
 	go func() {
 		for restartReq := range reconnectChan {
-			log.Println("Received restart signal, restarting Discord session...")
+			slog.Info("restarting discord session", logging.KeyChannel, restartReq.ChannelID)
 
 			// Send the initial restart message
 			_, err := dg.ChannelMessageSendComplex(restartReq.ChannelID, &discordgo.MessageSend{
@@ -131,20 +134,20 @@ func main() {
 				Flags:   discordgo.MessageFlagsSuppressEmbeds,
 			})
 			if err != nil {
-				log.Printf("Error sending restart message: %v", err)
+				slog.Error("sending restart message failed", logging.KeyChannel, restartReq.ChannelID, logging.Err(err))
 			}
 
 			// close current session
 			err = dg.Close()
 			if err != nil {
-				log.Printf("Error closing Discord session: %v", err)
+				slog.Error("closing discord session failed", logging.Err(err))
 				// Send error message
 				_, err := dg.ChannelMessageSendComplex(restartReq.ChannelID, &discordgo.MessageSend{
-					Content: "**Restart Complete** - Failed to close session",
+					Content: restartReq.Printer.T("restart.close_failed"),
 					Flags:   discordgo.MessageFlagsSuppressEmbeds,
 				})
 				if err != nil {
-					log.Printf("Error sending restart failure message: %v", err)
+					slog.Error("sending restart message failed", logging.KeyChannel, restartReq.ChannelID, logging.Err(err))
 				}
 				continue
 			}
@@ -155,38 +158,44 @@ func main() {
 			// reopen the session
 			err = dg.Open()
 			if err != nil {
-				log.Printf("Error reopening Discord session: %v", err)
+				slog.Error("reopening discord session failed", logging.Err(err))
 				// Send error message
 				_, err := dg.ChannelMessageSendComplex(restartReq.ChannelID, &discordgo.MessageSend{
-					Content: "**Restart Complete** - Failed to reconnect to Discord",
+					Content: restartReq.Printer.T("restart.reconnect_failed"),
 					Flags:   discordgo.MessageFlagsSuppressEmbeds,
 				})
 				if err != nil {
-					log.Printf("Error sending reconnection failure message: %v", err)
+					slog.Error("sending restart message failed", logging.KeyChannel, restartReq.ChannelID, logging.Err(err))
 				}
 				// if reconnection fails, we can't really do much more from here
 				// the main process will need to be restarted
 			} else {
-				log.Println("Leetbot restarted successfully")
+				slog.Info("discord session restarted")
 				// Update the handler's session reference
 				handler.SetSession(dg)
 
 				// Send success confirmation
 				_, err := dg.ChannelMessageSendComplex(restartReq.ChannelID, &discordgo.MessageSend{
-					Content: "**Restart Complete** - Leetbot has restarted successfully!",
+					Content: restartReq.Printer.T("restart.done"),
 					Flags:   discordgo.MessageFlagsSuppressEmbeds,
 				})
 				if err != nil {
-					log.Printf("Error sending restart success message: %v", err)
+					slog.Error("sending restart message failed", logging.KeyChannel, restartReq.ChannelID, logging.Err(err))
 				}
 			}
 		}
 	}()
 
-	fmt.Println("Leetbot is now running. Press CTRL-C to exit.")
+	slog.Info("leetbot is running, press CTRL-C to exit")
 	sc := make(chan os.Signal, 1)
 	signal.Notify(sc, syscall.SIGINT, syscall.SIGTERM, os.Interrupt)
 	<-sc
 
-	fmt.Println("Shutting down Leetbot...")
+	slog.Info("shutting down")
+}
+
+// fatal logs an error that prevents startup and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, logging.Err(err))
+	os.Exit(1)
 }
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/whotypes/leetbot/internal/config"
+	"github.com/whotypes/leetbot/internal/discord"
+	"github.com/whotypes/leetbot/internal/logging"
+)
+
+// configReloader re-reads the configuration on SIGHUP and !reload config,
+// applying it to the running handler without reconnecting
+type configReloader struct {
+	args    []string
+	handler *discord.Handler
+
+	mu      sync.Mutex // serializes reloads
+	current *config.Config
+}
+
+// reload parses the configuration again with the original flags. Nothing
+// changes unless every setting is valid.
+func (r *configReloader) reload() ([]config.Change, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	next, _, err := config.Parse("leetbot", r.args)
+	if err == nil {
+		err = next.Validate()
+	}
+	if err == nil {
+		err = r.handler.Reconfigure(next.BotPrefix, next.Bot)
+	}
+	if err != nil {
+		config.LogReloadFailure(err)
+		return nil, err
+	}
+
+	if level, err := logging.ParseLevel(next.Log.Level); err == nil {
+		logging.SetLevel(level)
+	}
+
+	changes := config.Filter(r.current.Diff(next), "discord.", "bot.", "data.", "log.")
+	config.LogChanges(changes, liveBotSetting)
+	r.current = next
+	return changes, nil
+}
+
+// watchSignals reloads the configuration on every SIGHUP
+func (r *configReloader) watchSignals() {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			_, _ = r.reload()
+		}
+	}()
+}
+
+// liveBotSetting reports whether a reload applies the setting, rather than
+// it waiting for a restart
+func liveBotSetting(key string) bool {
+	switch key {
+	case "discord.token", "bot.admin_addr", "bot.dataset_snapshot_dir", "log.format":
+		return false
+	}
+	return key == "discord.prefix" || key == "log.level" || strings.HasPrefix(key, "bot.")
+}
@@ -0,0 +1,390 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/whotypes/leetbot/internal/data"
+	"github.com/whotypes/leetbot/internal/export"
+)
+
+func runCompanies(a *app, args []string) error {
+	args, err := a.parseFlags("companies", args, nil)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		return errUsage
+	}
+
+	type companyRow struct {
+		Company    string   `json:"company"`
+		Timeframes []string `json:"timeframes"`
+		Problems   int      `json:"problems"`
+	}
+
+	companies := a.problemsData.GetAvailableCompanies()
+	if a.options.filter.Limit > 0 && len(companies) > a.options.filter.Limit {
+		companies = companies[:a.options.filter.Limit]
+	}
+
+	t := table{headers: []string{"company", "problems", "timeframes"}, difficultyColumn: -1}
+	values := make([]companyRow, 0, len(companies))
+	for _, company := range companies {
+		timeframes := a.problemsData.GetAvailableTimeframes(company)
+		problems := len(a.problemsData.GetProblems(company, "all"))
+		t.rows = append(t.rows, []string{company, strconv.Itoa(problems), strings.Join(timeframes, ",")})
+		values = append(values, companyRow{Company: company, Timeframes: timeframes, Problems: problems})
+	}
+	t.value = values
+
+	return a.render(t)
+}
+
+// companyProblems resolves a company and returns its problems for the timeframe,
+// falling back to the bot's priority order when no timeframe is given
+func (a *app) companyProblems(companyInput, timeframeInput string) (string, string, []data.Problem, error) {
+	company, err := a.resolveCompany(companyInput)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	if timeframeInput == "" {
+		problems, timeframe := a.problemsData.GetProblemsWithPriority(company)
+		return company, timeframe, a.options.filter.Apply(problems), nil
+	}
+
+	timeframe, ok := data.CanonicalTimeframe(timeframeInput)
+	if !ok {
+		return "", "", nil, fmt.Errorf("unknown timeframe %q", timeframeInput)
+	}
+	problems := a.problemsData.GetProblems(company, timeframe)
+	if problems == nil {
+		return "", "", nil, fmt.Errorf("no problems for %s in timeframe %s, available: %s",
+			company, timeframe, strings.Join(a.problemsData.GetAvailableTimeframes(company), ", "))
+	}
+
+	return company, timeframe, a.options.filter.Apply(problems), nil
+}
+
+// splitTimeframe treats a trailing timeframe keyword as the timeframe, so
+// multi-word company names work without quotes
+func splitTimeframe(args []string) (string, string) {
+	if len(args) > 1 {
+		if _, ok := data.CanonicalTimeframe(args[len(args)-1]); ok {
+			return strings.Join(args[:len(args)-1], " "), args[len(args)-1]
+		}
+	}
+	return strings.Join(args, " "), ""
+}
+
+func runProblems(a *app, args []string) error {
+	args, err := a.parseFlags("problems", args, nil)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	companyInput, timeframeInput := splitTimeframe(args)
+	company, timeframe, problems, err := a.companyProblems(companyInput, timeframeInput)
+	if err != nil {
+		return err
+	}
+
+	if a.options.format == outputTable {
+		fmt.Fprintln(a.stdout, a.options.bold(fmt.Sprintf("%s (%s) - %d problems", company, timeframe, len(problems))))
+	}
+	return a.render(problemsTable(problems))
+}
+
+func runProblem(a *app, args []string) error {
+	args, err := a.parseFlags("problem", args, nil)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	id, err := strconv.Atoi(strings.TrimPrefix(args[0], "#"))
+	if err != nil {
+		return errUsage
+	}
+
+	result, ok := a.problemsData.SearchIndex().Get(id)
+	if !ok {
+		return fmt.Errorf("problem %d is not asked by any company in the dataset", id)
+	}
+
+	p := result.Problem
+	t := table{
+		headers: []string{"field", "value"},
+		rows: [][]string{
+			{"id", strconv.Itoa(p.ID)},
+			{"title", p.Title},
+			{"difficulty", a.options.colorDifficulty(p.Difficulty)},
+			{"acceptance", fmt.Sprintf("%.1f%%", p.Acceptance)},
+			{"url", p.URL},
+			{"companies", strconv.Itoa(result.Companies)},
+			{"most frequent at", strings.Join(result.TopCompanies, ", ")},
+		},
+		difficultyColumn: -1,
+		value: struct {
+			jsonProblem
+			Companies    int      `json:"companies"`
+			TopCompanies []string `json:"top_companies"`
+		}{toJSONProblem(p), result.Companies, result.TopCompanies},
+	}
+	if a.options.format == outputCSV {
+		// CSV gets the raw value, without terminal colors
+		t.rows[2][1] = p.Difficulty
+	}
+
+	return a.render(t)
+}
+
+func runSearch(a *app, args []string) error {
+	args, err := a.parseFlags("search", args, nil)
+	if err != nil {
+		return err
+	}
+	query := strings.Join(args, " ")
+	if strings.TrimSpace(query) == "" {
+		return errUsage
+	}
+
+	// filter after searching so a difficulty filter doesn't eat the limit
+	limit := a.options.filter.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+	results := a.problemsData.Search(query, 0)
+
+	type searchRow struct {
+		jsonProblem
+		Score        float64  `json:"score"`
+		Companies    int      `json:"companies"`
+		TopCompanies []string `json:"top_companies"`
+	}
+
+	t := table{
+		headers:          []string{"id", "title", "difficulty", "companies", "url"},
+		difficultyColumn: 2,
+	}
+	values := []searchRow{}
+	for _, result := range results {
+		if len(values) >= limit {
+			break
+		}
+		if len(a.options.filter.Apply([]data.Problem{result.Problem})) == 0 {
+			continue
+		}
+		p := result.Problem
+		t.rows = append(t.rows, []string{strconv.Itoa(p.ID), p.Title, p.Difficulty, strconv.Itoa(result.Companies), p.URL})
+		values = append(values, searchRow{toJSONProblem(p), result.Score, result.Companies, result.TopCompanies})
+	}
+	t.value = values
+
+	if len(values) == 0 && a.options.format == outputTable {
+		return fmt.Errorf("no problems found matching '%s'", query)
+	}
+
+	return a.render(t)
+}
+
+func runCompare(a *app, args []string) error {
+	args, err := a.parseFlags("compare", args, nil)
+	if err != nil {
+		return err
+	}
+
+	var timeframe string
+	if len(args) == 3 {
+		timeframe = args[2]
+	} else if len(args) != 2 {
+		return errUsage
+	}
+
+	// the limit applies to the shared problems, not to each company's list
+	limit := a.options.filter.Limit
+	a.options.filter.Limit = 0
+
+	first, firstTimeframe, firstProblems, err := a.companyProblems(args[0], timeframe)
+	if err != nil {
+		return err
+	}
+	second, secondTimeframe, secondProblems, err := a.companyProblems(args[1], timeframe)
+	if err != nil {
+		return err
+	}
+
+	secondByID := make(map[int]data.Problem, len(secondProblems))
+	for _, p := range secondProblems {
+		secondByID[p.ID] = p
+	}
+
+	type sharedProblem struct {
+		jsonProblem
+		FirstFrequency  float64 `json:"first_frequency"`
+		SecondFrequency float64 `json:"second_frequency"`
+	}
+
+	var shared []sharedProblem
+	for _, p := range firstProblems {
+		if other, ok := secondByID[p.ID]; ok {
+			shared = append(shared, sharedProblem{toJSONProblem(p), p.Frequency, other.Frequency})
+		}
+	}
+	// problems both companies ask often come first
+	sort.SliceStable(shared, func(i, j int) bool {
+		return shared[i].FirstFrequency+shared[i].SecondFrequency > shared[j].FirstFrequency+shared[j].SecondFrequency
+	})
+
+	common := len(shared)
+	if limit > 0 && len(shared) > limit {
+		shared = shared[:limit]
+	}
+
+	if a.options.format == outputTable {
+		fmt.Fprintln(a.stdout, a.options.bold(fmt.Sprintf("%s (%s, %d problems) vs %s (%s, %d problems): %d in common",
+			first, firstTimeframe, len(firstProblems), second, secondTimeframe, len(secondProblems), common)))
+	}
+
+	t := table{
+		headers:          []string{"id", "title", "difficulty", first, second, "url"},
+		difficultyColumn: 2,
+		value: map[string]interface{}{
+			"companies":  []string{first, second},
+			"timeframes": []string{firstTimeframe, secondTimeframe},
+			"counts":     []int{len(firstProblems), len(secondProblems)},
+			"common":     common,
+			"shared":     shared,
+		},
+	}
+	for _, p := range shared {
+		t.rows = append(t.rows, []string{
+			strconv.Itoa(p.ID), p.Title, p.Difficulty,
+			fmt.Sprintf("%.1f%%", p.FirstFrequency), fmt.Sprintf("%.1f%%", p.SecondFrequency), p.URL,
+		})
+	}
+
+	return a.render(t)
+}
+
+func runRandom(a *app, args []string) error {
+	var seed int64
+	args, err := a.parseFlags("random", args, func(flags *flag.FlagSet) {
+		flags.Int64Var(&seed, "seed", 0, "random seed for a reproducible pick (default: current time)")
+	})
+	if err != nil {
+		return err
+	}
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	rng := rand.New(rand.NewSource(seed))
+
+	// the limit narrows the pool to the most popular problems
+	limit := a.options.filter.Limit
+	a.options.filter.Limit = 0
+
+	var pool []data.Problem
+	if len(args) > 0 {
+		companyInput, timeframeInput := splitTimeframe(args)
+		_, _, problems, err := a.companyProblems(companyInput, timeframeInput)
+		if err != nil {
+			return err
+		}
+		// a company's problems are already sorted by frequency
+		pool = problems
+	} else {
+		// across companies, a problem is as popular as the number of companies asking it
+		askedBy := make(map[int]int)
+		for _, timeframes := range a.problemsData.GetAllProblems() {
+			asked := make(map[int]bool)
+			for _, problems := range timeframes {
+				for _, p := range a.options.filter.Apply(problems) {
+					if asked[p.ID] {
+						continue
+					}
+					asked[p.ID] = true
+					if askedBy[p.ID] == 0 {
+						pool = append(pool, p)
+					}
+					askedBy[p.ID]++
+				}
+			}
+		}
+		// map iteration order is random, break ties by ID so -seed is reproducible
+		sort.Slice(pool, func(i, j int) bool {
+			if askedBy[pool[i].ID] != askedBy[pool[j].ID] {
+				return askedBy[pool[i].ID] > askedBy[pool[j].ID]
+			}
+			return pool[i].ID < pool[j].ID
+		})
+	}
+
+	if limit > 0 && len(pool) > limit {
+		pool = pool[:limit]
+	}
+	if len(pool) == 0 {
+		return fmt.Errorf("no problems match the filters")
+	}
+
+	return a.render(problemsTable([]data.Problem{pool[rng.Intn(len(pool))]}))
+}
+
+func runExport(a *app, args []string) error {
+	var formatName, file string
+	args, err := a.parseFlags("export", args, func(flags *flag.FlagSet) {
+		flags.StringVar(&formatName, "format", "csv", "file format: csv, json, md or anki")
+		flags.StringVar(&file, "file", "", "write to this file instead of stdout (\"auto\" picks a name)")
+	})
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	format, err := export.ParseFormat(formatName)
+	if err != nil {
+		return err
+	}
+
+	companyInput, timeframeInput := splitTimeframe(args)
+	company, timeframe, problems, err := a.companyProblems(companyInput, timeframeInput)
+	if err != nil {
+		return err
+	}
+
+	list := export.List{Company: company, Timeframe: timeframe, Problems: problems}
+
+	var buf bytes.Buffer
+	if err := export.Render(&buf, format, list); err != nil {
+		return err
+	}
+
+	if file == "" {
+		_, err := a.stdout.Write(buf.Bytes())
+		return err
+	}
+	if file == "auto" {
+		file = export.Filename(list, format)
+	}
+	if err := os.WriteFile(file, buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stderr, "Wrote %d problems to %s\n", len(problems), file)
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/whotypes/leetbot/internal/data"
+)
+
+func TestRandomLimitPicksPopular(t *testing.T) {
+	problemsData, err := data.LoadAllProblems()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// count the companies asking each problem, like runRandom
+	askedBy := make(map[int]int)
+	for _, timeframes := range problemsData.GetAllProblems() {
+		asked := make(map[int]bool)
+		for _, problems := range timeframes {
+			for _, p := range problems {
+				asked[p.ID] = true
+			}
+		}
+		for id := range asked {
+			askedBy[id]++
+		}
+	}
+	counts := make([]int, 0, len(askedBy))
+	for _, count := range askedBy {
+		counts = append(counts, count)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(counts)))
+	// ties at the cut are broken by ID, anything asked this often may be picked
+	least := counts[4]
+
+	for seed := 1; seed <= 10; seed++ {
+		var stdout, stderr bytes.Buffer
+		args := []string{"random", "-limit", "5", "-seed", fmt.Sprint(seed), "-o", "json"}
+		if code := run(args, &stdout, &stderr); code != 0 {
+			t.Fatalf("run(%q) = %d: %s", args, code, stderr.String())
+		}
+		var picked []struct {
+			ID int `json:"id"`
+		}
+		if err := json.Unmarshal(stdout.Bytes(), &picked); err != nil || len(picked) != 1 {
+			t.Fatalf("random output %q isn't one problem: %v", stdout.String(), err)
+		}
+		if got := askedBy[picked[0].ID]; got < least {
+			t.Errorf("seed %d picked #%d, asked by %d companies, want one of the 5 most asked (at least %d)",
+				seed, picked[0].ID, got, least)
+		}
+	}
+}
+
+func TestExportReportsToStderr(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "google.csv")
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"export", "google", "-file", file}, &stdout, &stderr); code != 0 {
+		t.Fatalf("export failed with %d: %s", code, stderr.String())
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("stdout = %q, want nothing when writing to a file", stdout.String())
+	}
+	if !strings.HasPrefix(stderr.String(), "Wrote ") || !strings.Contains(stderr.String(), file) {
+		t.Errorf("stderr = %q, want the written file reported", stderr.String())
+	}
+}
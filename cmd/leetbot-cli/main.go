@@ -0,0 +1,173 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/whotypes/leetbot/internal/data"
+	"github.com/whotypes/leetbot/internal/discord"
+)
+
+// errUsage marks errors caused by bad arguments, they exit with status 2
+var errUsage = errors.New("usage error")
+
+// command is a leetbot-cli subcommand
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(app *app, args []string) error
+}
+
+var commands = []command{
+	{"companies", "", "List every company in the dataset", runCompanies},
+	{"problems", "<company> [timeframe]", "Show a company's most popular problems", runProblems},
+	{"problem", "<id>", "Show a problem and the companies that ask it", runProblem},
+	{"search", "<query>", "Search problems by title, keywords or number", runSearch},
+	{"compare", "<company> <company> [timeframe]", "Show problems two companies have in common", runCompare},
+	{"random", "[company] [timeframe]", "Pick a random problem", runRandom},
+	{"export", "<company> [timeframe]", "Write a problem list as CSV, JSON, Markdown or an Anki deck", runExport},
+}
+
+// app holds what every subcommand needs
+type app struct {
+	problemsData *data.ProblemsByCompany
+	stdout       io.Writer
+	// stderr gets progress and status messages, keeping stdout for results
+	stderr  io.Writer
+	options outputOptions
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printUsage(stdout)
+		return 0
+	}
+
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == args[0] {
+			cmd = &commands[i]
+			break
+		}
+	}
+	if cmd == nil {
+		fmt.Fprintf(stderr, "Unknown command: %s\n\n", args[0])
+		printUsage(stderr)
+		return 2
+	}
+
+	// company lookups never leave the machine
+	discord.DisableCompanyEnrich()
+
+	problemsData, err := data.LoadAllProblems()
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to load problems data: %v\n", err)
+		return 1
+	}
+
+	a := &app{problemsData: problemsData, stdout: stdout, stderr: stderr}
+	if err := cmd.run(a, args[1:]); err != nil {
+		if errors.Is(err, errUsage) {
+			fmt.Fprintf(stderr, "Usage: leetbot-cli %s %s\n", cmd.name, cmd.args)
+			return 2
+		}
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "leetbot-cli - browse company interview problems offline")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Usage: leetbot-cli <command> [flags] [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-10s %-34s %s\n", cmd.name, cmd.args, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Common flags:")
+	fmt.Fprintln(w, "  -o, -output table|json|csv   output format (default table)")
+	fmt.Fprintln(w, "  -limit n                     maximum number of rows")
+	fmt.Fprintln(w, "  -difficulty easy,medium      only show these difficulties")
+	fmt.Fprintln(w, "  -no-color                    disable colors (also NO_COLOR=1)")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Examples:")
+	fmt.Fprintln(w, "  leetbot-cli problems google 30d")
+	fmt.Fprintln(w, "  leetbot-cli problems \"jane street\" -difficulty hard -limit 10")
+	fmt.Fprintln(w, "  leetbot-cli search median stream -o json")
+	fmt.Fprintln(w, "  leetbot-cli compare google meta")
+	fmt.Fprintln(w, "  leetbot-cli export google -format anki -file google.txt")
+}
+
+// parseFlags parses the common output flags plus any command specific ones
+// registered by extra, and returns the positional arguments
+func (a *app) parseFlags(name string, args []string, extra func(flags *flag.FlagSet)) ([]string, error) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+
+	format := "table"
+	flags.StringVar(&format, "output", format, "output format: table, json or csv")
+	flags.StringVar(&format, "o", format, "shorthand for -output")
+	limit := flags.Int("limit", 0, "maximum number of rows (0 for no limit)")
+	difficulty := flags.String("difficulty", "", "comma separated difficulties to show")
+	noColor := flags.Bool("no-color", false, "disable colored output")
+	if extra != nil {
+		extra(flags)
+	}
+
+	// flags may come before, between or after the arguments
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		args = flags.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+
+	outputFormat, err := parseOutputFormat(format)
+	if err != nil {
+		return nil, err
+	}
+
+	a.options = outputOptions{
+		format: outputFormat,
+		filter: data.Filter{
+			Difficulties: data.ParseDifficulties(*difficulty),
+			Limit:        *limit,
+		},
+		color: !*noColor && colorSupported(a.stdout),
+	}
+
+	return positional, nil
+}
+
+// resolveCompany finds a company with the bot's fuzzy matching
+func (a *app) resolveCompany(input string) (string, error) {
+	company, found, suggestions := discord.ResolveCompany(input, a.problemsData)
+	if found {
+		return company, nil
+	}
+	if len(suggestions) > 0 {
+		return "", fmt.Errorf("could not find company matching '%s', did you mean: %s", input, strings.Join(suggestions, ", "))
+	}
+	return "", fmt.Errorf("could not find company matching '%s'", input)
+}
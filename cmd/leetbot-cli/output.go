@@ -0,0 +1,177 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/whotypes/leetbot/internal/data"
+)
+
+type outputFormat string
+
+const (
+	outputTable outputFormat = "table"
+	outputJSON  outputFormat = "json"
+	outputCSV   outputFormat = "csv"
+)
+
+// outputOptions are the common flags shared by every subcommand
+type outputOptions struct {
+	format outputFormat
+	filter data.Filter
+	color  bool
+}
+
+func parseOutputFormat(s string) (outputFormat, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "table":
+		return outputTable, nil
+	case "json":
+		return outputJSON, nil
+	case "csv":
+		return outputCSV, nil
+	}
+	return "", fmt.Errorf("unknown output format %q, expected table, json or csv", s)
+}
+
+// colorSupported reports whether w is a terminal that should get ANSI colors
+func colorSupported(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+const (
+	ansiReset  = "\x1b[0m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+	ansiRed    = "\x1b[31m"
+	ansiBold   = "\x1b[1m"
+)
+
+// colorDifficulty colors a difficulty like the bot's 🟢🟡🔴 indicators.
+// Every color code has the same length so tabwriter columns stay aligned.
+func (o outputOptions) colorDifficulty(difficulty string) string {
+	if !o.color {
+		return difficulty
+	}
+	switch strings.ToLower(difficulty) {
+	case "easy":
+		return ansiGreen + difficulty + ansiReset
+	case "medium":
+		return ansiYellow + difficulty + ansiReset
+	case "hard":
+		return ansiRed + difficulty + ansiReset
+	}
+	return difficulty
+}
+
+func (o outputOptions) bold(s string) string {
+	if !o.color {
+		return s
+	}
+	return ansiBold + s + ansiReset
+}
+
+// table is tabular output; value is what gets encoded for -o json
+type table struct {
+	headers []string
+	rows    [][]string
+	// difficultyColumn is colored in table output, -1 for none
+	difficultyColumn int
+	value            interface{}
+}
+
+func (a *app) render(t table) error {
+	switch a.options.format {
+	case outputJSON:
+		encoder := json.NewEncoder(a.stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(t.value)
+	case outputCSV:
+		writer := csv.NewWriter(a.stdout)
+		if err := writer.Write(t.headers); err != nil {
+			return err
+		}
+		if err := writer.WriteAll(t.rows); err != nil {
+			return err
+		}
+		writer.Flush()
+		return writer.Error()
+	}
+
+	writer := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+	headers := make([]string, len(t.headers))
+	for i, header := range t.headers {
+		headers[i] = strings.ToUpper(header)
+	}
+	fmt.Fprintln(writer, strings.Join(headers, "\t"))
+	for _, row := range t.rows {
+		cells := append([]string(nil), row...)
+		if t.difficultyColumn >= 0 && t.difficultyColumn < len(cells) {
+			cells[t.difficultyColumn] = a.options.colorDifficulty(cells[t.difficultyColumn])
+		}
+		fmt.Fprintln(writer, strings.Join(cells, "\t"))
+	}
+	return writer.Flush()
+}
+
+// jsonProblem is the JSON shape of a problem, matching the HTTP API
+type jsonProblem struct {
+	ID         int     `json:"id"`
+	URL        string  `json:"url"`
+	Title      string  `json:"title"`
+	Difficulty string  `json:"difficulty"`
+	Acceptance float64 `json:"acceptance"`
+	Frequency  float64 `json:"frequency"`
+	Source     string  `json:"source,omitempty"`
+}
+
+func toJSONProblem(p data.Problem) jsonProblem {
+	return jsonProblem{
+		ID:         p.ID,
+		URL:        p.URL,
+		Title:      p.Title,
+		Difficulty: p.Difficulty,
+		Acceptance: p.Acceptance,
+		Frequency:  p.Frequency,
+		Source:     p.Source,
+	}
+}
+
+// problemsTable renders a ranked problem list
+func problemsTable(problems []data.Problem) table {
+	t := table{
+		headers:          []string{"#", "id", "title", "difficulty", "frequency", "acceptance", "url"},
+		difficultyColumn: 3,
+	}
+	values := make([]jsonProblem, 0, len(problems))
+	for i, p := range problems {
+		t.rows = append(t.rows, []string{
+			fmt.Sprint(i + 1),
+			fmt.Sprint(p.ID),
+			p.Title,
+			p.Difficulty,
+			fmt.Sprintf("%.1f%%", p.Frequency),
+			fmt.Sprintf("%.1f%%", p.Acceptance),
+			p.URL,
+		})
+		values = append(values, toJSONProblem(p))
+	}
+	t.value = values
+	return t
+}
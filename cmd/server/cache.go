@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/whotypes/leetbot/internal/data"
+	"github.com/whotypes/leetbot/internal/export"
+)
+
+// precomputedPaths are cached at startup, the rest on first request
+var precomputedPaths = []string{
+	"/api/companies",
+	"/api/all-problems",
+	"/api/dataset/version",
+	"/api/v2/companies",
+	"/api/v2/problems",
+}
+
+// cacheKey keys responses on the request path when it's the canonical one.
+// Handlers accept /Google/, 30d or an ID with leading zeros too, but caching
+// each spelling would let clients fill the cache with copies of one response,
+// so those are answered uncached. Exports asked for with an Accept header
+// share the path of the JSON response and pass through as well.
+func cacheKey(r *http.Request) (string, bool) {
+	if _, ok := export.FormatFromAccept(r.Header.Get("Accept")); ok {
+		return "", false
+	}
+	for name, value := range mux.Vars(r) {
+		var canonical bool
+		switch name {
+		case "company", "slug":
+			canonical = value == strings.ToLower(strings.TrimSpace(value)) && problemsData.CompanyExists(value)
+		case "timeframe":
+			resolved, ok := data.CanonicalTimeframe(value)
+			canonical = ok && resolved == value
+		case "id":
+			id, err := strconv.Atoi(value)
+			canonical = err == nil && strconv.Itoa(id) == value
+		}
+		if !canonical {
+			return "", false
+		}
+	}
+	return r.URL.Path, true
+}
+
+// successfulResponse keeps API responses that found something. Errors and
+// lookups of unknown companies aren't cached, so made up paths can't fill
+// the cache.
+func successfulResponse(status int, body []byte) bool {
+	return status == http.StatusOK && bytes.HasPrefix(body, []byte(`{"success":true`))
+}
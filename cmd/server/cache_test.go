@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/whotypes/leetbot/internal/config"
+	"github.com/whotypes/leetbot/internal/data"
+	"github.com/whotypes/leetbot/internal/httpcache"
+)
+
+var loadOnce sync.Once
+
+// testRouter loads the embedded dataset once and returns a router with a
+// fresh response cache
+func testRouter(tb testing.TB) (*mux.Router, *httpcache.Cache) {
+	tb.Helper()
+	loadOnce.Do(func() {
+		var err error
+		problemsData, err = data.LoadWithOverlays(nil, false)
+		if err != nil {
+			tb.Fatal(err)
+		}
+	})
+	cache := httpcache.New(problemsData.Version().Version)
+	router, err := newRouter(cache, config.Defaults().Server.WebDir)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return router, cache
+}
+
+func TestCachedMatchesUncached(t *testing.T) {
+	router, _ := testRouter(t)
+
+	for _, path := range []string{"/api/companies", "/api/all-problems", "/api/companies/google/problems"} {
+		t.Run(path, func(t *testing.T) {
+			uncached := httptest.NewRecorder()
+			switch path {
+			case "/api/companies":
+				getCompanies(uncached, httptest.NewRequest(http.MethodGet, path, nil))
+			case "/api/all-problems":
+				getAllProblems(uncached, httptest.NewRequest(http.MethodGet, path, nil))
+			default:
+				req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, path, nil), map[string]string{"company": "google"})
+				getProblems(uncached, req)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			req.Header.Set("Accept-Encoding", "gzip")
+			cached := httptest.NewRecorder()
+			router.ServeHTTP(cached, req)
+
+			if cached.Header().Get("Content-Encoding") != "gzip" {
+				t.Fatalf("expected a gzip response, got %q", cached.Header().Get("Content-Encoding"))
+			}
+			zr, err := gzip.NewReader(cached.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, err := io.ReadAll(zr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(body, uncached.Body.Bytes()) {
+				t.Error("the cached body differs from the handler's")
+			}
+
+			revalidate := httptest.NewRequest(http.MethodGet, path, nil)
+			revalidate.Header.Set("If-None-Match", cached.Header().Get("ETag"))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, revalidate)
+			if rec.Code != http.StatusNotModified {
+				t.Errorf("expected 304, got %d", rec.Code)
+			}
+		})
+	}
+}
+
+func TestUnknownCompanyNotCached(t *testing.T) {
+	router, cache := testRouter(t)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/companies/not-a-company/problems", nil))
+	if rec.Header().Get("ETag") != "" || cache.Len() != 0 {
+		t.Error("lookups of unknown companies shouldn't be cached")
+	}
+}
+
+func TestTimeframeExportIsCanonical(t *testing.T) {
+	router, _ := testRouter(t)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/companies/google/timeframes/30d/problems?format=csv", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got %d: %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Disposition"); !strings.Contains(got, "thirty-days") || strings.Contains(got, "30d") {
+		t.Errorf("Content-Disposition = %q, want the canonical timeframe", got)
+	}
+}
+
+func TestInfoNotCached(t *testing.T) {
+	router, cache := testRouter(t)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/info", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Cache-Control") != "no-store" {
+		t.Errorf("/api/info got %d with Cache-Control %q, want no-store", rec.Code, rec.Header().Get("Cache-Control"))
+	}
+	if rec.Header().Get("ETag") != "" || cache.Len() != 0 {
+		t.Error("/api/info shouldn't be cached")
+	}
+}
+
+func TestExportNotServedFromCache(t *testing.T) {
+	router, cache := testRouter(t)
+
+	for _, path := range []string{
+		"/api/companies/google/problems",
+		"/api/companies/google/timeframes/all/problems",
+		"/api/v2/companies/google/problems",
+	} {
+		t.Run(path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+			if rec.Header().Get("ETag") == "" || !strings.HasPrefix(rec.Header().Get("Content-Type"), "application/json") {
+				t.Fatalf("expected a cached JSON response, got %q", rec.Header().Get("Content-Type"))
+			}
+
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			req.Header.Set("Accept", "text/csv")
+			rec = httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/csv") {
+				t.Errorf("Accept: text/csv got %d %q, want the CSV export", rec.Code, rec.Header().Get("Content-Type"))
+			}
+			if !slices.Contains(rec.Header().Values("Vary"), "Accept") {
+				t.Errorf("Vary = %v, want Accept", rec.Header().Values("Vary"))
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/companies/google/problems", nil))
+	if !slices.Contains(rec.Header().Values("Vary"), "Accept") {
+		t.Errorf("cached Vary = %v, want Accept", rec.Header().Values("Vary"))
+	}
+	if cache.Len() != 3 {
+		t.Errorf("expected only the 3 JSON responses cached, got %d entries", cache.Len())
+	}
+}
+
+func TestVariantsShareEntry(t *testing.T) {
+	router, cache := testRouter(t)
+
+	for _, path := range []string{
+		"/api/companies/google/problems",
+		"/api/companies/Google/problems",
+		"/api/companies/GOOGLE/problems",
+		"/api/companies/gOOgle/problems",
+		"/api/companies/google/timeframes/all/problems",
+		"/api/companies/google/timeframes/ALL/problems",
+		"/api/v2/companies/facebook",
+		"/api/v2/companies/meta",
+		"/api/v2/companies/Facebook",
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: got %d, want 200", path, rec.Code)
+		}
+	}
+	if cache.Len() != 3 {
+		t.Errorf("expected one entry per canonical path, got %d", cache.Len())
+	}
+}
+
+// the Uncached benchmarks call the handlers directly, as every request did
+// before responses were cached
+
+func BenchmarkAllProblemsUncached(b *testing.B) {
+	testRouter(b)
+	req := httptest.NewRequest(http.MethodGet, "/api/all-problems", nil)
+	b.ReportAllocs()
+	for b.Loop() {
+		getAllProblems(discardWriter{}, req)
+	}
+}
+
+func BenchmarkAllProblemsCached(b *testing.B) {
+	benchmarkCached(b, "/api/all-problems", "")
+}
+
+func BenchmarkAllProblemsCachedBrotli(b *testing.B) {
+	benchmarkCached(b, "/api/all-problems", "br")
+}
+
+func BenchmarkAllProblemsNotModified(b *testing.B) {
+	router, _ := testRouter(b)
+	httpcache.Warm(router, "/api/all-problems")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/all-problems", nil))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/all-problems", nil)
+	req.Header.Set("If-None-Match", rec.Header().Get("ETag"))
+	b.ReportAllocs()
+	for b.Loop() {
+		router.ServeHTTP(discardWriter{}, req)
+	}
+}
+
+func BenchmarkCompanyProblemsUncached(b *testing.B) {
+	testRouter(b)
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/api/companies/google/problems", nil), map[string]string{"company": "google"})
+	b.ReportAllocs()
+	for b.Loop() {
+		getProblems(discardWriter{}, req)
+	}
+}
+
+func BenchmarkCompanyProblemsCached(b *testing.B) {
+	benchmarkCached(b, "/api/companies/google/problems", "gzip")
+}
+
+func benchmarkCached(b *testing.B, path, acceptEncoding string) {
+	router, _ := testRouter(b)
+	httpcache.Warm(router, path)
+
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("Accept-Encoding", acceptEncoding)
+	b.ReportAllocs()
+	for b.Loop() {
+		router.ServeHTTP(discardWriter{}, req)
+	}
+}
+
+// discardWriter keeps the benchmarks from measuring a growing buffer
+type discardWriter struct{}
+
+func (discardWriter) Header() http.Header         { return make(http.Header) }
+func (discardWriter) WriteHeader(int)             {}
+func (discardWriter) Write(b []byte) (int, error) { return len(b), nil }
@@ -0,0 +1,336 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/whotypes/leetbot/internal/data"
+	"github.com/whotypes/leetbot/internal/graphql"
+)
+
+// graphQLLimits keep a single query from walking the whole dataset. A
+// dashboard asking for 20 companies with 10 problems per timeframe costs
+// about 3000.
+var graphQLLimits = graphql.Limits{MaxDepth: 10, MaxComplexity: 10000, MaxExpansions: 10000}
+
+// timeframeNode is a company's timeframe in the graph
+type timeframeNode struct {
+	company string
+	data.TimeframeSummary
+}
+
+// datasetNode is the dataset's version and difficulty breakdown
+type datasetNode struct {
+	data.DatasetVersion
+	Difficulty data.DifficultyBreakdown
+}
+
+// graphQLSchema describes companies, timeframes and problems, resolved from
+// the loaded dataset on every query
+func graphQLSchema() (*graphql.Schema, error) {
+	first := func(def int) *graphql.ArgDef {
+		return &graphql.ArgDef{Name: "first", Type: "Int", Default: def, Description: "Maximum number of items"}
+	}
+	offset := &graphql.ArgDef{Name: "offset", Type: "Int", Default: 0}
+	difficulty := &graphql.ArgDef{Name: "difficulty", Type: "[String!]", Description: "Keep only these difficulties: easy, medium or hard"}
+
+	difficultyBreakdown := &graphql.Object{
+		Name: "DifficultyBreakdown",
+		Fields: []*graphql.FieldDef{
+			{Name: "easy", Type: "Int!"},
+			{Name: "medium", Type: "Int!"},
+			{Name: "hard", Type: "Int!"},
+		},
+	}
+
+	problem := &graphql.Object{
+		Name:        "Problem",
+		Description: "A LeetCode problem",
+		Fields: []*graphql.FieldDef{
+			{Name: "id", Type: "Int!"},
+			{Name: "title", Type: "String!"},
+			{Name: "url", Type: "String!"},
+			{Name: "difficulty", Type: "String!"},
+			{Name: "acceptance", Type: "Float!"},
+			{Name: "frequency", Type: "Float!", Description: "Frequency at the company and timeframe the problem was listed for, or the highest across companies"},
+			{Name: "source", Type: "String!", Description: "Where the problem came from, leetcode for the bundled data"},
+			{Name: "companyCount", Type: "Int!", Description: "How many companies ask the problem",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					result, _ := problemsData.SearchIndex().Get(p.Source.(data.Problem).ID)
+					return result.Companies, nil
+				}},
+			{Name: "topCompanies", Type: "[String!]!", ListSize: 5, Description: "The companies where the problem is most frequent",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					result, _ := problemsData.SearchIndex().Get(p.Source.(data.Problem).ID)
+					return result.TopCompanies, nil
+				}},
+			{Name: "occurrences", Type: "[Occurrence!]!", Args: []*graphql.ArgDef{first(50)},
+				Description: "Every company and timeframe that lists the problem",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					n, err := countArg(p, "first")
+					if err != nil {
+						return nil, err
+					}
+					return limitSlice(problemsData.GetOccurrences(p.Source.(data.Problem).ID), 0, n), nil
+				}},
+			{Name: "similar", Type: "[SimilarProblem!]!", Args: []*graphql.ArgDef{first(5)},
+				Description: "Problems asked by the most of the same companies",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					n, err := countArg(p, "first")
+					if err != nil || n == 0 {
+						return nil, err
+					}
+					return problemsData.SimilarProblems(p.Source.(data.Problem).ID, n), nil
+				}},
+		},
+	}
+
+	timeframe := &graphql.Object{
+		Name: "Timeframe",
+		Fields: []*graphql.FieldDef{
+			{Name: "name", Type: "String!", Resolve: func(p graphql.ResolveParams) (any, error) {
+				return p.Source.(timeframeNode).Timeframe, nil
+			}},
+			{Name: "count", Type: "Int!"},
+			{Name: "difficulty", Type: "DifficultyBreakdown!"},
+			{Name: "problems", Type: "[Problem!]!", Args: []*graphql.ArgDef{difficulty, first(50)},
+				Description: "Problems by descending frequency",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					tf := p.Source.(timeframeNode)
+					filter, n, err := graphQLFilter(p)
+					if err != nil {
+						return nil, err
+					}
+					return limitSlice(filter.Apply(problemsData.GetProblems(tf.company, tf.Timeframe)), 0, n), nil
+				}},
+		},
+	}
+
+	company := &graphql.Object{
+		Name: "Company",
+		Fields: []*graphql.FieldDef{
+			{Name: "slug", Type: "String!", Resolve: func(p graphql.ResolveParams) (any, error) {
+				return p.Source.(*data.CompanySummary).Company, nil
+			}},
+			{Name: "name", Type: "String!", Description: "The display name, e.g. Meta for facebook",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return companyDisplayName(p.Source.(*data.CompanySummary).Company), nil
+				}},
+			{Name: "uniqueProblems", Type: "Int!"},
+			{Name: "defaultTimeframe", Type: "String!", Description: "The most recent timeframe with problems",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					_, timeframe := problemsData.GetProblemsWithPriority(p.Source.(*data.CompanySummary).Company)
+					return timeframe, nil
+				}},
+			{Name: "timeframes", Type: "[Timeframe!]!", ListSize: 5, Description: "Timeframes from most to least recent",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					summary := p.Source.(*data.CompanySummary)
+					nodes := make([]timeframeNode, len(summary.Timeframes))
+					for i, tf := range summary.Timeframes {
+						nodes[i] = timeframeNode{company: summary.Company, TimeframeSummary: tf}
+					}
+					return nodes, nil
+				}},
+			{Name: "timeframe", Type: "Timeframe", Args: []*graphql.ArgDef{{Name: "name", Type: "String!", Description: "A timeframe or alias like 30d"}},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					summary := p.Source.(*data.CompanySummary)
+					name, ok := data.CanonicalTimeframe(p.Args["name"].(string))
+					if !ok {
+						return nil, fmt.Errorf("unknown timeframe: %s", p.Args["name"])
+					}
+					for _, tf := range summary.Timeframes {
+						if tf.Timeframe == name {
+							return timeframeNode{company: summary.Company, TimeframeSummary: tf}, nil
+						}
+					}
+					return nil, nil
+				}},
+			{Name: "evergreen", Type: "[Problem!]!", Args: []*graphql.ArgDef{first(10)},
+				Description: "Problems asked in every timeframe other than all time",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					n, err := countArg(p, "first")
+					if err != nil {
+						return nil, err
+					}
+					return limitSlice(p.Source.(*data.CompanySummary).Evergreen, 0, n), nil
+				}},
+			{Name: "similar", Type: "[SimilarCompany!]!", Args: []*graphql.ArgDef{first(5)},
+				Description: "Companies asking the most of the same problems",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					n, err := countArg(p, "first")
+					if err != nil || n == 0 {
+						return nil, err
+					}
+					return problemsData.SimilarCompanies(p.Source.(*data.CompanySummary).Company, n), nil
+				}},
+		},
+	}
+
+	resolveCompany := func(p graphql.ResolveParams) (any, error) {
+		var slug string
+		switch source := p.Source.(type) {
+		case data.Occurrence:
+			slug = source.Company
+		case data.SimilarCompany:
+			slug = source.Company
+		}
+		return problemsData.GetCompanySummary(canonicalCompany(problemsData, slug), 0), nil
+	}
+
+	occurrence := &graphql.Object{
+		Name: "Occurrence",
+		Fields: []*graphql.FieldDef{
+			{Name: "company", Type: "Company!", Resolve: resolveCompany},
+			{Name: "timeframe", Type: "String!"},
+			{Name: "frequency", Type: "Float!"},
+		},
+	}
+	similarProblem := &graphql.Object{
+		Name: "SimilarProblem",
+		Fields: []*graphql.FieldDef{
+			{Name: "problem", Type: "Problem!"},
+			{Name: "score", Type: "Float!", Description: "Shared companies over the companies asking either problem"},
+			{Name: "sharedCompanies", Type: "Int!"},
+		},
+	}
+	similarCompany := &graphql.Object{
+		Name: "SimilarCompany",
+		Fields: []*graphql.FieldDef{
+			{Name: "company", Type: "Company!", Resolve: resolveCompany},
+			{Name: "score", Type: "Float!", Description: "Shared problems over the problems either company asks"},
+			{Name: "sharedProblems", Type: "Int!"},
+		},
+	}
+	searchResult := &graphql.Object{
+		Name: "SearchResult",
+		Fields: []*graphql.FieldDef{
+			{Name: "problem", Type: "Problem!"},
+			{Name: "score", Type: "Float!"},
+		},
+	}
+	dataset := &graphql.Object{
+		Name: "Dataset",
+		Fields: []*graphql.FieldDef{
+			{Name: "version", Type: "String!"},
+			{Name: "hash", Type: "String!"},
+			{Name: "companies", Type: "Int!"},
+			{Name: "problems", Type: "Int!", Description: "Problem listings across companies and timeframes"},
+			{Name: "uniqueProblems", Type: "Int!"},
+			{Name: "difficulty", Type: "DifficultyBreakdown!", Description: "Difficulties of the unique problems"},
+		},
+	}
+
+	query := &graphql.Object{
+		Name: "Query",
+		Fields: []*graphql.FieldDef{
+			{Name: "companies", Type: "[Company!]!", Args: []*graphql.ArgDef{first(50), offset},
+				Description: "Companies in alphabetical order",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					n, err := countArg(p, "first")
+					if err != nil {
+						return nil, err
+					}
+					skip, err := countArg(p, "offset")
+					if err != nil {
+						return nil, err
+					}
+					slugs := limitSlice(problemsData.GetAvailableCompanies(), skip, n)
+					companies := make([]*data.CompanySummary, 0, len(slugs))
+					for _, slug := range slugs {
+						if summary := problemsData.GetCompanySummary(slug, 0); summary != nil {
+							companies = append(companies, summary)
+						}
+					}
+					return companies, nil
+				}},
+			{Name: "company", Type: "Company", Args: []*graphql.ArgDef{{Name: "slug", Type: "String!"}},
+				Description: "A company by slug, alias, ticker or former name",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return problemsData.GetCompanySummary(canonicalCompany(problemsData, p.Args["slug"].(string)), 0), nil
+				}},
+			{Name: "problems", Type: "[Problem!]!", Args: []*graphql.ArgDef{difficulty, first(50), offset},
+				Description: "Every problem any company asks, ordered by ID",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					filter, n, err := graphQLFilter(p)
+					if err != nil {
+						return nil, err
+					}
+					skip, err := countArg(p, "offset")
+					if err != nil {
+						return nil, err
+					}
+					all := problemsData.SearchIndex().All()
+					problems := make([]data.Problem, len(all))
+					for i, result := range all {
+						problems[i] = result.Problem
+					}
+					return limitSlice(filter.Apply(problems), skip, n), nil
+				}},
+			{Name: "problem", Type: "Problem", Args: []*graphql.ArgDef{{Name: "id", Type: "Int!"}},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					result, ok := problemsData.SearchIndex().Get(p.Args["id"].(int))
+					if !ok {
+						return nil, nil
+					}
+					return result.Problem, nil
+				}},
+			{Name: "search", Type: "[SearchResult!]!", Args: []*graphql.ArgDef{{Name: "query", Type: "String!"}, first(defaultSearchLimit)},
+				Description: "Problems matching a title, slug or ID",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					n, err := countArg(p, "first")
+					if err != nil || n == 0 {
+						return nil, err
+					}
+					return problemsData.Search(p.Args["query"].(string), n), nil
+				}},
+			{Name: "dataset", Type: "Dataset!", Resolve: func(p graphql.ResolveParams) (any, error) {
+				node := datasetNode{DatasetVersion: problemsData.Version()}
+				for _, result := range problemsData.SearchIndex().All() {
+					switch strings.ToLower(result.Problem.Difficulty) {
+					case "easy":
+						node.Difficulty.Easy++
+					case "medium":
+						node.Difficulty.Medium++
+					case "hard":
+						node.Difficulty.Hard++
+					}
+				}
+				return node, nil
+			}},
+		},
+	}
+
+	return graphql.NewSchema(query, graphQLLimits,
+		company, timeframe, problem, occurrence, similarProblem, similarCompany, searchResult, dataset, difficultyBreakdown)
+}
+
+// countArg reads a non-negative integer argument, 0 when it's null
+func countArg(p graphql.ResolveParams, name string) (int, error) {
+	n, _ := p.Args[name].(int)
+	if n < 0 {
+		return 0, fmt.Errorf("%s can't be negative", name)
+	}
+	return n, nil
+}
+
+// graphQLFilter reads the difficulty argument and the first argument's count
+func graphQLFilter(p graphql.ResolveParams) (data.Filter, int, error) {
+	var filter data.Filter
+	difficulties, _ := p.Args["difficulty"].([]any)
+	for _, d := range difficulties {
+		difficulty := strings.ToLower(strings.TrimSpace(d.(string)))
+		if difficulty != "easy" && difficulty != "medium" && difficulty != "hard" {
+			return filter, 0, fmt.Errorf("unknown difficulty: %s", d)
+		}
+		filter.Difficulties = append(filter.Difficulties, difficulty)
+	}
+
+	n, err := countArg(p, "first")
+	return filter, n, err
+}
+
+// limitSlice returns up to n items after skipping offset
+func limitSlice[T any](items []T, offset, n int) []T {
+	items = items[min(offset, len(items)):]
+	return items[:min(n, len(items))]
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// graphQLQuery posts query to /graphql and decodes the response into out
+func graphQLQuery(t *testing.T, query string, out any) int {
+	t.Helper()
+	router, _ := testRouter(t)
+
+	body, _ := json.Marshal(map[string]string{"query": query})
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+		t.Fatalf("decoding %s: %v", rec.Body.String(), err)
+	}
+	return rec.Code
+}
+
+func TestGraphQLDashboard(t *testing.T) {
+	var resp struct {
+		Data struct {
+			Company struct {
+				Slug             string
+				DefaultTimeframe string
+				Timeframes       []struct {
+					Name     string
+					Count    int
+					Problems []struct {
+						ID         int
+						Difficulty string
+					}
+				}
+			}
+			Missing *struct{ Slug string }
+		}
+		Errors []struct{ Message string }
+	}
+	code := graphQLQuery(t, `{
+		company(slug: "google") {
+			slug
+			defaultTimeframe
+			timeframes { name count problems(first: 3, difficulty: ["hard"]) { id difficulty } }
+		}
+		missing: company(slug: "no-such-company") { slug }
+	}`, &resp)
+
+	if code != http.StatusOK || len(resp.Errors) > 0 {
+		t.Fatalf("expected 200 without errors, got %d: %v", code, resp.Errors)
+	}
+	company := resp.Data.Company
+	if company.Slug != "google" || company.DefaultTimeframe != "thirty-days" {
+		t.Errorf("unexpected company %q with default timeframe %q", company.Slug, company.DefaultTimeframe)
+	}
+	if len(company.Timeframes) == 0 || company.Timeframes[0].Name != "thirty-days" {
+		t.Fatalf("expected timeframes starting with thirty-days, got %+v", company.Timeframes)
+	}
+	for _, tf := range company.Timeframes {
+		if tf.Count == 0 || len(tf.Problems) == 0 || len(tf.Problems) > 3 {
+			t.Errorf("%s: unexpected count %d with %d problems", tf.Name, tf.Count, len(tf.Problems))
+		}
+		for _, p := range tf.Problems {
+			if p.Difficulty != "Hard" {
+				t.Errorf("%s: problem %d is %s, want Hard", tf.Name, p.ID, p.Difficulty)
+			}
+		}
+	}
+	if resp.Data.Missing != nil {
+		t.Errorf("expected null for an unknown company, got %+v", resp.Data.Missing)
+	}
+}
+
+func TestGraphQLCompanyNames(t *testing.T) {
+	var resp struct {
+		Data struct {
+			Alias      *struct{ Slug string }
+			FormerName *struct{ Slug string }
+		}
+		Errors []struct{ Message string }
+	}
+	code := graphQLQuery(t, `{
+		alias: company(slug: "meta") { slug }
+		formerName: company(slug: "Grofers") { slug }
+	}`, &resp)
+
+	if code != http.StatusOK || len(resp.Errors) > 0 {
+		t.Fatalf("expected 200 without errors, got %d: %v", code, resp.Errors)
+	}
+	if resp.Data.Alias == nil || resp.Data.Alias.Slug != "facebook" {
+		t.Errorf("expected meta to resolve to facebook, got %+v", resp.Data.Alias)
+	}
+	if resp.Data.FormerName == nil || resp.Data.FormerName.Slug != "blinkit" {
+		t.Errorf("expected Grofers to resolve to blinkit, got %+v", resp.Data.FormerName)
+	}
+}
+
+func TestGraphQLSimilar(t *testing.T) {
+	var resp struct {
+		Data struct {
+			Problem struct {
+				Similar []struct {
+					Problem         struct{ ID int }
+					Score           float64
+					SharedCompanies int
+				}
+			}
+		}
+		Errors []struct{ Message string }
+	}
+	graphQLQuery(t, `{ problem(id: 1) { similar(first: 3) { problem { id } score sharedCompanies } } }`, &resp)
+
+	similar := resp.Data.Problem.Similar
+	if len(resp.Errors) > 0 || len(similar) != 3 {
+		t.Fatalf("expected 3 similar problems, got %+v with errors %v", similar, resp.Errors)
+	}
+	for i, s := range similar {
+		if s.Problem.ID == 1 || s.Score <= 0 || s.Score > 1 || s.SharedCompanies == 0 {
+			t.Errorf("unexpected similar problem %+v", s)
+		}
+		if i > 0 && s.Score > similar[i-1].Score {
+			t.Errorf("similar problems aren't ordered by score: %+v", similar)
+		}
+	}
+}
+
+func TestGraphQLErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		code    int
+		message string
+	}{
+		{"too costly", `{ companies(first: 500) { timeframes { problems(first: 100) { id } } } }`, http.StatusBadRequest, "more than the limit"},
+		{"unknown field", `{ company(slug: "google") { tags } }`, http.StatusBadRequest, "tags"},
+		{"negative first", `{ companies(first: -1) { slug } }`, http.StatusBadRequest, "can't be negative"},
+		{"unknown difficulty", `{ problems(difficulty: ["trivial"]) { id } }`, http.StatusBadRequest, "unknown difficulty"},
+		{"unknown timeframe", `{ company(slug: "google") { timeframe(name: "decade") { count } } }`, http.StatusOK, "unknown timeframe"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp struct {
+				Errors []struct{ Message string }
+			}
+			code := graphQLQuery(t, tt.query, &resp)
+			if code != tt.code {
+				t.Errorf("expected %d, got %d", tt.code, code)
+			}
+			if len(resp.Errors) == 0 || !strings.Contains(resp.Errors[0].Message, tt.message) {
+				t.Errorf("expected an error containing %q, got %v", tt.message, resp.Errors)
+			}
+		})
+	}
+}
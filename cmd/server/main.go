@@ -1,88 +1,74 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
-	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
+	"github.com/whotypes/leetbot/internal/config"
 	"github.com/whotypes/leetbot/internal/data"
+	"github.com/whotypes/leetbot/internal/export"
+	"github.com/whotypes/leetbot/internal/health"
+	"github.com/whotypes/leetbot/internal/httpcache"
+	"github.com/whotypes/leetbot/internal/i18n"
+	"github.com/whotypes/leetbot/internal/logging"
+	"github.com/whotypes/leetbot/internal/metrics"
+	"github.com/whotypes/leetbot/pkg/api"
 )
 
-type APIResponse struct {
-	Success bool        `json:"success"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
-}
-
-type CompanyData struct {
-	Company    string               `json:"company"`
-	Timeframes map[string][]Problem `json:"timeframes"`
-}
-
-type Problem struct {
-	ID         int     `json:"id"`
-	URL        string  `json:"url"`
-	Title      string  `json:"title"`
-	Difficulty string  `json:"difficulty"`
-	Acceptance float64 `json:"acceptance"`
-	Frequency  float64 `json:"frequency"`
-}
-
-type CompaniesList struct {
-	Companies []string `json:"companies"`
-}
-
-type TimeframesList struct {
-	Timeframes []string `json:"timeframes"`
-}
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
 
 var problemsData *data.ProblemsByCompany
 
 func main() {
+	settings := config.MustLoad("server", os.Args[1:])
+	logging.Setup(settings.Log.Options())
+	cfg := &settings.Server
+
 	var err error
-	problemsData, err = data.LoadAllProblems()
+	problemsData, err = data.LoadWithOverlays(settings.Data.Overlays, settings.Data.Strict)
 	if err != nil {
-		log.Fatalf("Failed to load problems data: %v", err)
+		fatal("failed to load problems data", err)
 	}
 
-	r := mux.NewRouter()
+	// responses only change with the dataset, so they're computed once
+	responseCache := httpcache.New(problemsData.Version().Version)
+	r, err := newRouter(responseCache, cfg.WebDir)
+	if err != nil {
+		fatal("failed to set up routes", err)
+	}
+
+	start := time.Now()
+	httpcache.Warm(r, precomputedPaths...)
+	slog.Info("precomputed responses", "count", responseCache.Len(), logging.Latency(start))
 
-	api := r.PathPrefix("/api").Subrouter()
-	api.HandleFunc("/companies", getCompanies).Methods("GET")
-	api.HandleFunc("/companies/{company}/timeframes", getTimeframes).Methods("GET")
-	api.HandleFunc("/companies/{company}/problems", getProblems).Methods("GET")
-	api.HandleFunc("/companies/{company}/timeframes/{timeframe}/problems", getProblemsByTimeframe).Methods("GET")
-	api.HandleFunc("/all-problems", getAllProblems).Methods("GET")
-
-	r.PathPrefix("/").Handler(http.FileServer(http.Dir("./web/dist/")))
-
-	corsHandler := handlers.CORS(
-		handlers.AllowedOrigins([]string{"*"}),
-		handlers.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
-		handlers.AllowedHeaders([]string{"X-Requested-With", "Content-Type", "Authorization"}),
-	)(r)
-
-	// cloud run listens on 8080, lets expose $PORT
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	// SIGHUP applies changes to the middleware settings without a restart
+	handler, err := newReloadableHandler(r, settings)
+	if err != nil {
+		fatal("invalid server configuration", err)
 	}
+	handler.watchSignals(os.Args[1:])
 
-	// Create HTTP server with timeouts
 	srv := &http.Server{
-		Addr:         ":" + port,
-		Handler:      corsHandler,
-		ReadTimeout:  30 * time.Second,
-		WriteTimeout: 30 * time.Second,
-		IdleTimeout:  120 * time.Second,
+		Addr:         cfg.Addr,
+		Handler:      requestLogger(handler),
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		IdleTimeout:  cfg.IdleTimeout,
 	}
 
 	// Channel to listen for interrupt signal
@@ -91,16 +77,21 @@ func main() {
 
 	// Start server in a goroutine
 	go func() {
-		fmt.Printf("Server starting on port %s\n", port)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Server failed to start: %v", err)
+		slog.Info("server starting", "addr", cfg.Addr, "tls", cfg.TLS())
+		var err error
+		if cfg.TLS() {
+			err = srv.ListenAndServeTLS(cfg.TLSCertFile, cfg.TLSKeyFile)
+		} else {
+			err = srv.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
+			fatal("server failed to start", err)
 		}
 	}()
 
 	// Wait for interrupt signal
-	fmt.Println("Server is running. Press CTRL-C to exit.")
 	<-done
-	fmt.Println("Shutting down server...")
+	slog.Info("shutting down server")
 
 	// Create a context with timeout for graceful shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -108,175 +99,342 @@ func main() {
 
 	// Attempt graceful shutdown
 	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+		fatal("server forced to shutdown", err)
 	}
 
-	fmt.Println("Server exited")
+	slog.Info("server exited")
 }
 
-func getCompanies(w http.ResponseWriter, r *http.Request) {
-	companies := problemsData.GetAvailableCompanies()
+// newRouter sets up the API, GraphQL, probe and frontend routes, serving the
+// frontend from webDir unless it's compiled in. API responses without a
+// query string are served from the cache.
+func newRouter(responseCache *httpcache.Cache, webDir string) (*mux.Router, error) {
+	spec, err := openAPIHandler()
+	if err != nil {
+		return nil, err
+	}
 
-	response := APIResponse{
-		Success: true,
-		Data:    CompaniesList{Companies: companies},
+	schema, err := graphQLSchema()
+	if err != nil {
+		return nil, err
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
-		return
+	r := mux.NewRouter()
+
+	// the document is served as is, it's already a cache entry
+	r.Handle("/api/openapi.json", spec).Methods("GET")
+
+	// the cache wraps each handler rather than the subrouter, so the headers
+	// of deprecated routes are set on cached responses too
+	cached := responseCache.Middleware(cacheKey, successfulResponse)
+	apiRouter := r.PathPrefix("/api").Subrouter()
+	for _, route := range apiRoutes {
+		handler := cached(route.handler)
+		if route.successor != "" {
+			handler = deprecated(route.successor, handler)
+		}
+		apiRouter.Handle(route.path, handler).Methods("GET")
 	}
+
+	// queries are arbitrary, so GraphQL responses aren't cached
+	r.Handle("/graphql", schema.Handler()).Methods("GET", "HEAD", "POST")
+
+	checker := health.NewChecker()
+	checker.Add("data", health.DataLoaded(currentProblems))
+	r.HandleFunc("/healthz", health.Healthz).Methods("GET")
+	r.HandleFunc("/readyz", checker.Readyz).Methods("GET")
+
+	r.Handle("/metrics", metrics.Handler()).Methods("GET")
+
+	files, source := webFiles(webDir)
+	slog.Debug("serving frontend", "from", source)
+	r.PathPrefix("/").Handler(newSPAHandler(files))
+
+	return r, nil
 }
 
-func getTimeframes(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	company := vars["company"]
+// currentProblems returns the loaded dataset for health checks and /api/info
+func currentProblems() *data.ProblemsByCompany {
+	return problemsData
+}
 
-	timeframes := problemsData.GetAvailableTimeframes(company)
+// fatal logs an error that stops the server and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, logging.Err(err))
+	os.Exit(1)
+}
 
-	response := APIResponse{
+func getCompanies(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, api.Response[api.CompaniesList]{
 		Success: true,
-		Data:    TimeframesList{Timeframes: timeframes},
-	}
+		Data:    api.CompaniesList{Companies: problemsData.GetAvailableCompanies()},
+	})
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
-		return
-	}
+func getTimeframes(w http.ResponseWriter, r *http.Request) {
+	company := requestedCompany(r)
+
+	writeJSON(w, http.StatusOK, api.Response[api.TimeframesList]{
+		Success: true,
+		Data:    api.TimeframesList{Timeframes: problemsData.GetAvailableTimeframes(company)},
+	})
 }
 
 func getProblems(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	company := vars["company"]
+	company := requestedCompany(r)
+
+	format, wantsExport, err := requestedExportFormat(w, r)
+	if err != nil {
+		writeBadRequest(w, err.Error())
+		return
+	}
 
 	// Get problems with priority (most recent timeframe with data)
 	problems, timeframe := problemsData.GetProblemsWithPriority(company)
+	if problems == nil {
+		writeNotFound(w, printer(w, r).T("api.no_problems_company", company))
+		return
+	}
 
+	writeProblems(w, r, company, timeframe, problems, format, wantsExport)
+}
+
+func getProblemsByTimeframe(w http.ResponseWriter, r *http.Request) {
+	company := requestedCompany(r)
+	// 30d and the other spellings are named by their canonical timeframe, in
+	// the response and the export's filename
+	timeframe := mux.Vars(r)["timeframe"]
+	if canonical, ok := data.CanonicalTimeframe(timeframe); ok {
+		timeframe = canonical
+	}
+
+	format, wantsExport, err := requestedExportFormat(w, r)
+	if err != nil {
+		writeBadRequest(w, err.Error())
+		return
+	}
+
+	problems := problemsData.GetProblems(company, timeframe)
 	if problems == nil {
-		response := APIResponse{
-			Success: false,
-			Error:   "No problems found for company: " + company,
-		}
-		w.Header().Set("Content-Type", "application/json")
-		if err := json.NewEncoder(w).Encode(response); err != nil {
-			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
-			return
-		}
+		writeNotFound(w, printer(w, r).T("api.no_problems_timeframe", company, timeframe))
 		return
 	}
 
-	// Convert to our API format
-	apiProblems := make([]Problem, len(problems))
-	for i, p := range problems {
-		apiProblems[i] = Problem{
-			ID:         p.ID,
-			URL:        p.URL,
-			Title:      p.Title,
-			Difficulty: p.Difficulty,
-			Acceptance: p.Acceptance,
-			Frequency:  p.Frequency,
-		}
+	writeProblems(w, r, company, timeframe, problems, format, wantsExport)
+}
+
+// writeProblems filters a problem list and writes it as JSON or as an export
+func writeProblems(w http.ResponseWriter, r *http.Request, company, timeframe string, problems []data.Problem, format export.Format, wantsExport bool) {
+	problems = problemFilterFromRequest(r).Apply(problems)
+
+	if wantsExport {
+		writeExport(w, r, format, export.List{Company: company, Timeframe: timeframe, Problems: problems})
+		return
 	}
 
-	response := APIResponse{
+	apiProblems := toAPIProblems(problems)
+	writeJSON(w, http.StatusOK, api.Response[api.ProblemsList]{
 		Success: true,
-		Data: map[string]interface{}{
-			"company":   company,
-			"timeframe": timeframe,
-			"problems":  apiProblems,
-			"count":     len(apiProblems),
+		Data: api.ProblemsList{
+			Company:   company,
+			Timeframe: timeframe,
+			Problems:  apiProblems,
+			Count:     len(apiProblems),
 		},
+	})
+}
+
+func getAllProblems(w http.ResponseWriter, r *http.Request) {
+	allProblems := problemsData.GetAllProblems()
+
+	allProblemsMap := make(api.AllProblems, len(allProblems))
+	for company, timeframes := range allProblems {
+		allProblemsMap[company] = make(map[string][]api.Problem, len(timeframes))
+		for timeframe, problems := range timeframes {
+			allProblemsMap[company][timeframe] = toAPIProblems(problems)
+		}
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	writeJSON(w, http.StatusOK, api.Response[api.AllProblems]{Success: true, Data: allProblemsMap})
+}
+
+func getCompanySummary(w http.ResponseWriter, r *http.Request) {
+	company := requestedCompany(r)
+
+	summary := problemsData.GetCompanySummary(company, 5)
+	if summary == nil {
+		writeNotFound(w, printer(w, r).T("api.no_problems_company", company))
 		return
 	}
+
+	apiSummary := api.CompanySummary{
+		Company:    summary.Company,
+		Timeframes: make([]api.TimeframeSummary, len(summary.Timeframes)),
+		Evergreen:  toAPIProblems(summary.Evergreen),
+	}
+	for i, tf := range summary.Timeframes {
+		apiSummary.Timeframes[i] = api.TimeframeSummary{
+			Timeframe:  tf.Timeframe,
+			Count:      tf.Count,
+			Difficulty: toAPIDifficulty(tf.Difficulty),
+			Top:        toAPIProblems(tf.Top),
+		}
+	}
+
+	writeJSON(w, http.StatusOK, api.Response[api.CompanySummary]{Success: true, Data: apiSummary})
 }
 
-func getProblemsByTimeframe(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	company := vars["company"]
-	timeframe := vars["timeframe"]
+// toAPIProblems converts data problems to our API format
+func toAPIProblems(problems []data.Problem) []api.Problem {
+	apiProblems := make([]api.Problem, len(problems))
+	for i, p := range problems {
+		apiProblems[i] = toAPIProblem(p)
+	}
+	return apiProblems
+}
 
-	problems := problemsData.GetProblems(company, timeframe)
+func toAPIProblem(p data.Problem) api.Problem {
+	return api.Problem{
+		ID:         p.ID,
+		URL:        p.URL,
+		Title:      p.Title,
+		Difficulty: p.Difficulty,
+		Acceptance: p.Acceptance,
+		Frequency:  p.Frequency,
+		Source:     p.Source,
+	}
+}
 
-	if problems == nil {
-		response := APIResponse{
-			Success: false,
-			Error:   fmt.Sprintf("No problems found for company: %s, timeframe: %s", company, timeframe),
-		}
-		w.Header().Set("Content-Type", "application/json")
-		if err := json.NewEncoder(w).Encode(response); err != nil {
-			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
-			return
-		}
+func toAPIDifficulty(d data.DifficultyBreakdown) api.DifficultyBreakdown {
+	return api.DifficultyBreakdown{Easy: d.Easy, Medium: d.Medium, Hard: d.Hard}
+}
+
+func getDatasetVersion(w http.ResponseWriter, r *http.Request) {
+	version := problemsData.Version()
+
+	writeJSON(w, http.StatusOK, api.Response[api.DatasetVersion]{
+		Success: true,
+		Data: api.DatasetVersion{
+			Hash:           version.Hash,
+			Version:        version.Version,
+			Companies:      version.Companies,
+			Problems:       version.Problems,
+			UniqueProblems: version.UniqueProblems,
+		},
+	})
+}
+
+func searchProblems(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		writeBadRequest(w, printer(w, r).T("api.missing_query"))
 		return
 	}
 
-	// Convert to our API format
-	apiProblems := make([]Problem, len(problems))
-	for i, p := range problems {
-		apiProblems[i] = Problem{
-			ID:         p.ID,
-			URL:        p.URL,
-			Title:      p.Title,
-			Difficulty: p.Difficulty,
-			Acceptance: p.Acceptance,
-			Frequency:  p.Frequency,
+	limit := defaultSearchLimit
+	if value, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && value > 0 {
+		limit = min(value, maxSearchLimit)
+	}
+
+	results := problemsData.Search(query, limit)
+
+	apiResults := make([]api.SearchResult, len(results))
+	for i, result := range results {
+		apiResults[i] = api.SearchResult{
+			Problem:      toAPIProblem(result.Problem),
+			Score:        result.Score,
+			Companies:    result.Companies,
+			TopCompanies: result.TopCompanies,
 		}
 	}
 
-	response := APIResponse{
+	writeJSON(w, http.StatusOK, api.Response[api.SearchResults]{
 		Success: true,
-		Data: map[string]interface{}{
-			"company":   company,
-			"timeframe": timeframe,
-			"problems":  apiProblems,
-			"count":     len(apiProblems),
+		Data: api.SearchResults{
+			Query:   query,
+			Results: apiResults,
+			Count:   len(apiResults),
 		},
+	})
+}
+
+// requestedExportFormat checks ?format= first, then the Accept header.
+// The boolean is false when the client wants the regular JSON API response.
+// The response varies with Accept either way, which it says for caches. An
+// unknown format is returned as an error in the request's language.
+func requestedExportFormat(w http.ResponseWriter, r *http.Request) (export.Format, bool, error) {
+	w.Header().Add("Vary", "Accept")
+	if value := r.URL.Query().Get("format"); value != "" {
+		format, err := export.ParseFormat(value)
+		if err != nil {
+			return "", false, errors.New(printer(w, r).T("api.unknown_format", value))
+		}
+		return format, true, nil
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
-		return
+	if format, ok := export.FormatFromAccept(r.Header.Get("Accept")); ok {
+		return format, true, nil
 	}
+
+	return "", false, nil
 }
 
-func getAllProblems(w http.ResponseWriter, r *http.Request) {
-	allProblems := problemsData.GetAllProblems()
+// problemFilterFromRequest reads the optional ?difficulty= and ?limit= query parameters
+func problemFilterFromRequest(r *http.Request) data.Filter {
+	query := r.URL.Query()
+	filter := data.Filter{
+		Difficulties: data.ParseDifficulties(query.Get("difficulty")),
+	}
+	if limit, err := strconv.Atoi(query.Get("limit")); err == nil && limit > 0 {
+		filter.Limit = limit
+	}
+	return filter
+}
 
-	allProblemsMap := make(map[string]map[string][]Problem)
-	for company, timeframes := range allProblems {
-		allProblemsMap[company] = make(map[string][]Problem)
-		for timeframe, problems := range timeframes {
-			apiProblems := make([]Problem, len(problems))
-			for i, p := range problems {
-				apiProblems[i] = Problem{
-					ID:         p.ID,
-					URL:        p.URL,
-					Title:      p.Title,
-					Difficulty: p.Difficulty,
-					Acceptance: p.Acceptance,
-					Frequency:  p.Frequency,
-				}
-			}
-			allProblemsMap[company][timeframe] = apiProblems
-		}
+func writeExport(w http.ResponseWriter, r *http.Request, format export.Format, list export.List) {
+	var buf bytes.Buffer
+	if err := export.Render(&buf, format, list); err != nil {
+		http.Error(w, printer(w, r).T("api.export_failed"), http.StatusInternalServerError)
+		return
 	}
 
-	response := APIResponse{
-		Success: true,
-		Data:    allProblemsMap,
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", export.Filename(list, format)))
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		slog.Error("writing export failed", logging.Err(err))
 	}
+}
+
+// printer picks the language of an error message from the Accept-Language
+// header. Only errors are translated, and they aren't cached.
+func printer(w http.ResponseWriter, r *http.Request) *i18n.Printer {
+	tr := i18n.FromAcceptLanguage(r.Header.Get("Accept-Language"))
+	w.Header().Add("Vary", "Accept-Language")
+	w.Header().Set("Content-Language", tr.Locale())
+	return tr
+}
+
+func writeBadRequest(w http.ResponseWriter, message string) {
+	writeError(w, http.StatusBadRequest, message)
+}
+
+// writeError answers with an error envelope and status
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, api.Response[any]{Success: false, Error: message})
+}
+
+// writeNotFound reports a v1 lookup that found nothing. It answers 200 like
+// it always has, since existing clients check success rather than the
+// status; v2 answers 404.
+func writeNotFound(w http.ResponseWriter, message string) {
+	writeJSON(w, http.StatusOK, api.Response[any]{Success: false, Error: message})
+}
 
+// writeJSON encodes a response body with the given status
+func writeJSON(w http.ResponseWriter, status int, v any) {
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
-		return
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("encoding response failed", logging.Err(err))
 	}
 }
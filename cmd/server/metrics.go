@@ -0,0 +1,56 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/whotypes/leetbot/internal/metrics"
+)
+
+var (
+	httpRequests = metrics.NewCounterVec("leetbot_http_requests_total",
+		"HTTP requests, by method, route and status code.",
+		"method", "route", "status")
+	httpDuration = metrics.NewHistogramVec("leetbot_http_request_duration_seconds",
+		"Time to serve an HTTP request, by method and route.",
+		metrics.DefaultBuckets, "method", "route")
+)
+
+// requestMetrics counts requests and their latency by route template, so
+// /api/companies/google/problems and /api/companies/meta/problems share a series
+func requestMetrics(router *mux.Router, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		route := routeTemplate(router, r)
+
+		recorder := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(recorder, r)
+
+		status := recorder.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		httpRequests.Inc(r.Method, route, strconv.Itoa(status))
+		httpDuration.ObserveDuration(time.Since(start), r.Method, route)
+	})
+}
+
+// routeTemplate returns the path template of the matching route. Anything
+// served by the static file handler is "static" and anything else
+// "unmatched" so arbitrary paths can't blow up the number of series.
+func routeTemplate(router *mux.Router, r *http.Request) string {
+	var match mux.RouteMatch
+	if !router.Match(r, &match) || match.Route == nil {
+		return "unmatched"
+	}
+	template, err := match.Route.GetPathTemplate()
+	if err != nil {
+		return "unmatched"
+	}
+	if template == "/" {
+		return "static"
+	}
+	return template
+}
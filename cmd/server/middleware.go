@@ -0,0 +1,96 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/whotypes/leetbot/internal/logging"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+// statusRecorder remembers the status code a handler wrote
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// requestLogger gives every request an ID, returned in the X-Request-ID header
+// and stored in the request context, and logs the request once it completes.
+// A well-formed X-Request-ID from the client or a proxy is kept.
+func requestLogger(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID(id) {
+			id = logging.NewRequestID()
+		}
+		w.Header().Set(requestIDHeader, id)
+
+		recorder := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(recorder, r.WithContext(logging.WithRequestID(r.Context(), id)))
+
+		status := recorder.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case isProbe(r.URL.Path):
+			// uptime checks and load balancers poll these constantly,
+			// a failing /readyz is reported by the probe itself
+			level = slog.LevelDebug
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		}
+		slog.Default().LogAttrs(r.Context(), level, "http request",
+			slog.String(logging.KeyRequestID, id),
+			slog.String(logging.KeyMethod, r.Method),
+			slog.String(logging.KeyPath, r.URL.Path),
+			slog.Int(logging.KeyStatus, status),
+			slog.Duration(logging.KeyDuration, time.Since(start)),
+		)
+	})
+}
+
+// isProbe reports whether a path is a health or readiness probe
+func isProbe(path string) bool {
+	return path == "/healthz" || path == "/readyz"
+}
+
+// validRequestID accepts short IDs made of letters, digits, dashes and underscores
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/whotypes/leetbot/internal/openapi"
+	"github.com/whotypes/leetbot/pkg/client"
+)
+
+// contractRequests exercise every documented operation, including
+// responses where nothing was found
+var contractRequests = []struct {
+	path   string
+	status int
+}{
+	{"/api/companies", http.StatusOK},
+	{"/api/companies/google/timeframes", http.StatusOK},
+	{"/api/companies/google/problems", http.StatusOK},
+	{"/api/companies/google/problems?difficulty=hard&limit=3", http.StatusOK},
+	{"/api/companies/not-a-company/problems", http.StatusOK},
+	{"/api/companies/google/summary", http.StatusOK},
+	{"/api/companies/not-a-company/summary", http.StatusOK},
+	{"/api/companies/google/timeframes/all/problems", http.StatusOK},
+	{"/api/companies/google/timeframes/all/problems?format=pdf", http.StatusBadRequest},
+	{"/api/all-problems", http.StatusOK},
+	{"/api/dataset/version", http.StatusOK},
+	{"/api/search?q=two+sum&limit=5", http.StatusOK},
+	{"/api/search", http.StatusBadRequest},
+	{"/api/info", http.StatusOK},
+	{"/api/v2/companies", http.StatusOK},
+	{"/api/v2/companies/google", http.StatusOK},
+	{"/api/v2/companies/not-a-company", http.StatusNotFound},
+	{"/api/v2/companies/google/problems", http.StatusOK},
+	{"/api/v2/companies/google/problems?timeframe=30d&difficulty=easy,medium&limit=3", http.StatusOK},
+	{"/api/v2/companies/google/problems?timeframe=yesterday", http.StatusBadRequest},
+	{"/api/v2/companies/not-a-company/problems", http.StatusNotFound},
+	{"/api/v2/problems?difficulty=hard&offset=10&limit=5", http.StatusOK},
+	{"/api/v2/problems?limit=-1", http.StatusBadRequest},
+	{"/api/v2/problems/1", http.StatusOK},
+	{"/api/v2/problems/999999", http.StatusNotFound},
+	{"/api/v2/problems/two-sum", http.StatusBadRequest},
+}
+
+func TestHandlersMatchOpenAPI(t *testing.T) {
+	router, _ := testRouter(t)
+	doc := fetchOpenAPI(t, router)
+
+	for _, tt := range contractRequests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != tt.status {
+				t.Fatalf("expected status %d, got %d: %s", tt.status, rec.Code, rec.Body.String())
+			}
+
+			op := findOperation(t, doc, router, tt.path)
+			response, ok := op.Responses[strconv.Itoa(rec.Code)]
+			if !ok {
+				t.Fatalf("status %d isn't documented for %s", rec.Code, op.OperationID)
+			}
+			schema := response.Content["application/json"].Schema
+
+			var body any
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if err := doc.Validate(schema, body); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestEveryRouteDocumented(t *testing.T) {
+	router, _ := testRouter(t)
+	doc := fetchOpenAPI(t, router)
+
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil || !strings.HasPrefix(path, "/api/") || path == "/api/openapi.json" {
+			return nil
+		}
+		if methods, _ := route.GetMethods(); len(methods) == 0 {
+			// the /api subrouter itself
+			return nil
+		}
+		if item, ok := doc.Paths[path]; !ok || item.Get == nil {
+			t.Errorf("%s isn't in the OpenAPI document", path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClientAgainstServer(t *testing.T) {
+	router, _ := testRouter(t)
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := client.New(srv.URL)
+
+	companies, err := c.Companies(ctx)
+	if err != nil || len(companies) == 0 {
+		t.Fatalf("Companies() = %d companies, %v", len(companies), err)
+	}
+	list, err := c.Problems(ctx, "google", &client.ProblemsOptions{Timeframe: "all", Difficulties: []string{"hard"}, Limit: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list.Count != 3 || list.Problems[0].Difficulty != "Hard" || list.ResolvedTimeframe != "all" {
+		t.Errorf("expected 3 hard problems from all, got %+v", list)
+	}
+	detail, err := c.Problem(ctx, 1)
+	if err != nil || detail.Title != "Two Sum" || len(detail.Occurrences) == 0 {
+		t.Errorf("Problem(1) = %+v, %v", detail, err)
+	}
+	if _, err := c.Company(ctx, "not-a-company"); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for an unknown company, got %v", err)
+	}
+	results, err := c.Search(ctx, "two sum", 1)
+	if err != nil || results.Count != 1 {
+		t.Errorf("Search() = %+v, %v", results, err)
+	}
+	info, err := c.Info(ctx)
+	if err != nil || info.DatasetVersion != problemsData.Version().Version {
+		t.Errorf("Info() = %+v, %v", info, err)
+	}
+	if _, err := c.Summary(ctx, "not-a-company"); err == nil {
+		t.Error("expected an error for an unknown company")
+	}
+}
+
+func fetchOpenAPI(t *testing.T, router http.Handler) *openapi.Document {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("/api/openapi.json answered %d", rec.Code)
+	}
+	var doc openapi.Document
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.OpenAPI != openapi.Version {
+		t.Errorf("expected openapi %s, got %s", openapi.Version, doc.OpenAPI)
+	}
+	return &doc
+}
+
+// findOperation returns the documented operation for the route that
+// serves path
+func findOperation(t *testing.T, doc *openapi.Document, router *mux.Router, path string) *openapi.Operation {
+	t.Helper()
+	var match mux.RouteMatch
+	if !router.Match(httptest.NewRequest(http.MethodGet, path, nil), &match) {
+		t.Fatalf("no route for %s", path)
+	}
+	template, err := match.Route.GetPathTemplate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	item, ok := doc.Paths[template]
+	if !ok || item.Get == nil {
+		t.Fatalf("%s isn't documented", template)
+	}
+	return item.Get
+}
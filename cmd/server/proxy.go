@@ -0,0 +1,63 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// clientIP returns the IP a request came from. When the request comes from a
+// trusted proxy, X-Forwarded-For is read from the right, skipping the
+// proxies' own entries; anything left of the first untrusted address is
+// whatever the client claimed.
+func clientIP(r *http.Request, trustedProxies []netip.Prefix) string {
+	peer := remoteHost(r)
+	if !isTrusted(peer, trustedProxies) {
+		return peer
+	}
+
+	forwarded := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		ip := strings.TrimSpace(forwarded[i])
+		if ip == "" {
+			continue
+		}
+		if !isTrusted(ip, trustedProxies) || i == 0 {
+			return ip
+		}
+	}
+	return peer
+}
+
+// isHTTPS reports whether the client connected over HTTPS, either to this
+// server or to a trusted proxy
+func isHTTPS(r *http.Request, trustedProxies []netip.Prefix) bool {
+	if r.TLS != nil {
+		return true
+	}
+	return isTrusted(remoteHost(r), trustedProxies) &&
+		strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https")
+}
+
+func remoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func isTrusted(ip string, trustedProxies []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"net/netip"
+	"strconv"
+
+	"github.com/whotypes/leetbot/internal/logging"
+	"github.com/whotypes/leetbot/internal/ratelimit"
+	"github.com/whotypes/leetbot/pkg/api"
+)
+
+// rateLimit answers 429 with a Retry-After header once a client IP runs out
+// of requests. Probes and metrics scrapes are never limited.
+func rateLimit(limiter *ratelimit.Limiter, trustedProxies []netip.Prefix, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isProbe(r.URL.Path) || r.URL.Path == "/metrics" || r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ip := clientIP(r, trustedProxies)
+		ok, wait := limiter.Allow(ip)
+		if ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		slog.Default().LogAttrs(r.Context(), slog.LevelInfo, "request rate limited",
+			slog.String(logging.KeyRequestID, logging.RequestID(r.Context())),
+			slog.String("ip", ip),
+			slog.Duration("retry_after", wait),
+		)
+
+		seconds := int(math.Ceil(wait.Seconds()))
+		if seconds < 1 {
+			seconds = 1
+		}
+		w.Header().Set("Retry-After", strconv.Itoa(seconds))
+		writeJSON(w, http.StatusTooManyRequests, api.Response[any]{Success: false, Error: printer(w, r).T("api.rate_limited")})
+	})
+}
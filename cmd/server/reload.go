@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/gorilla/handlers"
+	"github.com/gorilla/mux"
+	"github.com/whotypes/leetbot/internal/config"
+	"github.com/whotypes/leetbot/internal/logging"
+	"github.com/whotypes/leetbot/internal/ratelimit"
+)
+
+// reloadableHandler wraps the router in the middleware configured by the
+// server section, rebuilding it on SIGHUP and swapping it in atomically.
+// Requests in flight finish with the middleware they started with.
+type reloadableHandler struct {
+	router  *mux.Router
+	handler atomic.Pointer[http.Handler]
+
+	mu      sync.Mutex // serializes reloads
+	current *config.Config
+	limiter *ratelimit.Limiter
+}
+
+func newReloadableHandler(router *mux.Router, cfg *config.Config) (*reloadableHandler, error) {
+	h := &reloadableHandler{router: router}
+	if err := h.apply(cfg); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func (h *reloadableHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	(*h.handler.Load()).ServeHTTP(w, r)
+}
+
+// apply builds the middleware for cfg. The rate limiter, and the clients it
+// is tracking, are kept unless the limit changed.
+func (h *reloadableHandler) apply(cfg *config.Config) error {
+	server := &cfg.Server
+	limiter := h.limiter
+	if h.current == nil || h.current.Server.RateLimit != server.RateLimit {
+		limit, err := ratelimit.ParseLimit(server.RateLimit)
+		if err != nil {
+			return fmt.Errorf("server.rate_limit: %w", err)
+		}
+		limiter = ratelimit.New(limit)
+	}
+
+	corsHandler := handlers.CORS(
+		handlers.AllowedOrigins(server.AllowedOrigins),
+		handlers.AllowedMethods(server.AllowedMethods),
+		handlers.AllowedHeaders([]string{"X-Requested-With", "Content-Type", "Authorization", requestIDHeader}),
+		handlers.ExposedHeaders([]string{requestIDHeader, "Retry-After"}),
+	)(rateLimit(limiter, server.TrustedProxies, h.router))
+	var handler http.Handler = securityHeaders(server, requestMetrics(h.router, corsHandler))
+
+	h.handler.Store(&handler)
+	h.current, h.limiter = cfg, limiter
+	return nil
+}
+
+// reload parses the configuration again with the original flags. Nothing
+// changes unless every setting is valid.
+func (h *reloadableHandler) reload(args []string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	next, _, err := config.Parse("server", args)
+	if err == nil {
+		previous := h.current
+		if err = h.apply(next); err == nil {
+			if level, err := logging.ParseLevel(next.Log.Level); err == nil {
+				logging.SetLevel(level)
+			}
+			config.LogChanges(config.Filter(previous.Diff(next), "server.", "data.", "log."), liveServerSetting)
+			return nil
+		}
+	}
+	config.LogReloadFailure(err)
+	return err
+}
+
+// watchSignals reloads the configuration on every SIGHUP
+func (h *reloadableHandler) watchSignals(args []string) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			_ = h.reload(args)
+		}
+	}()
+}
+
+// liveServerSetting reports whether a reload applies the setting, rather
+// than it waiting for a restart
+func liveServerSetting(key string) bool {
+	switch key {
+	case "server.trusted_proxies", "server.content_security_policy", "server.hsts_max_age",
+		"server.rate_limit", "log.level":
+		return true
+	}
+	return strings.HasPrefix(key, "server.cors.")
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/whotypes/leetbot/internal/config"
+)
+
+func TestReloadableHandler(t *testing.T) {
+	router, _ := testRouter(t)
+	initial, _, err := config.Parse("server", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler, err := newReloadableHandler(router, initial)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	get := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/companies", nil)
+		r.Header.Set("Origin", "https://leetbot.org")
+		handler.ServeHTTP(w, r)
+		return w
+	}
+	if w := get(); w.Header().Get("Content-Security-Policy") != config.DefaultContentSecurityPolicy {
+		t.Fatalf("unexpected CSP before the reload: %q", w.Header().Get("Content-Security-Policy"))
+	}
+
+	path := filepath.Join(t.TempDir(), "leetbot.yaml")
+	write := func(content string) {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	args := []string{"-config", path}
+
+	write("server:\n  content_security_policy: default-src 'none'\n  cors:\n    allowed_origins: [https://leetbot.org]\n  rate_limit: 1/1m\n")
+	if err := handler.reload(args); err != nil {
+		t.Fatalf("reload() error = %v", err)
+	}
+	w := get()
+	if got := w.Header().Get("Content-Security-Policy"); got != "default-src 'none'" {
+		t.Errorf("CSP after the reload = %q", got)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://leetbot.org" {
+		t.Errorf("Access-Control-Allow-Origin after the reload = %q", got)
+	}
+	if w := get(); w.Code != http.StatusTooManyRequests {
+		t.Errorf("the reloaded rate limit should apply, got %d", w.Code)
+	}
+
+	// a bad setting rejects the whole file
+	write("server:\n  content_security_policy: off\n  read_timeout: soon\n")
+	if err := handler.reload(args); err == nil {
+		t.Fatal("reload() should reject an invalid configuration")
+	}
+	if w := get(); w.Header().Get("Content-Security-Policy") != "default-src 'none'" || w.Code != http.StatusTooManyRequests {
+		t.Error("a rejected reload shouldn't change the middleware or reset the rate limiter")
+	}
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/whotypes/leetbot/internal/buildinfo"
+	"github.com/whotypes/leetbot/internal/health"
+	"github.com/whotypes/leetbot/internal/httpcache"
+	"github.com/whotypes/leetbot/internal/openapi"
+	"github.com/whotypes/leetbot/pkg/api"
+)
+
+// apiRoute is a GET endpoint under /api and what the OpenAPI document says
+// about it
+type apiRoute struct {
+	path    string
+	handler http.HandlerFunc
+	op      openapi.Operation
+	// response is a zero value of the JSON response
+	response any
+	// exports is set when ?format= or an Accept header can ask for a file
+	exports bool
+	// notFound is set when lookups that find nothing answer 404 instead of
+	// 200 with success false
+	notFound bool
+	// successor is the v2 route replacing a v1 route, with the v1 path
+	// variables in braces
+	successor string
+}
+
+var (
+	companyParam = openapi.Parameter{
+		Name: "company", In: "path", Required: true,
+		Description: "Company name as listed by /api/companies",
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+	timeframeParam = openapi.Parameter{
+		Name: "timeframe", In: "path", Required: true,
+		Description: "thirty-days, three-months, six-months, more-than-six-months or all; aliases like 30d are accepted",
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+	difficultyParam = openapi.Parameter{
+		Name: "difficulty", In: "query",
+		Description: "Comma separated difficulties to keep, e.g. easy,medium",
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+	limitParam = openapi.Parameter{
+		Name: "limit", In: "query",
+		Description: "Maximum number of problems",
+		Schema:      &openapi.Schema{Type: "integer", Minimum: float(1)},
+	}
+	slugParam = openapi.Parameter{
+		Name: "slug", In: "path", Required: true,
+		Description: "Company slug as listed by /api/v2/companies",
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+	timeframeQueryParam = openapi.Parameter{
+		Name: "timeframe", In: "query",
+		Description: "thirty-days, three-months, six-months, more-than-six-months or all; aliases like 30d are accepted. Defaults to the company's most recent timeframe with problems.",
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+	offsetParam = openapi.Parameter{
+		Name: "offset", In: "query",
+		Description: "Number of problems to skip",
+		Schema:      &openapi.Schema{Type: "integer", Minimum: float(0)},
+	}
+	problemIDParam = openapi.Parameter{
+		Name: "id", In: "path", Required: true,
+		Description: "LeetCode problem number",
+		Schema:      &openapi.Schema{Type: "integer", Minimum: float(1)},
+	}
+	formatParam = openapi.Parameter{
+		Name: "format", In: "query",
+		Description: "Download the list as a file instead of the JSON response",
+		Schema:      &openapi.Schema{Type: "string", Enum: []string{"csv", "json", "markdown", "anki"}},
+	}
+)
+
+func float(f float64) *float64 {
+	return &f
+}
+
+// apiRoutes are served under /api and documented at /api/openapi.json
+var apiRoutes = []apiRoute{
+	{
+		path:      "/companies",
+		handler:   getCompanies,
+		op:        openapi.Operation{OperationID: "listCompanies", Summary: "List companies with problem data", Tags: []string{"companies"}},
+		response:  api.Response[api.CompaniesList]{},
+		successor: "/v2/companies",
+	},
+	{
+		path:    "/companies/{company}/timeframes",
+		handler: getTimeframes,
+		op: openapi.Operation{OperationID: "listTimeframes", Summary: "List the timeframes a company has problems for", Tags: []string{"companies"},
+			Parameters: []openapi.Parameter{companyParam}},
+		response:  api.Response[api.TimeframesList]{},
+		successor: "/v2/companies/{company}",
+	},
+	{
+		path:    "/companies/{company}/problems",
+		handler: getProblems,
+		op: openapi.Operation{OperationID: "getProblems", Summary: "Get a company's problems from its most recent timeframe", Tags: []string{"problems"},
+			Parameters: []openapi.Parameter{companyParam, difficultyParam, limitParam, formatParam}},
+		response:  api.Response[api.ProblemsList]{},
+		exports:   true,
+		successor: "/v2/companies/{company}/problems",
+	},
+	{
+		path:    "/companies/{company}/summary",
+		handler: getCompanySummary,
+		op: openapi.Operation{OperationID: "getCompanySummary", Summary: "Get a company's overview across timeframes", Tags: []string{"companies"},
+			Parameters: []openapi.Parameter{companyParam}},
+		response: api.Response[api.CompanySummary]{},
+	},
+	{
+		path:    "/companies/{company}/timeframes/{timeframe}/problems",
+		handler: getProblemsByTimeframe,
+		op: openapi.Operation{OperationID: "getProblemsByTimeframe", Summary: "Get a company's problems for a timeframe", Tags: []string{"problems"},
+			Parameters: []openapi.Parameter{companyParam, timeframeParam, difficultyParam, limitParam, formatParam}},
+		response:  api.Response[api.ProblemsList]{},
+		exports:   true,
+		successor: "/v2/companies/{company}/problems?timeframe={timeframe}",
+	},
+	{
+		path:      "/all-problems",
+		handler:   getAllProblems,
+		op:        openapi.Operation{OperationID: "getAllProblems", Summary: "Get every company's problems for every timeframe", Tags: []string{"problems"}},
+		response:  api.Response[api.AllProblems]{},
+		successor: "/v2/problems",
+	},
+	{
+		path:     "/dataset/version",
+		handler:  getDatasetVersion,
+		op:       openapi.Operation{OperationID: "getDatasetVersion", Summary: "Get the version of the loaded dataset", Tags: []string{"meta"}},
+		response: api.Response[api.DatasetVersion]{},
+	},
+	{
+		path:    "/search",
+		handler: searchProblems,
+		op: openapi.Operation{OperationID: "searchProblems", Summary: "Search problems by title, slug or ID", Tags: []string{"problems"},
+			Parameters: []openapi.Parameter{
+				{Name: "q", In: "query", Required: true, Description: "Search query", Schema: &openapi.Schema{Type: "string"}},
+				{Name: "limit", In: "query", Description: "Maximum number of results, at most 100", Schema: &openapi.Schema{Type: "integer", Minimum: float(1), Maximum: float(maxSearchLimit)}},
+			}},
+		response: api.Response[api.SearchResults]{},
+	},
+	{
+		path:     "/info",
+		handler:  health.InfoHandler(currentProblems),
+		op:       openapi.Operation{OperationID: "getInfo", Summary: "Get the server's version and dataset", Tags: []string{"meta"}},
+		response: api.Response[api.Info]{},
+	},
+	{
+		path:     "/v2/companies",
+		handler:  getCompaniesV2,
+		op:       openapi.Operation{OperationID: "listCompaniesV2", Summary: "List companies with their timeframes", Tags: []string{"v2"}},
+		response: api.Response[api.CompanyList]{},
+	},
+	{
+		path:    "/v2/companies/{slug}",
+		handler: getCompanyV2,
+		op: openapi.Operation{OperationID: "getCompanyV2", Summary: "Get a company and its problem counts per timeframe", Tags: []string{"v2"},
+			Description: "Names, aliases and tickers from data/companies.json work in place of the slug, e.g. meta answers with facebook.",
+			Parameters:  []openapi.Parameter{slugParam}},
+		response: api.Response[api.Company]{},
+		notFound: true,
+	},
+	{
+		path:    "/v2/companies/{slug}/problems",
+		handler: getCompanyProblemsV2,
+		op: openapi.Operation{OperationID: "getCompanyProblemsV2", Summary: "Get a company's problems for a timeframe", Tags: []string{"v2"},
+			Parameters: []openapi.Parameter{slugParam, timeframeQueryParam, difficultyParam, limitParam, formatParam}},
+		response: api.Response[api.CompanyProblems]{},
+		exports:  true,
+		notFound: true,
+	},
+	{
+		path:    "/v2/problems",
+		handler: listProblemsV2,
+		op: openapi.Operation{OperationID: "listProblemsV2", Summary: "List every problem any company asks, ordered by ID", Tags: []string{"v2"},
+			Parameters: []openapi.Parameter{difficultyParam, limitParam, offsetParam}},
+		response: api.Response[api.ProblemList]{},
+	},
+	{
+		path:    "/v2/problems/{id}",
+		handler: getProblemV2,
+		op: openapi.Operation{OperationID: "getProblemV2", Summary: "Get a problem and every company and timeframe that asks it", Tags: []string{"v2"},
+			Parameters: []openapi.Parameter{problemIDParam}},
+		response: api.Response[api.ProblemDetail]{},
+		notFound: true,
+	},
+}
+
+// deprecated points clients of a v1 route at its v2 successor with the
+// Deprecation and Link headers
+func deprecated(successor string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		link := successor
+		for name, value := range mux.Vars(r) {
+			link = strings.ReplaceAll(link, "{"+name+"}", url.PathEscape(value))
+		}
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Link", fmt.Sprintf(`</api%s>; rel="successor-version"`, link))
+		next.ServeHTTP(w, r)
+	})
+}
+
+// exportContentTypes are the file formats problem lists can be downloaded as,
+// besides JSON
+var exportContentTypes = []string{"text/csv", "text/markdown", "text/tab-separated-values"}
+
+// openAPIDocument describes apiRoutes
+func openAPIDocument() *openapi.Document {
+	doc := openapi.NewDocument("leetbot API", buildinfo.Get().Version,
+		"Company tagged LeetCode problems. JSON responses are wrapped in an envelope with success, data and error.")
+
+	errorResponse := func(description string) openapi.Response {
+		return openapi.Response{
+			Description: description,
+			Content:     map[string]openapi.MediaType{"application/json": {Schema: doc.SchemaFor(api.Response[any]{})}},
+		}
+	}
+
+	for _, route := range apiRoutes {
+		op := route.op
+		description := "success is false with an error when nothing matches"
+		if route.notFound {
+			description = "OK"
+		}
+		success := openapi.Response{
+			Description: description,
+			Content: map[string]openapi.MediaType{
+				"application/json": {Schema: doc.SchemaFor(route.response)},
+			},
+		}
+		if route.exports {
+			for _, contentType := range exportContentTypes {
+				success.Content[contentType] = openapi.MediaType{Schema: &openapi.Schema{Type: "string"}}
+			}
+		}
+
+		op.Responses = map[string]openapi.Response{
+			"200": success,
+			"429": {
+				Description: "Rate limited",
+				Headers: map[string]openapi.Header{
+					"Retry-After": {Description: "Seconds until the next request is allowed", Schema: &openapi.Schema{Type: "integer"}},
+				},
+				Content: errorResponse("").Content,
+			},
+		}
+		// query parameters and numeric path parameters can be malformed
+		for _, param := range op.Parameters {
+			if param.In == "query" || param.Schema.Type != "string" {
+				op.Responses["400"] = errorResponse("Invalid parameters")
+				break
+			}
+		}
+		if route.notFound {
+			op.Responses["404"] = errorResponse("Not found")
+		}
+		if route.successor != "" {
+			op.Deprecated = true
+			op.Description = "Use GET /api" + route.successor + " instead."
+		}
+		doc.AddGet("/api"+route.path, &op)
+	}
+	return doc
+}
+
+// openAPIHandler serves the document, which doesn't change while the
+// process runs
+func openAPIHandler() (http.Handler, error) {
+	body, err := json.MarshalIndent(openAPIDocument(), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return httpcache.NewEntry(buildinfo.Get().Version, "application/json", body)
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/whotypes/leetbot/internal/config"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted, _ := config.ParsePrefixes("10.0.0.0/8,192.0.2.1")
+
+	tests := []struct {
+		name      string
+		remote    string
+		forwarded string
+		want      string
+	}{
+		{"direct", "203.0.113.9:1234", "", "203.0.113.9"},
+		{"untrusted peer can't spoof", "203.0.113.9:1234", "198.51.100.1", "203.0.113.9"},
+		{"one proxy", "10.1.2.3:1234", "198.51.100.1", "198.51.100.1"},
+		{"client claims are ignored", "10.1.2.3:1234", "1.1.1.1, 198.51.100.1", "198.51.100.1"},
+		{"chain of proxies", "10.1.2.3:1234", "198.51.100.1, 192.0.2.1, 10.9.9.9", "198.51.100.1"},
+		{"proxy without header", "10.1.2.3:1234", "", "10.1.2.3"},
+		{"only proxies", "10.1.2.3:1234", "10.0.0.1, 10.0.0.2", "10.0.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remote
+			if tt.forwarded != "" {
+				r.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			if got := clientIP(r, trusted); got != tt.want {
+				t.Errorf("clientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	trusted, _ := config.ParsePrefixes("10.0.0.0/8")
+	cfg := &config.ServerConfig{
+		ContentSecurityPolicy: config.DefaultContentSecurityPolicy,
+		HSTSMaxAge:            24 * time.Hour,
+		TrustedProxies:        trusted,
+	}
+	handler := securityHeaders(cfg, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	tests := []struct {
+		name   string
+		remote string
+		proto  string
+		tls    bool
+		hsts   bool
+	}{
+		{"plain http", "203.0.113.9:1234", "", false, false},
+		{"tls", "203.0.113.9:1234", "", true, true},
+		{"https at a trusted proxy", "10.1.2.3:1234", "https", false, true},
+		{"spoofed proto", "203.0.113.9:1234", "https", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/companies", nil)
+			r.RemoteAddr = tt.remote
+			if tt.proto != "" {
+				r.Header.Set("X-Forwarded-Proto", tt.proto)
+			}
+			if tt.tls {
+				r.TLS = &tls.ConnectionState{}
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, r)
+
+			if got := rec.Header().Get("X-Content-Type-Options"); got != "nosniff" {
+				t.Errorf("X-Content-Type-Options = %q", got)
+			}
+			if got := rec.Header().Get("Content-Security-Policy"); got != config.DefaultContentSecurityPolicy {
+				t.Errorf("Content-Security-Policy = %q", got)
+			}
+			if got := rec.Header().Get("Strict-Transport-Security"); (got != "") != tt.hsts {
+				t.Errorf("Strict-Transport-Security = %q, want it set: %v", got, tt.hsts)
+			}
+		})
+	}
+}
+
+func TestIndexScriptHashes(t *testing.T) {
+	testRouter(t)
+	index := `<html><head>
+<script type="application/ld+json">{"@type": "WebApplication"}</script>
+<script>document.documentElement.classList.add('dark')</script>
+<script type="module" src="/assets/index-abc1.js"></script>
+</head></html>`
+	h := newSPAHandler(fstest.MapFS{"index.html": {Data: []byte(index)}})
+	handler := securityHeaders(&config.ServerConfig{ContentSecurityPolicy: config.DefaultContentSecurityPolicy}, h)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	// the hash of the theme script only
+	policy := rec.Header().Get("Content-Security-Policy")
+	want := "script-src 'self' 'sha256-"
+	if !strings.Contains(policy, want) || strings.Count(policy, "sha256-") != 1 {
+		t.Errorf("expected one inline script hash in script-src, got %q", policy)
+	}
+	if !strings.HasPrefix(policy, "default-src 'self';") {
+		t.Errorf("other directives changed: %q", policy)
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/whotypes/leetbot/internal/data"
+	"github.com/whotypes/leetbot/internal/logging"
+	"github.com/whotypes/leetbot/web"
+)
+
+// vite puts content hashed bundles under assets/, so they never change
+const (
+	immutableCache = "public, max-age=31536000, immutable"
+	staticCache    = "public, max-age=3600"
+)
+
+var (
+	companyPage = regexp.MustCompile(`^/company/([^/]+)/?$`)
+	ogURL       = regexp.MustCompile(`<meta\s+property="og:url"\s+content="([^"]*)"`)
+	metaTag     = regexp.MustCompile(`(<meta\s+(?:name|property)="([a-z:]+)"\s+content=")[^"]*(")`)
+	titleTag    = regexp.MustCompile(`<title>[^<]*</title>`)
+	canonical   = regexp.MustCompile(`(<link\s+rel="canonical"\s+href=")[^"]*(")`)
+	inlineJS    = regexp.MustCompile(`(?s)<script(\s[^>]*)?>(.*?)</script>`)
+)
+
+// webFiles returns the frontend compiled into the binary, or the files in
+// WEB_DIR, ./web/dist by default
+func webFiles(dir string) (fs.FS, string) {
+	if web.Embedded {
+		return web.Dist(), "embedded"
+	}
+	return os.DirFS(dir), dir
+}
+
+// spaHandler serves the frontend's files, and index.html for any other path
+// so client side routes like /company/google load the app
+type spaHandler struct {
+	files fs.FS
+	index []byte
+	// site is the og:url of index.html, used to build page URLs
+	site string
+	// scriptHashes let index.html's inline scripts past the CSP
+	scriptHashes []string
+}
+
+func newSPAHandler(files fs.FS) *spaHandler {
+	h := &spaHandler{files: files}
+	index, err := fs.ReadFile(files, "index.html")
+	if err != nil {
+		slog.Warn("frontend not found, only the API will be served", logging.Err(err))
+		return h
+	}
+	h.index = index
+	if m := ogURL.FindSubmatch(index); m != nil {
+		h.site = strings.TrimRight(string(m[1]), "/")
+	}
+	for _, m := range inlineJS.FindAllSubmatch(index, -1) {
+		attrs := string(m[1])
+		// JSON-LD and other data blocks never run
+		if strings.Contains(attrs, "src=") || (strings.Contains(attrs, "type=") && !strings.Contains(attrs, "javascript") && !strings.Contains(attrs, "module")) {
+			continue
+		}
+		sum := sha256.Sum256(m[2])
+		h.scriptHashes = append(h.scriptHashes, "'sha256-"+base64.StdEncoding.EncodeToString(sum[:])+"'")
+	}
+	return h
+}
+
+func (h *spaHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	urlPath := path.Clean("/" + r.URL.Path)
+	if urlPath == "/index.html" {
+		urlPath = "/"
+	}
+	name := strings.TrimPrefix(urlPath, "/")
+
+	if name != "" {
+		if info, err := fs.Stat(h.files, name); err == nil && !info.IsDir() {
+			if strings.HasPrefix(name, "assets/") {
+				w.Header().Set("Cache-Control", immutableCache)
+			} else {
+				w.Header().Set("Cache-Control", staticCache)
+			}
+			http.ServeFileFS(w, r, h.files, name)
+			return
+		}
+	}
+
+	// missing files and API routes shouldn't get a page back
+	if h.index == nil || strings.HasPrefix(urlPath, "/api/") || path.Ext(urlPath) != "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	page, status := h.page(urlPath)
+	if policy := w.Header().Get("Content-Security-Policy"); policy != "" {
+		w.Header().Set("Content-Security-Policy", withScriptHashes(policy, h.scriptHashes))
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(status)
+	if r.Method != http.MethodHead {
+		_, _ = w.Write(page)
+	}
+}
+
+// page returns index.html with Open Graph tags describing urlPath, so links
+// to company pages preview with the company's problems
+func (h *spaHandler) page(urlPath string) ([]byte, int) {
+	match := companyPage.FindStringSubmatch(urlPath)
+	if match == nil {
+		return h.index, http.StatusOK
+	}
+	pbc := currentProblems()
+	summary := pbc.GetCompanySummary(canonicalCompany(pbc, match[1]), 0)
+	if summary == nil {
+		return h.index, http.StatusNotFound
+	}
+
+	name := companyDisplayName(summary.Company)
+	title := name + " interview problems - Leetbot"
+	description := fmt.Sprintf("%d LeetCode problems asked at %s.", summary.UniqueProblems, name)
+	if problems, timeframe := pbc.GetProblemsWithPriority(summary.Company); timeframe != "all" {
+		if label, ok := recentLabels[timeframe]; ok {
+			description = fmt.Sprintf("%d LeetCode problems asked at %s, %d of them %s.",
+				summary.UniqueProblems, name, len(problems), label)
+		}
+	}
+	url := ""
+	if h.site != "" {
+		url = h.site + "/company/" + summary.Company
+	}
+
+	tags := map[string]string{
+		"title":               title,
+		"description":         description,
+		"og:title":            title,
+		"og:description":      description,
+		"twitter:title":       title,
+		"twitter:description": description,
+	}
+	if url != "" {
+		tags["og:url"] = url
+		tags["twitter:url"] = url
+	}
+
+	page := metaTag.ReplaceAllFunc(h.index, func(tag []byte) []byte {
+		m := metaTag.FindSubmatch(tag)
+		content, ok := tags[string(m[2])]
+		if !ok {
+			return tag
+		}
+		return bytes.Join([][]byte{m[1], []byte(html.EscapeString(content)), m[3]}, nil)
+	})
+	page = titleTag.ReplaceAll(page, []byte("<title>"+html.EscapeString(title)+"</title>"))
+	if url != "" {
+		page = canonical.ReplaceAll(page, []byte("${1}"+html.EscapeString(url)+"${2}"))
+	}
+	return page, http.StatusOK
+}
+
+// withScriptHashes adds hashes to a policy's script-src directive. Policies
+// without one are left alone.
+func withScriptHashes(policy string, hashes []string) string {
+	if len(hashes) == 0 {
+		return policy
+	}
+	directives := strings.Split(policy, ";")
+	for i, directive := range directives {
+		fields := strings.Fields(directive)
+		if len(fields) > 0 && fields[0] == "script-src" {
+			directives[i] = " " + strings.Join(append(fields, hashes...), " ")
+			if i == 0 {
+				directives[i] = directives[i][1:]
+			}
+			return strings.Join(directives, ";")
+		}
+	}
+	return policy
+}
+
+// recentLabels finish a sentence about a company's most recent timeframe
+var recentLabels = map[string]string{
+	"thirty-days":          "in the last 30 days",
+	"three-months":         "in the last three months",
+	"six-months":           "in the last six months",
+	"more-than-six-months": "more than six months ago",
+}
+
+// companyDisplayName is the company's name in data/companies.json, or its
+// slug title cased, e.g. Jane Street for jane-street
+func companyDisplayName(slug string) string {
+	return data.Companies().DisplayName(slug)
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+const testIndex = `<!doctype html>
+<html>
+  <head>
+    <title>Leetbot Data Explorer</title>
+    <meta name="description"
+      content="See the LeetCode problems." />
+    <link rel="canonical" href="https://leetbot.example" />
+    <meta property="og:url" content="https://leetbot.example/" />
+    <meta property="og:title" content="Leetbot" />
+    <meta property="og:description" content="See the LeetCode problems." />
+    <meta property="og:image" content="https://leetbot.example/og.webp" />
+  </head>
+  <body><div id="root"></div></body>
+</html>`
+
+func TestSPAHandler(t *testing.T) {
+	testRouter(t) // loads the dataset
+	h := newSPAHandler(fstest.MapFS{
+		"index.html":           {Data: []byte(testIndex)},
+		"favicon.png":          {Data: []byte("png")},
+		"assets/index-abc1.js": {Data: []byte("console.log(1)")},
+	})
+
+	tests := []struct {
+		path     string
+		status   int
+		cache    string
+		contains []string
+	}{
+		{"/", http.StatusOK, "no-cache", []string{"<title>Leetbot Data Explorer</title>"}},
+		{"/index.html", http.StatusOK, "no-cache", []string{`<div id="root">`}},
+		{"/assets/index-abc1.js", http.StatusOK, immutableCache, []string{"console.log(1)"}},
+		{"/favicon.png", http.StatusOK, staticCache, []string{"png"}},
+		{"/some/client/route", http.StatusOK, "no-cache", []string{`<div id="root">`}},
+		{"/assets/missing-123.js", http.StatusNotFound, "", nil},
+		{"/api/typo", http.StatusNotFound, "", nil},
+		{"/company/google", http.StatusOK, "no-cache", []string{
+			"<title>Google interview problems - Leetbot</title>",
+			`<meta property="og:title" content="Google interview problems - Leetbot" />`,
+			`<meta property="og:url" content="https://leetbot.example/company/google" />`,
+			`<link rel="canonical" href="https://leetbot.example/company/google" />`,
+			"LeetCode problems asked at Google, ",
+			"of them in the last 30 days.",
+			`<meta property="og:image" content="https://leetbot.example/og.webp" />`,
+		}},
+		{"/company/fb", http.StatusOK, "no-cache", []string{
+			"<title>Meta interview problems - Leetbot</title>",
+			`<meta property="og:url" content="https://leetbot.example/company/facebook" />`,
+			`<link rel="canonical" href="https://leetbot.example/company/facebook" />`,
+		}},
+		{"/company/no-such-company", http.StatusNotFound, "no-cache", []string{"<title>Leetbot Data Explorer</title>"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != tt.status {
+				t.Fatalf("expected %d, got %d", tt.status, rec.Code)
+			}
+			if got := rec.Header().Get("Cache-Control"); tt.cache != "" && got != tt.cache {
+				t.Errorf("expected Cache-Control %q, got %q", tt.cache, got)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(rec.Body.String(), want) {
+					t.Errorf("expected body to contain %q, got:\n%s", want, rec.Body.String())
+				}
+			}
+		})
+	}
+}
+
+func TestSPAHandlerWithoutFrontend(t *testing.T) {
+	h := newSPAHandler(fstest.MapFS{})
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 without index.html, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/whotypes/leetbot/internal/data"
+	"github.com/whotypes/leetbot/internal/export"
+	"github.com/whotypes/leetbot/pkg/api"
+)
+
+// v2 handlers answer 404 for anything unknown and 400 for malformed
+// parameters instead of falling back to defaults like v1 does
+
+func getCompaniesV2(w http.ResponseWriter, r *http.Request) {
+	companies := problemsData.GetAvailableCompanies()
+
+	list := api.CompanyList{Companies: make([]api.CompanyListing, 0, len(companies))}
+	for _, company := range companies {
+		summary := problemsData.GetCompanySummary(company, 0)
+		if summary == nil || len(summary.Timeframes) == 0 {
+			continue
+		}
+
+		timeframes := make([]string, len(summary.Timeframes))
+		for i, tf := range summary.Timeframes {
+			timeframes[i] = tf.Timeframe
+		}
+		list.Companies = append(list.Companies, api.CompanyListing{
+			Slug:           company,
+			CompanyNames:   companyNames(company),
+			Timeframes:     timeframes,
+			UniqueProblems: summary.UniqueProblems,
+		})
+	}
+	list.Count = len(list.Companies)
+
+	writeJSON(w, http.StatusOK, api.Response[api.CompanyList]{Success: true, Data: list})
+}
+
+func getCompanyV2(w http.ResponseWriter, r *http.Request) {
+	slug := requestedCompany(r)
+
+	summary := problemsData.GetCompanySummary(slug, 0)
+	if summary == nil || len(summary.Timeframes) == 0 {
+		writeError(w, http.StatusNotFound, printer(w, r).T("api.unknown_company", slug))
+		return
+	}
+
+	_, defaultTimeframe := problemsData.GetProblemsWithPriority(slug)
+	info, _ := data.Companies().Info(summary.Company)
+	company := api.Company{
+		Slug:             summary.Company,
+		CompanyNames:     companyNames(summary.Company),
+		Domain:           info.Domain,
+		LogoURL:          info.LogoURL,
+		Parent:           info.Parent,
+		DefaultTimeframe: defaultTimeframe,
+		Timeframes:       make([]api.TimeframeCount, len(summary.Timeframes)),
+		UniqueProblems:   summary.UniqueProblems,
+	}
+	for i, tf := range summary.Timeframes {
+		company.Timeframes[i] = api.TimeframeCount{
+			Timeframe:  tf.Timeframe,
+			Count:      tf.Count,
+			Difficulty: toAPIDifficulty(tf.Difficulty),
+		}
+	}
+
+	writeJSON(w, http.StatusOK, api.Response[api.Company]{Success: true, Data: company})
+}
+
+// requestedCompany returns the company a {company} or {slug} route names.
+// Other names like meta answer with the company they belong to.
+func requestedCompany(r *http.Request) string {
+	vars := mux.Vars(r)
+	slug, ok := vars["slug"]
+	if !ok {
+		slug = vars["company"]
+	}
+	return canonicalCompany(problemsData, slug)
+}
+
+// canonicalCompany resolves an alias, ticker or former name to the slug
+// pbc stores the company under. Slugs pbc already has are kept as they are.
+func canonicalCompany(pbc *data.ProblemsByCompany, slug string) string {
+	slug = strings.ToLower(strings.TrimSpace(slug))
+	if resolved, ok := data.Companies().Resolve(slug); ok && !pbc.CompanyExists(slug) {
+		slug = resolved
+	}
+	return slug
+}
+
+// companyNames is what data/companies.json says the company is called
+func companyNames(slug string) api.CompanyNames {
+	info, _ := data.Companies().Info(slug)
+	return api.CompanyNames{
+		Name:        companyDisplayName(slug),
+		Aliases:     info.Aliases,
+		Tickers:     info.Tickers,
+		FormerNames: info.FormerNames,
+	}
+}
+
+func getCompanyProblemsV2(w http.ResponseWriter, r *http.Request) {
+	slug := requestedCompany(r)
+
+	format, wantsExport, err := requestedExportFormat(w, r)
+	if err != nil {
+		writeBadRequest(w, err.Error())
+		return
+	}
+	filter, err := strictProblemFilter(w, r)
+	if err != nil {
+		writeBadRequest(w, err.Error())
+		return
+	}
+
+	if !problemsData.CompanyExists(slug) {
+		writeError(w, http.StatusNotFound, printer(w, r).T("api.unknown_company", slug))
+		return
+	}
+
+	requested := r.URL.Query().Get("timeframe")
+	var problems []data.Problem
+	var timeframe string
+	if requested == "" {
+		problems, timeframe = problemsData.GetProblemsWithPriority(slug)
+	} else {
+		var ok bool
+		timeframe, ok = data.CanonicalTimeframe(requested)
+		if !ok {
+			writeBadRequest(w, printer(w, r).T("api.unknown_timeframe", requested))
+			return
+		}
+		problems = problemsData.GetProblems(slug, timeframe)
+	}
+	if len(problems) == 0 {
+		writeError(w, http.StatusNotFound, printer(w, r).T("api.no_timeframe", slug, timeframe))
+		return
+	}
+
+	problems = filter.Apply(problems)
+	if wantsExport {
+		writeExport(w, r, format, export.List{Company: slug, Timeframe: timeframe, Problems: problems})
+		return
+	}
+
+	apiProblems := toAPIProblems(problems)
+	writeJSON(w, http.StatusOK, api.Response[api.CompanyProblems]{
+		Success: true,
+		Data: api.CompanyProblems{
+			Company:            slug,
+			RequestedTimeframe: requested,
+			ResolvedTimeframe:  timeframe,
+			Problems:           apiProblems,
+			Count:              len(apiProblems),
+		},
+	})
+}
+
+func listProblemsV2(w http.ResponseWriter, r *http.Request) {
+	filter, err := strictProblemFilter(w, r)
+	if err != nil {
+		writeBadRequest(w, err.Error())
+		return
+	}
+	offset, err := queryInt(w, r, "offset", 0)
+	if err != nil {
+		writeBadRequest(w, err.Error())
+		return
+	}
+
+	allowed := make(map[string]bool, len(filter.Difficulties))
+	for _, difficulty := range filter.Difficulties {
+		allowed[difficulty] = true
+	}
+
+	var matched []api.ProblemListing
+	for _, result := range problemsData.SearchIndex().All() {
+		if len(allowed) > 0 && !allowed[strings.ToLower(result.Problem.Difficulty)] {
+			continue
+		}
+		matched = append(matched, api.ProblemListing{
+			Problem:      toAPIProblem(result.Problem),
+			Companies:    result.Companies,
+			TopCompanies: result.TopCompanies,
+		})
+	}
+
+	list := api.ProblemList{Total: len(matched)}
+	matched = matched[min(offset, len(matched)):]
+	if filter.Limit > 0 && len(matched) > filter.Limit {
+		matched = matched[:filter.Limit]
+	}
+	list.Problems = matched
+	if list.Problems == nil {
+		list.Problems = []api.ProblemListing{}
+	}
+	list.Count = len(list.Problems)
+
+	writeJSON(w, http.StatusOK, api.Response[api.ProblemList]{Success: true, Data: list})
+}
+
+func getProblemV2(w http.ResponseWriter, r *http.Request) {
+	rawID := mux.Vars(r)["id"]
+	id, err := strconv.Atoi(rawID)
+	if err != nil || id <= 0 {
+		writeBadRequest(w, printer(w, r).T("api.invalid_problem_id", rawID))
+		return
+	}
+
+	result, ok := problemsData.SearchIndex().Get(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, printer(w, r).T("api.unknown_problem", id))
+		return
+	}
+
+	occurrences := problemsData.GetOccurrences(id)
+	detail := api.ProblemDetail{
+		Problem:     toAPIProblem(result.Problem),
+		Companies:   result.Companies,
+		Occurrences: make([]api.Occurrence, len(occurrences)),
+	}
+	for i, o := range occurrences {
+		detail.Occurrences[i] = api.Occurrence{Company: o.Company, Timeframe: o.Timeframe, Frequency: o.Frequency}
+	}
+
+	writeJSON(w, http.StatusOK, api.Response[api.ProblemDetail]{Success: true, Data: detail})
+}
+
+// strictProblemFilter reads ?difficulty= and ?limit= like
+// problemFilterFromRequest, but rejects unknown difficulties and bad limits
+// with an error in the client's language
+func strictProblemFilter(w http.ResponseWriter, r *http.Request) (data.Filter, error) {
+	filter := data.Filter{Difficulties: data.ParseDifficulties(r.URL.Query().Get("difficulty"))}
+	for _, difficulty := range filter.Difficulties {
+		if difficulty != "easy" && difficulty != "medium" && difficulty != "hard" {
+			return data.Filter{}, errors.New(printer(w, r).T("api.unknown_difficulty", difficulty))
+		}
+	}
+
+	limit, err := queryInt(w, r, "limit", 1)
+	if err != nil {
+		return data.Filter{}, err
+	}
+	filter.Limit = limit
+	return filter, nil
+}
+
+// queryInt reads an optional integer query parameter of at least minimum,
+// returning 0 when it is missing
+func queryInt(w http.ResponseWriter, r *http.Request, name string, minimum int) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < minimum {
+		return 0, errors.New(printer(w, r).T("api.invalid_number", name, value))
+	}
+	return n, nil
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/whotypes/leetbot/pkg/api"
+)
+
+func TestCompanyProblemsV2ResolvesTimeframe(t *testing.T) {
+	router, _ := testRouter(t)
+
+	tests := []struct {
+		path      string
+		requested string
+		resolved  string
+	}{
+		{"/api/v2/companies/google/problems", "", "thirty-days"},
+		{"/api/v2/companies/google/problems?timeframe=6mo", "6mo", "six-months"},
+		{"/api/v2/companies/google/problems?timeframe=all", "all", "all"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+			}
+
+			var resp api.Response[api.CompanyProblems]
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Data.RequestedTimeframe != tt.requested || resp.Data.ResolvedTimeframe != tt.resolved {
+				t.Errorf("requested %q resolved %q, want %q and %q",
+					resp.Data.RequestedTimeframe, resp.Data.ResolvedTimeframe, tt.requested, tt.resolved)
+			}
+			if resp.Data.Count == 0 || resp.Data.Count != len(resp.Data.Problems) {
+				t.Errorf("unexpected count %d for %d problems", resp.Data.Count, len(resp.Data.Problems))
+			}
+		})
+	}
+}
+
+func TestCompanyV2Names(t *testing.T) {
+	router, _ := testRouter(t)
+
+	for _, path := range []string{"/api/v2/companies/facebook", "/api/v2/companies/meta", "/api/v2/companies/META"} {
+		t.Run(path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+			}
+
+			var resp api.Response[api.Company]
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Data.Slug != "facebook" || resp.Data.Name != "Meta" || resp.Data.Domain != "meta.com" {
+				t.Errorf("got slug %q name %q domain %q, want facebook, Meta and meta.com",
+					resp.Data.Slug, resp.Data.Name, resp.Data.Domain)
+			}
+			if len(resp.Data.FormerNames) != 1 || resp.Data.FormerNames[0] != "Facebook" {
+				t.Errorf("former names = %q, want [Facebook]", resp.Data.FormerNames)
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v2/companies", nil))
+	var list api.Response[api.CompanyList]
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	names := make(map[string]string)
+	for _, c := range list.Data.Companies {
+		names[c.Slug] = c.Name
+	}
+	if names["facebook"] != "Meta" || names["jane-street"] != "Jane Street" {
+		t.Errorf("listing names facebook %q and jane-street %q, want Meta and Jane Street",
+			names["facebook"], names["jane-street"])
+	}
+}
+
+func TestCompanyAliasOnEveryRoute(t *testing.T) {
+	router, _ := testRouter(t)
+
+	for _, path := range []string{
+		"/api/v2/companies/meta/problems",
+		"/api/companies/meta/problems",
+		"/api/companies/Meta/timeframes/all/problems",
+		"/api/companies/meta/summary",
+	} {
+		t.Run(path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+			}
+			if !strings.Contains(rec.Body.String(), `"company":"facebook"`) {
+				t.Errorf("expected the problems of facebook, got %.200s", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestProblemsV2Pagination(t *testing.T) {
+	router, _ := testRouter(t)
+
+	page := func(path string) api.ProblemList {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		var resp api.Response[api.ProblemList]
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp.Data
+	}
+
+	first := page("/api/v2/problems?limit=10")
+	second := page("/api/v2/problems?limit=10&offset=5")
+	if first.Count != 10 || second.Count != 10 {
+		t.Fatalf("expected pages of 10, got %d and %d", first.Count, second.Count)
+	}
+	if first.Total != problemsData.Version().UniqueProblems || second.Total != first.Total {
+		t.Errorf("expected total %d, got %d and %d", problemsData.Version().UniqueProblems, first.Total, second.Total)
+	}
+	if first.Problems[5].ID != second.Problems[0].ID {
+		t.Errorf("offset 5 should start at problem %d, got %d", first.Problems[5].ID, second.Problems[0].ID)
+	}
+
+	if past := page("/api/v2/problems?offset=100000"); past.Count != 0 || past.Problems == nil {
+		t.Errorf("expected an empty list past the end, got %+v", past)
+	}
+}
+
+func TestV2ErrorsTranslated(t *testing.T) {
+	router, _ := testRouter(t)
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/api/v2/problems?difficulty=extreme", "dificultad desconocida: extreme"},
+		{"/api/v2/problems?offset=-1", "valor no válido para offset: -1"},
+		{"/api/v2/companies/google/problems?limit=many", "valor no válido para limit: many"},
+		{"/api/v2/problems?limit=0", "valor no válido para limit: 0"},
+		{"/api/v2/companies/google/problems?format=pdf", "formato de exportación desconocido: pdf, se esperaba csv, json, markdown o anki"},
+		{"/api/companies/google/problems?format=pdf", "formato de exportación desconocido: pdf, se esperaba csv, json, markdown o anki"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set("Accept-Language", "es")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d: %s", rec.Code, rec.Body.String())
+			}
+
+			var resp api.Response[any]
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Error != tt.expected {
+				t.Errorf("error = %q, want %q", resp.Error, tt.expected)
+			}
+		})
+	}
+}
+
+func TestV1Deprecation(t *testing.T) {
+	router, _ := testRouter(t)
+
+	tests := []struct {
+		path string
+		link string
+	}{
+		{"/api/companies", `</api/v2/companies>; rel="successor-version"`},
+		{"/api/companies/google/timeframes/30d/problems", `</api/v2/companies/google/problems?timeframe=30d>; rel="successor-version"`},
+		{"/api/search?q=two", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			// the second request is served from the cache
+			for range 2 {
+				rec := httptest.NewRecorder()
+				router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+				if got := rec.Header().Get("Link"); got != tt.link {
+					t.Errorf("expected Link %q, got %q", tt.link, got)
+				}
+				if deprecated := rec.Header().Get("Deprecation") == "true"; deprecated != (tt.link != "") {
+					t.Errorf("unexpected Deprecation header %q", rec.Header().Get("Deprecation"))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,11 @@
+// Package data holds the company datasets and the company registry that
+// names them, see internal/data for how they're loaded
+package data
+
+import _ "embed"
+
+// Companies is companies.json, the display names, aliases and metadata of
+// the companies in this directory
+//
+//go:embed companies.json
+var Companies []byte
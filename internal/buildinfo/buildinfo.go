@@ -0,0 +1,55 @@
+// Package buildinfo reports which build of leetbot is running.
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Version and Commit are set at build time with
+//
+//	-ldflags "-X github.com/whotypes/leetbot/internal/buildinfo.Version=v1.2.3 -X github.com/whotypes/leetbot/internal/buildinfo.Commit=abc123"
+//
+// the Makefile and Dockerfile do this. Without them the commit falls back to
+// the VCS revision the go tool stamps into binaries built inside a checkout.
+var (
+	Version = "dev"
+	Commit  = ""
+)
+
+// Info describes the running binary
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the build info, filling in the commit from the VCS stamp when
+// it wasn't set at build time
+func Get() Info {
+	info := Info{Version: Version, Commit: Commit, GoVersion: runtime.Version()}
+	if info.Commit != "" {
+		return info
+	}
+
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		info.Commit = "unknown"
+		return info
+	}
+	var modified bool
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.Commit = setting.Value
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	} else if modified {
+		info.Commit += "-dirty"
+	}
+	return info
+}
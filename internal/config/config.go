@@ -1,48 +1,183 @@
+// Package config builds the settings of the bot and the server from
+// defaults, an optional YAML, TOML or JSON file, the environment and
+// command line flags, each overriding the last.
+//
+// Every setting is a struct field tagged with its key in the file, and
+// optionally the environment variable that sets it:
+//
+//	AdminID string `config:"bot.admin_id" env:"BOT_ADMIN_ID" help:"..."`
+//
+// Fields tagged secret:"true" are redacted when the configuration is printed.
 package config
 
 import (
-	"log"
+	"log/slog"
 	"os"
+	"sync"
 
 	"github.com/joho/godotenv"
+	"github.com/whotypes/leetbot/internal/i18n"
+	"github.com/whotypes/leetbot/internal/logging"
 )
 
+// Config holds the settings shared by cmd/bot and cmd/server
 type Config struct {
-	DiscordToken string
-	BotPrefix    string
+	DiscordToken string `config:"discord.token" env:"DISCORD_TOKEN" secret:"true" help:"Bot token from the Discord developer portal"`
+	BotPrefix    string `config:"discord.prefix" env:"BOT_PREFIX" help:"Prefix for text commands"`
+
+	Bot    BotConfig
+	Server ServerConfig
+	Data   DataConfig
+	Log    LogConfig
+
+	// sources says where each setting that isn't a default came from
+	sources map[string]string
 }
 
-func Load() (*Config, error) {
+// BotConfig configures the Discord bot
+type BotConfig struct {
+	AdminID     string   `config:"bot.admin_id" env:"BOT_ADMIN_ID" help:"Discord user ID allowed to run admin commands, empty for none"`
+	Channels    []string `config:"bot.channels" env:"BOT_CHANNELS" help:"Channel IDs the bot answers in without !init"`
+	AdminAddr   string   `config:"bot.admin_addr" env:"BOT_ADMIN_ADDR" help:"Address serving metrics and health checks, empty to disable"`
+	RateLimits  string   `config:"bot.rate_limits" env:"BOT_RATE_LIMITS" help:"Command rate limit overrides, e.g. query.user=3/30s"`
+	SnapshotDir string   `config:"bot.dataset_snapshot_dir" env:"DATASET_SNAPSHOT_DIR" help:"Dataset snapshots compared against by /dataset changelog"`
 
-	_ = godotenv.Load()
+	PopularCompanies    []string          `config:"bot.popular_companies" help:"Companies autocomplete suggests first"`
+	ProblemsPerPage     int               `config:"bot.problems_per_page" help:"Problems on each page of a paginated list"`
+	PaginationThreshold int               `config:"bot.pagination_threshold" help:"Lists with more problems than this are paginated"`
+	CompanyAliases      map[string]string `config:"bot.company_aliases" help:"Names for companies on top of those in data/companies.json, e.g. goog=google"`
+	Locale              string            `config:"bot.locale" env:"BOT_LOCALE" help:"Language of replies when neither the user's nor the guild's is supported, e.g. en-US"`
+	GuildLocales        map[string]string `config:"bot.guild_locales" help:"Languages guilds are answered in whatever their users' are, e.g. 123456789012345678=de"`
+	CommandGuilds       []string          `config:"bot.command_guilds" env:"BOT_COMMAND_GUILDS" help:"Guild IDs to register slash commands in instead of globally, for development"`
+	DisabledCommands    []string          `config:"bot.disabled_commands" env:"BOT_DISABLED_COMMANDS" help:"Slash commands turned off in a guild, as guild:command, e.g. 123456789012345678:export"`
+	CompanyEnrichAPIKey string            `config:"bot.company_enrich_api_key" env:"COMPANY_ENRICH_API_KEY" secret:"true" help:"Company Enrich API key, optional. Looked up in the background for company names data/companies.json doesn't know"`
+}
+
+// DataConfig says which problem data to load
+type DataConfig struct {
+	Strict   bool     `config:"data.strict" env:"STRICT_DATA" help:"Refuse to start when the data has validation errors"`
+	Overlays []string `config:"data.overlays" env:"DATA_OVERLAYS" help:"Extra sources layered over the bundled data, later ones taking precedence"`
+}
 
-	config := &Config{
-		DiscordToken: getEnvVar("DISCORD_TOKEN", ""),
-		BotPrefix:    getEnvVar("BOT_PREFIX", "!"),
+// LogConfig configures log/slog
+type LogConfig struct {
+	Level  string `config:"log.level" env:"LOG_LEVEL" help:"debug, info, warn or error"`
+	Format string `config:"log.format" env:"LOG_FORMAT" help:"text or json"`
+}
+
+// Options returns the logging options, falling back to the defaults for
+// settings Parse would have rejected
+func (c LogConfig) Options() logging.Options {
+	opts := logging.Options{Level: slog.LevelInfo, Format: logging.FormatText, Output: os.Stderr}
+	if l, err := logging.ParseLevel(c.Level); err == nil {
+		opts.Level = l
+	}
+	if f, err := logging.ParseFormat(c.Format); err == nil {
+		opts.Format = f
+	}
+	return opts
+}
+
+// Defaults returns the configuration used when nothing overrides it
+func Defaults() *Config {
+	return &Config{
+		BotPrefix: "!",
+		Bot: BotConfig{
+			// nyumat
+			AdminID: "700444827287945316",
+			Channels: []string{
+				"947389742859812884",  // production channel 1
+				"1395661511950729308", // production channel 2
+				"1242309460689424504", // production channel 3
+				"971974276859170886",  // production channel 4
+				"905854653571420190",  // production channel 5
+				"1431649138084155403", // test channel
+			},
+			PopularCompanies: []string{
+				"amazon", "google", "facebook", "microsoft", "apple", "netflix",
+				"uber", "meta", "tesla", "nvidia", "openai", "anthropic",
+			},
+			ProblemsPerPage:     10,
+			PaginationThreshold: 10,
+			Locale:              i18n.DefaultLocale,
+			GuildLocales:        map[string]string{},
+		},
+		Server: ServerConfig{
+			Addr:                  ":8080",
+			AllowedOrigins:        []string{"*"},
+			AllowedMethods:        []string{"GET", "HEAD", "POST", "OPTIONS"},
+			ReadTimeout:           defaultReadTimeout,
+			WriteTimeout:          defaultWriteTimeout,
+			IdleTimeout:           defaultIdleTimeout,
+			ContentSecurityPolicy: DefaultContentSecurityPolicy,
+			HSTSMaxAge:            defaultHSTSMaxAge,
+			// per client IP, enough for the web app to load a few pages a
+			// second while stopping scrapers from hammering the API
+			RateLimit: "120/1m",
+			WebDir:    "./web/dist",
+		},
+		Log: LogConfig{Level: "info", Format: "text"},
+	}
+}
+
+// Load reads the bot's configuration from the environment and a .env file,
+// without a config file or flags
+func Load() (*Config, error) {
+	config, _, err := Parse("leetbot", nil)
+	if err != nil {
+		return nil, err
 	}
 
 	if config.DiscordToken == "" {
 		return nil, ErrMissingDiscordToken
 	}
 
-
 	return config, nil
 }
 
-func getEnvVar(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// dotEnv remembers the variables loadDotEnv set and their values, so a
+// reload can tell them apart from the real environment
+var dotEnv = struct {
+	sync.Mutex
+	set map[string]string
+}{set: make(map[string]string)}
+
+// loadDotEnv sets variables from a .env file that the environment doesn't
+// set. Variables an earlier load set are updated to the file's current
+// values, and unset when the file drops them.
+func loadDotEnv() {
+	dotEnv.Lock()
+	defer dotEnv.Unlock()
+
+	// a missing .env file is the same as an empty one
+	values, _ := godotenv.Read()
+
+	set := make(map[string]string, len(values))
+	for key, value := range values {
+		if current, exists := os.LookupEnv(key); exists {
+			if previous, ours := dotEnv.set[key]; !ours || current != previous {
+				continue
+			}
+		}
+		os.Setenv(key, value)
+		set[key] = value
+	}
+	for key, previous := range dotEnv.set {
+		if _, ok := set[key]; !ok && os.Getenv(key) == previous {
+			os.Unsetenv(key)
+		}
 	}
-	return defaultValue
+	dotEnv.set = set
 }
 
+// Validate checks the settings only the bot needs
 func (c *Config) Validate() error {
 	if c.DiscordToken == "" {
 		return ErrMissingDiscordToken
 	}
 
 	if c.BotPrefix == "" {
-		log.Println("Warning: BOT_PREFIX is empty, using default '!'")
+		slog.Warn("BOT_PREFIX is empty, using default", "prefix", "!")
 		c.BotPrefix = "!"
 	}
 
@@ -86,3 +86,41 @@ func TestValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestDotEnvReload(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv("LOG_LEVEL", "debug")
+	os.Unsetenv("BOT_PREFIX")
+	os.Unsetenv("BOT_ADMIN_ID")
+	t.Cleanup(func() {
+		os.Unsetenv("BOT_PREFIX")
+		os.Unsetenv("BOT_ADMIN_ID")
+		dotEnv.set = make(map[string]string)
+	})
+
+	writeEnv := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(".env", []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writeEnv("BOT_PREFIX=?\nBOT_ADMIN_ID=123456789012345678\nLOG_LEVEL=warn\n")
+	loadDotEnv()
+	if os.Getenv("BOT_PREFIX") != "?" || os.Getenv("BOT_ADMIN_ID") != "123456789012345678" {
+		t.Fatalf("expected .env to be loaded, got BOT_PREFIX=%q", os.Getenv("BOT_PREFIX"))
+	}
+
+	writeEnv("BOT_PREFIX=$\nLOG_LEVEL=warn\n")
+	loadDotEnv()
+	if got := os.Getenv("BOT_PREFIX"); got != "$" {
+		t.Errorf("a reload should pick up the edited BOT_PREFIX, got %q", got)
+	}
+	if _, ok := os.LookupEnv("BOT_ADMIN_ID"); ok {
+		t.Error("a variable removed from .env should be unset")
+	}
+	if got := os.Getenv("LOG_LEVEL"); got != "debug" {
+		t.Errorf("the environment should win over .env, got LOG_LEVEL=%q", got)
+	}
+}
@@ -1,7 +1,57 @@
 package config
 
-import "errors"
+import (
+	"errors"
+	"strings"
+)
 
 var (
 	ErrMissingDiscordToken = errors.New("DISCORD_TOKEN environment variable is required")
 )
+
+// FieldError is a setting that couldn't be parsed or isn't valid
+type FieldError struct {
+	// Key is the setting's key in the config file, e.g. server.read_timeout
+	Key string
+	// Source says where the value came from, e.g. env SERVER_READ_TIMEOUT
+	Source string
+	Err    error
+}
+
+func (e FieldError) Error() string {
+	if e.Source == "" {
+		return e.Key + ": " + e.Err.Error()
+	}
+	return e.Key + " (" + e.Source + "): " + e.Err.Error()
+}
+
+func (e FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError lists every bad setting, so they can all be fixed at once
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		lines[i] = "  " + err.Error()
+	}
+	return "invalid configuration:\n" + strings.Join(lines, "\n")
+}
+
+// problems collects field errors while checking a configuration
+type problems []FieldError
+
+func (p *problems) add(key string, err error) {
+	*p = append(*p, FieldError{Key: key, Err: err})
+}
+
+func (p problems) err() error {
+	if len(p) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: p}
+}
@@ -0,0 +1,305 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	prefixType   = reflect.TypeOf(netip.Prefix{})
+)
+
+// field is a setting, found by its config tag
+type field struct {
+	key    string
+	env    string
+	help   string
+	secret bool
+	value  reflect.Value
+}
+
+// fields lists the settings of c in declaration order
+func (c *Config) fields() []field {
+	var fields []field
+	collectFields(reflect.ValueOf(c).Elem(), &fields)
+	return fields
+}
+
+func collectFields(v reflect.Value, fields *[]field) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		key := sf.Tag.Get("config")
+		if key == "" {
+			if sf.Type.Kind() == reflect.Struct && sf.IsExported() {
+				collectFields(v.Field(i), fields)
+			}
+			continue
+		}
+		*fields = append(*fields, field{
+			key:    key,
+			env:    sf.Tag.Get("env"),
+			help:   sf.Tag.Get("help"),
+			secret: sf.Tag.Get("secret") == "true",
+			value:  v.Field(i),
+		})
+	}
+}
+
+// setValue stores raw in v. Values from the environment and flags are
+// strings, where lists are comma separated and maps are k=v pairs; values
+// from files are strings, int64, float64, bool, []any, map[string]any or
+// nil, which clears the setting.
+func setValue(v reflect.Value, raw any) error {
+	if raw == nil {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	switch v.Type() {
+	case durationType:
+		s, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("want a duration like 30s, got %s", describe(raw))
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(s))
+		if err != nil {
+			return fmt.Errorf("invalid duration %q, want one like 30s or 2m", s)
+		}
+		v.SetInt(int64(d))
+		return nil
+	case prefixType:
+		s, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("want an address or CIDR range, got %s", describe(raw))
+		}
+		prefix, err := parsePrefix(strings.TrimSpace(s))
+		if err != nil {
+			return fmt.Errorf("invalid address or CIDR range %q", s)
+		}
+		v.Set(reflect.ValueOf(prefix))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		switch raw := raw.(type) {
+		case string:
+			v.SetString(raw)
+		case int64:
+			// unquoted IDs in YAML and TOML
+			v.SetString(strconv.FormatInt(raw, 10))
+		default:
+			return fmt.Errorf("want a string, got %s", describe(raw))
+		}
+	case reflect.Bool:
+		switch raw := raw.(type) {
+		case bool:
+			v.SetBool(raw)
+		case string:
+			b, err := strconv.ParseBool(strings.TrimSpace(raw))
+			if err != nil {
+				return fmt.Errorf("want true or false, got %q", raw)
+			}
+			v.SetBool(b)
+		default:
+			return fmt.Errorf("want true or false, got %s", describe(raw))
+		}
+	case reflect.Int:
+		switch raw := raw.(type) {
+		case int64:
+			v.SetInt(raw)
+		case string:
+			n, err := strconv.Atoi(strings.TrimSpace(raw))
+			if err != nil {
+				return fmt.Errorf("want a whole number, got %q", raw)
+			}
+			v.SetInt(int64(n))
+		default:
+			return fmt.Errorf("want a whole number, got %s", describe(raw))
+		}
+	case reflect.Slice:
+		var items []any
+		switch raw := raw.(type) {
+		case []any:
+			items = raw
+		case string:
+			for _, item := range strings.Split(raw, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+		default:
+			return fmt.Errorf("want a list, got %s", describe(raw))
+		}
+		if len(items) == 0 {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		list := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setValue(list.Index(i), item); err != nil {
+				return fmt.Errorf("item %d: %w", i+1, err)
+			}
+		}
+		v.Set(list)
+	case reflect.Map:
+		entries := map[string]any{}
+		switch raw := raw.(type) {
+		case map[string]any:
+			entries = raw
+		case string:
+			for _, pair := range strings.Split(raw, ",") {
+				if pair = strings.TrimSpace(pair); pair == "" {
+					continue
+				}
+				key, value, ok := strings.Cut(pair, "=")
+				if !ok {
+					return fmt.Errorf("%q must look like key=value", pair)
+				}
+				entries[strings.TrimSpace(key)] = strings.TrimSpace(value)
+			}
+		default:
+			return fmt.Errorf("want a table of key and value pairs, got %s", describe(raw))
+		}
+		m := reflect.MakeMapWithSize(v.Type(), len(entries))
+		for key, item := range entries {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := setValue(elem, item); err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			m.SetMapIndex(reflect.ValueOf(key), elem)
+		}
+		v.Set(m)
+	default:
+		return fmt.Errorf("unsupported setting type %s", v.Type())
+	}
+	return nil
+}
+
+// describe names the type of a decoded value for error messages
+func describe(raw any) string {
+	switch raw := raw.(type) {
+	case string:
+		return strconv.Quote(raw)
+	case bool, int64, float64:
+		return fmt.Sprint(raw)
+	case []any:
+		return "a list"
+	case map[string]any:
+		return "a table"
+	}
+	return fmt.Sprintf("%T", raw)
+}
+
+// formatValue writes v as a TOML value
+func formatValue(v reflect.Value) string {
+	switch v.Type() {
+	case durationType:
+		return strconv.Quote(time.Duration(v.Int()).String())
+	case prefixType:
+		return strconv.Quote(v.Interface().(netip.Prefix).String())
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return strconv.Quote(v.String())
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Slice:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = formatValue(v.Index(i))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case reflect.Map:
+		keys := make([]string, 0, v.Len())
+		for _, key := range v.MapKeys() {
+			keys = append(keys, key.String())
+		}
+		if len(keys) == 0 {
+			return "{}"
+		}
+		slices.Sort(keys)
+		pairs := make([]string, len(keys))
+		for i, key := range keys {
+			pairs[i] = strconv.Quote(key) + " = " + formatValue(v.MapIndex(reflect.ValueOf(key)))
+		}
+		return "{ " + strings.Join(pairs, ", ") + " }"
+	}
+	out, _ := json.Marshal(v.Interface())
+	return string(out)
+}
+
+// redacted replaces set secrets
+const redacted = "<redacted>"
+
+// Print writes the configuration as TOML a config file could contain, with
+// secrets redacted and a comment saying where each value came from
+func (c *Config) Print(w io.Writer) error {
+	var b strings.Builder
+	section := ""
+	for _, f := range c.fields() {
+		if top, _, _ := strings.Cut(f.key, "."); top != section {
+			if section != "" {
+				b.WriteString("\n")
+			}
+			section = top
+		}
+
+		source := c.sources[f.key]
+		if source == "" {
+			source = "default"
+		}
+		fmt.Fprintf(&b, "%s = %s # %s\n", f.key, printedValue(f), source)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Change is a setting that differs between two configurations, with
+// secrets redacted
+type Change struct {
+	Key string
+	Old string
+	New string
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %s -> %s", c.Key, c.Old, c.New)
+}
+
+// Diff lists the settings next changes, in declaration order
+func (c *Config) Diff(next *Config) []Change {
+	var changes []Change
+	nextFields := next.fields()
+	for i, f := range c.fields() {
+		g := nextFields[i]
+		if reflect.DeepEqual(f.value.Interface(), g.value.Interface()) {
+			continue
+		}
+		changes = append(changes, Change{Key: f.key, Old: printedValue(f), New: printedValue(g)})
+	}
+	return changes
+}
+
+// printedValue formats a setting's value, redacting set secrets
+func printedValue(f field) string {
+	if f.secret && !f.value.IsZero() {
+		return strconv.Quote(redacted)
+	}
+	return formatValue(f.value)
+}
+
+var errUnknownSetting = errors.New("unknown setting")
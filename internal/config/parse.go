@@ -0,0 +1,357 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+
+	"github.com/whotypes/leetbot/internal/i18n"
+	"github.com/whotypes/leetbot/internal/logging"
+	"github.com/whotypes/leetbot/internal/ratelimit"
+)
+
+// ConfigFileEnv names the config file when -config isn't passed
+const ConfigFileEnv = "LEETBOT_CONFIG"
+
+// Parse builds the configuration from the defaults, the config file named by
+// -config or LEETBOT_CONFIG, the environment and a .env file, and the flags
+// in args, each overriding the last. Every setting has a flag named after
+// its key, e.g. -server.listen_addr=:9000.
+//
+// It reports whether -print-config was passed. A *ValidationError lists
+// every bad setting; the configuration is still returned with it so it can
+// be printed.
+func Parse(name string, args []string) (*Config, bool, error) {
+	loadDotEnv()
+
+	c := Defaults()
+	c.sources = make(map[string]string)
+	fields := c.fields()
+
+	// flags are parsed first to find the config file, but applied last
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	file := fs.String("config", os.Getenv(ConfigFileEnv), "YAML, TOML or JSON config `file` ($"+ConfigFileEnv+")")
+	printConfig := fs.Bool("print-config", false, "print the effective configuration with secrets redacted and exit")
+	flagValues := make(map[string]string)
+	for _, f := range fields {
+		usage := f.help
+		if f.env != "" {
+			usage += " ($" + f.env + ")"
+		}
+		record := func(s string) error {
+			flagValues[f.key] = s
+			return nil
+		}
+		if f.value.Kind() == reflect.Bool {
+			fs.BoolFunc(f.key, usage, record)
+		} else {
+			fs.Func(f.key, usage, record)
+		}
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, false, err
+	}
+	if fs.NArg() > 0 {
+		return nil, false, fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+
+	var p problems
+	set := func(f field, raw any, source string) {
+		if err := setValue(f.value, raw); err != nil {
+			p = append(p, FieldError{Key: f.key, Source: source, Err: err})
+			return
+		}
+		c.sources[f.key] = source
+	}
+
+	if *file != "" {
+		tree, err := readFile(*file)
+		if err != nil {
+			return nil, false, err
+		}
+		applyTree(tree, "", fields, "file "+*file, set, &p)
+	}
+
+	for _, f := range fields {
+		if value := os.Getenv(f.env); f.env != "" && value != "" {
+			set(f, value, "env "+f.env)
+		}
+	}
+	// Cloud Run only sets PORT
+	if port := os.Getenv("PORT"); port != "" && os.Getenv("LISTEN_ADDR") == "" {
+		set(fieldByKey(fields, "server.listen_addr"), ":"+port, "env PORT")
+	}
+	if os.Getenv("TRUST_PROXY") == "true" {
+		p = append(p, FieldError{Key: "server.trusted_proxies", Source: "env TRUST_PROXY",
+			Err: errors.New("TRUST_PROXY was replaced by TRUSTED_PROXIES, set it to your proxy's addresses, e.g. 169.254.0.0/16 on Cloud Run")})
+	}
+
+	for _, f := range fields {
+		if value, ok := flagValues[f.key]; ok {
+			set(f, value, "flag -"+f.key)
+		}
+	}
+
+	if c.Server.ContentSecurityPolicy == "off" {
+		c.Server.ContentSecurityPolicy = ""
+	}
+
+	// values that parsed but don't make sense are reported with their source
+	var invalid problems
+	c.check(&invalid)
+	for _, err := range invalid {
+		err.Source = c.sources[err.Key]
+		p = append(p, err)
+	}
+	return c, *printConfig, p.err()
+}
+
+// MustLoad parses the configuration like Parse for the binary called name.
+// It exits after -help or -print-config, and when the configuration is
+// invalid.
+func MustLoad(name string, args []string) *Config {
+	cfg, printConfig, err := Parse(name, args)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if printConfig && cfg != nil {
+		if err := cfg.Print(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, "failed to print configuration:", err)
+			os.Exit(1)
+		}
+	}
+	if err != nil {
+		// the errors are listed one per line, which slog would escape
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if printConfig {
+		os.Exit(0)
+	}
+	return cfg
+}
+
+// readFile decodes a config file by its extension
+func readFile(path string) (map[string]any, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+
+	var tree map[string]any
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &tree)
+	case ".toml":
+		_, err = toml.Decode(string(content), &tree)
+	case ".json":
+		tree, err = parseJSON(content)
+	default:
+		return nil, fmt.Errorf("config file %s must end in .yaml, .yml, .toml or .json", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+	if tree == nil {
+		// an empty YAML file
+		return map[string]any{}, nil
+	}
+	return normalize(tree).(map[string]any), nil
+}
+
+// parseJSON decodes JSON keeping whole numbers apart from fractions, as the
+// YAML and TOML decoders do
+func parseJSON(content []byte) (map[string]any, error) {
+	decoder := json.NewDecoder(strings.NewReader(string(content)))
+	decoder.UseNumber()
+	var tree map[string]any
+	if err := decoder.Decode(&tree); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// normalize converts a decoded file to the values setValue takes: strings,
+// bools, int64, float64, []any and map[string]any
+func normalize(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	case int:
+		return int64(v)
+	case uint64:
+		if v <= math.MaxInt64 {
+			return int64(v)
+		}
+		return strconv.FormatUint(v, 10)
+	case []map[string]any:
+		// arrays of tables in TOML
+		items := make([]any, len(v))
+		for i := range v {
+			items[i] = normalize(v[i])
+		}
+		return items
+	case []any:
+		for i := range v {
+			v[i] = normalize(v[i])
+		}
+	case map[string]any:
+		for key := range v {
+			v[key] = normalize(v[key])
+		}
+	case map[any]any:
+		// YAML mappings with keys that aren't strings, like 3: three
+		m := make(map[string]any, len(v))
+		for key, value := range v {
+			m[fmt.Sprint(key)] = normalize(value)
+		}
+		return m
+	}
+	return v
+}
+
+// applyTree sets the fields found in a decoded file, reporting keys that
+// aren't settings
+func applyTree(tree map[string]any, prefix string, fields []field, source string, set func(field, any, string), p *problems) {
+	keys := make([]string, 0, len(tree))
+	for key := range tree {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		path := prefix + key
+		if f := fieldByKey(fields, path); f.key != "" {
+			set(f, tree[key], source)
+			continue
+		}
+		table, isTable := tree[key].(map[string]any)
+		if isTable && hasSection(fields, path) {
+			applyTree(table, path+".", fields, source, set, p)
+			continue
+		}
+		*p = append(*p, FieldError{Key: path, Source: source, Err: errUnknownSetting})
+	}
+}
+
+func fieldByKey(fields []field, key string) field {
+	for _, f := range fields {
+		if f.key == key {
+			return f
+		}
+	}
+	return field{}
+}
+
+func hasSection(fields []field, section string) bool {
+	for _, f := range fields {
+		if strings.HasPrefix(f.key, section+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// check reports settings that parsed but can't work
+func (c *Config) check(p *problems) {
+	if strings.IndexFunc(c.BotPrefix, unicode.IsSpace) >= 0 {
+		p.add("discord.prefix", errors.New("can't contain spaces"))
+	}
+
+	if c.Bot.AdminID != "" && !isSnowflake(c.Bot.AdminID) {
+		p.add("bot.admin_id", fmt.Errorf("%q isn't a Discord user ID", c.Bot.AdminID))
+	}
+	for _, channel := range c.Bot.Channels {
+		if !isSnowflake(channel) {
+			p.add("bot.channels", fmt.Errorf("%q isn't a Discord channel ID", channel))
+		}
+	}
+	if _, err := ratelimit.ParseCommandLimits(c.Bot.RateLimits); err != nil {
+		p.add("bot.rate_limits", err)
+	}
+	// a page is one embed, which holds at most 25 fields
+	if c.Bot.ProblemsPerPage < 1 || c.Bot.ProblemsPerPage > 25 {
+		p.add("bot.problems_per_page", errors.New("must be between 1 and 25"))
+	}
+	if c.Bot.PaginationThreshold < 0 {
+		p.add("bot.pagination_threshold", errors.New("can't be negative"))
+	}
+	for _, company := range c.Bot.PopularCompanies {
+		if company == "" || company != strings.ToLower(company) {
+			p.add("bot.popular_companies", fmt.Errorf("%q isn't a company slug like jane-street", company))
+		}
+	}
+	aliases := make([]string, 0, len(c.Bot.CompanyAliases))
+	for alias := range c.Bot.CompanyAliases {
+		aliases = append(aliases, alias)
+	}
+	slices.Sort(aliases)
+	for _, alias := range aliases {
+		company := c.Bot.CompanyAliases[alias]
+		if alias == "" || alias != strings.ToLower(alias) || strings.ContainsAny(alias, " \t") || company == "" {
+			p.add("bot.company_aliases", fmt.Errorf("%s=%s must map a lowercase name without spaces to a company", alias, company))
+		}
+	}
+
+	if _, ok := i18n.Match(c.Bot.Locale); !ok {
+		p.add("bot.locale", fmt.Errorf("%q isn't supported, use one of %s", c.Bot.Locale, strings.Join(i18n.Locales(), ", ")))
+	}
+	guilds := make([]string, 0, len(c.Bot.GuildLocales))
+	for guild := range c.Bot.GuildLocales {
+		guilds = append(guilds, guild)
+	}
+	slices.Sort(guilds)
+	for _, guild := range guilds {
+		locale := c.Bot.GuildLocales[guild]
+		if !isSnowflake(guild) {
+			p.add("bot.guild_locales", fmt.Errorf("%q isn't a Discord guild ID", guild))
+		} else if _, ok := i18n.Match(locale); !ok {
+			p.add("bot.guild_locales", fmt.Errorf("%s=%s: %q isn't supported, use one of %s", guild, locale, locale, strings.Join(i18n.Locales(), ", ")))
+		}
+	}
+
+	for _, guild := range c.Bot.CommandGuilds {
+		if !isSnowflake(guild) {
+			p.add("bot.command_guilds", fmt.Errorf("%q isn't a Discord guild ID", guild))
+		}
+	}
+	for _, entry := range c.Bot.DisabledCommands {
+		guild, command, ok := strings.Cut(entry, ":")
+		if !ok || !isSnowflake(guild) || command == "" {
+			p.add("bot.disabled_commands", fmt.Errorf("%q isn't guild:command", entry))
+		}
+	}
+
+	if _, err := logging.ParseLevel(c.Log.Level); err != nil {
+		p.add("log.level", err)
+	}
+	if _, err := logging.ParseFormat(c.Log.Format); err != nil {
+		p.add("log.format", err)
+	}
+
+	c.Server.check(p)
+}
+
+// isSnowflake reports whether s looks like a Discord ID
+func isSnowflake(s string) bool {
+	if len(s) < 15 || len(s) > 20 {
+		return false
+	}
+	return strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' }) < 0
+}
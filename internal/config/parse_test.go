@@ -0,0 +1,331 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParsePrecedence(t *testing.T) {
+	path := writeConfig(t, "leetbot.yaml", `
+discord:
+  prefix: "?"
+bot:
+  problems_per_page: 5
+  pagination_threshold: 20
+server:
+  listen_addr: ":7000"
+  read_timeout: 10s
+`)
+	t.Setenv(ConfigFileEnv, path)
+	t.Setenv("BOT_PREFIX", "$")
+	t.Setenv("LISTEN_ADDR", ":7001")
+
+	c, _, err := Parse("leetbot", []string{"-server.listen_addr=:7002", "-bot.problems_per_page", "7"})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tests := []struct {
+		key    string
+		got    any
+		want   any
+		source string
+	}{
+		{"discord.prefix", c.BotPrefix, "$", "env BOT_PREFIX"},
+		{"bot.problems_per_page", c.Bot.ProblemsPerPage, 7, "flag -bot.problems_per_page"},
+		{"bot.pagination_threshold", c.Bot.PaginationThreshold, 20, "file " + path},
+		{"server.listen_addr", c.Server.Addr, ":7002", "flag -server.listen_addr"},
+		{"server.read_timeout", c.Server.ReadTimeout, 10 * time.Second, "file " + path},
+		{"server.write_timeout", c.Server.WriteTimeout, defaultWriteTimeout, ""},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.key, tt.got, tt.want)
+		}
+		if c.sources[tt.key] != tt.source {
+			t.Errorf("%s came from %q, want %q", tt.key, c.sources[tt.key], tt.source)
+		}
+	}
+}
+
+func TestParseFileFormats(t *testing.T) {
+	want := Defaults()
+	want.Bot.AdminID = "123456789012345678"
+	want.Bot.Channels = []string{"111111111111111111", "222222222222222222"}
+	want.Bot.CompanyAliases = map[string]string{"fb": "facebook", "goog": "google"}
+	want.Data.Strict = true
+	want.Server.AllowedOrigins = []string{"https://leetbot.org"}
+	want.Log.Format = "json"
+
+	files := map[string]string{
+		"leetbot.yaml": `
+# comments are ignored
+bot:
+  admin_id: 123456789012345678
+  channels:
+  - "111111111111111111"
+  - 222222222222222222 # unquoted IDs are read as numbers
+  company_aliases: {fb: facebook, goog: google}
+data:
+  strict: true
+server:
+  cors:
+    allowed_origins: [https://leetbot.org]
+log:
+  format: json
+`,
+		"leetbot.toml": `
+[bot]
+admin_id = "123456789012345678"
+channels = [
+  "111111111111111111",
+  "222222222222222222",
+]
+company_aliases = { fb = "facebook", "goog" = "google" }
+
+[data]
+strict = true
+
+[server.cors]
+allowed_origins = ["https://leetbot.org"]
+
+[log]
+format = 'json'
+`,
+		"leetbot.json": `{
+  "bot": {
+    "admin_id": "123456789012345678",
+    "channels": ["111111111111111111", "222222222222222222"],
+    "company_aliases": {"fb": "facebook", "goog": "google"}
+  },
+  "data": {"strict": true},
+  "server": {"cors": {"allowed_origins": ["https://leetbot.org"]}},
+  "log": {"format": "json"}
+}`,
+	}
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			c, _, err := Parse("leetbot", []string{"-config", writeConfig(t, name, content)})
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			c.sources = nil
+			if !reflect.DeepEqual(c, want) {
+				t.Errorf("Parse() = %+v, want %+v", c, want)
+			}
+		})
+	}
+}
+
+func TestParseListsEveryError(t *testing.T) {
+	path := writeConfig(t, "leetbot.toml", `
+[bot]
+admin_id = "nyumat"
+problems_per_page = 50
+popular_companies = "amazon, Google"
+locale = "tlh"
+disabled_commands = ["export"]
+colour = "blue"
+
+[server]
+read_timeout = 30
+`)
+	t.Setenv("LOG_LEVEL", "loud")
+
+	c, _, err := Parse("leetbot", []string{"-config", path, "-server.cors.allowed_methods=get"})
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("Parse() error = %v, want a *ValidationError", err)
+	}
+	if c == nil {
+		t.Fatal("Parse() should return the configuration with a validation error")
+	}
+
+	var got []string
+	for _, e := range invalid.Errors {
+		got = append(got, e.Key+" ("+e.Source+")")
+	}
+	want := []string{
+		"bot.colour (file " + path + ")",
+		"server.read_timeout (file " + path + ")",
+		"bot.admin_id (file " + path + ")",
+		"bot.problems_per_page (file " + path + ")",
+		"bot.popular_companies (file " + path + ")",
+		"bot.locale (file " + path + ")",
+		"bot.disabled_commands (file " + path + ")",
+		"log.level (env LOG_LEVEL)",
+		"server.cors.allowed_methods (flag -server.cors.allowed_methods)",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("errors = %q, want %q", got, want)
+	}
+	if !strings.Contains(err.Error(), "bot.colour (file "+path+"): unknown setting") {
+		t.Errorf("error message doesn't name the unknown setting:\n%s", err)
+	}
+}
+
+func TestParseChecksRateLimits(t *testing.T) {
+	path := writeConfig(t, "leetbot.yaml", `
+bot:
+  locale: xx
+  rate_limits: query.user=abc
+`)
+
+	_, _, err := Parse("leetbot", []string{"-config", path})
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("Parse() error = %v, want a *ValidationError", err)
+	}
+	var got []string
+	for _, e := range invalid.Errors {
+		got = append(got, e.Key)
+	}
+	if want := []string{"bot.rate_limits", "bot.locale"}; !slices.Equal(got, want) {
+		t.Errorf("errors = %q, want %q", got, want)
+	}
+}
+
+func TestParseFileFeatures(t *testing.T) {
+	files := map[string]string{
+		"leetbot.yaml": `
+bot:
+  channels: &channels
+    - "111111111111111111"
+  popular_companies: *channels
+server:
+  content_security_policy: >-
+    default-src 'self';
+    img-src 'self' data:
+`,
+		"leetbot.toml": `
+bot = { channels = ["111111111111111111"], popular_companies = ["111111111111111111"] }
+server.content_security_policy = """
+default-src 'self'; \
+img-src 'self' data:"""
+`,
+	}
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			c, _, err := Parse("leetbot", []string{"-config", writeConfig(t, name, content)})
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if want := []string{"111111111111111111"}; !slices.Equal(c.Bot.Channels, want) || !slices.Equal(c.Bot.PopularCompanies, want) {
+				t.Errorf("channels = %q, popular companies = %q, want %q", c.Bot.Channels, c.Bot.PopularCompanies, want)
+			}
+			if want := "default-src 'self'; img-src 'self' data:"; c.Server.ContentSecurityPolicy != want {
+				t.Errorf("content security policy = %q, want %q", c.Server.ContentSecurityPolicy, want)
+			}
+		})
+	}
+}
+
+func TestParseFileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"leetbot.yaml", "bot:\n  channels: [1, 2\n", "did not find expected"},
+		{"leetbot.yaml", "bot:\n  admin_id: 1\n   prefix: x\n", "line 3"},
+		{"leetbot.toml", "[bot]\nadmin_id = \"1\"\nadmin_id = \"2\"\n", "line 3"},
+		{"leetbot.toml", "[bot]\nchannels = [\"1\",\n  oops]\n", "line 3"},
+		{"leetbot.json", "{\"bot\": }", "invalid character"},
+		{"leetbot.ini", "[bot]", "must end in"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := Parse("leetbot", []string{"-config", writeConfig(t, tt.name, tt.content)})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse() error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPrint(t *testing.T) {
+	t.Setenv("DISCORD_TOKEN", "super-secret-token")
+	t.Setenv("COMPANY_ENRICH_API_KEY", "")
+
+	c, _, err := Parse("leetbot", []string{"-bot.company_aliases=fb=facebook"})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	var b strings.Builder
+	if err := c.Print(&b); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+
+	if strings.Contains(out, "super-secret-token") {
+		t.Errorf("Print() leaked the token:\n%s", out)
+	}
+	for _, line := range []string{
+		`discord.token = "<redacted>" # env DISCORD_TOKEN`,
+		`bot.company_enrich_api_key = "" # default`,
+		`bot.company_aliases = { "fb" = "facebook" } # flag -bot.company_aliases`,
+		`server.read_timeout = "30s" # default`,
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("Print() output is missing %s:\n%s", line, out)
+		}
+	}
+
+	// the output is a valid config file
+	t.Setenv("DISCORD_TOKEN", "")
+	printed := writeConfig(t, "printed.toml", out)
+	reread, _, err := Parse("leetbot", []string{"-config", printed})
+	if err != nil {
+		t.Fatalf("Parse() of the printed config error = %v", err)
+	}
+	if reread.DiscordToken != redacted || !reflect.DeepEqual(reread.Bot, c.Bot) || !reflect.DeepEqual(reread.Server, c.Server) {
+		t.Errorf("printed config read back differently:\n%+v\n%+v", reread, c)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	old := Defaults()
+	old.DiscordToken = "old-token"
+
+	next := Defaults()
+	next.DiscordToken = "new-token"
+	next.BotPrefix = "?"
+	next.Bot.Channels = next.Bot.Channels[:1]
+	next.Server.ReadTimeout = time.Minute
+
+	var got []string
+	for _, change := range old.Diff(next) {
+		got = append(got, change.String())
+	}
+	want := []string{
+		`discord.token: "<redacted>" -> "<redacted>"`,
+		`discord.prefix: "!" -> "?"`,
+		`bot.channels: ["947389742859812884", "1395661511950729308", "1242309460689424504", "971974276859170886", "905854653571420190", "1431649138084155403"] -> ["947389742859812884"]`,
+		`server.read_timeout: "30s" -> "1m0s"`,
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Diff() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if changes := old.Diff(old); len(changes) != 0 {
+		t.Errorf("Diff() of the same configuration = %v", changes)
+	}
+}
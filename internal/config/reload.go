@@ -0,0 +1,50 @@
+package config
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/whotypes/leetbot/internal/logging"
+)
+
+// Filter keeps the changes to settings under the given sections, e.g.
+// "server." for the settings a binary uses
+func Filter(changes []Change, sections ...string) []Change {
+	var kept []Change
+	for _, change := range changes {
+		for _, section := range sections {
+			if strings.HasPrefix(change.Key, section) {
+				kept = append(kept, change)
+				break
+			}
+		}
+	}
+	return kept
+}
+
+// LogChanges logs what a reload changed. Changes to settings live doesn't
+// accept only apply after a restart, which is logged as a warning.
+func LogChanges(changes []Change, live func(key string) bool) {
+	for _, change := range changes {
+		if live(change.Key) {
+			slog.Info("setting changed", "key", change.Key, "old", change.Old, "new", change.New)
+		} else {
+			slog.Warn("setting changed, restart to apply it", "key", change.Key, "old", change.Old, "new", change.New)
+		}
+	}
+	slog.Info("configuration reloaded", "changes", len(changes))
+}
+
+// LogReloadFailure logs why a reload was rejected, with a line for each
+// bad setting
+func LogReloadFailure(err error) {
+	var invalid *ValidationError
+	if errors.As(err, &invalid) {
+		for _, e := range invalid.Errors {
+			slog.Error("invalid setting", "key", e.Key, "source", e.Source, logging.Err(e.Err))
+		}
+		err = errors.New("invalid configuration")
+	}
+	slog.Error("configuration not reloaded, keeping the running settings", logging.Err(err))
+}
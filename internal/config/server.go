@@ -0,0 +1,137 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/whotypes/leetbot/internal/ratelimit"
+)
+
+// DefaultContentSecurityPolicy allows the frontend's own scripts, styles and
+// API calls and nothing else. The server adds hashes for index.html's inline
+// scripts.
+const DefaultContentSecurityPolicy = "default-src 'self'; script-src 'self'; style-src 'self' 'unsafe-inline'; " +
+	"img-src 'self' data:; font-src 'self' data:; connect-src 'self'; object-src 'none'; " +
+	"base-uri 'self'; form-action 'self'; frame-ancestors 'none'"
+
+const (
+	defaultReadTimeout  = 30 * time.Second
+	defaultWriteTimeout = 30 * time.Second
+	defaultIdleTimeout  = 120 * time.Second
+	defaultHSTSMaxAge   = 180 * 24 * time.Hour
+)
+
+// ServerConfig configures the HTTP server in cmd/server
+type ServerConfig struct {
+	// Addr also defaults to :$PORT when PORT is set, as on Cloud Run
+	Addr string `config:"server.listen_addr" env:"LISTEN_ADDR" help:"Address to listen on"`
+	// an origin of * allows any site
+	AllowedOrigins []string `config:"server.cors.allowed_origins" env:"CORS_ALLOWED_ORIGINS" help:"Origins allowed to call the API, like https://leetbot.org, or *"`
+	AllowedMethods []string `config:"server.cors.allowed_methods" env:"CORS_ALLOWED_METHODS" help:"Methods allowed in cross origin requests"`
+
+	// HTTPS is served when both are set
+	TLSCertFile string `config:"server.tls.cert_file" env:"TLS_CERT_FILE" help:"Certificate to serve HTTPS with"`
+	TLSKeyFile  string `config:"server.tls.key_file" env:"TLS_KEY_FILE" help:"Private key of the certificate"`
+
+	ReadTimeout  time.Duration `config:"server.read_timeout" env:"SERVER_READ_TIMEOUT" help:"Time to read a request"`
+	WriteTimeout time.Duration `config:"server.write_timeout" env:"SERVER_WRITE_TIMEOUT" help:"Time to write a response"`
+	IdleTimeout  time.Duration `config:"server.idle_timeout" env:"SERVER_IDLE_TIMEOUT" help:"Time to keep idle connections open"`
+
+	// only the X-Forwarded-For and X-Forwarded-Proto headers of these are believed
+	TrustedProxies []netip.Prefix `config:"server.trusted_proxies" env:"TRUSTED_PROXIES" help:"Addresses and CIDR ranges of proxies in front of the server"`
+
+	// off leaves the header out
+	ContentSecurityPolicy string `config:"server.content_security_policy" env:"CONTENT_SECURITY_POLICY" help:"Content-Security-Policy sent with every response, or off"`
+	// 0 leaves the header out
+	HSTSMaxAge time.Duration `config:"server.hsts_max_age" env:"HSTS_MAX_AGE" help:"max-age of Strict-Transport-Security on HTTPS responses, 0 to leave it out"`
+
+	RateLimit string `config:"server.rate_limit" env:"RATE_LIMIT" help:"Requests allowed per client IP, e.g. 60/1m, or off"`
+	WebDir    string `config:"server.web_dir" env:"WEB_DIR" help:"Built frontend to serve, unless it's compiled in"`
+}
+
+// Validate checks that the options are usable together
+func (c *ServerConfig) Validate() error {
+	var p problems
+	c.check(&p)
+	return p.err()
+}
+
+func (c *ServerConfig) check(p *problems) {
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		p.add("server.tls", errors.New("cert_file and key_file must be set together"))
+	}
+	if len(c.AllowedOrigins) == 0 {
+		p.add("server.cors.allowed_origins", errors.New("can't be empty, use * to allow any site"))
+	}
+	for _, origin := range c.AllowedOrigins {
+		if origin == "*" {
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || (u.Path != "" && u.Path != "/") {
+			p.add("server.cors.allowed_origins", fmt.Errorf("invalid origin %q, want a scheme and host like https://example.com", origin))
+		}
+	}
+	for _, method := range c.AllowedMethods {
+		if method == "" || strings.ToUpper(method) != method {
+			p.add("server.cors.allowed_methods", fmt.Errorf("invalid method %q", method))
+		}
+	}
+	durations := []struct {
+		key string
+		d   time.Duration
+	}{
+		{"server.read_timeout", c.ReadTimeout},
+		{"server.write_timeout", c.WriteTimeout},
+		{"server.idle_timeout", c.IdleTimeout},
+		{"server.hsts_max_age", c.HSTSMaxAge},
+	}
+	for _, d := range durations {
+		if d.d < 0 {
+			p.add(d.key, errors.New("can't be negative"))
+		}
+	}
+	if _, err := ratelimit.ParseLimit(c.RateLimit); err != nil {
+		p.add("server.rate_limit", err)
+	}
+}
+
+// TLS reports whether the server should serve HTTPS
+func (c *ServerConfig) TLS() bool {
+	return c.TLSCertFile != ""
+}
+
+// ParsePrefixes reads a comma separated list of CIDR ranges and addresses,
+// e.g. 10.0.0.0/8,192.0.2.1
+func ParsePrefixes(list string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		prefix, err := parsePrefix(item)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	return prefixes, nil
+}
+
+// parsePrefix reads a CIDR range or a single address
+func parsePrefix(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		prefix, err := netip.ParsePrefix(s)
+		return prefix.Masked(), err
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"net/netip"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseServer(t *testing.T) {
+	t.Setenv("PORT", "9000")
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://leetbot.org, http://localhost:5173")
+	t.Setenv("SERVER_WRITE_TIMEOUT", "1m")
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, 192.0.2.1")
+
+	cfg, _, err := Parse("server", nil)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	c := cfg.Server
+
+	if c.Addr != ":9000" {
+		t.Errorf("Addr = %q, want :9000", c.Addr)
+	}
+	if want := []string{"https://leetbot.org", "http://localhost:5173"}; !slices.Equal(c.AllowedOrigins, want) {
+		t.Errorf("AllowedOrigins = %v, want %v", c.AllowedOrigins, want)
+	}
+	if want := []string{"GET", "HEAD", "POST", "OPTIONS"}; !slices.Equal(c.AllowedMethods, want) {
+		t.Errorf("AllowedMethods = %v, want %v", c.AllowedMethods, want)
+	}
+	if c.ReadTimeout != 30*time.Second || c.WriteTimeout != time.Minute {
+		t.Errorf("timeouts = %v and %v, want 30s and 1m", c.ReadTimeout, c.WriteTimeout)
+	}
+	want := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("192.0.2.1/32")}
+	if !slices.Equal(c.TrustedProxies, want) {
+		t.Errorf("TrustedProxies = %v, want %v", c.TrustedProxies, want)
+	}
+	if c.ContentSecurityPolicy != DefaultContentSecurityPolicy || c.TLS() {
+		t.Errorf("unexpected defaults: CSP %q, TLS %v", c.ContentSecurityPolicy, c.TLS())
+	}
+}
+
+func TestParseServerErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+	}{
+		{"legacy TRUST_PROXY", map[string]string{"TRUST_PROXY": "true"}},
+		{"cert without key", map[string]string{"TLS_CERT_FILE": "cert.pem"}},
+		{"bad origin", map[string]string{"CORS_ALLOWED_ORIGINS": "leetbot.org"}},
+		{"origin with path", map[string]string{"CORS_ALLOWED_ORIGINS": "https://leetbot.org/app"}},
+		{"lowercase method", map[string]string{"CORS_ALLOWED_METHODS": "get"}},
+		{"bad timeout", map[string]string{"SERVER_READ_TIMEOUT": "30"}},
+		{"negative timeout", map[string]string{"SERVER_IDLE_TIMEOUT": "-1s"}},
+		{"bad proxy", map[string]string{"TRUSTED_PROXIES": "10.0.0.0/33"}},
+		{"bad rate limit", map[string]string{"RATE_LIMIT": "lots"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			if _, _, err := Parse("server", nil); err == nil {
+				t.Error("Parse() expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package data
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+
+	companyfiles "github.com/whotypes/leetbot/data"
+)
+
+// CompanyInfo is a company's entry in data/companies.json. Only the slug and
+// name are required.
+type CompanyInfo struct {
+	// Slug is the company's directory under data/
+	Slug string `json:"slug"`
+	// Name is how the company is shown, e.g. Meta for facebook
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases,omitempty"`
+	Tickers []string `json:"tickers,omitempty"`
+	// FormerNames are names the company went by before, e.g. Facebook
+	FormerNames []string `json:"former_names,omitempty"`
+	Domain      string   `json:"domain,omitempty"`
+	LogoURL     string   `json:"logo_url,omitempty"`
+	// Parent is the slug of the company that owns this one
+	Parent string `json:"parent,omitempty"`
+}
+
+// names lists everything the company can be looked up by besides its slug
+func (c CompanyInfo) names() []string {
+	names := []string{c.Name}
+	names = append(names, c.Aliases...)
+	names = append(names, c.Tickers...)
+	return append(names, c.FormerNames...)
+}
+
+// CompanyRegistry resolves the names, aliases, tickers and former names of
+// companies to their slugs
+type CompanyRegistry struct {
+	companies map[string]CompanyInfo
+	// byName maps normalized names to slugs
+	byName map[string]string
+}
+
+// ParseCompanyRegistry reads a companies.json array, reporting every invalid
+// entry rather than the first
+func ParseCompanyRegistry(content []byte) (*CompanyRegistry, error) {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	var entries []CompanyInfo
+	if err := decoder.Decode(&entries); err != nil {
+		return nil, fmt.Errorf("error parsing companies: %w", err)
+	}
+
+	r := &CompanyRegistry{
+		companies: make(map[string]CompanyInfo, len(entries)),
+		byName:    make(map[string]string),
+	}
+	var errs []error
+	for i, c := range entries {
+		entry := fmt.Sprintf("company %d (%s)", i+1, c.Slug)
+		switch {
+		case !isCompanySlug(c.Slug):
+			errs = append(errs, fmt.Errorf("%s: slug must be lowercase letters, digits and dashes", entry))
+			continue
+		case r.companies[c.Slug].Slug != "":
+			errs = append(errs, fmt.Errorf("%s: slug is listed twice", entry))
+			continue
+		}
+		if strings.TrimSpace(c.Name) == "" {
+			errs = append(errs, fmt.Errorf("%s: name is required", entry))
+		}
+		if strings.Contains(c.Domain, "/") {
+			errs = append(errs, fmt.Errorf("%s: domain %q should be a host name, not a URL", entry, c.Domain))
+		}
+		if c.LogoURL != "" {
+			if u, err := url.Parse(c.LogoURL); err != nil || u.Scheme != "https" || u.Host == "" {
+				errs = append(errs, fmt.Errorf("%s: logo_url %q must be an https URL", entry, c.LogoURL))
+			}
+		}
+		r.companies[c.Slug] = c
+
+		for _, name := range c.names() {
+			key := NormalizeCompanyName(name)
+			if key == "" {
+				if name != c.Name {
+					errs = append(errs, fmt.Errorf("%s: %q has no letters or digits", entry, name))
+				}
+				continue
+			}
+			if other, ok := r.byName[key]; ok && other != c.Slug {
+				errs = append(errs, fmt.Errorf("%s: %q already names %s", entry, name, other))
+				continue
+			}
+			r.byName[key] = c.Slug
+		}
+	}
+
+	// parents can come later in the file, so they're checked once all are read
+	for i, c := range entries {
+		if c.Parent == "" || r.companies[c.Slug].Slug != c.Slug {
+			continue
+		}
+		if c.Parent == c.Slug {
+			errs = append(errs, fmt.Errorf("company %d (%s): a company can't be its own parent", i+1, c.Slug))
+		} else if _, ok := r.companies[c.Parent]; !ok {
+			errs = append(errs, fmt.Errorf("company %d (%s): parent %q isn't in the registry", i+1, c.Slug, c.Parent))
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return r, nil
+}
+
+var companies = sync.OnceValue(func() *CompanyRegistry {
+	r, err := ParseCompanyRegistry(companyfiles.Companies)
+	if err != nil {
+		// data/companies.json is checked by the tests
+		panic(err)
+	}
+	return r
+})
+
+// Companies returns the registry embedded from data/companies.json
+func Companies() *CompanyRegistry {
+	return companies()
+}
+
+// Info returns the registry entry for slug
+func (r *CompanyRegistry) Info(slug string) (CompanyInfo, bool) {
+	c, ok := r.companies[slug]
+	return c, ok
+}
+
+// All returns every entry, sorted by slug
+func (r *CompanyRegistry) All() []CompanyInfo {
+	all := make([]CompanyInfo, 0, len(r.companies))
+	for _, c := range r.companies {
+		all = append(all, c)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Slug < all[j].Slug })
+	return all
+}
+
+// Resolve finds the company a name, alias, ticker or former name belongs to,
+// ignoring case, spaces and punctuation, so "J.P. Morgan" finds jpmorgan
+func (r *CompanyRegistry) Resolve(name string) (string, bool) {
+	slug, ok := r.byName[NormalizeCompanyName(name)]
+	return slug, ok
+}
+
+// Names returns what the company can be searched by besides its slug: its
+// display name, aliases, tickers and former names
+func (r *CompanyRegistry) Names(slug string) []string {
+	c, ok := r.companies[slug]
+	if !ok {
+		return nil
+	}
+	return c.names()
+}
+
+// DisplayName returns the company's name, falling back to title casing the
+// slug for companies the registry doesn't list, e.g. jane-street is Jane Street
+func (r *CompanyRegistry) DisplayName(slug string) string {
+	if c, ok := r.companies[slug]; ok {
+		return c.Name
+	}
+	words := strings.Split(slug, "-")
+	caser := cases.Title(language.English)
+	for i, word := range words {
+		words[i] = caser.String(word)
+	}
+	return strings.Join(words, " ")
+}
+
+// NormalizeCompanyName keeps only the lowercased letters and digits of name,
+// which is how the registry compares names
+func NormalizeCompanyName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func isCompanySlug(slug string) bool {
+	if slug == "" || strings.HasPrefix(slug, "-") || strings.HasSuffix(slug, "-") {
+		return false
+	}
+	for _, r := range slug {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,149 @@
+package data
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestEmbeddedCompanyRegistry(t *testing.T) {
+	r, err := ParseCompanyRegistry(mustReadFile(t, "../../data/companies.json"))
+	if err != nil {
+		t.Fatalf("data/companies.json is invalid:\n%v", err)
+	}
+
+	entries, err := os.ReadDir("../../data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	slugs := make(map[string]string)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			slugs[NormalizeCompanyName(entry.Name())] = entry.Name()
+		}
+	}
+
+	for _, c := range r.All() {
+		if !dirExists("../../data/" + c.Slug) {
+			t.Errorf("%s is in companies.json but has no data directory", c.Slug)
+		}
+		// an alias matching another company's slug would hide that company
+		for _, name := range c.names() {
+			if other, ok := slugs[NormalizeCompanyName(name)]; ok && other != c.Slug {
+				t.Errorf("%s: %q is also the slug of %s", c.Slug, name, other)
+			}
+		}
+	}
+}
+
+func TestCompanyRegistryResolve(t *testing.T) {
+	r := Companies()
+
+	tests := []struct {
+		name  string
+		slug  string
+		found bool
+	}{
+		{"meta", "facebook", true},
+		{"Facebook", "facebook", true},
+		{"FB", "facebook", true},
+		{"J.P. Morgan", "jpmorgan", true},
+		{"jp-morgan", "jpmorgan", true},
+		{"AMZN", "amazon", true},
+		{"alphabet", "google", true},
+		{"Hudson River Trading", "hrt", true},
+		{"AT&T", "at-t", true},
+		{"  booking.com ", "bookingcom", true},
+		{"jane street", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		slug, found := r.Resolve(tt.name)
+		if found != tt.found || slug != tt.slug {
+			t.Errorf("Resolve(%q) = %q, %v, want %q, %v", tt.name, slug, found, tt.slug, tt.found)
+		}
+	}
+}
+
+func TestCompanyRegistryDisplayName(t *testing.T) {
+	r := Companies()
+
+	tests := map[string]string{
+		"facebook":    "Meta",
+		"hrt":         "Hudson River Trading",
+		"ibm":         "IBM",
+		"jane-street": "Jane Street",
+		"airbnb":      "Airbnb",
+	}
+	for slug, want := range tests {
+		if got := r.DisplayName(slug); got != want {
+			t.Errorf("DisplayName(%q) = %q, want %q", slug, got, want)
+		}
+	}
+
+	info, ok := r.Info("deepmind")
+	if !ok || info.Parent != "google" {
+		t.Errorf("Info(deepmind) = %+v, %v, want google as the parent", info, ok)
+	}
+}
+
+func TestParseCompanyRegistryErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr []string
+	}{
+		{"not an array", `{"slug": "acme"}`, []string{"error parsing companies"}},
+		{"unknown field", `[{"slug": "acme", "name": "Acme", "ticker": "ACME"}]`, []string{`unknown field "ticker"`}},
+		{
+			"every bad entry",
+			`[
+				{"slug": "Acme", "name": "Acme"},
+				{"slug": "globex", "name": ""},
+				{"slug": "globex", "name": "Globex"},
+				{"slug": "initech", "name": "Initech", "domain": "https://initech.com", "logo_url": "http://initech.com/logo.png"},
+				{"slug": "hooli", "name": "Hooli", "aliases": ["initech"], "parent": "hooli"},
+				{"slug": "umbrella", "name": "Umbrella", "parent": "wayne"}
+			]`,
+			[]string{
+				"company 1 (Acme): slug must be",
+				"company 2 (globex): name is required",
+				"company 3 (globex): slug is listed twice",
+				`company 4 (initech): domain "https://initech.com"`,
+				`company 4 (initech): logo_url "http://initech.com/logo.png" must be an https URL`,
+				`company 5 (hooli): "initech" already names initech`,
+				"company 5 (hooli): a company can't be its own parent",
+				`company 6 (umbrella): parent "wayne" isn't in the registry`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCompanyRegistry([]byte(tt.content))
+			if err == nil {
+				t.Fatal("ParseCompanyRegistry() error = nil")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error is missing %q:\n%v", want, err)
+				}
+			}
+		})
+	}
+}
+
+func mustReadFile(t *testing.T, path string) []byte {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return content
+}
+
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
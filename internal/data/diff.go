@@ -0,0 +1,129 @@
+package data
+
+import (
+	"math"
+	"sort"
+)
+
+// FrequencyChange records a problem whose frequency moved between two datasets
+type FrequencyChange struct {
+	Problem Problem `json:"problem"`
+	Old     float64 `json:"old"`
+	New     float64 `json:"new"`
+}
+
+// Delta returns the signed change in frequency percentage points
+func (c FrequencyChange) Delta() float64 {
+	return c.New - c.Old
+}
+
+// TimeframeDiff lists changes within a single company timeframe
+type TimeframeDiff struct {
+	Company          string            `json:"company"`
+	Timeframe        string            `json:"timeframe"`
+	Added            []Problem         `json:"added,omitempty"`
+	Removed          []Problem         `json:"removed,omitempty"`
+	FrequencyChanges []FrequencyChange `json:"frequency_changes,omitempty"`
+}
+
+// Changelog describes every difference between two datasets
+type Changelog struct {
+	From             DatasetVersion  `json:"from"`
+	To               DatasetVersion  `json:"to"`
+	AddedCompanies   []string        `json:"added_companies,omitempty"`
+	RemovedCompanies []string        `json:"removed_companies,omitempty"`
+	Timeframes       []TimeframeDiff `json:"timeframes,omitempty"`
+}
+
+// Empty reports whether the two datasets are equivalent for the diff threshold
+func (c *Changelog) Empty() bool {
+	return len(c.AddedCompanies) == 0 && len(c.RemovedCompanies) == 0 && len(c.Timeframes) == 0
+}
+
+// Diff compares two datasets. Frequency changes smaller than threshold
+// percentage points are ignored. Added companies are reported once instead
+// of listing every problem they contain, and likewise for removed companies.
+func Diff(from, to *ProblemsByCompany, threshold float64) *Changelog {
+	changelog := &Changelog{
+		From: from.Version(),
+		To:   to.Version(),
+	}
+
+	for _, company := range to.GetAvailableCompanies() {
+		if _, ok := from.data[company]; !ok {
+			changelog.AddedCompanies = append(changelog.AddedCompanies, company)
+		}
+	}
+
+	for _, company := range from.GetAvailableCompanies() {
+		toTimeframes, ok := to.data[company]
+		if !ok {
+			changelog.RemovedCompanies = append(changelog.RemovedCompanies, company)
+			continue
+		}
+
+		fromTimeframes := from.data[company]
+		var timeframes []string
+		for timeframe := range fromTimeframes {
+			timeframes = append(timeframes, timeframe)
+		}
+		for timeframe := range toTimeframes {
+			if _, ok := fromTimeframes[timeframe]; !ok {
+				timeframes = append(timeframes, timeframe)
+			}
+		}
+
+		for _, timeframe := range sortTimeframes(timeframes) {
+			tfDiff := diffProblems(fromTimeframes[timeframe], toTimeframes[timeframe], threshold)
+			if len(tfDiff.Added) == 0 && len(tfDiff.Removed) == 0 && len(tfDiff.FrequencyChanges) == 0 {
+				continue
+			}
+			tfDiff.Company = company
+			tfDiff.Timeframe = timeframe
+			changelog.Timeframes = append(changelog.Timeframes, tfDiff)
+		}
+	}
+
+	return changelog
+}
+
+func diffProblems(from, to []Problem, threshold float64) TimeframeDiff {
+	var tfDiff TimeframeDiff
+
+	fromByID := make(map[int]Problem, len(from))
+	for _, p := range from {
+		fromByID[p.ID] = p
+	}
+	toByID := make(map[int]Problem, len(to))
+	for _, p := range to {
+		toByID[p.ID] = p
+	}
+
+	for _, p := range to {
+		old, ok := fromByID[p.ID]
+		if !ok {
+			tfDiff.Added = append(tfDiff.Added, p)
+			continue
+		}
+		if p.Frequency != old.Frequency && math.Abs(p.Frequency-old.Frequency) >= threshold {
+			tfDiff.FrequencyChanges = append(tfDiff.FrequencyChanges, FrequencyChange{
+				Problem: p,
+				Old:     old.Frequency,
+				New:     p.Frequency,
+			})
+		}
+	}
+
+	for _, p := range from {
+		if _, ok := toByID[p.ID]; !ok {
+			tfDiff.Removed = append(tfDiff.Removed, p)
+		}
+	}
+
+	// biggest movers first
+	sort.SliceStable(tfDiff.FrequencyChanges, func(i, j int) bool {
+		return math.Abs(tfDiff.FrequencyChanges[i].Delta()) > math.Abs(tfDiff.FrequencyChanges[j].Delta())
+	})
+
+	return tfDiff
+}
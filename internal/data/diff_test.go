@@ -0,0 +1,80 @@
+package data
+
+import (
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	from := NewTestProblemsByCompany(map[string]map[string][]Problem{
+		"airbnb": {
+			"all": []Problem{
+				{ID: 1, Title: "Two Sum", Difficulty: "Easy", Frequency: 100.0},
+				{ID: 2, Title: "Add Two Numbers", Difficulty: "Medium", Frequency: 80.0},
+				{ID: 3, Title: "Longest Substring", Difficulty: "Medium", Frequency: 50.0},
+			},
+		},
+		"oldco": {
+			"all": []Problem{{ID: 1, Title: "Two Sum", Difficulty: "Easy", Frequency: 100.0}},
+		},
+	})
+	to := NewTestProblemsByCompany(map[string]map[string][]Problem{
+		"airbnb": {
+			"all": []Problem{
+				{ID: 1, Title: "Two Sum", Difficulty: "Easy", Frequency: 98.0},
+				{ID: 2, Title: "Add Two Numbers", Difficulty: "Medium", Frequency: 40.0},
+				{ID: 4, Title: "Median of Two Sorted Arrays", Difficulty: "Hard", Frequency: 30.0},
+			},
+			"thirty-days": []Problem{{ID: 1, Title: "Two Sum", Difficulty: "Easy", Frequency: 100.0}},
+		},
+		"newco": {
+			"all": []Problem{{ID: 1, Title: "Two Sum", Difficulty: "Easy", Frequency: 100.0}},
+		},
+	})
+
+	changelog := Diff(from, to, 10)
+
+	if changelog.Empty() {
+		t.Fatal("Diff() should not be empty")
+	}
+	if len(changelog.AddedCompanies) != 1 || changelog.AddedCompanies[0] != "newco" {
+		t.Errorf("added companies = %v, want [newco]", changelog.AddedCompanies)
+	}
+	if len(changelog.RemovedCompanies) != 1 || changelog.RemovedCompanies[0] != "oldco" {
+		t.Errorf("removed companies = %v, want [oldco]", changelog.RemovedCompanies)
+	}
+
+	if len(changelog.Timeframes) != 2 {
+		t.Fatalf("timeframe diffs = %d, want 2", len(changelog.Timeframes))
+	}
+
+	// thirty-days is more recent so it comes first
+	if changelog.Timeframes[0].Timeframe != "thirty-days" || len(changelog.Timeframes[0].Added) != 1 {
+		t.Errorf("first diff = %+v, want thirty-days with one added problem", changelog.Timeframes[0])
+	}
+
+	all := changelog.Timeframes[1]
+	if len(all.Added) != 1 || all.Added[0].ID != 4 {
+		t.Errorf("added = %v, want problem 4", all.Added)
+	}
+	if len(all.Removed) != 1 || all.Removed[0].ID != 3 {
+		t.Errorf("removed = %v, want problem 3", all.Removed)
+	}
+	// the 2 point move on Two Sum is below the threshold
+	if len(all.FrequencyChanges) != 1 || all.FrequencyChanges[0].Problem.ID != 2 || all.FrequencyChanges[0].Delta() != -40 {
+		t.Errorf("frequency changes = %+v, want problem 2 down 40 points", all.FrequencyChanges)
+	}
+}
+
+func TestDiff_Identical(t *testing.T) {
+	testData := map[string]map[string][]Problem{
+		"airbnb": {"all": []Problem{{ID: 1, Title: "Two Sum", Difficulty: "Easy", Frequency: 100.0}}},
+	}
+
+	changelog := Diff(NewTestProblemsByCompany(testData), NewTestProblemsByCompany(testData), 0)
+	if !changelog.Empty() {
+		t.Errorf("Diff() of identical datasets = %+v, want empty", changelog)
+	}
+	if changelog.From.Hash != changelog.To.Hash {
+		t.Error("identical datasets should have the same hash")
+	}
+}
@@ -0,0 +1,51 @@
+package data
+
+import (
+	"strings"
+)
+
+// Filter narrows down a list of problems.
+// The zero value matches every problem.
+type Filter struct {
+	// Difficulties keeps only problems with one of these difficulties (case-insensitive)
+	Difficulties []string
+	// Limit caps the number of problems returned, 0 means no limit
+	Limit int
+}
+
+// ParseDifficulties splits a comma separated difficulty list such as "easy,medium"
+func ParseDifficulties(s string) []string {
+	var difficulties []string
+	for _, part := range strings.Split(s, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part != "" && part != "any" && part != "all" {
+			difficulties = append(difficulties, part)
+		}
+	}
+	return difficulties
+}
+
+// Apply returns the problems matching the filter, preserving their order
+func (f Filter) Apply(problems []Problem) []Problem {
+	if len(f.Difficulties) == 0 && f.Limit <= 0 {
+		return problems
+	}
+
+	allowed := make(map[string]bool, len(f.Difficulties))
+	for _, difficulty := range f.Difficulties {
+		allowed[strings.ToLower(strings.TrimSpace(difficulty))] = true
+	}
+
+	filtered := make([]Problem, 0, len(problems))
+	for _, problem := range problems {
+		if len(allowed) > 0 && !allowed[strings.ToLower(problem.Difficulty)] {
+			continue
+		}
+		filtered = append(filtered, problem)
+		if f.Limit > 0 && len(filtered) >= f.Limit {
+			break
+		}
+	}
+
+	return filtered
+}
@@ -0,0 +1,68 @@
+package data
+
+import (
+	"testing"
+)
+
+func TestParseDifficulties(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"", nil},
+		{"easy", []string{"easy"}},
+		{"Easy, HARD", []string{"easy", "hard"}},
+		{"medium,,", []string{"medium"}},
+		{"any", nil},
+	}
+
+	for _, tt := range tests {
+		result := ParseDifficulties(tt.input)
+		if len(result) != len(tt.expected) {
+			t.Errorf("ParseDifficulties(%q) = %v, want %v", tt.input, result, tt.expected)
+			continue
+		}
+		for i := range result {
+			if result[i] != tt.expected[i] {
+				t.Errorf("ParseDifficulties(%q) = %v, want %v", tt.input, result, tt.expected)
+				break
+			}
+		}
+	}
+}
+
+func TestFilterApply(t *testing.T) {
+	problems := []Problem{
+		{ID: 1, Title: "Two Sum", Difficulty: "Easy", Frequency: 100.0},
+		{ID: 2, Title: "Add Two Numbers", Difficulty: "Medium", Frequency: 90.0},
+		{ID: 4, Title: "Median of Two Sorted Arrays", Difficulty: "Hard", Frequency: 80.0},
+		{ID: 20, Title: "Valid Parentheses", Difficulty: "Easy", Frequency: 70.0},
+	}
+
+	tests := []struct {
+		name    string
+		filter  Filter
+		wantIDs []int
+	}{
+		{"zero value matches all", Filter{}, []int{1, 2, 4, 20}},
+		{"single difficulty", Filter{Difficulties: []string{"easy"}}, []int{1, 20}},
+		{"case insensitive", Filter{Difficulties: []string{"HARD", "Medium"}}, []int{2, 4}},
+		{"limit", Filter{Limit: 2}, []int{1, 2}},
+		{"difficulty and limit", Filter{Difficulties: []string{"easy"}, Limit: 1}, []int{1}},
+		{"no matches", Filter{Difficulties: []string{"impossible"}}, []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.filter.Apply(problems)
+			if len(result) != len(tt.wantIDs) {
+				t.Fatalf("Apply() returned %d problems, want %d", len(result), len(tt.wantIDs))
+			}
+			for i, id := range tt.wantIDs {
+				if result[i].ID != id {
+					t.Errorf("Apply()[%d].ID = %d, want %d", i, result[i].ID, id)
+				}
+			}
+		})
+	}
+}
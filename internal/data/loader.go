@@ -1,11 +1,10 @@
 package data
 
 import (
-	"encoding/csv"
-	"fmt"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 type Problem struct {
@@ -15,29 +14,39 @@ type Problem struct {
 	Difficulty string
 	Acceptance float64
 	Frequency  float64
+	// Source names where the problem came from, see Source
+	Source string
 }
 
 type ProblemsByCompany struct {
-	data map[string]map[string][]Problem
+	data    map[string]map[string][]Problem
+	version DatasetVersion
+
+	searchOnce sync.Once
+	search     *SearchIndex
+
+	cooccurrenceOnce sync.Once
+	cooccurrenceData *cooccurrence
+}
+
+// LoadOptions controls how the embedded dataset is loaded
+type LoadOptions struct {
+	// Strict fails the load on any validation error instead of skipping bad rows
+	Strict bool
+	// Overlays are layered over the embedded dataset in order of increasing precedence
+	Overlays []Source
 }
 
 func LoadAllProblems() (*ProblemsByCompany, error) {
-	pbc := &ProblemsByCompany{
-		data: make(map[string]map[string][]Problem),
-	}
+	return LoadAllProblemsWithOptions(LoadOptions{})
+}
 
-	for company, timeframes := range embeddedCSVs {
-		pbc.data[company] = make(map[string][]Problem)
-		for timeframe, csvData := range timeframes {
-			problems, err := parseCSV(csvData)
-			if err != nil {
-				return nil, fmt.Errorf("error parsing CSV for %s/%s: %w", company, timeframe, err)
-			}
-			pbc.data[company][timeframe] = problems
-		}
+func LoadAllProblemsWithOptions(opts LoadOptions) (*ProblemsByCompany, error) {
+	source := NewEmbeddedSource(opts.Strict)
+	if len(opts.Overlays) > 0 {
+		source = NewMergedSource(append([]Source{source}, opts.Overlays...)...)
 	}
-
-	return pbc, nil
+	return NewProblemsByCompany(source)
 }
 
 func (pbc *ProblemsByCompany) GetProblems(company, timeframe string) []Problem {
@@ -87,10 +96,8 @@ func (pbc *ProblemsByCompany) CompanyExists(company string) bool {
 func (pbc *ProblemsByCompany) GetProblemsWithPriority(company string) ([]Problem, string) {
 	company = strings.ToLower(strings.TrimSpace(company))
 
-	priorities := []string{"thirty-days", "three-months", "six-months", "more-than-six-months", "all"}
-
 	if companyData, ok := pbc.data[company]; ok {
-		for _, timeframe := range priorities {
+		for _, timeframe := range timeframePriority {
 			if problems, ok := companyData[timeframe]; ok && len(problems) > 0 {
 				return problems, timeframe
 			}
@@ -112,74 +119,51 @@ func (pbc *ProblemsByCompany) GetAllProblems() map[string]map[string][]Problem {
 }
 
 func normalizeTimeframe(timeframe string) string {
+	if tf, ok := recognizeTimeframe(timeframe); ok {
+		return tf
+	}
+	return "all"
+}
+
+// recognizeTimeframe maps a timeframe alias to its canonical name
+func recognizeTimeframe(timeframe string) (string, bool) {
 	timeframe = strings.ToLower(strings.TrimSpace(timeframe))
 	timeframe = strings.ReplaceAll(timeframe, " ", "-")
 
 	switch timeframe {
 	case "30", "30days", "30-days", "thirty", "thirtydays", "thirty-days", "30d":
-		return "thirty-days"
+		return "thirty-days", true
 	case "90", "90days", "90-days", "three", "threemonths", "three-months", "3months", "3-months", "3mo", "90d":
-		return "three-months"
+		return "three-months", true
 	case "180", "180days", "180-days", "six", "sixmonths", "six-months", "6months", "6-months", "6mo":
-		return "six-months"
+		return "six-months", true
 	case "all", "alltime", "all-time", "everything", "":
-		return "all"
+		return "all", true
 	case "more-than-six-months", "morethan6months", "more-than-6-months", ">6mo", ">6months":
-		return "more-than-six-months"
+		return "more-than-six-months", true
 	}
 
-	for _, tf := range []string{"all", "thirty-days", "three-months", "six-months", "more-than-six-months"} {
-		if timeframe == tf {
-			return tf
-		}
-	}
-
-	return "all"
+	return "", false
 }
 
+// parseCSV parses a data file, skipping rows that cannot be used.
+// Use a Validator to find out what was skipped.
 func parseCSV(csvData []byte) ([]Problem, error) {
-	reader := csv.NewReader(strings.NewReader(string(csvData)))
-	records, err := reader.ReadAll()
+	problems, _, _, err := parseCSVFile("", csvData)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(records) < 2 {
-		return nil, nil
-	}
-
-	var problems []Problem
-	for i := 1; i < len(records); i++ {
-		record := records[i]
-		if len(record) < 6 {
-			continue
-		}
-
-		id, err := strconv.Atoi(record[0])
-		if err != nil {
-			continue
-		}
-
-		acceptance, _ := parsePercentage(record[4])
-		frequency, _ := parsePercentage(record[5])
-
-		problem := Problem{
-			ID:         id,
-			URL:        record[1],
-			Title:      record[2],
-			Difficulty: record[3],
-			Acceptance: acceptance,
-			Frequency:  frequency,
-		}
+	sortByFrequency(problems)
 
-		problems = append(problems, problem)
-	}
+	return problems, nil
+}
 
-	sort.Slice(problems, func(i, j int) bool {
+func sortByFrequency(problems []Problem) {
+	// stable so ties keep their file order and reloading a snapshot is deterministic
+	sort.SliceStable(problems, func(i, j int) bool {
 		return problems[i].Frequency > problems[j].Frequency
 	})
-
-	return problems, nil
 }
 
 func parsePercentage(s string) (float64, error) {
@@ -200,5 +184,7 @@ func NewTestProblemsByCompany(testData map[string]map[string][]Problem) *Problem
 		}
 	}
 
+	pbc.version = computeVersion(pbc.data)
+
 	return pbc
 }
@@ -0,0 +1,427 @@
+package data
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	// match weights, an exact token beats a prefix which beats a typo
+	exactMatchWeight  = 1.0
+	prefixMatchWeight = 0.7
+	typoMatchWeight   = 0.5
+
+	// popularityWeight scales how much being asked at many companies boosts a result
+	popularityWeight = 0.5
+
+	// searchTopCompanies is how many companies are listed per search result
+	searchTopCompanies = 5
+)
+
+// searchStopwords are ignored in queries unless the query has nothing else
+var searchStopwords = map[string]bool{
+	"a": true, "an": true, "and": true, "the": true, "of": true, "in": true,
+	"to": true, "from": true, "for": true, "with": true, "on": true, "by": true,
+}
+
+// SearchResult is a problem matched by a search query
+type SearchResult struct {
+	// Problem is the occurrence with the highest frequency across all companies
+	Problem Problem `json:"problem"`
+	Score   float64 `json:"score"`
+	// Companies is how many companies list the problem in any timeframe
+	Companies int `json:"companies"`
+	// TopCompanies lists the companies where the problem is most frequent
+	TopCompanies []string `json:"top_companies"`
+}
+
+type searchDoc struct {
+	problem      Problem
+	companies    int
+	topCompanies []string
+	titleTokens  []string
+}
+
+// SearchIndex is an inverted index over problem titles and URL slugs
+type SearchIndex struct {
+	docs     []searchDoc
+	byID     map[int]int
+	postings map[string][]int
+	// terms holds every indexed token, sorted for prefix lookups
+	terms        []string
+	maxCompanies int
+}
+
+// SearchIndex returns the full-text index for the dataset, building it on first use
+func (pbc *ProblemsByCompany) SearchIndex() *SearchIndex {
+	pbc.searchOnce.Do(func() {
+		pbc.search = NewSearchIndex(pbc)
+	})
+	return pbc.search
+}
+
+// Search is shorthand for pbc.SearchIndex().Search
+func (pbc *ProblemsByCompany) Search(query string, limit int) []SearchResult {
+	return pbc.SearchIndex().Search(query, limit)
+}
+
+// NewSearchIndex indexes every unique problem in the dataset
+func NewSearchIndex(pbc *ProblemsByCompany) *SearchIndex {
+	type occurrence struct {
+		company   string
+		frequency float64
+	}
+
+	best := make(map[int]Problem)
+	seen := make(map[int]map[string]float64)
+
+	for company, timeframes := range pbc.data {
+		for _, problems := range timeframes {
+			for _, p := range problems {
+				if current, ok := best[p.ID]; !ok || p.Frequency > current.Frequency {
+					best[p.ID] = p
+				}
+				if seen[p.ID] == nil {
+					seen[p.ID] = make(map[string]float64)
+				}
+				if p.Frequency >= seen[p.ID][company] {
+					seen[p.ID][company] = p.Frequency
+				}
+			}
+		}
+	}
+
+	ids := make([]int, 0, len(best))
+	for id := range best {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	idx := &SearchIndex{
+		docs:     make([]searchDoc, 0, len(ids)),
+		byID:     make(map[int]int, len(ids)),
+		postings: make(map[string][]int),
+	}
+
+	for _, id := range ids {
+		occurrences := make([]occurrence, 0, len(seen[id]))
+		for company, frequency := range seen[id] {
+			occurrences = append(occurrences, occurrence{company, frequency})
+		}
+		sort.Slice(occurrences, func(i, j int) bool {
+			if occurrences[i].frequency != occurrences[j].frequency {
+				return occurrences[i].frequency > occurrences[j].frequency
+			}
+			return occurrences[i].company < occurrences[j].company
+		})
+
+		top := make([]string, 0, searchTopCompanies)
+		for i := 0; i < len(occurrences) && i < searchTopCompanies; i++ {
+			top = append(top, occurrences[i].company)
+		}
+
+		problem := best[id]
+		doc := searchDoc{
+			problem:      problem,
+			companies:    len(occurrences),
+			topCompanies: top,
+			titleTokens:  tokenize(problem.Title),
+		}
+
+		docIndex := len(idx.docs)
+		idx.docs = append(idx.docs, doc)
+		idx.byID[id] = docIndex
+
+		terms := make(map[string]bool)
+		for _, token := range doc.titleTokens {
+			terms[token] = true
+		}
+		for _, token := range tokenize(problemSlug(problem.URL)) {
+			terms[token] = true
+		}
+		for term := range terms {
+			idx.postings[term] = append(idx.postings[term], docIndex)
+		}
+
+		if doc.companies > idx.maxCompanies {
+			idx.maxCompanies = doc.companies
+		}
+	}
+
+	idx.terms = make([]string, 0, len(idx.postings))
+	for term := range idx.postings {
+		idx.terms = append(idx.terms, term)
+	}
+	sort.Strings(idx.terms)
+
+	return idx
+}
+
+// Len returns the number of indexed problems
+func (idx *SearchIndex) Len() int {
+	return len(idx.docs)
+}
+
+// Search finds problems whose title or slug matches every word of the query.
+// Words match exactly, as a prefix of a title word, or with a small typo.
+// Results are ranked by text relevance boosted by how many companies ask the
+// problem. A query that is a problem ID returns that problem first.
+func (idx *SearchIndex) Search(query string, limit int) []SearchResult {
+	tokens := queryTokens(query)
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	scores := make(map[int]float64)
+	for i, token := range tokens {
+		matches := idx.matchToken(token)
+		if len(matches) == 0 {
+			return idx.exactIDResult(query)
+		}
+
+		if i == 0 {
+			scores = matches
+			continue
+		}
+
+		// every query word has to match
+		for doc, score := range scores {
+			if extra, ok := matches[doc]; ok {
+				scores[doc] = score + extra
+			} else {
+				delete(scores, doc)
+			}
+		}
+	}
+
+	results := make([]SearchResult, 0, len(scores))
+	for docIndex, textScore := range scores {
+		doc := idx.docs[docIndex]
+
+		// normalize by title length so "LRU Cache" ranks above "LFU Cache with LRU Eviction"
+		textScore /= math.Sqrt(float64(len(doc.titleTokens)) + 1)
+		if titleStartsWith(doc.titleTokens, tokens) {
+			textScore *= 1.2
+		}
+
+		popularity := 0.0
+		if idx.maxCompanies > 0 {
+			popularity = math.Log1p(float64(doc.companies)) / math.Log1p(float64(idx.maxCompanies))
+		}
+
+		results = append(results, SearchResult{
+			Problem:      doc.problem,
+			Score:        math.Round(textScore*(1+popularityWeight*popularity)*1000) / 1000,
+			Companies:    doc.companies,
+			TopCompanies: doc.topCompanies,
+		})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if results[i].Companies != results[j].Companies {
+			return results[i].Companies > results[j].Companies
+		}
+		return results[i].Problem.ID < results[j].Problem.ID
+	})
+
+	// a query that is a problem ID puts that problem first
+	if id, err := strconv.Atoi(strings.TrimSpace(query)); err == nil {
+		if docIndex, ok := idx.byID[id]; ok {
+			score := 1.0
+			if len(results) > 0 && results[0].Score > score {
+				score = results[0].Score
+			}
+			results = dedupeResults(append([]SearchResult{idx.resultFor(docIndex, score)}, results...))
+		}
+	}
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results
+}
+
+// Get returns the indexed result for a problem ID
+func (idx *SearchIndex) Get(id int) (SearchResult, bool) {
+	docIndex, ok := idx.byID[id]
+	if !ok {
+		return SearchResult{}, false
+	}
+	return idx.resultFor(docIndex, 0), true
+}
+
+// All returns every indexed problem ordered by ID
+func (idx *SearchIndex) All() []SearchResult {
+	results := make([]SearchResult, len(idx.docs))
+	for i := range idx.docs {
+		results[i] = idx.resultFor(i, 0)
+	}
+	return results
+}
+
+func (idx *SearchIndex) resultFor(docIndex int, score float64) SearchResult {
+	doc := idx.docs[docIndex]
+	return SearchResult{
+		Problem:      doc.problem,
+		Score:        score,
+		Companies:    doc.companies,
+		TopCompanies: doc.topCompanies,
+	}
+}
+
+func (idx *SearchIndex) exactIDResult(query string) []SearchResult {
+	id, err := strconv.Atoi(strings.TrimSpace(query))
+	if err != nil {
+		return nil
+	}
+	docIndex, ok := idx.byID[id]
+	if !ok {
+		return nil
+	}
+	return []SearchResult{idx.resultFor(docIndex, 1)}
+}
+
+// matchToken scores every document containing a term that matches the token,
+// keeping the best weight per document
+func (idx *SearchIndex) matchToken(token string) map[int]float64 {
+	matches := make(map[int]float64)
+	add := func(term string, weight float64) {
+		// rarer terms are worth more
+		weight *= 1 + math.Log(float64(len(idx.docs))/float64(len(idx.postings[term])+1))
+		for _, doc := range idx.postings[term] {
+			if weight > matches[doc] {
+				matches[doc] = weight
+			}
+		}
+	}
+
+	if _, ok := idx.postings[token]; ok {
+		add(token, exactMatchWeight)
+	}
+
+	// prefix matches, e.g. "isl" → "island", "islands"
+	if len(token) >= 2 {
+		start := sort.SearchStrings(idx.terms, token)
+		for i := start; i < len(idx.terms) && strings.HasPrefix(idx.terms[i], token); i++ {
+			if idx.terms[i] != token {
+				add(idx.terms[i], prefixMatchWeight)
+			}
+		}
+	}
+
+	// typo tolerance for longer words, where a single slip is unlikely to be another word
+	if maxEdits := allowedEdits(token); maxEdits > 0 {
+		for _, term := range idx.terms {
+			if term == token || abs(len(term)-len(token)) > maxEdits {
+				continue
+			}
+			if editDistance(token, term) <= maxEdits {
+				add(term, typoMatchWeight)
+			}
+		}
+	}
+
+	return matches
+}
+
+func allowedEdits(token string) int {
+	switch {
+	case len(token) >= 8:
+		return 2
+	case len(token) >= 4:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// tokenize lowercases text and splits it into alphanumeric words
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// queryTokens tokenizes a query and drops stopwords unless nothing else is left
+func queryTokens(query string) []string {
+	tokens := tokenize(query)
+	var filtered []string
+	for _, token := range tokens {
+		if !searchStopwords[token] {
+			filtered = append(filtered, token)
+		}
+	}
+	if len(filtered) == 0 {
+		return tokens
+	}
+	return filtered
+}
+
+// problemSlug returns the last path segment of a problem URL
+func problemSlug(url string) string {
+	url = strings.TrimSuffix(url, "/")
+	if i := strings.LastIndex(url, "/"); i >= 0 {
+		return url[i+1:]
+	}
+	return url
+}
+
+// titleStartsWith reports whether the title begins with the first query word
+func titleStartsWith(titleTokens, queryTokens []string) bool {
+	return len(titleTokens) > 0 && len(queryTokens) > 0 && strings.HasPrefix(titleTokens[0], queryTokens[0])
+}
+
+func dedupeResults(results []SearchResult) []SearchResult {
+	seen := make(map[int]bool, len(results))
+	deduped := results[:0]
+	for _, result := range results {
+		if seen[result.Problem.ID] {
+			continue
+		}
+		seen[result.Problem.ID] = true
+		deduped = append(deduped, result)
+	}
+	return deduped
+}
+
+// editDistance is the optimal string alignment distance between two words,
+// Levenshtein plus adjacent transpositions so "medain" is one edit from "median"
+func editDistance(a, b string) int {
+	rows := make([][]int, len(a)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(b)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			rows[i][j] = min(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				rows[i][j] = min(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+
+	return rows[len(a)][len(b)]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,149 @@
+package data
+
+import "testing"
+
+func createSearchTestData() *ProblemsByCompany {
+	return NewTestProblemsByCompany(map[string]map[string][]Problem{
+		"google": {
+			"all": []Problem{
+				{ID: 146, URL: "https://leetcode.com/problems/lru-cache", Title: "LRU Cache", Difficulty: "Medium", Frequency: 90},
+				{ID: 200, URL: "https://leetcode.com/problems/number-of-islands", Title: "Number of Islands", Difficulty: "Medium", Frequency: 80},
+				{ID: 295, URL: "https://leetcode.com/problems/find-median-from-data-stream", Title: "Find Median from Data Stream", Difficulty: "Hard", Frequency: 70},
+			},
+			"thirty-days": []Problem{
+				{ID: 146, URL: "https://leetcode.com/problems/lru-cache", Title: "LRU Cache", Difficulty: "Medium", Frequency: 100},
+			},
+		},
+		"amazon": {
+			"all": []Problem{
+				{ID: 200, URL: "https://leetcode.com/problems/number-of-islands", Title: "Number of Islands", Difficulty: "Medium", Frequency: 100},
+				{ID: 694, URL: "https://leetcode.com/problems/number-of-distinct-islands", Title: "Number of Distinct Islands", Difficulty: "Medium", Frequency: 40},
+				{ID: 460, URL: "https://leetcode.com/problems/lfu-cache", Title: "LFU Cache", Difficulty: "Hard", Frequency: 30},
+			},
+		},
+		"meta": {
+			"all": []Problem{
+				{ID: 200, URL: "https://leetcode.com/problems/number-of-islands", Title: "Number of Islands", Difficulty: "Medium", Frequency: 60},
+				{ID: 4, URL: "https://leetcode.com/problems/median-of-two-sorted-arrays", Title: "Median of Two Sorted Arrays", Difficulty: "Hard", Frequency: 50},
+			},
+		},
+	})
+}
+
+func TestSearch(t *testing.T) {
+	pbc := createSearchTestData()
+
+	tests := []struct {
+		name    string
+		query   string
+		wantIDs []int
+	}{
+		{name: "acronym", query: "lru", wantIDs: []int{146}},
+		{name: "multiple words", query: "median stream", wantIDs: []int{295}},
+		{name: "plural ranks popular first", query: "islands", wantIDs: []int{200, 694}},
+		{name: "prefix", query: "isla", wantIDs: []int{200, 694}},
+		{name: "typo", query: "medain", wantIDs: []int{4, 295}},
+		{name: "case and punctuation", query: "  LRU-Cache!", wantIDs: []int{146}},
+		{name: "stopwords ignored", query: "median of the stream", wantIDs: []int{295}},
+		{name: "problem id", query: "460", wantIDs: []int{460}},
+		{name: "every word must match", query: "lru islands", wantIDs: nil},
+		{name: "no match", query: "zzzz", wantIDs: nil},
+		{name: "empty", query: "   ", wantIDs: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := pbc.Search(tt.query, 10)
+
+			var ids []int
+			for _, r := range results {
+				ids = append(ids, r.Problem.ID)
+			}
+			if len(ids) != len(tt.wantIDs) {
+				t.Fatalf("Search(%q) = %v, want %v", tt.query, ids, tt.wantIDs)
+			}
+			for i := range ids {
+				if ids[i] != tt.wantIDs[i] {
+					t.Errorf("Search(%q) = %v, want %v", tt.query, ids, tt.wantIDs)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestSearchResultDetails(t *testing.T) {
+	pbc := createSearchTestData()
+
+	results := pbc.Search("number of islands", 1)
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result with limit 1, got %d", len(results))
+	}
+
+	result := results[0]
+	if result.Companies != 3 {
+		t.Errorf("companies = %d, want 3", result.Companies)
+	}
+	if len(result.TopCompanies) != 3 || result.TopCompanies[0] != "amazon" || result.TopCompanies[2] != "meta" {
+		t.Errorf("top companies = %v, want ordered by frequency", result.TopCompanies)
+	}
+	if result.Problem.Frequency != 100 {
+		t.Errorf("problem should be the most frequent occurrence, got %.1f", result.Problem.Frequency)
+	}
+
+	if _, ok := pbc.SearchIndex().Get(146); !ok {
+		t.Error("Get(146) should find LRU Cache")
+	}
+	if _, ok := pbc.SearchIndex().Get(9999); ok {
+		t.Error("Get(9999) should not find anything")
+	}
+
+	all := pbc.SearchIndex().All()
+	if len(all) != 6 {
+		t.Fatalf("All() = %d problems, want 6", len(all))
+	}
+	for i := 1; i < len(all); i++ {
+		if all[i-1].Problem.ID >= all[i].Problem.ID {
+			t.Errorf("All() isn't ordered by ID: %d before %d", all[i-1].Problem.ID, all[i].Problem.ID)
+		}
+	}
+}
+
+func TestSearchEmbeddedData(t *testing.T) {
+	pbc, err := LoadAllProblems()
+	if err != nil {
+		t.Fatalf("LoadAllProblems() error = %v", err)
+	}
+
+	tests := map[string]int{
+		"lru":           146,
+		"median stream": 295,
+		"islands":       200,
+	}
+
+	for query, wantID := range tests {
+		results := pbc.Search(query, 5)
+		if len(results) == 0 || results[0].Problem.ID != wantID {
+			t.Errorf("Search(%q) top result = %v, want problem %d", query, results, wantID)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"median", "median", 0},
+		{"medain", "median", 1},
+		{"islnd", "island", 1},
+		{"kitten", "sitting", 3},
+		{"", "abc", 3},
+	}
+
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package data
+
+import (
+	"sort"
+	"strings"
+)
+
+// SimilarProblem is a problem asked by many of the same companies as another
+type SimilarProblem struct {
+	Problem Problem
+	// Score is the Jaccard index of the two problems' company sets
+	Score           float64
+	SharedCompanies int
+}
+
+// SimilarCompany is a company that asks many of the same problems as another
+type SimilarCompany struct {
+	Company string
+	// Score is the Jaccard index of the two companies' problem sets
+	Score          float64
+	SharedProblems int
+}
+
+// cooccurrence maps problems to the companies asking them and back, across
+// all timeframes
+type cooccurrence struct {
+	companiesByProblem map[int][]string
+	problemsByCompany  map[string][]int
+}
+
+func (pbc *ProblemsByCompany) cooccurrence() *cooccurrence {
+	pbc.cooccurrenceOnce.Do(func() {
+		c := &cooccurrence{
+			companiesByProblem: make(map[int][]string),
+			problemsByCompany:  make(map[string][]int, len(pbc.data)),
+		}
+		for _, company := range pbc.GetAvailableCompanies() {
+			seen := make(map[int]bool)
+			for _, problems := range pbc.data[company] {
+				for _, p := range problems {
+					if !seen[p.ID] {
+						seen[p.ID] = true
+						c.problemsByCompany[company] = append(c.problemsByCompany[company], p.ID)
+						c.companiesByProblem[p.ID] = append(c.companiesByProblem[p.ID], company)
+					}
+				}
+			}
+		}
+		pbc.cooccurrenceData = c
+	})
+	return pbc.cooccurrenceData
+}
+
+// SimilarProblems returns up to limit problems sharing the most companies
+// with a problem, most similar first
+func (pbc *ProblemsByCompany) SimilarProblems(id, limit int) []SimilarProblem {
+	c := pbc.cooccurrence()
+	companies := c.companiesByProblem[id]
+
+	shared := make(map[int]int)
+	for _, company := range companies {
+		for _, other := range c.problemsByCompany[company] {
+			if other != id {
+				shared[other]++
+			}
+		}
+	}
+
+	index := pbc.SearchIndex()
+	similar := make([]SimilarProblem, 0, len(shared))
+	for other, count := range shared {
+		result, ok := index.Get(other)
+		if !ok {
+			continue
+		}
+		similar = append(similar, SimilarProblem{
+			Problem:         result.Problem,
+			Score:           jaccard(count, len(companies), len(c.companiesByProblem[other])),
+			SharedCompanies: count,
+		})
+	}
+	sort.Slice(similar, func(i, j int) bool {
+		if similar[i].Score != similar[j].Score {
+			return similar[i].Score > similar[j].Score
+		}
+		if similar[i].SharedCompanies != similar[j].SharedCompanies {
+			return similar[i].SharedCompanies > similar[j].SharedCompanies
+		}
+		return similar[i].Problem.ID < similar[j].Problem.ID
+	})
+
+	if limit > 0 && len(similar) > limit {
+		similar = similar[:limit]
+	}
+	return similar
+}
+
+// SimilarCompanies returns up to limit companies sharing the most problems
+// with a company, most similar first
+func (pbc *ProblemsByCompany) SimilarCompanies(company string, limit int) []SimilarCompany {
+	company = strings.ToLower(strings.TrimSpace(company))
+	c := pbc.cooccurrence()
+	problems := c.problemsByCompany[company]
+
+	shared := make(map[string]int)
+	for _, id := range problems {
+		for _, other := range c.companiesByProblem[id] {
+			if other != company {
+				shared[other]++
+			}
+		}
+	}
+
+	similar := make([]SimilarCompany, 0, len(shared))
+	for other, count := range shared {
+		similar = append(similar, SimilarCompany{
+			Company:        other,
+			Score:          jaccard(count, len(problems), len(c.problemsByCompany[other])),
+			SharedProblems: count,
+		})
+	}
+	sort.Slice(similar, func(i, j int) bool {
+		if similar[i].Score != similar[j].Score {
+			return similar[i].Score > similar[j].Score
+		}
+		if similar[i].SharedProblems != similar[j].SharedProblems {
+			return similar[i].SharedProblems > similar[j].SharedProblems
+		}
+		return similar[i].Company < similar[j].Company
+	})
+
+	if limit > 0 && len(similar) > limit {
+		similar = similar[:limit]
+	}
+	return similar
+}
+
+// jaccard is the size of the intersection over the size of the union
+func jaccard(shared, a, b int) float64 {
+	union := a + b - shared
+	if union == 0 {
+		return 0
+	}
+	return float64(shared) / float64(union)
+}
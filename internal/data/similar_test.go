@@ -0,0 +1,58 @@
+package data
+
+import "testing"
+
+func TestSimilarProblems(t *testing.T) {
+	pbc := createSearchTestData()
+
+	// lru cache is only asked by google, like find median; number of islands
+	// is also asked by amazon and meta
+	similar := pbc.SimilarProblems(146, 0)
+	want := []struct {
+		id     int
+		score  float64
+		shared int
+	}{
+		{295, 1, 1},
+		{200, 1.0 / 3, 1},
+	}
+	if len(similar) != len(want) {
+		t.Fatalf("SimilarProblems(146) = %+v, want %d results", similar, len(want))
+	}
+	for i, w := range want {
+		if similar[i].Problem.ID != w.id || similar[i].Score != w.score || similar[i].SharedCompanies != w.shared {
+			t.Errorf("result %d = %+v, want %+v", i, similar[i], w)
+		}
+	}
+
+	for _, s := range pbc.SimilarProblems(200, 0) {
+		if s.Problem.ID == 200 {
+			t.Error("a problem shouldn't be similar to itself")
+		}
+	}
+	if limited := pbc.SimilarProblems(200, 2); len(limited) != 2 {
+		t.Errorf("expected 2 results with limit 2, got %d", len(limited))
+	}
+	if none := pbc.SimilarProblems(9999, 5); len(none) != 0 {
+		t.Errorf("expected nothing for an unknown problem, got %+v", none)
+	}
+}
+
+func TestSimilarCompanies(t *testing.T) {
+	pbc := createSearchTestData()
+
+	// google {146, 200, 295}, amazon {200, 694, 460}, meta {200, 4}
+	similar := pbc.SimilarCompanies(" Meta ", 0)
+	if len(similar) != 2 {
+		t.Fatalf("SimilarCompanies(meta) = %+v, want google and amazon", similar)
+	}
+	// ties are broken by name
+	if similar[0].Company != "amazon" || similar[1].Company != "google" {
+		t.Errorf("unexpected order %+v", similar)
+	}
+	for _, s := range similar {
+		if s.Score != 0.25 || s.SharedProblems != 1 {
+			t.Errorf("unexpected result %+v", s)
+		}
+	}
+}
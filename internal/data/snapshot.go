@@ -0,0 +1,249 @@
+package data
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// manifestFile is written next to the CSVs of every saved snapshot
+const manifestFile = "manifest.json"
+
+// DatasetVersion identifies the contents of a loaded dataset
+type DatasetVersion struct {
+	// Hash is the hex encoded sha256 of the normalized dataset contents
+	Hash string `json:"hash"`
+	// Version is a short label, the hash prefix unless a snapshot manifest sets one
+	Version        string    `json:"version"`
+	Companies      int       `json:"companies"`
+	Problems       int       `json:"problems"`
+	UniqueProblems int       `json:"unique_problems"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// Version returns the content hash and version of the dataset
+func (pbc *ProblemsByCompany) Version() DatasetVersion {
+	return pbc.version
+}
+
+// computeVersion hashes the dataset in a canonical order so equal content
+// always produces the same hash regardless of file formatting or row order
+func computeVersion(data map[string]map[string][]Problem) DatasetVersion {
+	companies := make([]string, 0, len(data))
+	for company := range data {
+		companies = append(companies, company)
+	}
+	sort.Strings(companies)
+
+	hash := sha256.New()
+	unique := make(map[int]bool)
+	total := 0
+
+	for _, company := range companies {
+		timeframes := make([]string, 0, len(data[company]))
+		for timeframe := range data[company] {
+			timeframes = append(timeframes, timeframe)
+		}
+		sort.Strings(timeframes)
+
+		for _, timeframe := range timeframes {
+			problems := append([]Problem(nil), data[company][timeframe]...)
+			sort.Slice(problems, func(i, j int) bool {
+				return problems[i].ID < problems[j].ID
+			})
+
+			fmt.Fprintf(hash, "%s/%s\n", company, timeframe)
+			for _, p := range problems {
+				fmt.Fprintf(hash, "%d\t%s\t%s\t%s\t%.1f\t%.1f\n", p.ID, p.URL, p.Title, p.Difficulty, p.Acceptance, p.Frequency)
+				unique[p.ID] = true
+				total++
+			}
+		}
+	}
+
+	sum := hex.EncodeToString(hash.Sum(nil))
+	return DatasetVersion{
+		Hash:           sum,
+		Version:        sum[:12],
+		Companies:      len(companies),
+		Problems:       total,
+		UniqueProblems: len(unique),
+	}
+}
+
+// LoadFromDir loads a dataset laid out like data/, one directory per company
+// containing one CSV file per timeframe, the same way overlay directories are
+// read
+func LoadFromDir(dir string) (*ProblemsByCompany, error) {
+	return NewProblemsByCompany(NewCSVDirSource(filepath.Base(dir), dir, false))
+}
+
+// LoadSnapshot loads a snapshot directory, picking up the version label and
+// creation time from its manifest when one exists
+func LoadSnapshot(dir string) (*ProblemsByCompany, error) {
+	pbc, err := LoadFromDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := readManifest(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return pbc, nil
+		}
+		return nil, err
+	}
+
+	if manifest.Hash != "" && manifest.Hash != pbc.version.Hash {
+		return nil, fmt.Errorf("snapshot %s does not match its manifest hash", dir)
+	}
+	pbc.version.Version = manifest.Version
+	pbc.version.CreatedAt = manifest.CreatedAt
+
+	return pbc, nil
+}
+
+// SaveSnapshot writes the dataset into a new directory under root, named after
+// its version, so several snapshots can be kept side by side. Saving a dataset
+// that already has a snapshot is a no-op. Returns the snapshot directory.
+func SaveSnapshot(pbc *ProblemsByCompany, root string, createdAt time.Time) (string, error) {
+	existing, err := ListSnapshots(root)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	for _, snapshot := range existing {
+		if snapshot.Version.Hash == pbc.version.Hash {
+			return snapshot.Dir, nil
+		}
+	}
+
+	version := pbc.version
+	version.CreatedAt = createdAt.UTC()
+	version.Version = fmt.Sprintf("%s-%s", version.CreatedAt.Format("20060102"), version.Hash[:12])
+
+	dir := filepath.Join(root, version.Version)
+	for company, timeframes := range pbc.data {
+		companyDir := filepath.Join(dir, company)
+		if err := os.MkdirAll(companyDir, 0o755); err != nil {
+			return "", fmt.Errorf("error creating snapshot directory: %w", err)
+		}
+		for timeframe, problems := range timeframes {
+			if err := writeCSVFile(filepath.Join(companyDir, timeframe+".csv"), problems); err != nil {
+				return "", fmt.Errorf("error writing %s/%s: %w", company, timeframe, err)
+			}
+		}
+	}
+
+	manifest, err := json.MarshalIndent(version, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(dir, manifestFile), append(manifest, '\n'), 0o644); err != nil {
+		return "", fmt.Errorf("error writing snapshot manifest: %w", err)
+	}
+
+	return dir, nil
+}
+
+// Snapshot describes a saved snapshot directory
+type Snapshot struct {
+	Dir     string
+	Version DatasetVersion
+}
+
+// ListSnapshots returns every snapshot under root, oldest first
+func ListSnapshots(root string) ([]Snapshot, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshots []Snapshot
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dir := filepath.Join(root, entry.Name())
+		manifest, err := readManifest(dir)
+		if err != nil {
+			continue // not a snapshot
+		}
+		snapshots = append(snapshots, Snapshot{Dir: dir, Version: manifest})
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Version.CreatedAt.Before(snapshots[j].Version.CreatedAt)
+	})
+
+	return snapshots, nil
+}
+
+// LatestSnapshotBefore loads the newest snapshot under root whose contents differ
+// from current. Returns nil without an error if there is no such snapshot.
+func LatestSnapshotBefore(root string, current DatasetVersion) (*ProblemsByCompany, error) {
+	snapshots, err := ListSnapshots(root)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		if snapshots[i].Version.Hash != current.Hash {
+			return LoadSnapshot(snapshots[i].Dir)
+		}
+	}
+
+	return nil, nil
+}
+
+func readManifest(dir string) (DatasetVersion, error) {
+	var version DatasetVersion
+
+	content, err := os.ReadFile(filepath.Join(dir, manifestFile))
+	if err != nil {
+		return version, err
+	}
+	if err := json.Unmarshal(content, &version); err != nil {
+		return version, fmt.Errorf("invalid snapshot manifest in %s: %w", dir, err)
+	}
+
+	return version, nil
+}
+
+func writeCSVFile(path string, problems []Problem) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write([]string{"ID", "URL", "Title", "Difficulty", "Acceptance %", "Frequency %"}); err != nil {
+		return err
+	}
+	for _, p := range problems {
+		record := []string{
+			strconv.Itoa(p.ID),
+			p.URL,
+			p.Title,
+			p.Difficulty,
+			fmt.Sprintf("%.1f%%", p.Acceptance),
+			fmt.Sprintf("%.1f%%", p.Frequency),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+
+	return file.Close()
+}
@@ -0,0 +1,145 @@
+package data
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestVersion_Deterministic(t *testing.T) {
+	a := NewTestProblemsByCompany(map[string]map[string][]Problem{
+		"airbnb": {
+			"all": []Problem{
+				{ID: 1, Title: "Two Sum", Difficulty: "Easy", Frequency: 100.0},
+				{ID: 2, Title: "Add Two Numbers", Difficulty: "Medium", Frequency: 100.0},
+			},
+		},
+	})
+	// same content with tied rows in a different order
+	b := NewTestProblemsByCompany(map[string]map[string][]Problem{
+		"airbnb": {
+			"all": []Problem{
+				{ID: 2, Title: "Add Two Numbers", Difficulty: "Medium", Frequency: 100.0},
+				{ID: 1, Title: "Two Sum", Difficulty: "Easy", Frequency: 100.0},
+			},
+		},
+	})
+	c := NewTestProblemsByCompany(map[string]map[string][]Problem{
+		"airbnb": {
+			"all": []Problem{
+				{ID: 1, Title: "Two Sum", Difficulty: "Easy", Frequency: 90.0},
+			},
+		},
+	})
+
+	if a.Version().Hash != b.Version().Hash {
+		t.Error("Version() hash should not depend on row order")
+	}
+	if a.Version().Hash == c.Version().Hash {
+		t.Error("Version() hash should change when content changes")
+	}
+	if len(a.Version().Version) != 12 {
+		t.Errorf("Version() version = %q, want a 12 character hash prefix", a.Version().Version)
+	}
+	if a.Version().Companies != 1 || a.Version().Problems != 2 || a.Version().UniqueProblems != 2 {
+		t.Errorf("Version() counts = %+v, want 1 company and 2 problems", a.Version())
+	}
+}
+
+func TestSaveAndLoadSnapshot(t *testing.T) {
+	root := t.TempDir()
+	pbc := NewTestProblemsByCompany(map[string]map[string][]Problem{
+		"airbnb": {
+			"all": []Problem{
+				{ID: 1, URL: "https://leetcode.com/problems/two-sum", Title: "Two Sum", Difficulty: "Easy", Acceptance: 55.9, Frequency: 100.0},
+				{ID: 68, URL: "https://leetcode.com/problems/text-justification", Title: "Text Justification, Again", Difficulty: "Hard", Acceptance: 48.4, Frequency: 50.0},
+			},
+			"thirty-days": []Problem{
+				{ID: 68, URL: "https://leetcode.com/problems/text-justification", Title: "Text Justification, Again", Difficulty: "Hard", Acceptance: 48.4, Frequency: 100.0},
+			},
+		},
+	})
+	createdAt := time.Date(2025, 11, 27, 12, 0, 0, 0, time.UTC)
+
+	dir, err := SaveSnapshot(pbc, root, createdAt)
+	if err != nil {
+		t.Fatalf("SaveSnapshot() error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "airbnb", "thirty-days.csv")); err != nil {
+		t.Errorf("snapshot should contain CSV files: %v", err)
+	}
+
+	loaded, err := LoadSnapshot(dir)
+	if err != nil {
+		t.Fatalf("LoadSnapshot() error = %v", err)
+	}
+
+	if loaded.Version().Hash != pbc.Version().Hash {
+		t.Errorf("loaded snapshot hash = %s, want %s", loaded.Version().Hash, pbc.Version().Hash)
+	}
+	if want := "20251127-" + pbc.Version().Hash[:12]; loaded.Version().Version != want {
+		t.Errorf("loaded snapshot version = %q, want %q", loaded.Version().Version, want)
+	}
+	if !loaded.Version().CreatedAt.Equal(createdAt) {
+		t.Errorf("loaded snapshot created at = %v, want %v", loaded.Version().CreatedAt, createdAt)
+	}
+
+	again, err := SaveSnapshot(pbc, root, createdAt.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("SaveSnapshot() second call error = %v", err)
+	}
+	if again != dir {
+		t.Errorf("saving identical content should reuse %s, got %s", dir, again)
+	}
+}
+
+func TestLoadFromDirMatchesOverlays(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "Airbnb", "30d.csv"), validCSVHeader+
+		"1,https://leetcode.com/problems/two-sum,Two Sum,Easy,50%,100%\n")
+	writeTestFile(t, filepath.Join(dir, ".git", "all.csv"), validCSVHeader+
+		"2,https://leetcode.com/problems/add-two-numbers,Add Two Numbers,Medium,40%,90%\n")
+
+	loaded, err := LoadFromDir(dir)
+	if err != nil {
+		t.Fatalf("LoadFromDir() error = %v", err)
+	}
+	if companies := loaded.GetAvailableCompanies(); len(companies) != 1 || companies[0] != "airbnb" {
+		t.Errorf("companies = %v, want only airbnb", companies)
+	}
+	if problems := loaded.GetProblems("airbnb", "thirty-days"); len(problems) != 1 {
+		t.Errorf("30d.csv should load as thirty-days, got %+v", problems)
+	}
+}
+
+func TestLatestSnapshotBefore(t *testing.T) {
+	root := t.TempDir()
+	older := NewTestProblemsByCompany(map[string]map[string][]Problem{
+		"airbnb": {"all": []Problem{{ID: 1, Title: "Two Sum", Difficulty: "Easy", Frequency: 100.0}}},
+	})
+	current := NewTestProblemsByCompany(map[string]map[string][]Problem{
+		"airbnb": {"all": []Problem{{ID: 2, Title: "Add Two Numbers", Difficulty: "Medium", Frequency: 100.0}}},
+	})
+
+	if _, err := SaveSnapshot(older, root, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("SaveSnapshot() error = %v", err)
+	}
+	if _, err := SaveSnapshot(current, root, time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("SaveSnapshot() error = %v", err)
+	}
+
+	previous, err := LatestSnapshotBefore(root, current.Version())
+	if err != nil {
+		t.Fatalf("LatestSnapshotBefore() error = %v", err)
+	}
+	if previous == nil || previous.Version().Hash != older.Version().Hash {
+		t.Errorf("LatestSnapshotBefore() should return the older snapshot")
+	}
+
+	none, err := LatestSnapshotBefore(root, NewTestProblemsByCompany(nil).Version())
+	if err != nil || none == nil {
+		t.Errorf("LatestSnapshotBefore() = %v, %v; want newest snapshot", none, err)
+	}
+}
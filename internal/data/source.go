@@ -0,0 +1,446 @@
+package data
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultSourceName labels problems that come from the embedded public dataset
+const DefaultSourceName = "leetcode"
+
+// Source provides problem lists keyed by company and timeframe
+type Source interface {
+	// Name identifies the source in provenance labels
+	Name() string
+	// Load returns problems by company and timeframe, with Problem.Source set
+	Load() (map[string]map[string][]Problem, error)
+}
+
+// NewProblemsByCompany loads a dataset from a source
+func NewProblemsByCompany(source Source) (*ProblemsByCompany, error) {
+	loaded, err := source.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	pbc := &ProblemsByCompany{
+		data: make(map[string]map[string][]Problem, len(loaded)),
+	}
+	for company, timeframes := range loaded {
+		pbc.data[company] = make(map[string][]Problem, len(timeframes))
+		for timeframe, problems := range timeframes {
+			sortByFrequency(problems)
+			pbc.data[company][timeframe] = problems
+		}
+	}
+
+	pbc.version = computeVersion(pbc.data)
+
+	return pbc, nil
+}
+
+// embeddedSource reads the CSV files compiled into the binary
+type embeddedSource struct {
+	strict bool
+}
+
+// NewEmbeddedSource returns the public dataset compiled into the binary.
+// In strict mode Load fails on any validation error instead of skipping bad rows.
+func NewEmbeddedSource(strict bool) Source {
+	return &embeddedSource{strict: strict}
+}
+
+func (s *embeddedSource) Name() string {
+	return DefaultSourceName
+}
+
+func (s *embeddedSource) Load() (map[string]map[string][]Problem, error) {
+	validator := NewValidator()
+	result := make(map[string]map[string][]Problem, len(embeddedCSVs))
+
+	for company, timeframes := range embeddedCSVs {
+		result[company] = make(map[string][]Problem, len(timeframes))
+		for timeframe, csvData := range timeframes {
+			problems, err := validator.addFile(company+"/"+timeframe+".csv", timeframe, csvData)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing CSV for %s/%s: %w", company, timeframe, err)
+			}
+			result[company][timeframe] = withSource(problems, DefaultSourceName)
+		}
+	}
+
+	if s.strict {
+		if errs := validator.Errors(); len(errs) > 0 {
+			return nil, &ValidationError{Diagnostics: errs}
+		}
+	}
+
+	return result, nil
+}
+
+// csvDirSource reads a directory laid out like data/, one directory per company
+// and one CSV file per timeframe
+type csvDirSource struct {
+	name   string
+	dir    string
+	strict bool
+}
+
+// NewCSVDirSource returns a source that reads a directory laid out like data/
+func NewCSVDirSource(name, dir string, strict bool) Source {
+	return &csvDirSource{name: name, dir: dir, strict: strict}
+}
+
+func (s *csvDirSource) Name() string {
+	return s.name
+}
+
+func (s *csvDirSource) Load() (map[string]map[string][]Problem, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading data directory %s: %w", s.dir, err)
+	}
+
+	validator := NewValidator()
+	result := make(map[string]map[string][]Problem)
+	// loaded remembers the file each company and timeframe came from, so
+	// 30d.csv next to thirty-days.csv isn't loaded twice
+	loaded := make(map[string]string)
+
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		company := strings.ToLower(entry.Name())
+
+		files, err := os.ReadDir(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("error reading company directory %s: %w", entry.Name(), err)
+		}
+
+		for _, file := range files {
+			if file.IsDir() || !strings.HasSuffix(file.Name(), ".csv") {
+				continue
+			}
+			name := strings.TrimSuffix(file.Name(), ".csv")
+			path := filepath.Join(s.dir, entry.Name(), file.Name())
+
+			csvData, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("error reading CSV for %s/%s: %w", company, name, err)
+			}
+
+			problems, err := validator.addFile(path, name, csvData)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing CSV for %s/%s: %w", company, name, err)
+			}
+
+			// aliases like 30d.csv are accepted, unknown names are reported by
+			// the validator and fail a strict load
+			timeframe, ok := recognizeTimeframe(name)
+			if !ok {
+				slog.Warn("skipping a data file not named after a timeframe", "source", s.name, "file", path)
+				continue
+			}
+			key := company + "/" + timeframe
+			if previous, ok := loaded[key]; ok {
+				return nil, fmt.Errorf("%s and %s are both the %s problems of %s", previous, path, timeframe, company)
+			}
+			loaded[key] = path
+
+			if result[company] == nil {
+				result[company] = make(map[string][]Problem)
+			}
+			result[company][timeframe] = append(result[company][timeframe], withSource(problems, s.name)...)
+		}
+	}
+
+	if s.strict {
+		if errs := validator.Errors(); len(errs) > 0 {
+			return nil, &ValidationError{Diagnostics: errs}
+		}
+	}
+
+	return result, nil
+}
+
+// jsonRecord is one problem in a JSON or JSONL source
+type jsonRecord struct {
+	Company    string  `json:"company"`
+	Timeframe  string  `json:"timeframe"`
+	ID         int     `json:"id"`
+	URL        string  `json:"url"`
+	Title      string  `json:"title"`
+	Difficulty string  `json:"difficulty"`
+	Acceptance float64 `json:"acceptance"`
+	Frequency  float64 `json:"frequency"`
+}
+
+// jsonSource reads problems from a JSON array or a JSONL file of records
+type jsonSource struct {
+	name   string
+	path   string
+	strict bool
+}
+
+// NewJSONSource returns a source that reads a .json file holding an array of
+// problem records, or a .jsonl file with one record per line. Each record has
+// company, timeframe, id, url, title, difficulty, acceptance and frequency
+// fields. The timeframe defaults to "all". In strict mode Load fails when an
+// acceptance or frequency is outside 0-100.
+func NewJSONSource(name, path string, strict bool) Source {
+	return &jsonSource{name: name, path: path, strict: strict}
+}
+
+func (s *jsonSource) Name() string {
+	return s.name
+}
+
+func (s *jsonSource) Load() (map[string]map[string][]Problem, error) {
+	content, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", s.path, err)
+	}
+
+	var records []jsonRecord
+	var locations []string
+	// lines holds the line of each JSONL record, 0 for JSON arrays
+	var lines []int
+
+	if strings.EqualFold(filepath.Ext(s.path), ".jsonl") {
+		scanner := bufio.NewScanner(bytes.NewReader(content))
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		line := 0
+		for scanner.Scan() {
+			line++
+			text := strings.TrimSpace(scanner.Text())
+			if text == "" {
+				continue
+			}
+			var record jsonRecord
+			if err := json.Unmarshal([]byte(text), &record); err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", s.path, line, err)
+			}
+			records = append(records, record)
+			locations = append(locations, fmt.Sprintf("%s:%d", s.path, line))
+			lines = append(lines, line)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", s.path, err)
+		}
+	} else {
+		if err := json.Unmarshal(content, &records); err != nil {
+			return nil, fmt.Errorf("error parsing %s: %w", s.path, err)
+		}
+		// JSON arrays have no useful line numbers, report the record index instead
+		for i := range records {
+			locations = append(locations, fmt.Sprintf("%s record %d", s.path, i+1))
+			lines = append(lines, 0)
+		}
+	}
+
+	var diagnostics []Diagnostic
+	result := make(map[string]map[string][]Problem)
+	// seen remembers where each company, timeframe and ID was first found
+	seen := make(map[string]string, len(records))
+	for i, record := range records {
+		location := locations[i]
+
+		company := strings.ToLower(strings.TrimSpace(record.Company))
+		if company == "" {
+			return nil, fmt.Errorf("%s: record has no company", location)
+		}
+		timeframe, ok := recognizeTimeframe(record.Timeframe)
+		if !ok {
+			return nil, fmt.Errorf("%s: timeframe %q is not recognized", location, record.Timeframe)
+		}
+		if record.ID <= 0 {
+			return nil, fmt.Errorf("%s: record has no id", location)
+		}
+		difficulty := canonicalDifficulty(record.Difficulty)
+		if difficulty == "" {
+			return nil, fmt.Errorf("%s: difficulty %q is not one of Easy, Medium or Hard", location, record.Difficulty)
+		}
+		key := fmt.Sprintf("%s/%s/%d", company, timeframe, record.ID)
+		if first, ok := seen[key]; ok {
+			return nil, fmt.Errorf("%s: ID %d of %s/%s already appears at %s", location, record.ID, company, timeframe, first)
+		}
+		seen[key] = location
+
+		for _, value := range []struct {
+			column     string
+			percentage float64
+		}{{"acceptance", record.Acceptance}, {"frequency", record.Frequency}} {
+			if value.percentage < 0 || value.percentage > 100 {
+				message := fmt.Sprintf("%s %.1f is outside 0-100", value.column, value.percentage)
+				if lines[i] == 0 {
+					message = fmt.Sprintf("record %d: %s", i+1, message)
+				}
+				diagnostics = append(diagnostics, Diagnostic{
+					File: s.path, Line: lines[i], Severity: SeverityError, Code: CodePercentageRange, Message: message,
+				})
+			}
+		}
+
+		if result[company] == nil {
+			result[company] = make(map[string][]Problem)
+		}
+		result[company][timeframe] = append(result[company][timeframe], Problem{
+			ID:         record.ID,
+			URL:        record.URL,
+			Title:      record.Title,
+			Difficulty: difficulty,
+			Acceptance: record.Acceptance,
+			Frequency:  record.Frequency,
+			Source:     s.name,
+		})
+	}
+
+	if s.strict && len(diagnostics) > 0 {
+		return nil, &ValidationError{Diagnostics: diagnostics}
+	}
+
+	return result, nil
+}
+
+// mergedSource layers several sources on top of each other
+type mergedSource struct {
+	sources []Source
+}
+
+// NewMergedSource combines sources in order of increasing precedence. A problem
+// from a later source replaces the problem with the same ID in the same company
+// and timeframe of an earlier one; everything else is added alongside.
+func NewMergedSource(sources ...Source) Source {
+	return &mergedSource{sources: sources}
+}
+
+func (s *mergedSource) Name() string {
+	names := make([]string, 0, len(s.sources))
+	for _, source := range s.sources {
+		names = append(names, source.Name())
+	}
+	return strings.Join(names, "+")
+}
+
+func (s *mergedSource) Load() (map[string]map[string][]Problem, error) {
+	result := make(map[string]map[string][]Problem)
+
+	for _, source := range s.sources {
+		loaded, err := source.Load()
+		if err != nil {
+			return nil, fmt.Errorf("error loading source %s: %w", source.Name(), err)
+		}
+
+		for company, timeframes := range loaded {
+			if result[company] == nil {
+				result[company] = make(map[string][]Problem)
+			}
+			for timeframe, problems := range timeframes {
+				result[company][timeframe] = overlayProblems(result[company][timeframe], problems)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// overlayProblems replaces problems in base that share an ID with one in overlay
+// and appends the rest
+func overlayProblems(base, overlay []Problem) []Problem {
+	index := make(map[int]int, len(base))
+	merged := append([]Problem(nil), base...)
+	for i, p := range merged {
+		index[p.ID] = i
+	}
+
+	for _, p := range overlay {
+		if i, ok := index[p.ID]; ok {
+			merged[i] = p
+			continue
+		}
+		index[p.ID] = len(merged)
+		merged = append(merged, p)
+	}
+
+	return merged
+}
+
+// SourceFromPath picks a source implementation for a path: directories are read
+// as CSV datasets and .json/.jsonl files as JSON sources. The spec may be
+// prefixed with "name=" to set the provenance label, which otherwise defaults
+// to the base name of the path without its extension. A spec naming a file
+// that exists, or whose part before "=" isn't a label, is a path with an "="
+// in it.
+func SourceFromPath(spec string, strict bool) (Source, error) {
+	name, path, ok := strings.Cut(spec, "=")
+	if ok && isSourceName(name) {
+		if _, err := os.Stat(spec); err == nil {
+			ok = false
+		}
+	} else {
+		ok = false
+	}
+	if !ok {
+		path = spec
+		name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	name = strings.TrimSpace(name)
+	path = strings.TrimSpace(path)
+	if name == "" || path == "" {
+		return nil, fmt.Errorf("invalid source %q, expected [name=]path", spec)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading source %s: %w", path, err)
+	}
+	if info.IsDir() {
+		return NewCSVDirSource(name, path, strict), nil
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".jsonl":
+		return NewJSONSource(name, path, strict), nil
+	}
+
+	return nil, fmt.Errorf("unsupported source %s, expected a directory or a .json/.jsonl file", path)
+}
+
+// isSourceName reports whether the part of a spec before "=" is a provenance
+// label rather than part of a path
+func isSourceName(name string) bool {
+	name = strings.TrimSpace(name)
+	return name != "" && !strings.ContainsAny(name, `/\`) && name != "." && name != ".."
+}
+
+// LoadWithOverlays loads the embedded dataset with the overlays layered over
+// it, each a SourceFromPath spec as data.overlays lists them
+func LoadWithOverlays(overlays []string, strict bool) (*ProblemsByCompany, error) {
+	var sources []Source
+	for _, spec := range overlays {
+		if strings.TrimSpace(spec) == "" {
+			continue
+		}
+		source, err := SourceFromPath(spec, strict)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+
+	return LoadAllProblemsWithOptions(LoadOptions{Strict: strict, Overlays: sources})
+}
+
+func withSource(problems []Problem, source string) []Problem {
+	for i := range problems {
+		problems[i].Source = source
+	}
+	return problems
+}
@@ -0,0 +1,352 @@
+package data
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCSVDirSource(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "Acme", "all.csv"), validCSVHeader+
+		"1,https://leetcode.com/problems/two-sum,Two Sum,Easy,50%,40%\n"+
+		"2,https://leetcode.com/problems/add-two-numbers,Add Two Numbers,Medium,40%,90%\n")
+	writeTestFile(t, filepath.Join(dir, "Acme", "30d.csv"), validCSVHeader+
+		"1,https://leetcode.com/problems/two-sum,Two Sum,Easy,50%,100%\n")
+	writeTestFile(t, filepath.Join(dir, "Acme", "notes.txt"), "ignored")
+
+	pbc, err := NewProblemsByCompany(NewCSVDirSource("internal", dir, false))
+	if err != nil {
+		t.Fatalf("NewProblemsByCompany() error = %v", err)
+	}
+
+	problems := pbc.GetProblems("acme", "all")
+	if len(problems) != 2 {
+		t.Fatalf("expected 2 problems, got %d", len(problems))
+	}
+	if problems[0].ID != 2 {
+		t.Errorf("problems should be sorted by frequency, got ID %d first", problems[0].ID)
+	}
+	for _, p := range problems {
+		if p.Source != "internal" {
+			t.Errorf("problem %d source = %q, want internal", p.ID, p.Source)
+		}
+	}
+
+	if got := pbc.GetProblems("acme", "thirty-days"); len(got) != 1 {
+		t.Errorf("aliased timeframe file should load as thirty-days, got %v", pbc.GetAvailableTimeframes("acme"))
+	}
+}
+
+func TestCSVDirSourceTimeframeTwice(t *testing.T) {
+	dir := t.TempDir()
+	row := "1,https://leetcode.com/problems/two-sum,Two Sum,Easy,50%,100%\n"
+	writeTestFile(t, filepath.Join(dir, "acme", "30d.csv"), validCSVHeader+row)
+	writeTestFile(t, filepath.Join(dir, "acme", "thirty-days.csv"), validCSVHeader+row)
+
+	_, err := NewCSVDirSource("internal", dir, false).Load()
+	if err == nil || !strings.Contains(err.Error(), "both the thirty-days problems of acme") {
+		t.Errorf("Load() error = %v, want both files named as thirty-days", err)
+	}
+}
+
+func TestCSVDirSourceUnknownTimeframe(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "acme", "last-week.csv"), validCSVHeader+
+		"1,https://leetcode.com/problems/two-sum,Two Sum,Easy,50%,100%\n")
+
+	var logs bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	if _, err := NewCSVDirSource("internal", dir, false).Load(); err != nil {
+		t.Fatalf("lenient load error = %v", err)
+	}
+	if !strings.Contains(logs.String(), "last-week.csv") {
+		t.Errorf("expected a warning naming the skipped file, got %q", logs.String())
+	}
+}
+
+func TestCSVDirSourceStrict(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "acme", "all.csv"), validCSVHeader+
+		"one,https://leetcode.com/problems/two-sum,Two Sum,Easy,50%,100%\n")
+
+	if _, err := NewCSVDirSource("internal", dir, false).Load(); err != nil {
+		t.Errorf("lenient load should skip bad rows, got %v", err)
+	}
+
+	_, err := NewCSVDirSource("internal", dir, true).Load()
+	if _, ok := err.(*ValidationError); !ok {
+		t.Errorf("strict load error = %v, want *ValidationError", err)
+	}
+
+	// links to mirrors and private lists are fine in strict mode
+	writeTestFile(t, filepath.Join(dir, "acme", "all.csv"), validCSVHeader+
+		"1,https://leetcode.cn/problems/two-sum,Two Sum,Easy,50%,100%\n")
+	if _, err := NewCSVDirSource("internal", dir, true).Load(); err != nil {
+		t.Errorf("strict load of a mirror URL error = %v", err)
+	}
+}
+
+func TestJSONSourceStrict(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.jsonl")
+	writeTestFile(t, path, `{"company": "acme", "id": 1, "difficulty": "Easy", "frequency": 100}
+{"company": "acme", "id": 2, "difficulty": "Easy", "acceptance": 120, "frequency": 50}
+`)
+
+	loaded, err := NewJSONSource("notes", path, false).Load()
+	if err != nil {
+		t.Fatalf("lenient load error = %v", err)
+	}
+	if all := loaded["acme"]["all"]; len(all) != 2 || all[1].Acceptance != 120 {
+		t.Errorf("lenient load should keep the value as it is, got %+v", all)
+	}
+
+	_, err = NewJSONSource("notes", path, true).Load()
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("strict load error = %v, want *ValidationError", err)
+	}
+	if d := invalid.Diagnostics; len(d) != 1 || d[0].Line != 2 || d[0].Code != CodePercentageRange {
+		t.Errorf("diagnostics = %+v, want an out of range acceptance on line 2", d)
+	}
+}
+
+func TestJSONSource(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		wantErr string
+	}{
+		{
+			name: "json array",
+			file: "notes.json",
+			content: `[
+				{"company": "Acme", "timeframe": "30d", "id": 1, "title": "Two Sum", "difficulty": "easy", "frequency": 100},
+				{"company": "acme", "id": 2, "title": "Add Two Numbers", "difficulty": "Medium", "frequency": 50}
+			]`,
+		},
+		{
+			name: "jsonl",
+			file: "notes.jsonl",
+			content: `{"company": "acme", "timeframe": "thirty-days", "id": 1, "title": "Two Sum", "difficulty": "Easy", "frequency": 100}
+
+{"company": "acme", "timeframe": "all", "id": 2, "title": "Add Two Numbers", "difficulty": "Medium", "frequency": 50}
+`,
+		},
+		{
+			name:    "jsonl syntax error has line number",
+			file:    "broken.jsonl",
+			content: "{\"company\": \"acme\", \"id\": 1, \"difficulty\": \"Easy\"}\n{not json}\n",
+			wantErr: "broken.jsonl:2",
+		},
+		{
+			name:    "unknown timeframe",
+			file:    "timeframe.jsonl",
+			content: `{"company": "acme", "timeframe": "last-week", "id": 1, "difficulty": "Easy"}`,
+			wantErr: "not recognized",
+		},
+		{
+			name:    "missing company",
+			file:    "company.json",
+			content: `[{"id": 1, "difficulty": "Easy"}]`,
+			wantErr: "company.json record 1: record has no company",
+		},
+		{
+			name:    "invalid difficulty",
+			file:    "difficulty.jsonl",
+			content: `{"company": "acme", "id": 1, "difficulty": "Trivial"}`,
+			wantErr: "difficulty",
+		},
+		{
+			name: "duplicate id",
+			file: "duplicate.jsonl",
+			content: `{"company": "acme", "id": 1, "difficulty": "Easy"}
+{"company": "Acme", "timeframe": "all", "id": 1, "difficulty": "Hard"}`,
+			wantErr: "duplicate.jsonl:2: ID 1 of acme/all already appears at",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.file)
+			writeTestFile(t, path, tt.content)
+
+			loaded, err := NewJSONSource("notes", path, false).Load()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			thirty := loaded["acme"]["thirty-days"]
+			if len(thirty) != 1 || thirty[0].Difficulty != "Easy" || thirty[0].Source != "notes" {
+				t.Errorf("thirty-days = %+v", thirty)
+			}
+			if all := loaded["acme"]["all"]; len(all) != 1 || all[0].ID != 2 {
+				t.Errorf("all = %+v", all)
+			}
+		})
+	}
+}
+
+func TestMergedSource(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.jsonl")
+	writeTestFile(t, base, `{"company": "acme", "id": 1, "title": "Two Sum", "difficulty": "Easy", "frequency": 100}
+{"company": "acme", "id": 2, "title": "Add Two Numbers", "difficulty": "Medium", "frequency": 50}
+{"company": "globex", "id": 3, "title": "Longest Substring", "difficulty": "Medium", "frequency": 70}
+`)
+	overlay := filepath.Join(dir, "overlay.jsonl")
+	writeTestFile(t, overlay, `{"company": "acme", "id": 2, "title": "Add Two Numbers", "difficulty": "Medium", "frequency": 95}
+{"company": "acme", "id": 4, "title": "Median of Two Sorted Arrays", "difficulty": "Hard", "frequency": 10}
+{"company": "initech", "id": 1, "title": "Two Sum", "difficulty": "Easy", "frequency": 100}
+`)
+
+	merged := NewMergedSource(NewJSONSource("public", base, false), NewJSONSource("internal", overlay, false))
+	if got := merged.Name(); got != "public+internal" {
+		t.Errorf("Name() = %q, want public+internal", got)
+	}
+
+	pbc, err := NewProblemsByCompany(merged)
+	if err != nil {
+		t.Fatalf("NewProblemsByCompany() error = %v", err)
+	}
+
+	acme := pbc.GetProblems("acme", "all")
+	wantOrder := []struct {
+		id     int
+		source string
+	}{
+		{1, "public"},
+		{2, "internal"},
+		{4, "internal"},
+	}
+	if len(acme) != len(wantOrder) {
+		t.Fatalf("expected %d problems, got %+v", len(wantOrder), acme)
+	}
+	for i, want := range wantOrder {
+		if acme[i].ID != want.id || acme[i].Source != want.source {
+			t.Errorf("acme[%d] = %d from %q, want %d from %q", i, acme[i].ID, acme[i].Source, want.id, want.source)
+		}
+	}
+	if acme[1].Frequency != 95 {
+		t.Errorf("overlay should replace frequency, got %.1f", acme[1].Frequency)
+	}
+
+	if companies := pbc.GetAvailableCompanies(); len(companies) != 3 {
+		t.Errorf("expected companies from both sources, got %v", companies)
+	}
+}
+
+func TestLoadAllProblemsWithOverlays(t *testing.T) {
+	dir := t.TempDir()
+	overlay := filepath.Join(dir, "notes.jsonl")
+	writeTestFile(t, overlay, `{"company": "zz-internal-co", "id": 1, "title": "Two Sum", "difficulty": "Easy", "frequency": 100}`)
+
+	source, err := SourceFromPath("internal="+overlay, false)
+	if err != nil {
+		t.Fatalf("SourceFromPath() error = %v", err)
+	}
+
+	pbc, err := LoadAllProblemsWithOptions(LoadOptions{Overlays: []Source{source}})
+	if err != nil {
+		t.Fatalf("LoadAllProblemsWithOptions() error = %v", err)
+	}
+
+	problems := pbc.GetProblems("zz-internal-co", "all")
+	if len(problems) != 1 || problems[0].Source != "internal" {
+		t.Errorf("overlay problems = %+v", problems)
+	}
+
+	public := pbc.GetProblems("google", "all")
+	if len(public) == 0 || public[0].Source != DefaultSourceName {
+		t.Errorf("embedded problems should be labeled %q", DefaultSourceName)
+	}
+}
+
+func TestLoadWithOverlaysCommaInPath(t *testing.T) {
+	overlay := filepath.Join(t.TempDir(), "notes,extra.jsonl")
+	writeTestFile(t, overlay, `{"company": "zz-internal-co", "id": 1, "title": "Two Sum", "difficulty": "Easy", "frequency": 100}`)
+
+	pbc, err := LoadWithOverlays([]string{overlay}, false)
+	if err != nil {
+		t.Fatalf("LoadWithOverlays() error = %v", err)
+	}
+	if problems := pbc.GetProblems("zz-internal-co", "all"); len(problems) != 1 || problems[0].Source != "notes,extra" {
+		t.Errorf("overlay problems = %+v", problems)
+	}
+}
+
+func TestSourceFromPath(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "notes.jsonl"), "")
+	writeTestFile(t, filepath.Join(dir, "notes.txt"), "")
+	writeTestFile(t, filepath.Join(dir, "a=b", "notes.json"), "[]")
+	writeTestFile(t, filepath.Join(dir, "x=y.json"), "[]")
+
+	tests := []struct {
+		spec     string
+		wantName string
+		wantErr  bool
+	}{
+		{spec: dir, wantName: filepath.Base(dir)},
+		{spec: filepath.Join(dir, "notes.jsonl"), wantName: "notes"},
+		{spec: "team=" + filepath.Join(dir, "notes.jsonl"), wantName: "team"},
+		{spec: filepath.Join(dir, "notes.txt"), wantErr: true},
+		{spec: filepath.Join(dir, "missing.json"), wantErr: true},
+		{spec: "=" + dir, wantErr: true},
+		// paths with an "=" in them aren't split into a name and a path
+		{spec: filepath.Join(dir, "a=b", "notes.json"), wantName: "notes"},
+		{spec: filepath.Join(dir, "a=b"), wantName: "a=b"},
+		{spec: "team=" + filepath.Join(dir, "a=b", "notes.json"), wantName: "team"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			source, err := SourceFromPath(tt.spec, false)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("SourceFromPath(%q) should fail", tt.spec)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SourceFromPath(%q) error = %v", tt.spec, err)
+			}
+			if source.Name() != tt.wantName {
+				t.Errorf("Name() = %q, want %q", source.Name(), tt.wantName)
+			}
+		})
+	}
+
+	// a relative file named like name=path is the file
+	t.Chdir(dir)
+	source, err := SourceFromPath("x=y.json", false)
+	if err != nil || source.Name() != "x=y" {
+		t.Errorf("SourceFromPath(%q) = %v, %v, want the file x=y.json", "x=y.json", source, err)
+	}
+}
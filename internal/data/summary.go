@@ -0,0 +1,192 @@
+package data
+
+import (
+	"sort"
+	"strings"
+)
+
+// timeframePriority lists timeframes from most to least recent
+var timeframePriority = []string{"thirty-days", "three-months", "six-months", "more-than-six-months", "all"}
+
+// DifficultyBreakdown counts problems per difficulty level
+type DifficultyBreakdown struct {
+	Easy   int
+	Medium int
+	Hard   int
+}
+
+// TimeframeSummary describes a single timeframe of a company
+type TimeframeSummary struct {
+	Timeframe  string
+	Count      int
+	Difficulty DifficultyBreakdown
+	Top        []Problem
+}
+
+// CompanySummary is an overview of every timeframe available for a company
+type CompanySummary struct {
+	Company    string
+	Timeframes []TimeframeSummary
+	// UniqueProblems counts distinct problems across all timeframes
+	UniqueProblems int
+	// Evergreen holds problems that appear in every available timeframe
+	Evergreen []Problem
+}
+
+// GetCompanySummary builds an overview of a company across all of its timeframes.
+// Timeframes are ordered from most to least recent and each includes its topN problems.
+// Returns nil if the company does not exist.
+func (pbc *ProblemsByCompany) GetCompanySummary(company string, topN int) *CompanySummary {
+	company = strings.ToLower(strings.TrimSpace(company))
+
+	companyData, ok := pbc.data[company]
+	if !ok {
+		return nil
+	}
+
+	summary := &CompanySummary{Company: company}
+	unique := make(map[int]bool)
+
+	for _, timeframe := range orderedTimeframes(companyData) {
+		problems := companyData[timeframe]
+		if len(problems) == 0 {
+			continue
+		}
+		for _, problem := range problems {
+			unique[problem.ID] = true
+		}
+
+		top := problems
+		if topN >= 0 && len(top) > topN {
+			top = top[:topN]
+		}
+
+		summary.Timeframes = append(summary.Timeframes, TimeframeSummary{
+			Timeframe:  timeframe,
+			Count:      len(problems),
+			Difficulty: countDifficulties(problems),
+			Top:        top,
+		})
+	}
+
+	summary.UniqueProblems = len(unique)
+	summary.Evergreen = findEvergreenProblems(companyData, summary.Timeframes)
+
+	return summary
+}
+
+// orderedTimeframes returns the company's timeframes in priority order
+func orderedTimeframes(companyData map[string][]Problem) []string {
+	timeframes := make([]string, 0, len(companyData))
+	for timeframe := range companyData {
+		timeframes = append(timeframes, timeframe)
+	}
+	return sortTimeframes(timeframes)
+}
+
+// sortTimeframes orders timeframes from most to least recent,
+// followed by any unknown timeframes sorted alphabetically
+func sortTimeframes(timeframes []string) []string {
+	present := make(map[string]bool, len(timeframes))
+	for _, timeframe := range timeframes {
+		present[timeframe] = true
+	}
+
+	var ordered []string
+	known := make(map[string]bool, len(timeframePriority))
+	for _, timeframe := range timeframePriority {
+		known[timeframe] = true
+		if present[timeframe] {
+			ordered = append(ordered, timeframe)
+		}
+	}
+
+	var extra []string
+	for timeframe := range present {
+		if !known[timeframe] {
+			extra = append(extra, timeframe)
+		}
+	}
+	sort.Strings(extra)
+
+	return append(ordered, extra...)
+}
+
+// Occurrence is a company and timeframe that lists a problem
+type Occurrence struct {
+	Company   string
+	Timeframe string
+	Frequency float64
+}
+
+// GetOccurrences lists where a problem is asked, ordered by company and then
+// from most to least recent timeframe. Returns nil if no company lists it.
+func (pbc *ProblemsByCompany) GetOccurrences(id int) []Occurrence {
+	var occurrences []Occurrence
+	for _, company := range pbc.GetAvailableCompanies() {
+		companyData := pbc.data[company]
+		for _, timeframe := range orderedTimeframes(companyData) {
+			for _, problem := range companyData[timeframe] {
+				if problem.ID == id {
+					occurrences = append(occurrences, Occurrence{Company: company, Timeframe: timeframe, Frequency: problem.Frequency})
+					break
+				}
+			}
+		}
+	}
+	return occurrences
+}
+
+func countDifficulties(problems []Problem) DifficultyBreakdown {
+	var breakdown DifficultyBreakdown
+	for _, problem := range problems {
+		switch strings.ToLower(problem.Difficulty) {
+		case "easy":
+			breakdown.Easy++
+		case "medium":
+			breakdown.Medium++
+		case "hard":
+			breakdown.Hard++
+		}
+	}
+	return breakdown
+}
+
+// findEvergreenProblems returns problems present in every summarized timeframe
+// other than "all", which lists everything the others do and would make the
+// result for a company with a single recent timeframe that whole timeframe.
+// A company needs at least two recent timeframes for the result to be
+// meaningful. The order follows the least recent of them.
+func findEvergreenProblems(companyData map[string][]Problem, timeframes []TimeframeSummary) []Problem {
+	var recent []string
+	for _, tf := range timeframes {
+		if tf.Timeframe != "all" {
+			recent = append(recent, tf.Timeframe)
+		}
+	}
+	if len(recent) < 2 {
+		return nil
+	}
+
+	counts := make(map[int]int)
+	for _, timeframe := range recent {
+		seen := make(map[int]bool)
+		for _, problem := range companyData[timeframe] {
+			if !seen[problem.ID] {
+				seen[problem.ID] = true
+				counts[problem.ID]++
+			}
+		}
+	}
+
+	var evergreen []Problem
+	reference := companyData[recent[len(recent)-1]]
+	for _, problem := range reference {
+		if counts[problem.ID] == len(recent) {
+			evergreen = append(evergreen, problem)
+			counts[problem.ID] = 0 // avoid duplicates
+		}
+	}
+
+	return evergreen
+}
@@ -0,0 +1,155 @@
+package data
+
+import (
+	"testing"
+)
+
+func TestGetCompanySummary(t *testing.T) {
+	testData := map[string]map[string][]Problem{
+		"airbnb": {
+			"all": []Problem{
+				{ID: 1, Title: "Two Sum", Difficulty: "Easy", Frequency: 100.0},
+				{ID: 2, Title: "Add Two Numbers", Difficulty: "Medium", Frequency: 90.0},
+				{ID: 68, Title: "Text Justification", Difficulty: "Hard", Frequency: 80.0},
+				{ID: 4, Title: "Median of Two Sorted Arrays", Difficulty: "Hard", Frequency: 70.0},
+			},
+			"thirty-days": []Problem{
+				{ID: 68, Title: "Text Justification", Difficulty: "Hard", Frequency: 100.0},
+				{ID: 1, Title: "Two Sum", Difficulty: "Easy", Frequency: 50.0},
+			},
+			"six-months": []Problem{
+				{ID: 1, Title: "Two Sum", Difficulty: "Easy", Frequency: 100.0},
+				{ID: 68, Title: "Text Justification", Difficulty: "Hard", Frequency: 90.0},
+				{ID: 2, Title: "Add Two Numbers", Difficulty: "Medium", Frequency: 40.0},
+			},
+			"three-months": []Problem{},
+		},
+		"amazon": {
+			"all": []Problem{
+				{ID: 1, Title: "Two Sum", Difficulty: "Easy", Frequency: 100.0},
+			},
+		},
+	}
+	pbc := NewTestProblemsByCompany(testData)
+
+	summary := pbc.GetCompanySummary("  Airbnb ", 2)
+	if summary == nil {
+		t.Fatal("GetCompanySummary() returned nil for existing company")
+	}
+
+	if summary.Company != "airbnb" {
+		t.Errorf("GetCompanySummary() company = %q, want %q", summary.Company, "airbnb")
+	}
+
+	wantOrder := []string{"thirty-days", "six-months", "all"}
+	if len(summary.Timeframes) != len(wantOrder) {
+		t.Fatalf("GetCompanySummary() timeframes = %d, want %d", len(summary.Timeframes), len(wantOrder))
+	}
+	for i, tf := range summary.Timeframes {
+		if tf.Timeframe != wantOrder[i] {
+			t.Errorf("timeframe[%d] = %q, want %q", i, tf.Timeframe, wantOrder[i])
+		}
+		if len(tf.Top) > 2 {
+			t.Errorf("timeframe %q top = %d problems, want at most 2", tf.Timeframe, len(tf.Top))
+		}
+	}
+
+	if summary.UniqueProblems != 4 {
+		t.Errorf("unique problems = %d, want 4", summary.UniqueProblems)
+	}
+
+	all := summary.Timeframes[2]
+	if all.Count != 4 {
+		t.Errorf("all count = %d, want 4", all.Count)
+	}
+	if all.Difficulty != (DifficultyBreakdown{Easy: 1, Medium: 1, Hard: 2}) {
+		t.Errorf("all difficulty = %+v, want {Easy:1 Medium:1 Hard:2}", all.Difficulty)
+	}
+
+	if len(summary.Evergreen) != 2 {
+		t.Fatalf("evergreen = %d problems, want 2", len(summary.Evergreen))
+	}
+	if summary.Evergreen[0].ID != 1 || summary.Evergreen[1].ID != 68 {
+		t.Errorf("evergreen order = [%d %d], want [1 68]", summary.Evergreen[0].ID, summary.Evergreen[1].ID)
+	}
+}
+
+func TestGetCompanySummary_SingleTimeframe(t *testing.T) {
+	pbc := NewTestProblemsByCompany(map[string]map[string][]Problem{
+		"amazon": {
+			"all": []Problem{{ID: 1, Title: "Two Sum", Difficulty: "Easy", Frequency: 100.0}},
+		},
+	})
+
+	summary := pbc.GetCompanySummary("amazon", 5)
+	if summary == nil {
+		t.Fatal("GetCompanySummary() returned nil for existing company")
+	}
+
+	if len(summary.Evergreen) != 0 {
+		t.Errorf("evergreen = %v, want none for a single timeframe", summary.Evergreen)
+	}
+}
+
+func TestGetCompanySummary_EvergreenIgnoresAll(t *testing.T) {
+	pbc := NewTestProblemsByCompany(map[string]map[string][]Problem{
+		"jane-street": {
+			"all": []Problem{
+				{ID: 1, Title: "Two Sum", Difficulty: "Easy", Frequency: 100.0},
+				{ID: 2, Title: "Add Two Numbers", Difficulty: "Medium", Frequency: 90.0},
+			},
+			"more-than-six-months": []Problem{
+				{ID: 1, Title: "Two Sum", Difficulty: "Easy", Frequency: 100.0},
+				{ID: 2, Title: "Add Two Numbers", Difficulty: "Medium", Frequency: 90.0},
+			},
+		},
+	})
+
+	summary := pbc.GetCompanySummary("jane-street", 5)
+	if summary == nil {
+		t.Fatal("GetCompanySummary() returned nil for existing company")
+	}
+
+	if len(summary.Evergreen) != 0 {
+		t.Errorf("evergreen = %v, want none for a single timeframe besides all", summary.Evergreen)
+	}
+}
+
+func TestGetCompanySummary_NonexistentCompany(t *testing.T) {
+	pbc := NewTestProblemsByCompany(map[string]map[string][]Problem{})
+
+	if summary := pbc.GetCompanySummary("nonexistent", 5); summary != nil {
+		t.Errorf("GetCompanySummary() = %+v, want nil", summary)
+	}
+}
+
+func TestGetOccurrences(t *testing.T) {
+	pbc := NewTestProblemsByCompany(map[string]map[string][]Problem{
+		"google": {
+			"all":         []Problem{{ID: 1, Frequency: 60}, {ID: 2, Frequency: 40}},
+			"thirty-days": []Problem{{ID: 1, Frequency: 100}},
+		},
+		"amazon": {
+			"all": []Problem{{ID: 1, Frequency: 80}},
+		},
+	})
+
+	want := []Occurrence{
+		{Company: "amazon", Timeframe: "all", Frequency: 80},
+		{Company: "google", Timeframe: "thirty-days", Frequency: 100},
+		{Company: "google", Timeframe: "all", Frequency: 60},
+	}
+	got := pbc.GetOccurrences(1)
+	if len(got) != len(want) {
+		t.Fatalf("GetOccurrences(1) = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("occurrence[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	if got := pbc.GetOccurrences(3); got != nil {
+		t.Errorf("GetOccurrences(3) = %+v, want nil", got)
+	}
+}
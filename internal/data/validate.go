@@ -0,0 +1,516 @@
+package data
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Severity of a validation diagnostic
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// diagnostic codes reported by the validator
+const (
+	CodeMalformedCSV       = "malformed-csv"
+	CodeEmptyFile          = "empty-file"
+	CodeInvalidHeader      = "invalid-header"
+	CodeShortRecord        = "short-record"
+	CodeLongRecord         = "long-record"
+	CodeEmptyID            = "empty-id"
+	CodeInvalidID          = "invalid-id"
+	CodeEmptyURL           = "empty-url"
+	CodeInvalidURL         = "invalid-url"
+	CodeEmptyTitle         = "empty-title"
+	CodeDuplicateID        = "duplicate-id"
+	CodeInvalidDifficulty  = "invalid-difficulty"
+	CodeInvalidPercentage  = "invalid-percentage"
+	CodePercentageRange    = "percentage-out-of-range"
+	CodePercentageFormat   = "percentage-format"
+	CodeFrequencyFormat    = "frequency-format"
+	CodeUnknownTimeframe   = "unknown-timeframe"
+	CodeNonCanonical       = "noncanonical-timeframe"
+	CodeIDMismatch         = "id-mismatch"
+	CodeTitleMismatch      = "title-mismatch"
+	CodeURLMismatch        = "url-mismatch"
+	CodeDifficultyMismatch = "difficulty-mismatch"
+)
+
+// problemURLPrefix starts the URL of every problem in the public dataset.
+// Overlays may link elsewhere, so other URLs are only a warning.
+const problemURLPrefix = "https://leetcode.com/"
+
+// csvHeader is the expected header of every data file
+var csvHeader = []string{"ID", "URL", "Title", "Difficulty", "Acceptance %", "Frequency %"}
+
+// Diagnostic is a single problem found in a data file
+type Diagnostic struct {
+	File     string   `json:"file"`
+	Line     int      `json:"line,omitempty"`
+	Severity Severity `json:"severity"`
+	Code     string   `json:"code"`
+	Message  string   `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	location := d.File
+	if d.Line > 0 {
+		location = fmt.Sprintf("%s:%d", d.File, d.Line)
+	}
+	return fmt.Sprintf("%s: %s: %s [%s]", location, d.Severity, d.Message, d.Code)
+}
+
+// ValidationError is returned by strict loading when the dataset has errors
+type ValidationError struct {
+	Diagnostics []Diagnostic
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Diagnostics) == 1 {
+		return fmt.Sprintf("dataset validation failed: %s", e.Diagnostics[0])
+	}
+	return fmt.Sprintf("dataset validation failed with %d errors, first: %s", len(e.Diagnostics), e.Diagnostics[0])
+}
+
+// CanonicalTimeframe maps a timeframe alias such as "30d" to its canonical name.
+// The boolean is false if the name is not a recognized timeframe.
+func CanonicalTimeframe(timeframe string) (string, bool) {
+	return recognizeTimeframe(timeframe)
+}
+
+// problemRef remembers where a problem ID was first seen for cross-file checks
+type problemRef struct {
+	file       string
+	line       int
+	id         int
+	url        string
+	title      string
+	difficulty string
+}
+
+// Validator checks data files one at a time and cross-checks problems between
+// them, so an ID with a different title in another company is reported
+type Validator struct {
+	diagnostics []Diagnostic
+	byID        map[int]problemRef
+	byURL       map[string]problemRef
+}
+
+func NewValidator() *Validator {
+	return &Validator{
+		byID:  make(map[int]problemRef),
+		byURL: make(map[string]problemRef),
+	}
+}
+
+// AddFile validates one timeframe file of a company and returns the problems that parsed
+func (v *Validator) AddFile(file, timeframe string, content []byte) []Problem {
+	problems, _ := v.addFile(file, timeframe, content)
+	return problems
+}
+
+// addFile is AddFile that also returns malformed CSV errors for callers that need to abort
+func (v *Validator) addFile(file, timeframe string, content []byte) ([]Problem, error) {
+	if canonical, ok := recognizeTimeframe(timeframe); !ok {
+		v.report(file, 0, SeverityError, CodeUnknownTimeframe,
+			fmt.Sprintf("file name %q is not a recognized timeframe", timeframe))
+	} else if canonical != timeframe {
+		v.report(file, 0, SeverityWarning, CodeNonCanonical,
+			fmt.Sprintf("timeframe %q should be named %q", timeframe, canonical))
+	}
+
+	problems, refs, diagnostics, err := parseCSVFile(file, content)
+	v.diagnostics = append(v.diagnostics, diagnostics...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ref := range refs {
+		v.crossCheck(ref)
+	}
+
+	return problems, nil
+}
+
+func (v *Validator) crossCheck(ref problemRef) {
+	if first, ok := v.byID[ref.id]; ok {
+		if first.url != ref.url {
+			v.report(ref.file, ref.line, SeverityError, CodeURLMismatch,
+				fmt.Sprintf("ID %d has URL %q but %s:%d has %q", ref.id, ref.url, first.file, first.line, first.url))
+		}
+		if first.title != ref.title {
+			v.report(ref.file, ref.line, SeverityError, CodeTitleMismatch,
+				fmt.Sprintf("ID %d has title %q but %s:%d has %q", ref.id, ref.title, first.file, first.line, first.title))
+		}
+		if !strings.EqualFold(first.difficulty, ref.difficulty) {
+			v.report(ref.file, ref.line, SeverityWarning, CodeDifficultyMismatch,
+				fmt.Sprintf("ID %d has difficulty %q but %s:%d has %q", ref.id, ref.difficulty, first.file, first.line, first.difficulty))
+		}
+	} else {
+		v.byID[ref.id] = ref
+	}
+
+	if first, ok := v.byURL[ref.url]; ok {
+		if first.id != ref.id {
+			v.report(ref.file, ref.line, SeverityError, CodeIDMismatch,
+				fmt.Sprintf("URL %q has ID %d but %s:%d has ID %d", ref.url, ref.id, first.file, first.line, first.id))
+		}
+	} else {
+		v.byURL[ref.url] = ref
+	}
+}
+
+func (v *Validator) report(file string, line int, severity Severity, code, message string) {
+	v.diagnostics = append(v.diagnostics, Diagnostic{
+		File:     file,
+		Line:     line,
+		Severity: severity,
+		Code:     code,
+		Message:  message,
+	})
+}
+
+// Diagnostics returns everything found so far, sorted by file and line
+func (v *Validator) Diagnostics() []Diagnostic {
+	diagnostics := append([]Diagnostic(nil), v.diagnostics...)
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		if diagnostics[i].File != diagnostics[j].File {
+			return diagnostics[i].File < diagnostics[j].File
+		}
+		return diagnostics[i].Line < diagnostics[j].Line
+	})
+	return diagnostics
+}
+
+// Errors returns only the error severity diagnostics
+func (v *Validator) Errors() []Diagnostic {
+	var errs []Diagnostic
+	for _, d := range v.Diagnostics() {
+		if d.Severity == SeverityError {
+			errs = append(errs, d)
+		}
+	}
+	return errs
+}
+
+// ValidateDir validates every CSV file in a directory laid out like data/
+func ValidateDir(dir string) ([]Diagnostic, int, error) {
+	v := NewValidator()
+	files := 0
+
+	companies, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for _, company := range companies {
+		if !company.IsDir() || strings.HasPrefix(company.Name(), ".") {
+			continue
+		}
+
+		entries, err := os.ReadDir(filepath.Join(dir, company.Name()))
+		if err != nil {
+			return nil, files, err
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".csv") {
+				continue
+			}
+
+			path := filepath.Join(dir, company.Name(), entry.Name())
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return nil, files, err
+			}
+
+			files++
+			v.AddFile(path, strings.TrimSuffix(entry.Name(), ".csv"), content)
+		}
+	}
+
+	return v.Diagnostics(), files, nil
+}
+
+// parseCSVFile parses a data file, skipping rows that cannot be used and
+// reporting a diagnostic for every skipped or suspicious row. Only CSV syntax
+// errors are returned as an error.
+func parseCSVFile(file string, csvData []byte) ([]Problem, []problemRef, []Diagnostic, error) {
+	var diagnostics []Diagnostic
+	report := func(line int, severity Severity, code, message string) {
+		diagnostics = append(diagnostics, Diagnostic{File: file, Line: line, Severity: severity, Code: code, Message: message})
+	}
+
+	reader := csv.NewReader(bytes.NewReader(csvData))
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		report(0, SeverityError, CodeEmptyFile, "file is empty")
+		return nil, nil, diagnostics, nil
+	}
+	if err != nil {
+		report(csvErrorLine(err), SeverityError, CodeMalformedCSV, err.Error())
+		return nil, nil, diagnostics, err
+	}
+	if !equalHeader(header) {
+		report(1, SeverityError, CodeInvalidHeader,
+			fmt.Sprintf("header is %q, expected %q", strings.Join(header, ","), strings.Join(csvHeader, ",")))
+	}
+
+	var problems []Problem
+	var refs []problemRef
+	seen := make(map[int]int)
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			report(csvErrorLine(err), SeverityError, CodeMalformedCSV, err.Error())
+			return nil, nil, diagnostics, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		if len(record) < 6 {
+			report(line, SeverityError, CodeShortRecord, fmt.Sprintf("record has %d fields, expected 6", len(record)))
+			continue
+		}
+		if len(record) > 6 {
+			report(line, SeverityError, CodeLongRecord, fmt.Sprintf("record has %d fields, expected 6", len(record)))
+			continue
+		}
+
+		if strings.TrimSpace(record[0]) == "" {
+			report(line, SeverityError, CodeEmptyID, "ID is empty")
+			continue
+		}
+
+		id, err := strconv.Atoi(strings.TrimSpace(record[0]))
+		if err != nil {
+			report(line, SeverityError, CodeInvalidID, fmt.Sprintf("ID %q is not a number", record[0]))
+			continue
+		}
+
+		if firstLine, ok := seen[id]; ok {
+			report(line, SeverityError, CodeDuplicateID, fmt.Sprintf("ID %d already appears on line %d", id, firstLine))
+		} else {
+			seen[id] = line
+		}
+
+		switch {
+		case strings.TrimSpace(record[1]) == "":
+			report(line, SeverityError, CodeEmptyURL, "URL is empty")
+		case !strings.HasPrefix(record[1], problemURLPrefix):
+			report(line, SeverityWarning, CodeInvalidURL, fmt.Sprintf("URL %q doesn't start with %s", record[1], problemURLPrefix))
+		}
+		if strings.TrimSpace(record[2]) == "" {
+			report(line, SeverityError, CodeEmptyTitle, "title is empty")
+		}
+
+		switch record[3] {
+		case "Easy", "Medium", "Hard":
+		default:
+			report(line, SeverityError, CodeInvalidDifficulty,
+				fmt.Sprintf("difficulty %q is not one of Easy, Medium or Hard", record[3]))
+		}
+
+		acceptance := checkPercentage(record[4], "acceptance", false, line, report)
+		frequency := checkPercentage(record[5], "frequency", true, line, report)
+
+		problem := Problem{
+			ID:         id,
+			URL:        record[1],
+			Title:      record[2],
+			Difficulty: record[3],
+			Acceptance: acceptance,
+			Frequency:  frequency,
+		}
+
+		problems = append(problems, problem)
+		refs = append(refs, problemRef{
+			file:       file,
+			line:       line,
+			id:         id,
+			url:        problem.URL,
+			title:      problem.Title,
+			difficulty: problem.Difficulty,
+		})
+	}
+
+	// a header-only file is how the dataset marks a timeframe without activity
+	if len(problems) == 0 {
+		report(0, SeverityWarning, CodeEmptyFile, "file has no problems")
+	}
+
+	return problems, refs, diagnostics, nil
+}
+
+// checkPercentage parses a percentage column, reporting unparseable or out of range values.
+// Unparseable values are treated as 0 to match the lenient loader. A missing
+// percent sign is an error when requireSign is set and a warning otherwise.
+func checkPercentage(value, column string, requireSign bool, line int, report func(int, Severity, string, string)) float64 {
+	percentage, err := parsePercentage(value)
+	if err != nil {
+		report(line, SeverityError, CodeInvalidPercentage, fmt.Sprintf("%s %q is not a number", column, value))
+		return 0
+	}
+	if percentage < 0 || percentage > 100 {
+		report(line, SeverityError, CodePercentageRange, fmt.Sprintf("%s %.1f is outside 0-100", column, percentage))
+	}
+	if !strings.HasSuffix(strings.TrimSpace(value), "%") {
+		if requireSign {
+			report(line, SeverityError, CodeFrequencyFormat, fmt.Sprintf("%s %q must end with %%", column, value))
+		} else {
+			report(line, SeverityWarning, CodePercentageFormat, fmt.Sprintf("%s %q should end with %%", column, value))
+		}
+	}
+	return percentage
+}
+
+func equalHeader(header []string) bool {
+	if len(header) != len(csvHeader) {
+		return false
+	}
+	for i := range header {
+		if strings.TrimSpace(header[i]) != csvHeader[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func csvErrorLine(err error) int {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return parseErr.Line
+	}
+	return 0
+}
+
+// FixCSV applies safe automatic fixes to a data file: it restores or adds the header,
+// trims whitespace, normalizes difficulty casing, adds missing percent signs,
+// clamps percentages to 0-100 and drops repeated IDs (keeping the first).
+// Rows that cannot be repaired are kept as-is. Returns the fixed file and a
+// description of every change.
+func FixCSV(csvData []byte) ([]byte, []string, error) {
+	reader := csv.NewReader(bytes.NewReader(csvData))
+	reader.FieldsPerRecord = -1
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(records) == 0 {
+		return csvData, nil, nil
+	}
+
+	var fixes []string
+	out := [][]string{csvHeader}
+	// first is the index of the first data row
+	first := 1
+	if !equalHeader(records[0]) {
+		if isBrokenHeader(records[0]) {
+			fixes = append(fixes, "line 1: restored header")
+		} else {
+			// the first line is data, keep it and fix it like the others
+			first = 0
+			fixes = append(fixes, "line 1: added missing header")
+		}
+	}
+
+	seen := make(map[string]bool)
+	for i, record := range records[first:] {
+		line := first + i + 1
+		fixed := make([]string, len(record))
+		for j, field := range record {
+			fixed[j] = strings.TrimSpace(field)
+			if fixed[j] != field {
+				fixes = append(fixes, fmt.Sprintf("line %d: trimmed whitespace in column %d", line, j+1))
+			}
+		}
+
+		if len(fixed) >= 6 {
+			if seen[fixed[0]] {
+				fixes = append(fixes, fmt.Sprintf("line %d: removed duplicate ID %s", line, fixed[0]))
+				continue
+			}
+			seen[fixed[0]] = true
+
+			if difficulty := canonicalDifficulty(fixed[3]); difficulty != "" && difficulty != fixed[3] {
+				fixes = append(fixes, fmt.Sprintf("line %d: difficulty %q → %q", line, fixed[3], difficulty))
+				fixed[3] = difficulty
+			}
+
+			for _, column := range []int{4, 5} {
+				if percentage, err := parsePercentage(fixed[column]); err == nil {
+					normalized := fixed[column]
+					if percentage < 0 || percentage > 100 {
+						percentage = clamp(percentage, 0, 100)
+						normalized = fmt.Sprintf("%.1f%%", percentage)
+					} else if !strings.HasSuffix(normalized, "%") {
+						normalized += "%"
+					}
+					if normalized != fixed[column] {
+						fixes = append(fixes, fmt.Sprintf("line %d: %s %q → %q", line, csvHeader[column], fixed[column], normalized))
+						fixed[column] = normalized
+					}
+				}
+			}
+		}
+
+		out = append(out, fixed)
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.WriteAll(out); err != nil {
+		return nil, nil, err
+	}
+
+	return buf.Bytes(), fixes, nil
+}
+
+// isBrokenHeader reports whether a first line that isn't the header is a
+// damaged one rather than a problem, which always starts with a numeric ID
+func isBrokenHeader(record []string) bool {
+	if len(record) != len(csvHeader) {
+		return false
+	}
+	_, err := strconv.Atoi(strings.TrimSpace(record[0]))
+	return err != nil
+}
+
+func canonicalDifficulty(difficulty string) string {
+	switch strings.ToLower(strings.TrimSpace(difficulty)) {
+	case "easy":
+		return "Easy"
+	case "medium":
+		return "Medium"
+	case "hard":
+		return "Hard"
+	}
+	return ""
+}
+
+func clamp(value, min, max float64) float64 {
+	if value < min {
+		return min
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
@@ -0,0 +1,355 @@
+package data
+
+import (
+	"strings"
+	"testing"
+)
+
+const validCSVHeader = "ID,URL,Title,Difficulty,Acceptance %,Frequency %\n"
+
+func TestValidatorDiagnostics(t *testing.T) {
+	tests := []struct {
+		name      string
+		timeframe string
+		content   string
+		wantCode  string
+		wantLine  int
+		wantSev   Severity
+	}{
+		{
+			name:      "empty file",
+			timeframe: "all",
+			content:   "",
+			wantCode:  CodeEmptyFile,
+			wantSev:   SeverityError,
+		},
+		{
+			name:      "header only",
+			timeframe: "all",
+			content:   validCSVHeader,
+			wantCode:  CodeEmptyFile,
+			wantSev:   SeverityWarning,
+		},
+		{
+			name:      "invalid header",
+			timeframe: "all",
+			content:   "id,url,title\n1,https://leetcode.com/problems/two-sum,Two Sum,Easy,50%,100%\n",
+			wantCode:  CodeInvalidHeader,
+			wantLine:  1,
+			wantSev:   SeverityError,
+		},
+		{
+			name:      "short record",
+			timeframe: "all",
+			content:   validCSVHeader + "1,https://leetcode.com/problems/two-sum,Two Sum\n",
+			wantCode:  CodeShortRecord,
+			wantLine:  2,
+			wantSev:   SeverityError,
+		},
+		{
+			name:      "long record",
+			timeframe: "all",
+			content:   validCSVHeader + "1,https://leetcode.com/problems/two-sum,Two Sum,Easy,50%,100%,extra\n",
+			wantCode:  CodeLongRecord,
+			wantLine:  2,
+			wantSev:   SeverityError,
+		},
+		{
+			name:      "empty id",
+			timeframe: "all",
+			content:   validCSVHeader + ",https://leetcode.com/problems/two-sum,Two Sum,Easy,50%,100%\n",
+			wantCode:  CodeEmptyID,
+			wantLine:  2,
+			wantSev:   SeverityError,
+		},
+		{
+			name:      "empty url",
+			timeframe: "all",
+			content:   validCSVHeader + "1,,Two Sum,Easy,50%,100%\n",
+			wantCode:  CodeEmptyURL,
+			wantLine:  2,
+			wantSev:   SeverityError,
+		},
+		{
+			name:      "url outside leetcode",
+			timeframe: "all",
+			content:   validCSVHeader + "1,https://example.com/two-sum,Two Sum,Easy,50%,100%\n",
+			wantCode:  CodeInvalidURL,
+			wantLine:  2,
+			wantSev:   SeverityWarning,
+		},
+		{
+			name:      "empty title",
+			timeframe: "all",
+			content:   validCSVHeader + "1,https://leetcode.com/problems/two-sum, ,Easy,50%,100%\n",
+			wantCode:  CodeEmptyTitle,
+			wantLine:  2,
+			wantSev:   SeverityError,
+		},
+		{
+			name:      "invalid id",
+			timeframe: "all",
+			content:   validCSVHeader + "one,https://leetcode.com/problems/two-sum,Two Sum,Easy,50%,100%\n",
+			wantCode:  CodeInvalidID,
+			wantLine:  2,
+			wantSev:   SeverityError,
+		},
+		{
+			name:      "duplicate id",
+			timeframe: "all",
+			content: validCSVHeader +
+				"1,https://leetcode.com/problems/two-sum,Two Sum,Easy,50%,100%\n" +
+				"1,https://leetcode.com/problems/two-sum,Two Sum,Easy,50%,90%\n",
+			wantCode: CodeDuplicateID,
+			wantLine: 3,
+			wantSev:  SeverityError,
+		},
+		{
+			name:      "invalid difficulty",
+			timeframe: "all",
+			content:   validCSVHeader + "1,https://leetcode.com/problems/two-sum,Two Sum,Trivial,50%,100%\n",
+			wantCode:  CodeInvalidDifficulty,
+			wantLine:  2,
+			wantSev:   SeverityError,
+		},
+		{
+			name:      "invalid percentage",
+			timeframe: "all",
+			content:   validCSVHeader + "1,https://leetcode.com/problems/two-sum,Two Sum,Easy,half,100%\n",
+			wantCode:  CodeInvalidPercentage,
+			wantLine:  2,
+			wantSev:   SeverityError,
+		},
+		{
+			name:      "percentage out of range",
+			timeframe: "all",
+			content:   validCSVHeader + "1,https://leetcode.com/problems/two-sum,Two Sum,Easy,50%,140%\n",
+			wantCode:  CodePercentageRange,
+			wantLine:  2,
+			wantSev:   SeverityError,
+		},
+		{
+			name:      "percentage without percent sign",
+			timeframe: "all",
+			content:   validCSVHeader + "1,https://leetcode.com/problems/two-sum,Two Sum,Easy,50,100%\n",
+			wantCode:  CodePercentageFormat,
+			wantLine:  2,
+			wantSev:   SeverityWarning,
+		},
+		{
+			name:      "frequency without percent sign",
+			timeframe: "all",
+			content:   validCSVHeader + "1,https://leetcode.com/problems/two-sum,Two Sum,Easy,50%,100\n",
+			wantCode:  CodeFrequencyFormat,
+			wantLine:  2,
+			wantSev:   SeverityError,
+		},
+		{
+			name:      "malformed csv",
+			timeframe: "all",
+			content:   validCSVHeader + "1,\"https://leetcode.com/problems/two-sum,Two Sum,Easy,50%,100%\n",
+			wantCode:  CodeMalformedCSV,
+			wantLine:  2,
+			wantSev:   SeverityError,
+		},
+		{
+			name:      "unknown timeframe",
+			timeframe: "last-week",
+			content:   validCSVHeader + "1,https://leetcode.com/problems/two-sum,Two Sum,Easy,50%,100%\n",
+			wantCode:  CodeUnknownTimeframe,
+			wantSev:   SeverityError,
+		},
+		{
+			name:      "non canonical timeframe",
+			timeframe: "30d",
+			content:   validCSVHeader + "1,https://leetcode.com/problems/two-sum,Two Sum,Easy,50%,100%\n",
+			wantCode:  CodeNonCanonical,
+			wantSev:   SeverityWarning,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewValidator()
+			file := "acme/" + tt.timeframe + ".csv"
+			v.AddFile(file, tt.timeframe, []byte(tt.content))
+
+			var found *Diagnostic
+			for _, d := range v.Diagnostics() {
+				if d.Code == tt.wantCode {
+					d := d
+					found = &d
+					break
+				}
+			}
+			if found == nil {
+				t.Fatalf("expected %s diagnostic, got %v", tt.wantCode, v.Diagnostics())
+			}
+			if found.File != file {
+				t.Errorf("file = %q, want %q", found.File, file)
+			}
+			if found.Line != tt.wantLine {
+				t.Errorf("line = %d, want %d", found.Line, tt.wantLine)
+			}
+			if found.Severity != tt.wantSev {
+				t.Errorf("severity = %q, want %q", found.Severity, tt.wantSev)
+			}
+		})
+	}
+}
+
+func TestValidatorValidFile(t *testing.T) {
+	v := NewValidator()
+	problems := v.AddFile("acme/all.csv", "all", []byte(validCSVHeader+
+		"1,https://leetcode.com/problems/two-sum,Two Sum,Easy,50%,100%\n"+
+		"2,https://leetcode.com/problems/add-two-numbers,Add Two Numbers,Medium,40.5%,80%\n"))
+
+	if len(problems) != 2 {
+		t.Fatalf("expected 2 problems, got %d", len(problems))
+	}
+	if diagnostics := v.Diagnostics(); len(diagnostics) != 0 {
+		t.Errorf("expected no diagnostics, got %v", diagnostics)
+	}
+}
+
+func TestValidatorCrossCheck(t *testing.T) {
+	v := NewValidator()
+	v.AddFile("acme/all.csv", "all", []byte(validCSVHeader+
+		"1,https://leetcode.com/problems/two-sum,Two Sum,Easy,50%,100%\n"+
+		"2,https://leetcode.com/problems/add-two-numbers,Add Two Numbers,Medium,40%,80%\n"))
+	v.AddFile("globex/all.csv", "all", []byte(validCSVHeader+
+		"1,https://leetcode.com/problems/two-sum,2 Sum,Medium,50%,100%\n"+
+		"3,https://leetcode.com/problems/add-two-numbers,Add Two Numbers,Medium,40%,80%\n"))
+
+	codes := make(map[string]Diagnostic)
+	for _, d := range v.Diagnostics() {
+		codes[d.Code] = d
+	}
+
+	for _, code := range []string{CodeTitleMismatch, CodeDifficultyMismatch, CodeIDMismatch} {
+		d, ok := codes[code]
+		if !ok {
+			t.Errorf("expected %s diagnostic, got %v", code, v.Diagnostics())
+			continue
+		}
+		if d.File != "globex/all.csv" {
+			t.Errorf("%s reported in %q, want globex/all.csv", code, d.File)
+		}
+		if !strings.Contains(d.Message, "acme/all.csv:") {
+			t.Errorf("%s message %q should point at the first occurrence", code, d.Message)
+		}
+	}
+
+	if len(v.Errors()) != 2 {
+		t.Errorf("expected 2 errors (title and ID mismatch), got %v", v.Errors())
+	}
+}
+
+func TestDiagnosticString(t *testing.T) {
+	d := Diagnostic{File: "acme/all.csv", Line: 3, Severity: SeverityError, Code: CodeInvalidID, Message: "bad"}
+	if got, want := d.String(), "acme/all.csv:3: error: bad [invalid-id]"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestCanonicalTimeframe(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		ok    bool
+	}{
+		{"thirty-days", "thirty-days", true},
+		{"30d", "thirty-days", true},
+		{"3mo", "three-months", true},
+		{"all", "all", true},
+		{"last-week", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok := CanonicalTimeframe(tt.input)
+			if ok != tt.ok || (ok && got != tt.want) {
+				t.Errorf("CanonicalTimeframe(%q) = %q, %v; want %q, %v", tt.input, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestFixCSV(t *testing.T) {
+	input := "id,url,title,difficulty,acceptance,frequency\n" +
+		" 1 ,https://leetcode.com/problems/two-sum,Two Sum,easy,50,100%\n" +
+		"1,https://leetcode.com/problems/two-sum,Two Sum,Easy,50%,100%\n" +
+		"2,https://leetcode.com/problems/add-two-numbers,Add Two Numbers,MEDIUM,40%,120%\n"
+
+	fixed, fixes, err := FixCSV([]byte(input))
+	if err != nil {
+		t.Fatalf("FixCSV() error = %v", err)
+	}
+	if len(fixes) == 0 {
+		t.Fatal("expected fixes to be reported")
+	}
+
+	want := validCSVHeader +
+		"1,https://leetcode.com/problems/two-sum,Two Sum,Easy,50%,100%\n" +
+		"2,https://leetcode.com/problems/add-two-numbers,Add Two Numbers,Medium,40%,100.0%\n"
+	if string(fixed) != want {
+		t.Errorf("FixCSV() =\n%s\nwant\n%s", fixed, want)
+	}
+
+	v := NewValidator()
+	v.AddFile("acme/all.csv", "all", fixed)
+	if diagnostics := v.Diagnostics(); len(diagnostics) != 0 {
+		t.Errorf("fixed file should validate cleanly, got %v", diagnostics)
+	}
+
+	// fixing is idempotent
+	again, fixes, err := FixCSV(fixed)
+	if err != nil {
+		t.Fatalf("FixCSV() error = %v", err)
+	}
+	if len(fixes) != 0 || string(again) != string(fixed) {
+		t.Errorf("second FixCSV() changed the file: %v", fixes)
+	}
+}
+
+func TestFixCSVHeader(t *testing.T) {
+	rows := " 1,https://leetcode.com/problems/two-sum,Two Sum,easy,50%,100%\n" +
+		"2,https://leetcode.com/problems/add-two-numbers,Add Two Numbers,Medium,40%,90%\n"
+	want := validCSVHeader +
+		"1,https://leetcode.com/problems/two-sum,Two Sum,Easy,50%,100%\n" +
+		"2,https://leetcode.com/problems/add-two-numbers,Add Two Numbers,Medium,40%,90%\n"
+
+	tests := []struct {
+		name  string
+		input string
+		fix   string
+	}{
+		// a headerless file's first row is a problem, not a damaged header
+		{"missing", rows, "line 1: added missing header"},
+		{"damaged", "id,url,title,difficulty,acceptance,frequency\n" + rows, "line 1: restored header"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixed, fixes, err := FixCSV([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("FixCSV() error = %v", err)
+			}
+			if string(fixed) != want {
+				t.Errorf("FixCSV() =\n%s\nwant\n%s", fixed, want)
+			}
+			if len(fixes) == 0 || fixes[0] != tt.fix {
+				t.Errorf("fixes = %q, want %q first", fixes, tt.fix)
+			}
+		})
+	}
+}
+
+func TestLoadAllProblemsStrict(t *testing.T) {
+	pbc, err := LoadAllProblemsWithOptions(LoadOptions{Strict: true})
+	if err != nil {
+		t.Fatalf("embedded data should pass strict validation: %v", err)
+	}
+	if len(pbc.GetAvailableCompanies()) == 0 {
+		t.Error("expected companies to be loaded")
+	}
+}
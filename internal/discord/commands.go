@@ -0,0 +1,194 @@
+package discord
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/whotypes/leetbot/internal/config"
+	"github.com/whotypes/leetbot/internal/logging"
+)
+
+// commandScope is the slash commands that should be registered in a guild,
+// or globally when guildID is empty
+type commandScope struct {
+	guildID  string
+	commands []*discordgo.ApplicationCommand
+}
+
+// commandDiff names the commands a scope is missing, has registered
+// differently, or shouldn't have
+type commandDiff struct {
+	added, updated, removed []string
+}
+
+func (d commandDiff) empty() bool {
+	return len(d.added) == 0 && len(d.updated) == 0 && len(d.removed) == 0
+}
+
+// commandDisabled reports whether bot.disabled_commands turns a command off in a guild
+func commandDisabled(settings *config.BotConfig, guildID, name string) bool {
+	return guildID != "" && slices.Contains(settings.DisabledCommands, guildID+":"+name)
+}
+
+// commandScopes lists where commands should be registered. They're global
+// unless bot.command_guilds names development guilds, where changes show up
+// at once rather than within the hour; the global commands are then cleared
+// so they aren't listed twice. A guild's registration leaves out the commands
+// turned off in it, global commands are refused instead when they're run.
+// Guilds in dropped are cleared, for guilds no longer in bot.command_guilds.
+func (h *Handler) commandScopes(onlyHelp bool, dropped []string) []commandScope {
+	settings := currentSettings()
+
+	var commands []*discordgo.ApplicationCommand
+	for _, cmd := range GetSlashCommands(h.problemsData) {
+		// a disabled bot keeps /help so users can see it's still there
+		if !onlyHelp || cmd.Name == "help" {
+			commands = append(commands, cmd)
+		}
+	}
+
+	if len(settings.CommandGuilds) == 0 {
+		scopes := []commandScope{{commands: commands}}
+		for _, guildID := range dropped {
+			scopes = append(scopes, commandScope{guildID: guildID})
+		}
+		return scopes
+	}
+
+	scopes := []commandScope{{}}
+	for _, guildID := range settings.CommandGuilds {
+		scope := commandScope{guildID: guildID}
+		for _, cmd := range commands {
+			if !commandDisabled(settings, guildID, cmd.Name) {
+				scope.commands = append(scope.commands, cmd)
+			}
+		}
+		scopes = append(scopes, scope)
+	}
+	for _, guildID := range dropped {
+		if !slices.Contains(settings.CommandGuilds, guildID) {
+			scopes = append(scopes, commandScope{guildID: guildID})
+		}
+	}
+	return scopes
+}
+
+// SyncCommands brings the registered slash commands in line with the ones
+// the bot offers, overwriting a scope in one request when anything in it
+// differs and leaving it alone otherwise
+func (h *Handler) SyncCommands(s *discordgo.Session) error {
+	return h.syncCommands(s, h.disabled, nil)
+}
+
+func (h *Handler) syncCommands(s *discordgo.Session, onlyHelp bool, dropped []string) error {
+	appID := s.State.User.ID
+	var errs []error
+	for _, scope := range h.commandScopes(onlyHelp, dropped) {
+		logger := slog.With(logging.KeyGuild, scope.guildID)
+
+		registered, err := s.ApplicationCommands(appID, scope.guildID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("listing commands of guild %q: %w", scope.guildID, err))
+			continue
+		}
+		diff := diffCommands(registered, scope.commands)
+		if diff.empty() {
+			logger.Debug("slash commands up to date", "count", len(registered))
+			continue
+		}
+
+		// an empty list clears the scope, nil would be sent as null
+		desired := scope.commands
+		if desired == nil {
+			desired = []*discordgo.ApplicationCommand{}
+		}
+		if _, err := s.ApplicationCommandBulkOverwrite(appID, scope.guildID, desired); err != nil {
+			errs = append(errs, fmt.Errorf("overwriting commands of guild %q: %w", scope.guildID, err))
+			continue
+		}
+		logger.Info("synced slash commands", "added", diff.added, "updated", diff.updated, "removed", diff.removed)
+	}
+	return errors.Join(errs...)
+}
+
+// diffCommands compares registered commands with the desired ones by name
+func diffCommands(registered, desired []*discordgo.ApplicationCommand) commandDiff {
+	current := make(map[string]string, len(registered))
+	for _, cmd := range registered {
+		current[cmd.Name] = commandSpec(cmd)
+	}
+
+	var diff commandDiff
+	wanted := make(map[string]bool, len(desired))
+	for _, cmd := range desired {
+		wanted[cmd.Name] = true
+		spec, ok := current[cmd.Name]
+		switch {
+		case !ok:
+			diff.added = append(diff.added, cmd.Name)
+		case spec != commandSpec(cmd):
+			diff.updated = append(diff.updated, cmd.Name)
+		}
+	}
+	for _, cmd := range registered {
+		if !wanted[cmd.Name] {
+			diff.removed = append(diff.removed, cmd.Name)
+		}
+	}
+	return diff
+}
+
+// commandSpec encodes what the bot sets on a command, leaving out what
+// Discord adds when it's registered (IDs, versions and defaults) so a
+// registered command compares equal to the one it was created from
+func commandSpec(cmd *discordgo.ApplicationCommand) string {
+	spec := discordgo.ApplicationCommand{
+		Type:                     cmd.Type,
+		Name:                     cmd.Name,
+		NameLocalizations:        nonEmpty(cmd.NameLocalizations),
+		DefaultMemberPermissions: cmd.DefaultMemberPermissions,
+		Description:              cmd.Description,
+		DescriptionLocalizations: nonEmpty(cmd.DescriptionLocalizations),
+		Options:                  optionSpecs(cmd.Options),
+	}
+	if spec.Type == 0 {
+		spec.Type = discordgo.ChatApplicationCommand
+	}
+	encoded, err := json.Marshal(spec)
+	if err != nil {
+		// commands are plain data, this only fails for unencodable choice values
+		return fmt.Sprintf("%#v", spec)
+	}
+	return string(encoded)
+}
+
+func optionSpecs(options []*discordgo.ApplicationCommandOption) []*discordgo.ApplicationCommandOption {
+	if len(options) == 0 {
+		return nil
+	}
+	specs := make([]*discordgo.ApplicationCommandOption, len(options))
+	for i, opt := range options {
+		spec := *opt
+		if len(spec.ChannelTypes) == 0 {
+			spec.ChannelTypes = nil
+		}
+		if len(spec.Choices) == 0 {
+			spec.Choices = nil
+		}
+		spec.Options = optionSpecs(opt.Options)
+		specs[i] = &spec
+	}
+	return specs
+}
+
+// nonEmpty treats empty localizations like missing ones, Discord returns either
+func nonEmpty(localizations *map[discordgo.Locale]string) *map[discordgo.Locale]string {
+	if localizations == nil || len(*localizations) == 0 {
+		return nil
+	}
+	return localizations
+}
@@ -0,0 +1,194 @@
+package discord
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/whotypes/leetbot/internal/config"
+	"github.com/whotypes/leetbot/internal/discord/discordtest"
+)
+
+func commandNames(commands []*discordgo.ApplicationCommand) []string {
+	names := make([]string, len(commands))
+	for i, cmd := range commands {
+		names[i] = cmd.Name
+	}
+	slices.Sort(names)
+	return names
+}
+
+func commandIDs(commands []*discordgo.ApplicationCommand) []string {
+	ids := make([]string, len(commands))
+	for i, cmd := range commands {
+		ids[i] = cmd.ID
+	}
+	return ids
+}
+
+func TestDiffCommands(t *testing.T) {
+	desired := GetSlashCommands(createTestProblemsData())
+
+	// what Discord returns has IDs, versions and a type on top of what was sent
+	encoded, err := json.Marshal(desired)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var registered []*discordgo.ApplicationCommand
+	if err := json.Unmarshal(encoded, &registered); err != nil {
+		t.Fatal(err)
+	}
+	for i, cmd := range registered {
+		cmd.ID = "20000000000000000" + string(rune('0'+i))
+		cmd.Version = "1"
+		cmd.Type = discordgo.ChatApplicationCommand
+	}
+	if diff := diffCommands(registered, desired); !diff.empty() {
+		t.Errorf("diffCommands() of registered commands = %+v, want no changes", diff)
+	}
+
+	registered[0].Description = "outdated"
+	extra := &discordgo.ApplicationCommand{Name: "old", Description: "Removed from the bot"}
+	diff := diffCommands(slices.Concat(registered[1:], []*discordgo.ApplicationCommand{registered[0], extra}), desired[1:])
+	want := commandDiff{removed: []string{desired[0].Name, "old"}}
+	if !slices.Equal(diff.added, want.added) || !slices.Equal(diff.updated, want.updated) || !slices.Equal(diff.removed, want.removed) {
+		t.Errorf("diffCommands() = %+v, want %+v", diff, want)
+	}
+
+	diff = diffCommands(registered, desired)
+	if !slices.Equal(diff.updated, []string{desired[0].Name}) || len(diff.added) != 0 || len(diff.removed) != 0 {
+		t.Errorf("diffCommands() = %+v, want %s updated", diff, desired[0].Name)
+	}
+	if diff := diffCommands(nil, desired[:1]); !slices.Equal(diff.added, []string{desired[0].Name}) {
+		t.Errorf("diffCommands() = %+v, want %s added", diff, desired[0].Name)
+	}
+}
+
+func TestSyncCommands(t *testing.T) {
+	defaults := config.Defaults().Bot
+	t.Cleanup(func() { Configure(defaults) })
+
+	handler := NewHandler(createTestProblemsData(), "!")
+	session := discordtest.New()
+	all := commandNames(GetSlashCommands(createTestProblemsData()))
+
+	if err := handler.SyncCommands(session.Session); err != nil {
+		t.Fatalf("SyncCommands() error = %v", err)
+	}
+	registered := session.Commands("")
+	if got := commandNames(registered); !slices.Equal(got, all) {
+		t.Fatalf("global commands = %v, want %v", got, all)
+	}
+
+	// nothing changed, so nothing is overwritten
+	ids := commandIDs(registered)
+	if err := handler.SyncCommands(session.Session); err != nil {
+		t.Fatalf("SyncCommands() error = %v", err)
+	}
+	if got := commandIDs(session.Commands("")); !slices.Equal(got, ids) {
+		t.Errorf("unchanged commands were registered again, IDs %v became %v", ids, got)
+	}
+
+	// a command edited outside the bot is put back
+	edited := *registered[0]
+	edited.Description = "outdated"
+	if _, err := session.ApplicationCommandEdit(discordtest.BotUserID, "", edited.ID, &edited); err != nil {
+		t.Fatal(err)
+	}
+	if err := handler.SyncCommands(session.Session); err != nil {
+		t.Fatalf("SyncCommands() error = %v", err)
+	}
+	if got := session.Commands("")[0].Description; got == "outdated" {
+		t.Error("an outdated command wasn't overwritten")
+	}
+
+	// development guilds get the commands instead, less those turned off in them
+	const devGuild, otherGuild = "300000000000000001", "300000000000000002"
+	next := defaults
+	next.CommandGuilds = []string{devGuild, otherGuild}
+	next.DisabledCommands = []string{devGuild + ":export", devGuild + ":dataset"}
+	if err := handler.Reconfigure("!", next); err != nil {
+		t.Fatal(err)
+	}
+	if err := handler.SyncCommands(session.Session); err != nil {
+		t.Fatalf("SyncCommands() error = %v", err)
+	}
+	if got := session.Commands(""); len(got) != 0 {
+		t.Errorf("global commands = %v, want none with development guilds", commandNames(got))
+	}
+	wantDev := slices.DeleteFunc(slices.Clone(all), func(name string) bool { return name == "export" || name == "dataset" })
+	if got := commandNames(session.Commands(devGuild)); !slices.Equal(got, wantDev) {
+		t.Errorf("%s commands = %v, want %v", devGuild, got, wantDev)
+	}
+	if got := commandNames(session.Commands(otherGuild)); !slices.Equal(got, all) {
+		t.Errorf("%s commands = %v, want %v", otherGuild, got, all)
+	}
+
+	// dropping the guilds on reload clears them and goes back to global
+	handler.SetSession(session.Session)
+	if err := handler.Reconfigure("!", defaults); err != nil {
+		t.Fatal(err)
+	}
+	if got := commandNames(session.Commands("")); !slices.Equal(got, all) {
+		t.Errorf("global commands = %v, want %v", got, all)
+	}
+	for _, guildID := range []string{devGuild, otherGuild} {
+		if got := session.Commands(guildID); len(got) != 0 {
+			t.Errorf("%s commands = %v, want none once it's dropped", guildID, commandNames(got))
+		}
+	}
+}
+
+func TestShutdownAndStartupSyncCommands(t *testing.T) {
+	handler := NewHandler(createTestProblemsData(), "!")
+	session := discordtest.New()
+	handler.SetSession(session.Session)
+	handler.EnableChannel("1")
+	admin := &discordgo.User{ID: config.Defaults().Bot.AdminID, Username: "admin"}
+	all := commandNames(GetSlashCommands(createTestProblemsData()))
+
+	if err := handler.SyncCommands(session.Session); err != nil {
+		t.Fatal(err)
+	}
+
+	handler.HandleMessage(session.Session, session.MessageCreate("1", admin, "!shutdown indef"))
+	if got := commandNames(session.Commands("")); !slices.Equal(got, []string{"help"}) {
+		t.Errorf("commands after !shutdown indef = %v, want only help", got)
+	}
+	if !handler.disabled {
+		t.Error("!shutdown indef should disable the bot")
+	}
+
+	handler.HandleMessage(session.Session, session.MessageCreate("1", admin, "!startup"))
+	if got := commandNames(session.Commands("")); !slices.Equal(got, all) {
+		t.Errorf("commands after !startup = %v, want %v", got, all)
+	}
+	if handler.disabled {
+		t.Error("!startup should enable the bot")
+	}
+}
+
+func TestDisabledCommandIsRefused(t *testing.T) {
+	defaults := config.Defaults().Bot
+	t.Cleanup(func() { Configure(defaults) })
+	settings := defaults
+	settings.DisabledCommands = []string{discordtest.GuildID + ":search"}
+	Configure(settings)
+
+	handler := NewHandler(createTestProblemsData(), "!")
+	session := discordtest.New()
+	handler.SetSession(session.Session)
+	user := &discordgo.User{ID: "42", Username: "tester"}
+
+	handler.HandleSlashCommand(session.Session, session.SlashCommand("1", user, discordgo.ApplicationCommandInteractionData{
+		Name: "search",
+		Options: []*discordgo.ApplicationCommandInteractionDataOption{
+			{Name: "query", Type: discordgo.ApplicationCommandOptionString, Value: "two sum"},
+		},
+	}))
+	events := session.Events()
+	if len(events) != 1 || !events[0].Ephemeral || events[0].Message.Content != "This command is turned off in this server." {
+		t.Fatalf("expected an ephemeral refusal, got %+v", events)
+	}
+}
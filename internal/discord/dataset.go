@@ -0,0 +1,152 @@
+package discord
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/whotypes/leetbot/internal/data"
+	"github.com/whotypes/leetbot/internal/i18n"
+)
+
+const (
+	// defaultChangelogThreshold is the minimum frequency change reported by /dataset changelog
+	defaultChangelogThreshold = 10.0
+	// embedDescriptionLimit is discord's maximum length for an embed description
+	embedDescriptionLimit = 4096
+)
+
+// SetPreviousDataset sets the snapshot that /dataset changelog compares against
+func (h *Handler) SetPreviousDataset(previous *data.ProblemsByCompany) {
+	h.previousData = previous
+}
+
+// createChangelogEmbed summarizes a dataset changelog, truncating to fit a single embed
+func createChangelogEmbed(tr *i18n.Printer, changelog *data.Changelog) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title:     tr.T("dataset.title", changelog.From.Version, changelog.To.Version),
+		Color:     0x5865F2,
+		Timestamp: time.Now().Format(time.RFC3339),
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:   tr.T("dataset.companies"),
+				Value:  fmt.Sprintf("%d → %d", changelog.From.Companies, changelog.To.Companies),
+				Inline: true,
+			},
+			{
+				Name:   tr.T("dataset.unique_problems"),
+				Value:  fmt.Sprintf("%d → %d", changelog.From.UniqueProblems, changelog.To.UniqueProblems),
+				Inline: true,
+			},
+		},
+	}
+
+	if changelog.Empty() {
+		embed.Description = tr.T("dataset.no_changes")
+		return embed
+	}
+
+	var lines []string
+	if len(changelog.AddedCompanies) > 0 {
+		lines = append(lines, tr.T("dataset.added", len(changelog.AddedCompanies), formatCompanyList(tr, changelog.AddedCompanies)))
+	}
+	if len(changelog.RemovedCompanies) > 0 {
+		lines = append(lines, tr.T("dataset.removed", len(changelog.RemovedCompanies), formatCompanyList(tr, changelog.RemovedCompanies)))
+	}
+	for _, tf := range changelog.Timeframes {
+		var parts []string
+		if len(tf.Added) > 0 {
+			parts = append(parts, fmt.Sprintf("+%d", len(tf.Added)))
+		}
+		if len(tf.Removed) > 0 {
+			parts = append(parts, fmt.Sprintf("-%d", len(tf.Removed)))
+		}
+		if len(tf.FrequencyChanges) > 0 {
+			parts = append(parts, fmt.Sprintf("~%d", len(tf.FrequencyChanges)))
+		}
+		lines = append(lines, fmt.Sprintf("• %s (%s): %s",
+			formatCompanyName(tf.Company), formatTimeframeDisplay(tr, tf.Timeframe), strings.Join(parts, " ")))
+	}
+
+	var description strings.Builder
+	for i, line := range lines {
+		remaining := len(lines) - i
+		if description.Len()+len(line)+64 > embedDescriptionLimit {
+			description.WriteString(tr.T("dataset.more_changes", remaining))
+			break
+		}
+		description.WriteString(line + "\n")
+	}
+	embed.Description = description.String()
+
+	embed.Footer = &discordgo.MessageEmbedFooter{
+		Text: tr.T("dataset.footer"),
+	}
+
+	return embed
+}
+
+func formatCompanyList(tr *i18n.Printer, companies []string) string {
+	const maxShown = 15
+	names := make([]string, 0, maxShown)
+	for i, company := range companies {
+		if i >= maxShown {
+			names = append(names, tr.T("list.and_more", len(companies)-maxShown))
+			break
+		}
+		names = append(names, formatCompanyName(company))
+	}
+	return strings.Join(names, ", ")
+}
+
+func (h *Handler) handleDatasetSlash(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	tr := interactionPrinter(i.Interaction)
+	respondEphemeral := func(data *discordgo.InteractionResponseData) {
+		data.Flags |= discordgo.MessageFlagsEphemeral
+		err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: data,
+		})
+		if err != nil {
+			interactionFailed(i.Interaction, "responding to interaction failed", err)
+		}
+	}
+
+	var userID string
+	if i.Member != nil && i.Member.User != nil {
+		userID = i.Member.User.ID
+	} else if i.User != nil {
+		userID = i.User.ID
+	}
+	if !isAdmin(userID) {
+		respondEphemeral(&discordgo.InteractionResponseData{Content: tr.T("admin.owner_only")})
+		return
+	}
+
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 || options[0].Name != "changelog" {
+		respondEphemeral(&discordgo.InteractionResponseData{Content: tr.T("dataset.usage")})
+		return
+	}
+
+	if h.previousData == nil {
+		version := h.problemsData.Version()
+		respondEphemeral(&discordgo.InteractionResponseData{
+			Content: tr.T("dataset.no_previous", version.Version, version.Companies, version.UniqueProblems),
+		})
+		return
+	}
+
+	threshold := defaultChangelogThreshold
+	for _, opt := range options[0].Options {
+		if opt.Name == "threshold" {
+			threshold = opt.FloatValue()
+		}
+	}
+
+	changelog := data.Diff(h.previousData, h.problemsData, threshold)
+	respondEphemeral(&discordgo.InteractionResponseData{
+		Embeds: []*discordgo.MessageEmbed{createChangelogEmbed(tr, changelog)},
+	})
+}
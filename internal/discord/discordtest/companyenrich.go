@@ -0,0 +1,105 @@
+package discordtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Company is a search result of the fake Company Enrich API
+type Company struct {
+	Name   string
+	Domain string
+}
+
+// CompanyEnrich stands in for the Company Enrich search API, so the remote
+// enricher can be tested without an API key or network access. Point the
+// enricher at URL.
+type CompanyEnrich struct {
+	*httptest.Server
+	apiKey string
+
+	mu       sync.Mutex
+	results  map[string][]Company
+	status   int
+	requests []string
+}
+
+// NewCompanyEnrich starts a fake API that accepts apiKey, closed when the
+// test ends
+func NewCompanyEnrich(t testing.TB, apiKey string) *CompanyEnrich {
+	c := &CompanyEnrich{apiKey: apiKey, results: make(map[string][]Company)}
+	c.Server = httptest.NewServer(http.HandlerFunc(c.search))
+	t.Cleanup(c.Close)
+	return c
+}
+
+// Add makes a search for query answer with companies
+func (c *CompanyEnrich) Add(query string, companies ...Company) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.results[strings.ToLower(query)] = companies
+}
+
+// FailWith makes every search answer status, or work again with 0
+func (c *CompanyEnrich) FailWith(status int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.status = status
+}
+
+// Requests returns the queries searched for so far
+func (c *CompanyEnrich) Requests() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.requests...)
+}
+
+func (c *CompanyEnrich) search(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.URL.Path != "/companies/search" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Header.Get("Authorization") != "Bearer "+c.apiKey {
+		http.Error(w, `{"message":"invalid API key"}`, http.StatusUnauthorized)
+		return
+	}
+	var payload struct {
+		Query string `json:"query"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, `{"message":"invalid body"}`, http.StatusBadRequest)
+		return
+	}
+
+	c.mu.Lock()
+	c.requests = append(c.requests, payload.Query)
+	status := c.status
+	companies := c.results[strings.ToLower(payload.Query)]
+	c.mu.Unlock()
+
+	if status != 0 {
+		http.Error(w, `{"message":"unavailable"}`, status)
+		return
+	}
+
+	type item struct {
+		ID     string `json:"id"`
+		Name   string `json:"name"`
+		Domain string `json:"domain"`
+	}
+	items := make([]item, len(companies))
+	for i, company := range companies {
+		items[i] = item{ID: company.Domain, Name: company.Name, Domain: company.Domain}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"items":      items,
+		"page":       1,
+		"totalPages": 1,
+		"totalItems": len(items),
+	})
+}
@@ -0,0 +1,255 @@
+// Package discordtest fakes the Discord REST API in memory, so handlers can be
+// driven end to end without a bot token or network access.
+//
+// A Session wraps a real *discordgo.Session whose HTTP client is replaced by
+// an in-memory transport. Messages sent, edited and returned through
+// interactions are stored and reported as events, and paginator buttons can
+// be clicked by building component interactions against stored messages.
+package discordtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// BotUserID is the application and bot user ID of the fake session
+	BotUserID = "100000000000000001"
+	// GuildID is the guild fake interactions come from
+	GuildID = "100000000000000002"
+)
+
+// EventType describes what happened to a message
+type EventType string
+
+const (
+	EventMessageCreate EventType = "create"
+	EventMessageUpdate EventType = "update"
+	EventMessageDelete EventType = "delete"
+	EventAutocomplete  EventType = "autocomplete"
+)
+
+// File is an attachment uploaded with a message
+type File struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// Event is a change made through the REST API
+type Event struct {
+	Type EventType
+	// Message is the message after the change, nil for autocomplete results
+	Message *discordgo.Message
+	// Ephemeral is set for interaction responses only the invoking user sees
+	Ephemeral bool
+	// Files are the attachments uploaded with the request
+	Files []File
+	// Choices are the autocomplete suggestions
+	Choices []*discordgo.ApplicationCommandOptionChoice
+}
+
+// interaction is what the fake remembers about an interaction token
+type interaction struct {
+	channelID string
+	// messageID is the clicked message for component interactions
+	messageID string
+	// originalID is the message created by the interaction response
+	originalID string
+}
+
+// Session is a discordgo session backed by an in-memory Discord
+type Session struct {
+	*discordgo.Session
+
+	mu           sync.Mutex
+	nextID       int64
+	messages     map[string]map[string]json.RawMessage
+	interactions map[string]*interaction
+	// commands holds registered application commands by guild, "" for global
+	commands map[string]map[string]*discordgo.ApplicationCommand
+	// permissions holds the bot's permissions by channel, see SetBotPermissions
+	permissions map[string]int64
+	events      []Event
+}
+
+// New returns a fake session that answers REST calls from memory
+func New() *Session {
+	s, err := discordgo.New("Bot discordtest")
+	if err != nil {
+		// only fails for malformed tokens
+		panic(err)
+	}
+
+	fake := &Session{
+		Session:      s,
+		nextID:       200000000000000000,
+		messages:     make(map[string]map[string]json.RawMessage),
+		interactions: make(map[string]*interaction),
+		commands:     make(map[string]map[string]*discordgo.ApplicationCommand),
+		permissions:  make(map[string]int64),
+	}
+	s.Client = &http.Client{Transport: &transport{session: fake}}
+	s.ShouldRetryOnRateLimit = false
+	s.State.User = &discordgo.User{ID: BotUserID, Username: "leetbot", Bot: true}
+
+	return fake
+}
+
+func (s *Session) newID() string {
+	s.nextID++
+	return fmt.Sprint(s.nextID)
+}
+
+// Events returns the events recorded since the last call and clears them
+func (s *Session) Events() []Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	events := s.events
+	s.events = nil
+	return events
+}
+
+// Message returns a stored message
+func (s *Session) Message(id string) (*discordgo.Message, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	raw, ok := s.messages[id]
+	if !ok {
+		return nil, false
+	}
+	return decodeMessage(raw), true
+}
+
+// Commands returns the application commands registered for a guild, or the
+// global commands for an empty guildID, sorted by name
+func (s *Session) Commands(guildID string) []*discordgo.ApplicationCommand {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	commands := make([]*discordgo.ApplicationCommand, 0, len(s.commands[guildID]))
+	for _, cmd := range s.commands[guildID] {
+		commands = append(commands, cmd)
+	}
+	sort.Slice(commands, func(i, j int) bool { return commands[i].Name < commands[j].Name })
+	return commands
+}
+
+// SetBotPermissions gives the bot permissions in a channel of GuildID, both in
+// the session state read for messages and in the app permissions of
+// interactions from the channel. Channels without permissions set aren't in
+// the state, like channels the bot hasn't cached yet.
+func (s *Session) SetBotPermissions(channelID string, permissions int64) {
+	s.mu.Lock()
+	s.permissions[channelID] = permissions
+	s.mu.Unlock()
+
+	guild, err := s.State.Guild(GuildID)
+	if err != nil {
+		guild = &discordgo.Guild{ID: GuildID, Name: "discordtest"}
+		if err := s.State.GuildAdd(guild); err != nil {
+			panic(err)
+		}
+		if err := s.State.MemberAdd(&discordgo.Member{GuildID: GuildID, User: s.State.User}); err != nil {
+			panic(err)
+		}
+	}
+
+	// the bot only has @everyone, so a per-channel overwrite of it sets the
+	// bot's permissions there
+	err = s.State.ChannelAdd(&discordgo.Channel{
+		ID:      channelID,
+		GuildID: GuildID,
+		Type:    discordgo.ChannelTypeGuildText,
+		PermissionOverwrites: []*discordgo.PermissionOverwrite{{
+			ID:    GuildID,
+			Type:  discordgo.PermissionOverwriteTypeRole,
+			Allow: permissions,
+			Deny:  ^permissions,
+		}},
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// MessageCreate builds the gateway event for a user posting in a channel
+func (s *Session) MessageCreate(channelID string, author *discordgo.User, content string) *discordgo.MessageCreate {
+	s.mu.Lock()
+	id := s.newID()
+	s.mu.Unlock()
+
+	return &discordgo.MessageCreate{Message: &discordgo.Message{
+		ID:        id,
+		ChannelID: channelID,
+		GuildID:   GuildID,
+		Author:    author,
+		Content:   content,
+	}}
+}
+
+// SlashCommand builds the interaction for a user running a slash command
+func (s *Session) SlashCommand(channelID string, user *discordgo.User, data discordgo.ApplicationCommandInteractionData) *discordgo.InteractionCreate {
+	return s.newInteraction(discordgo.InteractionApplicationCommand, channelID, "", user, data)
+}
+
+// Autocomplete builds the interaction for a user typing into a slash command
+// option, the focused option should have Focused set
+func (s *Session) Autocomplete(channelID string, user *discordgo.User, data discordgo.ApplicationCommandInteractionData) *discordgo.InteractionCreate {
+	return s.newInteraction(discordgo.InteractionApplicationCommandAutocomplete, channelID, "", user, data)
+}
+
+// ComponentClick builds the interaction for a user clicking a button on a
+// stored message
+func (s *Session) ComponentClick(messageID string, user *discordgo.User, customID string) (*discordgo.InteractionCreate, error) {
+	msg, ok := s.Message(messageID)
+	if !ok {
+		return nil, fmt.Errorf("unknown message %s", messageID)
+	}
+
+	data := discordgo.MessageComponentInteractionData{
+		CustomID:      customID,
+		ComponentType: discordgo.ButtonComponent,
+	}
+	i := s.newInteraction(discordgo.InteractionMessageComponent, msg.ChannelID, messageID, user, data)
+	i.Message = msg
+	return i, nil
+}
+
+func (s *Session) newInteraction(kind discordgo.InteractionType, channelID, messageID string, user *discordgo.User, data discordgo.InteractionData) *discordgo.InteractionCreate {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.newID()
+	token := "token-" + id
+	s.interactions[token] = &interaction{channelID: channelID, messageID: messageID}
+
+	return &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		ID:        id,
+		AppID:     BotUserID,
+		Type:      kind,
+		Data:      data,
+		GuildID:   GuildID,
+		ChannelID: channelID,
+		Member:    &discordgo.Member{User: user, GuildID: GuildID},
+		Token:     token,
+		Version:   1,
+
+		AppPermissions: s.permissions[channelID],
+	}}
+}
+
+// decodeMessage turns a stored message into its discordgo form, which knows
+// how to decode components
+func decodeMessage(raw map[string]json.RawMessage) *discordgo.Message {
+	body, _ := json.Marshal(raw)
+	var msg discordgo.Message
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return &discordgo.Message{ID: string(raw["id"])}
+	}
+	return &msg
+}
@@ -0,0 +1,389 @@
+package discordtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// transport routes discordgo's REST requests to the in-memory session
+type transport struct {
+	session *Session
+}
+
+// apiError is the error body Discord returns
+type apiError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	payload, files, err := readBody(req)
+	if err != nil {
+		return jsonResponse(req, http.StatusBadRequest, apiError{Message: err.Error()}), nil
+	}
+
+	route := strings.TrimPrefix(req.URL.Path, "/api/v"+discordgo.APIVersion+"/")
+	parts := strings.Split(strings.Trim(route, "/"), "/")
+
+	s := t.session
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status, body := s.route(req.Method, parts, payload, files)
+	if status == http.StatusNoContent {
+		return &http.Response{
+			StatusCode: status,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(bytes.NewReader(nil)),
+			Request:    req,
+		}, nil
+	}
+	return jsonResponse(req, status, body), nil
+}
+
+func (s *Session) route(method string, parts []string, payload []byte, files []File) (int, interface{}) {
+	switch {
+	// channels/{channel}/messages
+	case len(parts) == 3 && parts[0] == "channels" && parts[2] == "messages" && method == http.MethodPost:
+		return s.createMessage(parts[1], payload, files, false)
+
+	// channels/{channel}/messages/{message}
+	case len(parts) == 4 && parts[0] == "channels" && parts[2] == "messages":
+		return s.messageRoute(method, parts[3], payload, files)
+
+	// interactions/{interaction}/{token}/callback
+	case len(parts) == 4 && parts[0] == "interactions" && parts[3] == "callback" && method == http.MethodPost:
+		return s.interactionCallback(parts[2], payload, files)
+
+	// webhooks/{application}/{token}/messages/{message}
+	case len(parts) == 5 && parts[0] == "webhooks" && parts[3] == "messages":
+		messageID := parts[4]
+		if messageID == "@original" {
+			it, ok := s.interactions[parts[2]]
+			if !ok || it.originalID == "" {
+				return http.StatusNotFound, apiError{Code: 10008, Message: "Unknown Message"}
+			}
+			messageID = it.originalID
+		}
+		return s.messageRoute(method, messageID, payload, files)
+
+	// webhooks/{application}/{token} sends a followup message
+	case len(parts) == 3 && parts[0] == "webhooks" && method == http.MethodPost:
+		it, ok := s.interactions[parts[2]]
+		if !ok {
+			return http.StatusNotFound, apiError{Code: 10015, Message: "Unknown Webhook"}
+		}
+		return s.createMessage(it.channelID, payload, files, false)
+
+	// applications/{application}[/guilds/{guild}]/commands[/{command}]
+	case len(parts) >= 3 && parts[0] == "applications":
+		guildID := ""
+		rest := parts[2:]
+		if rest[0] == "guilds" && len(rest) >= 3 {
+			guildID = rest[1]
+			rest = rest[2:]
+		}
+		if rest[0] == "commands" {
+			return s.commandsRoute(method, guildID, rest[1:], payload)
+		}
+	}
+
+	return http.StatusNotFound, apiError{Message: fmt.Sprintf("discordtest: unhandled route %s /%s", method, strings.Join(parts, "/"))}
+}
+
+func (s *Session) messageRoute(method, messageID string, payload []byte, files []File) (int, interface{}) {
+	raw, ok := s.messages[messageID]
+	if !ok {
+		return http.StatusNotFound, apiError{Code: 10008, Message: "Unknown Message"}
+	}
+
+	switch method {
+	case http.MethodGet:
+		return http.StatusOK, raw
+	case http.MethodPatch:
+		return s.updateMessage(messageID, payload, files)
+	case http.MethodDelete:
+		delete(s.messages, messageID)
+		s.events = append(s.events, Event{Type: EventMessageDelete, Message: decodeMessage(raw)})
+		return http.StatusNoContent, nil
+	}
+
+	return http.StatusMethodNotAllowed, apiError{Message: "Method Not Allowed"}
+}
+
+func (s *Session) createMessage(channelID string, payload []byte, files []File, ephemeral bool) (int, interface{}) {
+	raw := make(map[string]json.RawMessage)
+	if len(payload) > 0 {
+		if err := json.Unmarshal(payload, &raw); err != nil {
+			return http.StatusBadRequest, apiError{Code: 50109, Message: err.Error()}
+		}
+	}
+
+	id := s.newID()
+	raw["id"] = mustJSON(id)
+	raw["channel_id"] = mustJSON(channelID)
+	raw["guild_id"] = mustJSON(GuildID)
+	raw["author"] = mustJSON(s.State.User)
+	raw["type"] = mustJSON(discordgo.MessageTypeDefault)
+	if len(files) > 0 {
+		raw["attachments"] = attachments(s, files)
+	}
+	s.messages[id] = raw
+
+	s.events = append(s.events, Event{
+		Type:      EventMessageCreate,
+		Message:   decodeMessage(raw),
+		Ephemeral: ephemeral || isEphemeral(raw),
+		Files:     files,
+	})
+	return http.StatusOK, raw
+}
+
+func (s *Session) updateMessage(messageID string, payload []byte, files []File) (int, interface{}) {
+	var changes map[string]json.RawMessage
+	if len(payload) > 0 {
+		if err := json.Unmarshal(payload, &changes); err != nil {
+			return http.StatusBadRequest, apiError{Code: 50109, Message: err.Error()}
+		}
+	}
+
+	raw := s.messages[messageID]
+	for key, value := range changes {
+		// fields that are left out or null keep their current value
+		if key == "id" || key == "channel_id" || string(value) == "null" {
+			continue
+		}
+		raw[key] = value
+	}
+	if len(files) > 0 {
+		raw["attachments"] = attachments(s, files)
+	}
+
+	s.events = append(s.events, Event{
+		Type:      EventMessageUpdate,
+		Message:   decodeMessage(raw),
+		Ephemeral: isEphemeral(raw),
+		Files:     files,
+	})
+	return http.StatusOK, raw
+}
+
+func (s *Session) interactionCallback(token string, payload []byte, files []File) (int, interface{}) {
+	it, ok := s.interactions[token]
+	if !ok {
+		return http.StatusNotFound, apiError{Code: 10062, Message: "Unknown interaction"}
+	}
+
+	var resp struct {
+		Type discordgo.InteractionResponseType `json:"type"`
+		Data json.RawMessage                   `json:"data"`
+	}
+	if err := json.Unmarshal(payload, &resp); err != nil {
+		return http.StatusBadRequest, apiError{Code: 50109, Message: err.Error()}
+	}
+
+	switch resp.Type {
+	case discordgo.InteractionResponseChannelMessageWithSource, discordgo.InteractionResponseDeferredChannelMessageWithSource:
+		status, body := s.createMessage(it.channelID, resp.Data, files, false)
+		if status == http.StatusOK {
+			it.originalID = s.events[len(s.events)-1].Message.ID
+		}
+		return statusNoContent(status, body)
+
+	case discordgo.InteractionResponseUpdateMessage:
+		if it.messageID == "" {
+			return http.StatusBadRequest, apiError{Code: 40060, Message: "Interaction has no message to update"}
+		}
+		return statusNoContent(s.updateMessage(it.messageID, resp.Data, files))
+
+	case discordgo.InteractionResponseDeferredMessageUpdate:
+		return http.StatusNoContent, nil
+
+	case discordgo.InteractionApplicationCommandAutocompleteResult:
+		var data struct {
+			Choices []*discordgo.ApplicationCommandOptionChoice `json:"choices"`
+		}
+		if len(resp.Data) > 0 {
+			if err := json.Unmarshal(resp.Data, &data); err != nil {
+				return http.StatusBadRequest, apiError{Code: 50109, Message: err.Error()}
+			}
+		}
+		s.events = append(s.events, Event{Type: EventAutocomplete, Choices: data.Choices})
+		return http.StatusNoContent, nil
+	}
+
+	return http.StatusBadRequest, apiError{Message: fmt.Sprintf("discordtest: unsupported interaction response type %d", resp.Type)}
+}
+
+func (s *Session) commandsRoute(method, guildID string, rest []string, payload []byte) (int, interface{}) {
+	if s.commands[guildID] == nil {
+		s.commands[guildID] = make(map[string]*discordgo.ApplicationCommand)
+	}
+	commands := s.commands[guildID]
+
+	register := func(cmd *discordgo.ApplicationCommand) *discordgo.ApplicationCommand {
+		// like Discord, creating a command with an existing name replaces it
+		for id, existing := range commands {
+			if existing.Name == cmd.Name {
+				delete(commands, id)
+			}
+		}
+		cmd.ID = s.newID()
+		cmd.ApplicationID = BotUserID
+		cmd.GuildID = guildID
+		commands[cmd.ID] = cmd
+		return cmd
+	}
+
+	switch {
+	case len(rest) == 0 && method == http.MethodGet:
+		list := make([]*discordgo.ApplicationCommand, 0, len(commands))
+		for _, cmd := range commands {
+			list = append(list, cmd)
+		}
+		return http.StatusOK, list
+
+	case len(rest) == 0 && method == http.MethodPost:
+		var cmd discordgo.ApplicationCommand
+		if err := json.Unmarshal(payload, &cmd); err != nil {
+			return http.StatusBadRequest, apiError{Code: 50035, Message: err.Error()}
+		}
+		return http.StatusCreated, register(&cmd)
+
+	case len(rest) == 0 && method == http.MethodPut:
+		var list []*discordgo.ApplicationCommand
+		if err := json.Unmarshal(payload, &list); err != nil {
+			return http.StatusBadRequest, apiError{Code: 50035, Message: err.Error()}
+		}
+		for id := range commands {
+			delete(commands, id)
+		}
+		for _, cmd := range list {
+			register(cmd)
+		}
+		return http.StatusOK, list
+
+	case len(rest) == 1:
+		cmd, ok := commands[rest[0]]
+		if !ok {
+			return http.StatusNotFound, apiError{Code: 10063, Message: "Unknown application command"}
+		}
+		switch method {
+		case http.MethodGet:
+			return http.StatusOK, cmd
+		case http.MethodPatch:
+			if err := json.Unmarshal(payload, cmd); err != nil {
+				return http.StatusBadRequest, apiError{Code: 50035, Message: err.Error()}
+			}
+			return http.StatusOK, cmd
+		case http.MethodDelete:
+			delete(commands, rest[0])
+			return http.StatusNoContent, nil
+		}
+	}
+
+	return http.StatusMethodNotAllowed, apiError{Message: "Method Not Allowed"}
+}
+
+// readBody returns the JSON payload of a request and any uploaded files
+func readBody(req *http.Request) ([]byte, []File, error) {
+	if req.Body == nil {
+		return nil, nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mediaType, params, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return body, nil, nil
+	}
+
+	var payload []byte
+	var files []File
+	reader := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		content, err := io.ReadAll(part)
+		if err != nil {
+			return nil, nil, err
+		}
+		if part.FormName() == "payload_json" {
+			payload = content
+			continue
+		}
+		files = append(files, File{
+			Name:        part.FileName(),
+			ContentType: part.Header.Get("Content-Type"),
+			Data:        content,
+		})
+	}
+
+	return payload, files, nil
+}
+
+func attachments(s *Session, files []File) json.RawMessage {
+	list := make([]*discordgo.MessageAttachment, 0, len(files))
+	for _, file := range files {
+		list = append(list, &discordgo.MessageAttachment{
+			ID:          s.newID(),
+			Filename:    file.Name,
+			ContentType: file.ContentType,
+			Size:        len(file.Data),
+		})
+	}
+	return mustJSON(list)
+}
+
+func isEphemeral(raw map[string]json.RawMessage) bool {
+	var flags discordgo.MessageFlags
+	if err := json.Unmarshal(raw["flags"], &flags); err != nil {
+		return false
+	}
+	return flags&discordgo.MessageFlagsEphemeral != 0
+}
+
+// statusNoContent maps a successful message response to the empty body
+// Discord returns for interaction callbacks
+func statusNoContent(status int, body interface{}) (int, interface{}) {
+	if status == http.StatusOK {
+		return http.StatusNoContent, nil
+	}
+	return status, body
+}
+
+func mustJSON(v interface{}) json.RawMessage {
+	body, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return body
+}
+
+func jsonResponse(req *http.Request, status int, v interface{}) *http.Response {
+	body, _ := json.Marshal(v)
+	header := make(http.Header)
+	header.Set("Content-Type", "application/json")
+	return &http.Response{
+		StatusCode:    status,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
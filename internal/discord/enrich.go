@@ -0,0 +1,521 @@
+package discord
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/whotypes/leetbot/internal/config"
+	"github.com/whotypes/leetbot/internal/data"
+	"github.com/whotypes/leetbot/internal/logging"
+)
+
+// Enricher finds companies for input the dataset's names don't match, like a
+// domain or a name the company is better known by. findCompanyWithSuggestion
+// asks it when fuzzy matching isn't confident.
+type Enricher interface {
+	// Enrich returns the companies query may mean, best first
+	Enrich(ctx context.Context, query string) ([]EnrichedCompany, error)
+}
+
+// EnrichedCompany is a company an Enricher found. Slug is set when the
+// enricher knows the dataset's slug, otherwise Name is fuzzy matched.
+type EnrichedCompany struct {
+	Slug   string
+	Name   string
+	Domain string
+}
+
+// ErrCircuitOpen is returned instead of calling an enricher that keeps failing
+var ErrCircuitOpen = errors.New("enricher is failing, lookups are paused")
+
+// CompanyEnrichURL is the Company Enrich API
+const CompanyEnrichURL = "https://api.companyenrich.com"
+
+const (
+	// enrichTimeout bounds a lookup on the message path
+	enrichTimeout = time.Second
+	// the remote API is paused for remoteCooldown after remoteFailures
+	// failures in a row, and its results are kept for remoteCacheTTL
+	remoteFailures = 3
+	remoteCooldown = time.Minute
+	remoteCacheTTL = 6 * time.Hour
+	// maxEnrichEntries caps how many queries the remote results are kept for
+	maxEnrichEntries = 1000
+	// maxEnrichLookups caps the remote lookups running in the background
+	maxEnrichLookups = 4
+)
+
+var (
+	enricherMu sync.Mutex
+	enricher   Enricher
+	// enricherKey is the API key enricher was built with
+	enricherKey string
+	// enricherSet is true once SetEnricher replaced the configured enricher
+	enricherSet bool
+	// companyEnrichDisabled leaves out the remote API whatever the configuration
+	companyEnrichDisabled bool
+)
+
+// configureEnricher builds the enricher for bot.company_enrich_api_key. The
+// enricher is kept, with its cache, while the key doesn't change.
+func configureEnricher(c config.BotConfig) {
+	enricherMu.Lock()
+	defer enricherMu.Unlock()
+	if enricherSet || (enricher != nil && c.CompanyEnrichAPIKey == enricherKey) {
+		return
+	}
+	enricherKey = c.CompanyEnrichAPIKey
+	enricher = defaultEnricher(enricherKey)
+}
+
+// defaultEnricher searches data/companies.json, and the Company Enrich API
+// when there's a key. The API is only ever asked in the background, so a slow
+// or failing API never holds up a reply.
+func defaultEnricher(apiKey string) Enricher {
+	local := NewLocalEnricher(data.Companies())
+	if apiKey == "" || companyEnrichDisabled {
+		return local
+	}
+	remote := NewCircuitBreaker(NewCompanyEnrichAPI(apiKey, CompanyEnrichURL), remoteFailures, remoteCooldown)
+	return Enrichers(local, NewBackgroundEnricher(remote, remoteCacheTTL))
+}
+
+// SetEnricher replaces the configured enricher, or goes back to it with nil
+func SetEnricher(e Enricher) {
+	enricherMu.Lock()
+	enricher, enricherSet = e, e != nil
+	enricherMu.Unlock()
+	if e == nil {
+		configureEnricher(*currentSettings())
+	}
+}
+
+// DisableCompanyEnrich keeps company resolution fully offline, for tools like the CLI
+func DisableCompanyEnrich() {
+	enricherMu.Lock()
+	companyEnrichDisabled = true
+	if !enricherSet {
+		enricher = defaultEnricher("")
+	}
+	enricherMu.Unlock()
+}
+
+func currentEnricher() Enricher {
+	enricherMu.Lock()
+	defer enricherMu.Unlock()
+	return enricher
+}
+
+// enrichCompany asks the enricher about input, bounded by enrichTimeout
+func enrichCompany(input string) ([]EnrichedCompany, error) {
+	e := currentEnricher()
+	if e == nil {
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), enrichTimeout)
+	defer cancel()
+	return e.Enrich(ctx, input)
+}
+
+// localEnricher matches queries against the names, tickers and domains in
+// data/companies.json, without the network
+type localEnricher struct {
+	registry *data.CompanyRegistry
+	keys     []localKey
+}
+
+type localKey struct {
+	key  string
+	slug string
+}
+
+// NewLocalEnricher indexes every name, alias, ticker, former name and domain
+// in the registry. Domains match with or without their TLD, and URLs by their
+// host.
+func NewLocalEnricher(r *data.CompanyRegistry) Enricher {
+	e := &localEnricher{registry: r}
+	add := func(name, slug string) {
+		if key := data.NormalizeCompanyName(name); key != "" {
+			e.keys = append(e.keys, localKey{key: key, slug: slug})
+		}
+	}
+	for _, c := range r.All() {
+		add(c.Slug, c.Slug)
+		for _, name := range r.Names(c.Slug) {
+			add(name, c.Slug)
+		}
+		if c.Domain != "" {
+			add(c.Domain, c.Slug)
+			add(strings.Split(c.Domain, ".")[0], c.Slug)
+		}
+	}
+	return e
+}
+
+func (e *localEnricher) Enrich(_ context.Context, query string) ([]EnrichedCompany, error) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if u, err := url.Parse(query); err == nil && u.Host != "" {
+		query = u.Host
+	} else {
+		query, _, _ = strings.Cut(query, "/")
+	}
+	query = data.NormalizeCompanyName(strings.TrimPrefix(query, "www."))
+	if query == "" {
+		return nil, nil
+	}
+
+	// 3 for an exact match, 2 when the query is part of a name like
+	// "morgan chase", 1 when a name is part of the query like "google careers"
+	scores := make(map[string]int)
+	for _, k := range e.keys {
+		score := 0
+		switch {
+		case k.key == query:
+			score = 3
+		case len(query) >= 4 && strings.Contains(k.key, query):
+			score = 2
+		case len(k.key) >= 4 && strings.Contains(query, k.key):
+			score = 1
+		}
+		if score > scores[k.slug] {
+			scores[k.slug] = score
+		}
+	}
+
+	best := 0
+	for _, score := range scores {
+		best = max(best, score)
+	}
+	var results []EnrichedCompany
+	for slug, score := range scores {
+		if score == best && score > 0 {
+			info, _ := e.registry.Info(slug)
+			results = append(results, EnrichedCompany{Slug: slug, Name: info.Name, Domain: info.Domain})
+		}
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Slug < results[j].Slug })
+	return results, nil
+}
+
+// multiEnricher asks each enricher in turn and keeps every result
+type multiEnricher []Enricher
+
+// Enrichers combines enrichers, earlier ones first. It only fails when every
+// enricher does.
+func Enrichers(enrichers ...Enricher) Enricher {
+	return multiEnricher(enrichers)
+}
+
+func (m multiEnricher) Enrich(ctx context.Context, query string) ([]EnrichedCompany, error) {
+	var results []EnrichedCompany
+	var errs []error
+	for _, e := range m {
+		found, err := e.Enrich(ctx, query)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		results = append(results, found...)
+	}
+	if len(errs) == len(m) && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return results, nil
+}
+
+// CompanyEnrichResponse represents the response from the Company Enrich API
+type CompanyEnrichResponse struct {
+	Items      []CompanyEnrichItem `json:"items"`
+	Page       int                 `json:"page"`
+	TotalPages int                 `json:"totalPages"`
+	TotalItems int                 `json:"totalItems"`
+}
+
+// CompanyEnrichItem represents a single company from the API response
+type CompanyEnrichItem struct {
+	ID     string  `json:"id"`
+	Name   *string `json:"name"`
+	Domain *string `json:"domain"`
+}
+
+// companyEnrichAPI searches the Company Enrich API
+type companyEnrichAPI struct {
+	apiKey  string
+	baseURL string
+	client  *http.Client
+}
+
+// NewCompanyEnrichAPI returns an enricher that searches the Company Enrich
+// API at baseURL, usually CompanyEnrichURL
+func NewCompanyEnrichAPI(apiKey, baseURL string) Enricher {
+	return &companyEnrichAPI{
+		apiKey:  apiKey,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+func (a *companyEnrichAPI) Enrich(ctx context.Context, query string) ([]EnrichedCompany, error) {
+	payloadBytes, err := json.Marshal(map[string]string{
+		"semanticQuery": query,
+		"query":         query,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.baseURL+"/companies/search", bytes.NewReader(payloadBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Add("accept", "application/json")
+	req.Header.Add("content-type", "application/json")
+	req.Header.Add("Authorization", "Bearer "+a.apiKey)
+
+	res, err := a.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API returned non-200 status: %d, body: %s", res.StatusCode, string(body))
+	}
+
+	var response CompanyEnrichResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	var results []EnrichedCompany
+	for _, item := range response.Items {
+		if item.Name == nil || *item.Name == "" {
+			continue
+		}
+		result := EnrichedCompany{Name: *item.Name}
+		if item.Domain != nil {
+			result.Domain = *item.Domain
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// circuitBreaker stops calling an enricher for a cooldown after it fails
+// several times in a row. After the cooldown one lookup is let through, and
+// the breaker closes again if it succeeds.
+type circuitBreaker struct {
+	next      Enricher
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+// NewCircuitBreaker pauses next for cooldown after threshold failures in a
+// row, answering ErrCircuitOpen meanwhile
+func NewCircuitBreaker(next Enricher, threshold int, cooldown time.Duration) Enricher {
+	return &circuitBreaker{next: next, threshold: threshold, cooldown: cooldown, now: time.Now}
+}
+
+func (b *circuitBreaker) Enrich(ctx context.Context, query string) ([]EnrichedCompany, error) {
+	b.mu.Lock()
+	if b.failures >= b.threshold && (b.now().Before(b.openUntil) || b.probing) {
+		b.mu.Unlock()
+		return nil, ErrCircuitOpen
+	}
+	probe := b.failures >= b.threshold
+	b.probing = probe
+	b.mu.Unlock()
+
+	results, err := b.next.Enrich(ctx, query)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	if err != nil {
+		b.failures++
+		if b.failures >= b.threshold {
+			b.openUntil = b.now().Add(b.cooldown)
+			if !probe {
+				slog.Warn("company enrich failing, pausing lookups", "failures", b.failures, "cooldown", b.cooldown, logging.Err(err))
+			}
+		}
+		return nil, err
+	}
+	if probe {
+		slog.Info("company enrich recovered, resuming lookups")
+	}
+	b.failures = 0
+	return results, nil
+}
+
+// cachedEnricher keeps an enricher's results, including empty ones, for a
+// while. Queries are looked up trimmed and lowercased, as they're cached.
+// Errors aren't cached.
+type cachedEnricher struct {
+	next Enricher
+	ttl  time.Duration
+	// background answers a miss with nothing and looks it up in the background
+	background bool
+	now        func() time.Time
+	// maxEntries caps memory, the least recently used entry goes first
+	maxEntries int
+	// slots holds a token for each background lookup in flight, a miss while
+	// they're all taken isn't looked up, so a flood of queries can't use up
+	// the API's quota
+	slots chan struct{}
+
+	mu sync.Mutex
+	// entries maps a query to its element in recent, most recently used first
+	entries map[string]*list.Element
+	recent  *list.List
+	pending map[string]bool
+	// lookups counts background lookups in flight, for tests
+	lookups sync.WaitGroup
+}
+
+type cacheEntry struct {
+	key     string
+	results []EnrichedCompany
+	expires time.Time
+}
+
+// NewCachedEnricher caches next's results for ttl
+func NewCachedEnricher(next Enricher, ttl time.Duration) Enricher {
+	return newCachedEnricher(next, ttl, false)
+}
+
+// NewBackgroundEnricher caches next's results for ttl, but never waits for
+// next: a query that isn't cached answers nothing right away and is looked up
+// in the background, so asking again later finds it
+func NewBackgroundEnricher(next Enricher, ttl time.Duration) Enricher {
+	return newCachedEnricher(next, ttl, true)
+}
+
+func newCachedEnricher(next Enricher, ttl time.Duration, background bool) *cachedEnricher {
+	return &cachedEnricher{
+		next:       next,
+		ttl:        ttl,
+		background: background,
+		now:        time.Now,
+		maxEntries: maxEnrichEntries,
+		slots:      make(chan struct{}, maxEnrichLookups),
+		entries:    make(map[string]*list.Element),
+		recent:     list.New(),
+		pending:    make(map[string]bool),
+	}
+}
+
+func (c *cachedEnricher) Enrich(ctx context.Context, query string) ([]EnrichedCompany, error) {
+	key := strings.ToLower(strings.TrimSpace(query))
+
+	c.mu.Lock()
+	if results, ok := c.getLocked(key); ok {
+		c.mu.Unlock()
+		return results, nil
+	}
+	if !c.background {
+		c.mu.Unlock()
+		return c.lookup(ctx, key)
+	}
+	if !c.pending[key] {
+		select {
+		case c.slots <- struct{}{}:
+			c.pending[key] = true
+			c.lookups.Add(1)
+			go func() {
+				defer c.lookups.Done()
+				defer func() { <-c.slots }()
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				defer cancel()
+				if _, err := c.lookup(ctx, key); err != nil && !errors.Is(err, ErrCircuitOpen) {
+					slog.Warn("company enrich lookup failed", "query", key, logging.Err(err))
+				}
+				c.mu.Lock()
+				delete(c.pending, key)
+				c.mu.Unlock()
+			}()
+		default:
+			// asking again once a lookup finishes looks it up
+		}
+	}
+	c.mu.Unlock()
+	return nil, nil
+}
+
+// getLocked returns the cached results for key, forgetting them once expired
+func (c *cachedEnricher) getLocked(key string) ([]EnrichedCompany, bool) {
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*cacheEntry)
+	if !c.now().Before(entry.expires) {
+		c.recent.Remove(element)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.recent.MoveToFront(element)
+	return entry.results, true
+}
+
+func (c *cachedEnricher) lookup(ctx context.Context, key string) ([]EnrichedCompany, error) {
+	results, err := c.next.Enrich(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	entry := &cacheEntry{key: key, results: results, expires: now.Add(c.ttl)}
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.recent.MoveToFront(element)
+		return results, nil
+	}
+	c.evictLocked(now)
+	c.entries[key] = c.recent.PushFront(entry)
+	return results, nil
+}
+
+// evictLocked makes room for one more entry when the cache is full, dropping
+// the expired entries and then the least recently used ones
+func (c *cachedEnricher) evictLocked(now time.Time) {
+	if len(c.entries) < c.maxEntries {
+		return
+	}
+	for element := c.recent.Front(); element != nil; {
+		next := element.Next()
+		if entry := element.Value.(*cacheEntry); !now.Before(entry.expires) {
+			c.recent.Remove(element)
+			delete(c.entries, entry.key)
+		}
+		element = next
+	}
+	for len(c.entries) >= c.maxEntries {
+		oldest := c.recent.Back()
+		c.recent.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
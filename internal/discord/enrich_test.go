@@ -0,0 +1,289 @@
+package discord
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/whotypes/leetbot/internal/data"
+	"github.com/whotypes/leetbot/internal/discord/discordtest"
+)
+
+// stubEnricher answers from a map and counts lookups
+type stubEnricher struct {
+	mu      sync.Mutex
+	results map[string][]EnrichedCompany
+	err     error
+	calls   int
+}
+
+func (s *stubEnricher) Enrich(_ context.Context, query string) ([]EnrichedCompany, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.results[query], nil
+}
+
+func (s *stubEnricher) callCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+func slugsOf(results []EnrichedCompany) []string {
+	var slugs []string
+	for _, r := range results {
+		slugs = append(slugs, r.Slug)
+	}
+	return slugs
+}
+
+func TestLocalEnricher(t *testing.T) {
+	e := NewLocalEnricher(data.Companies())
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"meta.com", []string{"facebook"}},
+		{"https://www.gomotive.com/careers", []string{"keeptruckin"}},
+		{"JPM", []string{"jpmorgan"}},
+		{"morgan chase", []string{"jpmorgan"}},
+		{"thetradedesk", []string{"the-trade-desk"}},
+		{"ge.com", []string{"ge-digital", "general-electric"}},
+		{"zzzz", nil},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		got, err := e.Enrich(context.Background(), tt.query)
+		if err != nil {
+			t.Fatalf("Enrich(%q) error = %v", tt.query, err)
+		}
+		if !slices.Equal(slugsOf(got), tt.want) {
+			t.Errorf("Enrich(%q) = %v, want %v", tt.query, slugsOf(got), tt.want)
+		}
+	}
+}
+
+func TestCompanyEnrichAPI(t *testing.T) {
+	server := discordtest.NewCompanyEnrich(t, "test-key")
+	server.Add("social network", discordtest.Company{Name: "Meta Platforms", Domain: "meta.com"})
+
+	got, err := NewCompanyEnrichAPI("test-key", server.URL).Enrich(context.Background(), "social network")
+	if err != nil {
+		t.Fatalf("Enrich() error = %v", err)
+	}
+	want := []EnrichedCompany{{Name: "Meta Platforms", Domain: "meta.com"}}
+	if !slices.Equal(got, want) {
+		t.Errorf("Enrich() = %+v, want %+v", got, want)
+	}
+
+	if _, err := NewCompanyEnrichAPI("wrong-key", server.URL).Enrich(context.Background(), "x"); err == nil {
+		t.Error("Enrich() with a bad key should fail")
+	}
+	server.FailWith(http.StatusServiceUnavailable)
+	if _, err := NewCompanyEnrichAPI("test-key", server.URL).Enrich(context.Background(), "x"); err == nil {
+		t.Error("Enrich() should fail when the API does")
+	}
+}
+
+func TestCachedEnricher(t *testing.T) {
+	stub := &stubEnricher{results: map[string][]EnrichedCompany{"acme": {{Name: "Acme"}}}}
+	cache := newCachedEnricher(stub, time.Minute, false)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	for range 3 {
+		got, err := cache.Enrich(context.Background(), "Acme ")
+		if err != nil || len(got) != 1 {
+			t.Fatalf("Enrich() = %v, %v", got, err)
+		}
+	}
+	if stub.callCount() != 1 {
+		t.Errorf("lookups = %d, want 1 while cached", stub.callCount())
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, err := cache.Enrich(context.Background(), "acme"); err != nil {
+		t.Fatal(err)
+	}
+	if stub.callCount() != 2 {
+		t.Errorf("lookups = %d, want 2 after the entry expired", stub.callCount())
+	}
+
+	// errors aren't cached
+	stub.err = errors.New("down")
+	now = now.Add(2 * time.Minute)
+	for range 2 {
+		if _, err := cache.Enrich(context.Background(), "acme"); err == nil {
+			t.Error("Enrich() should pass the error on")
+		}
+	}
+	if stub.callCount() != 4 {
+		t.Errorf("lookups = %d, want 4", stub.callCount())
+	}
+}
+
+func TestBackgroundEnricher(t *testing.T) {
+	server := discordtest.NewCompanyEnrich(t, "test-key")
+	server.Add("acme corp", discordtest.Company{Name: "Acme", Domain: "acme.com"})
+	cache := newCachedEnricher(NewCompanyEnrichAPI("test-key", server.URL), time.Hour, true)
+
+	// the first lookup never waits for the API
+	got, err := cache.Enrich(context.Background(), "acme corp")
+	if err != nil || len(got) != 0 {
+		t.Fatalf("first Enrich() = %v, %v, want nothing yet", got, err)
+	}
+	cache.lookups.Wait()
+
+	got, err = cache.Enrich(context.Background(), "acme corp")
+	if err != nil || len(got) != 1 || got[0].Name != "Acme" {
+		t.Errorf("Enrich() after the background lookup = %v, %v", got, err)
+	}
+	if requests := server.Requests(); len(requests) != 1 {
+		t.Errorf("API requests = %q, want one", requests)
+	}
+}
+
+func TestCachedEnricherEvictsLeastRecentlyUsed(t *testing.T) {
+	stub := &stubEnricher{results: map[string][]EnrichedCompany{}}
+	cache := newCachedEnricher(stub, time.Minute, false)
+	cache.maxEntries = 2
+
+	for _, query := range []string{"a", "b", "a", "c"} {
+		if _, err := cache.Enrich(context.Background(), query); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// "b" was used least recently, so "c" took its place
+	if len(cache.entries) != 2 || cache.entries["a"] == nil || cache.entries["c"] == nil {
+		t.Errorf("cached %d entries, want a and c", len(cache.entries))
+	}
+	if stub.callCount() != 3 {
+		t.Errorf("lookups = %d, want 3", stub.callCount())
+	}
+
+	// expired entries go before live ones
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	cache.lookup(context.Background(), "d")
+	now = now.Add(2 * time.Minute)
+	cache.lookup(context.Background(), "e")
+	cache.lookup(context.Background(), "f")
+	if len(cache.entries) != 2 || cache.entries["e"] == nil || cache.entries["f"] == nil {
+		t.Errorf("cached %d entries, want e and f", len(cache.entries))
+	}
+}
+
+// blockingEnricher holds every lookup until release is closed
+type blockingEnricher struct {
+	started chan string
+	release chan struct{}
+}
+
+func (b *blockingEnricher) Enrich(_ context.Context, query string) ([]EnrichedCompany, error) {
+	b.started <- query
+	<-b.release
+	return nil, nil
+}
+
+func TestBackgroundEnricherLimitsLookups(t *testing.T) {
+	blocking := &blockingEnricher{started: make(chan string, 10), release: make(chan struct{})}
+	cache := newCachedEnricher(blocking, time.Hour, true)
+	cache.slots = make(chan struct{}, 2)
+
+	for _, query := range []string{"a", "b", "c", "d"} {
+		if _, err := cache.Enrich(context.Background(), query); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-blocking.started
+	<-blocking.started
+	close(blocking.release)
+	cache.lookups.Wait()
+	if len(blocking.started) != 0 {
+		t.Errorf("%d more lookups ran, want 2 at once", len(blocking.started))
+	}
+	if len(cache.entries) != 2 || cache.entries["a"] == nil || cache.entries["b"] == nil {
+		t.Errorf("cached %d entries, want a and b", len(cache.entries))
+	}
+
+	// once a slot is free a skipped query is looked up
+	cache.Enrich(context.Background(), "c")
+	cache.lookups.Wait()
+	if cache.entries["c"] == nil {
+		t.Error("c wasn't looked up once the lookups finished")
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	stub := &stubEnricher{err: errors.New("down")}
+	breaker := NewCircuitBreaker(stub, 2, time.Minute).(*circuitBreaker)
+	now := time.Now()
+	breaker.now = func() time.Time { return now }
+
+	for range 2 {
+		if _, err := breaker.Enrich(context.Background(), "acme"); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("Enrich() error = %v, want the enricher's error", err)
+		}
+	}
+	if _, err := breaker.Enrich(context.Background(), "acme"); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Enrich() error = %v, want ErrCircuitOpen", err)
+	}
+	if stub.callCount() != 2 {
+		t.Errorf("lookups = %d, want 2 while the breaker is open", stub.callCount())
+	}
+
+	// after the cooldown one lookup is let through, and closes the breaker
+	now = now.Add(2 * time.Minute)
+	stub.err = nil
+	if _, err := breaker.Enrich(context.Background(), "acme"); err != nil {
+		t.Errorf("Enrich() after the cooldown error = %v", err)
+	}
+	if _, err := breaker.Enrich(context.Background(), "acme"); err != nil {
+		t.Errorf("Enrich() after recovering error = %v", err)
+	}
+	if stub.callCount() != 4 {
+		t.Errorf("lookups = %d, want 4", stub.callCount())
+	}
+}
+
+func TestFindCompanyWithSuggestionUsesEnricher(t *testing.T) {
+	problemsData := data.NewTestProblemsByCompany(map[string]map[string][]data.Problem{
+		"facebook": {"all": []data.Problem{{ID: 1, Title: "Test", Difficulty: "Easy", Frequency: 100.0}}},
+		"stripe":   {"all": []data.Problem{{ID: 2, Title: "Test", Difficulty: "Easy", Frequency: 100.0}}},
+	})
+	stub := &stubEnricher{results: map[string][]EnrichedCompany{
+		"payments processor": {{Name: "Stripe"}},
+	}}
+	SetEnricher(Enrichers(NewLocalEnricher(data.Companies()), stub))
+	t.Cleanup(func() { SetEnricher(nil) })
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"https://www.meta.com/careers", "facebook"},
+		{"payments processor", "stripe"},
+	}
+	for _, tt := range tests {
+		company, found, suggestions := findCompanyWithSuggestion(tt.input, problemsData)
+		if !found || company != tt.want {
+			t.Errorf("findCompanyWithSuggestion(%q) = %q, %v, %v, want %s", tt.input, company, found, suggestions, tt.want)
+		}
+	}
+
+	// a failing enricher only costs the suggestion
+	SetEnricher(&stubEnricher{err: ErrCircuitOpen})
+	if _, found, _ := findCompanyWithSuggestion("payments processor", problemsData); found {
+		t.Error("findCompanyWithSuggestion() found a company without the enricher")
+	}
+}
@@ -0,0 +1,129 @@
+package discord
+
+import (
+	"bytes"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/whotypes/leetbot/internal/data"
+	"github.com/whotypes/leetbot/internal/export"
+	"github.com/whotypes/leetbot/internal/i18n"
+)
+
+// exportFormatChoices are offered as options for the /export command
+var exportFormatChoices = []*discordgo.ApplicationCommandOptionChoice{
+	{Name: "CSV", Value: string(export.FormatCSV)},
+	{Name: "JSON", Value: string(export.FormatJSON)},
+	localizedChoice("export.format.markdown", string(export.FormatMarkdown)),
+	localizedChoice("export.format.anki", string(export.FormatAnki)),
+}
+
+// exportMinLimit is the smallest accepted value for the /export limit option
+var exportMinLimit = 1.0
+
+// buildExportFile renders problems into a discord attachment
+func buildExportFile(tr *i18n.Printer, company, timeframe string, format export.Format, problems []data.Problem) (*discordgo.File, error) {
+	list := export.List{
+		Company:   company,
+		Timeframe: timeframe,
+		Title:     tr.T("problems.title", formatCompanyName(company), formatTimeframeDisplay(tr, timeframe)),
+		Problems:  problems,
+	}
+
+	var buf bytes.Buffer
+	if err := export.Render(&buf, format, list); err != nil {
+		return nil, err
+	}
+
+	return &discordgo.File{
+		Name:        export.Filename(list, format),
+		ContentType: format.ContentType(),
+		Reader:      &buf,
+	}, nil
+}
+
+func (h *Handler) handleExportSlash(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	tr := interactionPrinter(i.Interaction)
+	options := i.ApplicationCommandData().Options
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
+	for _, opt := range options {
+		optionMap[opt.Name] = opt
+	}
+
+	respondEphemeral := func(content string) {
+		err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: content,
+				Flags:   discordgo.MessageFlagsEphemeral | discordgo.MessageFlagsSuppressEmbeds,
+			},
+		})
+		if err != nil {
+			interactionFailed(i.Interaction, "responding to interaction failed", err)
+		}
+	}
+
+	companyOpt, ok := optionMap["company"]
+	if !ok {
+		respondEphemeral(tr.T("error.company_required"))
+		return
+	}
+
+	company, found, suggestions := findCompanyWithSuggestion(cleanCompanyInput(companyOpt.StringValue()), h.problemsData)
+	if !found {
+		respondEphemeral(formatCompanyNotFound(tr, companyOpt.StringValue(), suggestions))
+		return
+	}
+	companyQueries.Inc(company)
+
+	format := export.FormatCSV
+	if formatOpt, ok := optionMap["format"]; ok {
+		parsed, err := export.ParseFormat(formatOpt.StringValue())
+		if err != nil {
+			respondEphemeral(tr.T("export.unknown_format", formatOpt.StringValue()))
+			return
+		}
+		format = parsed
+	}
+
+	var problems []data.Problem
+	var timeframe string
+	if timeframeOpt, ok := optionMap["timeframe"]; ok {
+		timeframe = timeframeOpt.StringValue()
+		problems = h.problemsData.GetProblems(company, timeframe)
+	} else {
+		problems, timeframe = h.problemsData.GetProblemsWithPriority(company)
+	}
+
+	var filter data.Filter
+	if difficultyOpt, ok := optionMap["difficulty"]; ok {
+		filter.Difficulties = data.ParseDifficulties(difficultyOpt.StringValue())
+	}
+	if limitOpt, ok := optionMap["limit"]; ok {
+		filter.Limit = int(limitOpt.IntValue())
+	}
+	problems = filter.Apply(problems)
+
+	if len(problems) == 0 {
+		respondEphemeral(tr.T("problems.not_found", formatCompanyName(company), formatTimeframeDisplay(tr, timeframe)))
+		return
+	}
+
+	file, err := buildExportFile(tr, company, timeframe, format, problems)
+	if err != nil {
+		interactionFailed(i.Interaction, "rendering export failed", err)
+		respondEphemeral(tr.T("export.failed"))
+		return
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: tr.T("export.done", len(problems), formatCompanyName(company), formatTimeframeDisplay(tr, timeframe)),
+			Files:   []*discordgo.File{file},
+			Flags:   discordgo.MessageFlagsSuppressEmbeds,
+		},
+	})
+	if err != nil {
+		interactionFailed(i.Interaction, "responding to interaction failed", err)
+	}
+}
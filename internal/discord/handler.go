@@ -1,97 +1,25 @@
 package discord
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
+	"log/slog"
+	"maps"
 	"os"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/lithammer/fuzzysearch/fuzzy"
+	"github.com/whotypes/leetbot/internal/config"
 	"github.com/whotypes/leetbot/internal/data"
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
+	"github.com/whotypes/leetbot/internal/i18n"
+	"github.com/whotypes/leetbot/internal/logging"
+	"github.com/whotypes/leetbot/internal/ratelimit"
 )
 
-// CompanyEnrichResponse represents the response from the Company Enrich API
-type CompanyEnrichResponse struct {
-	Items      []CompanyEnrichItem `json:"items"`
-	Page       int                 `json:"page"`
-	TotalPages int                 `json:"totalPages"`
-	TotalItems int                 `json:"totalItems"`
-}
-
-// CompanyEnrichItem represents a single company from the API response
-type CompanyEnrichItem struct {
-	ID     string  `json:"id"`
-	Name   *string `json:"name"`
-	Domain *string `json:"domain"`
-}
-
-// searchCompanyEnrichAPI calls the Company Enrich API to find companies
-func searchCompanyEnrichAPI(query string) ([]CompanyEnrichItem, error) {
-	apiKey := os.Getenv("COMPANY_ENRICH_API_KEY")
-	if apiKey == "" {
-		return nil, fmt.Errorf("COMPANY_ENRICH_API_KEY not set")
-	}
-
-	url := "https://api.companyenrich.com/companies/search"
-
-	// create the request payload
-	payload := map[string]string{
-		"semanticQuery": query,
-		"query":         query,
-	}
-	payloadBytes, err := json.Marshal(payload)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal payload: %w", err)
-	}
-
-	// create the HTTP request
-	req, err := http.NewRequest("POST", url, bytes.NewReader(payloadBytes))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Add("accept", "application/json")
-	req.Header.Add("content-type", "application/json")
-	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", apiKey))
-
-	// send the request
-	client := &http.Client{
-		Timeout: 5 * time.Second, // add timeout to prevent hanging
-	}
-	res, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
-	}
-	defer res.Body.Close()
-
-	// check response status
-	if res.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(res.Body)
-		return nil, fmt.Errorf("API returned non-200 status: %d, body: %s", res.StatusCode, string(body))
-	}
-
-	// read and parse response
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	var response CompanyEnrichResponse
-	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
-	}
-
-	return response.Items, nil
-}
-
 // levenshteinDistance calculates the edit distance between two strings
 func levenshteinDistance(s1, s2 string) int {
 	s1Lower := strings.ToLower(s1)
@@ -173,24 +101,18 @@ func calculateMatchConfidence(input, target string) float64 {
 	return confidence
 }
 
-// companyAliases maps alternative names to canonical company slugs
-var companyAliases = map[string]string{
-	"meta":     "facebook",
-	"fb":       "facebook",
-	"alphabet": "google",
-	"amzn":     "amazon",
-	"msft":     "microsoft",
-	"aapl":     "apple",
-	"nflx":     "netflix",
-}
-
-// getCompanyAlias checks if the input matches a known alias
+// getCompanyAlias checks if the input is another name for a company, from
+// bot.company_aliases or data/companies.json
 func getCompanyAlias(input string) (string, bool) {
 	normalized := strings.ToLower(strings.TrimSpace(input))
 	normalized = strings.ReplaceAll(normalized, " ", "-")
-	if alias, ok := companyAliases[normalized]; ok {
+	if alias, ok := currentSettings().CompanyAliases[normalized]; ok {
 		return alias, true
 	}
+	// a company's own slug isn't an alias
+	if slug, ok := data.Companies().Resolve(normalized); ok && slug != normalized {
+		return slug, true
+	}
 	return "", false
 }
 
@@ -198,14 +120,31 @@ func getCompanyAlias(input string) (string, bool) {
 // we use this to dispatch slash commands to the appropriate handler
 var SlashCommandHandlers = map[string]string{
 	"problems": "problems",
+	"company":  "company",
+	"export":   "export",
+	"dataset":  "dataset",
+	"search":   "search",
 	"help":     "help",
 }
 
 func HandleAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate, problemsData *data.ProblemsByCompany) {
+	// autocomplete failures are counted but don't make a command outcome
+	defer failures.Delete(i.ID)
+
 	data := i.ApplicationCommandData()
 
-	// handle autocomplete for commands that use company autocomplete
-	if data.Name != "problems" {
+	// handle autocomplete for commands that use company or problem autocomplete
+	var focusedOption string
+	getChoices := getCompanyAutocompleteChoices
+	switch data.Name {
+	case "problems", "export":
+		focusedOption = "company"
+	case "company":
+		focusedOption = "name"
+	case "search":
+		focusedOption = "query"
+		getChoices = getSearchAutocompleteChoices
+	default:
 		return
 	}
 
@@ -213,9 +152,9 @@ func HandleAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate, pr
 	var currentInput string
 
 	for _, option := range data.Options {
-		if option.Name == "company" && option.Focused {
+		if option.Name == focusedOption && option.Focused {
 			currentInput = option.StringValue()
-			choices = getCompanyAutocompleteChoices(currentInput, problemsData)
+			choices = getChoices(currentInput, problemsData)
 			break
 		}
 	}
@@ -227,67 +166,145 @@ func HandleAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate, pr
 		},
 	})
 	if err != nil {
-		fmt.Printf("Error responding to autocomplete: %v\n", err)
+		interactionFailed(i.Interaction, "responding to autocomplete failed", err)
 	}
 }
 
+// timeframeChoices are the timeframe options shared by slash commands
+var timeframeChoices = []*discordgo.ApplicationCommandOptionChoice{
+	localizedChoice("timeframe.heading.all", "all"),
+	localizedChoice("timeframe.heading.thirty-days", "thirty-days"),
+	localizedChoice("timeframe.heading.three-months", "three-months"),
+	localizedChoice("timeframe.heading.six-months", "six-months"),
+	localizedChoice("timeframe.heading.more-than-six-months", "more-than-six-months"),
+}
+
+// GetSlashCommands returns the slash commands, described in every supported
+// locale
 func GetSlashCommands(problemsData *data.ProblemsByCompany) []*discordgo.ApplicationCommand {
-	return []*discordgo.ApplicationCommand{
+	commands := []*discordgo.ApplicationCommand{
 		{
-			Name:        "problems",
-			Description: "Show popular coding interview problems by company",
+			Name: "problems",
 			Options: []*discordgo.ApplicationCommandOption{
 				{
 					Type:         discordgo.ApplicationCommandOptionString,
 					Name:         "company",
-					Description:  "Company name (start typing to search)",
 					Required:     true,
 					Autocomplete: true,
 				},
 				{
-					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        "timeframe",
-					Description: "Time period (optional)",
-					Required:    false,
+					Type:     discordgo.ApplicationCommandOptionString,
+					Name:     "timeframe",
+					Required: false,
+					Choices:  timeframeChoices,
+				},
+			},
+		},
+		{
+			Name: "company",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "name",
+					Required:     true,
+					Autocomplete: true,
+				},
+			},
+		},
+		{
+			Name: "export",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "company",
+					Required:     true,
+					Autocomplete: true,
+				},
+				{
+					Type:     discordgo.ApplicationCommandOptionString,
+					Name:     "format",
+					Required: false,
+					Choices:  exportFormatChoices,
+				},
+				{
+					Type:     discordgo.ApplicationCommandOptionString,
+					Name:     "timeframe",
+					Required: false,
+					Choices:  timeframeChoices,
+				},
+				{
+					Type:     discordgo.ApplicationCommandOptionString,
+					Name:     "difficulty",
+					Required: false,
 					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						localizedChoice("difficulty.easy", "easy"),
+						localizedChoice("difficulty.medium", "medium"),
+						localizedChoice("difficulty.hard", "hard"),
+					},
+				},
+				{
+					Type:     discordgo.ApplicationCommandOptionInteger,
+					Name:     "limit",
+					Required: false,
+					MinValue: &exportMinLimit,
+				},
+			},
+		},
+		{
+			Name: "search",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "query",
+					Required:     true,
+					Autocomplete: true,
+				},
+			},
+		},
+		{
+			Name: "dataset",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type: discordgo.ApplicationCommandOptionSubCommand,
+					Name: "changelog",
+					Options: []*discordgo.ApplicationCommandOption{
 						{
-							Name:  "All Time",
-							Value: "all",
-						},
-						{
-							Name:  "Last 30 Days",
-							Value: "thirty-days",
-						},
-						{
-							Name:  "Last 3 Months",
-							Value: "three-months",
-						},
-						{
-							Name:  "Last 6 Months",
-							Value: "six-months",
-						},
-						{
-							Name:  "More than 6 Months",
-							Value: "more-than-six-months",
+							Type:     discordgo.ApplicationCommandOptionNumber,
+							Name:     "threshold",
+							Required: false,
 						},
 					},
 				},
 			},
 		},
 		{
-			Name:        "help",
-			Description: "Show available Leetbot commands and usage",
+			Name: "help",
 		},
 	}
+	for _, cmd := range commands {
+		localizeCommand(cmd)
+	}
+	return commands
 }
 
-func formatCompanyName(company string) string {
-	words := strings.Split(company, "-")
-	caser := cases.Title(language.English)
-	for i, word := range words {
-		words[i] = caser.String(word)
+// formatCompanyNotFound tells the user no company matches input, listing
+// the companies they might have meant
+func formatCompanyNotFound(tr *i18n.Printer, input string, suggestions []string) string {
+	var message strings.Builder
+	message.WriteString(tr.T("company.not_found", input))
+	if len(suggestions) > 0 {
+		message.WriteString("\n\n" + tr.T("company.did_you_mean"))
+		for _, suggestion := range suggestions {
+			message.WriteString("\n• " + formatCompanyName(suggestion))
+		}
 	}
-	return strings.Join(words, " ")
+	return message.String()
+}
+
+// formatCompanyName returns the name data/companies.json gives the company,
+// or its title cased slug
+func formatCompanyName(company string) string {
+	return data.Companies().DisplayName(company)
 }
 
 func getDifficultyIndicator(difficulty string) string {
@@ -303,6 +320,23 @@ func getDifficultyIndicator(difficulty string) string {
 	}
 }
 
+// formatDifficulty translates a difficulty, leaving ones it doesn't know as
+// they are
+func formatDifficulty(tr *i18n.Printer, difficulty string) string {
+	if key := "difficulty." + strings.ToLower(difficulty); i18n.Has(key) {
+		return tr.T(key)
+	}
+	return difficulty
+}
+
+// getSourceLabel names the overlay a problem came from, empty for the public dataset
+func getSourceLabel(source string) string {
+	if source == "" || source == data.DefaultSourceName {
+		return ""
+	}
+	return fmt.Sprintf(" `%s`", source)
+}
+
 func findCompanyByFuzzySearch(input string, problemsData *data.ProblemsByCompany) (string, bool) {
 	if input == "" {
 		return "", false
@@ -384,6 +418,13 @@ func findBestCompanyMatch(input string, companies []string) (string, float64) {
 	return bestMatch, bestConfidence
 }
 
+// ResolveCompany maps user input to a company exactly like the bot commands do,
+// cleaning job words and applying aliases and fuzzy matching. When nothing
+// matches confidently it returns up to three suggestions instead.
+func ResolveCompany(input string, problemsData *data.ProblemsByCompany) (company string, found bool, suggestions []string) {
+	return findCompanyWithSuggestion(cleanCompanyInput(input), problemsData)
+}
+
 // findCompanyWithSuggestion attempts to find a company by fuzzy search and returns suggestions if not found
 // uses confidence thresholds:
 // - confidence > 0.8 or distance <= 2: auto-correct
@@ -525,18 +566,33 @@ func findCompanyWithSuggestion(input string, problemsData *data.ProblemsByCompan
 			return "", false, suggestions
 		}
 
-		// low confidence: try the company enrich API as a fallback
-		apiResults, err := searchCompanyEnrichAPI(input)
+		// low confidence: ask the enricher, which knows domains, tickers and
+		// other names, and maybe the Company Enrich API
+		apiResults, err := enrichCompany(input)
 		if err == nil && len(apiResults) > 0 {
+			// results the enricher knows the slug of need no matching, one
+			// is taken as the answer and several are suggested
+			var known []string
+			for _, item := range apiResults {
+				if item.Slug != "" && problemsData.CompanyExists(item.Slug) && !slices.Contains(known, item.Slug) {
+					known = append(known, item.Slug)
+				}
+			}
+			if len(known) == 1 {
+				return known[0], true, nil
+			} else if len(known) > 1 {
+				return "", false, known[:min(len(known), 3)]
+			}
+
 			// we got API results, now combine them with our internal companies
 			// and re-run matching on the combined list
 			var combinedCandidates []string
 
 			// add API results (company names) to candidates
 			for _, item := range apiResults {
-				if item.Name != nil && *item.Name != "" {
+				if item.Name != "" {
 					// normalize the API company name for matching
-					apiCompanyName := strings.ToLower(*item.Name)
+					apiCompanyName := strings.ToLower(item.Name)
 					apiCompanyName = strings.ReplaceAll(apiCompanyName, " ", "-")
 					apiCompanyName = strings.TrimSpace(apiCompanyName)
 					combinedCandidates = append(combinedCandidates, apiCompanyName)
@@ -611,7 +667,7 @@ func findCompanyWithSuggestion(input string, problemsData *data.ProblemsByCompan
 }
 
 // validCommands lists all valid Leetbot commands
-var validCommands = []string{"problems", "help", "shutdown", "startup", "init"}
+var validCommands = []string{"problems", "help", "shutdown", "startup", "init", "reload"}
 
 // findCommandWithSuggestion attempts to match a command and returns suggestions if it's a typo
 // returns: (correctCommand, isValidCommand, didYouMeanSuggestion)
@@ -728,10 +784,7 @@ func getCompanyAutocompleteChoices(input string, problemsData *data.ProblemsByCo
 	var choices []*discordgo.ApplicationCommandOptionChoice
 
 	if input == "" {
-		popularCompanies := []string{
-			"amazon", "google", "facebook", "microsoft", "apple", "netflix",
-			"uber", "meta", "tesla", "nvidia", "openai", "anthropic",
-		}
+		popularCompanies := currentSettings().PopularCompanies
 		for _, company := range popularCompanies {
 			if problemsData.CompanyExists(company) {
 				choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
@@ -775,31 +828,49 @@ func getCompanyAutocompleteChoices(input string, problemsData *data.ProblemsByCo
 	normalizedInput := strings.ToLower(input)
 	normalizedInput = strings.ReplaceAll(normalizedInput, " ", "-")
 	type companyMatch struct {
-		slug        string
-		displayName string
+		slug string
+		name string
 	}
+	// companies in data/companies.json are found by their aliases, tickers
+	// and former names as well as their display name
 	var companyList []companyMatch
 	for _, company := range companies {
-		companyList = append(companyList, companyMatch{
-			slug:        company,
-			displayName: formatCompanyName(company),
-		})
+		names := data.Companies().Names(company)
+		if names == nil {
+			names = []string{formatCompanyName(company)}
+		}
+		for _, name := range names {
+			companyList = append(companyList, companyMatch{slug: company, name: name})
+		}
 	}
 
-	var displayNames []string
+	var names []string
 	for _, cm := range companyList {
-		displayNames = append(displayNames, cm.displayName)
+		names = append(names, cm.name)
 	}
-	matches := fuzzy.RankFindNormalizedFold(input, displayNames)
+	matches := fuzzy.RankFindNormalizedFold(input, names)
 
-	for i, match := range matches {
-		if i >= 25 {
+	// an exact alias like fb comes first
+	seen := make(map[string]bool)
+	if alias, ok := getCompanyAlias(input); ok && problemsData.CompanyExists(alias) {
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  formatCompanyName(alias),
+			Value: alias,
+		})
+		seen[alias] = true
+	}
+	for _, match := range matches {
+		if len(choices) >= 25 {
 			break
 		}
 		if match.OriginalIndex >= 0 && match.OriginalIndex < len(companyList) {
 			cm := companyList[match.OriginalIndex]
+			if seen[cm.slug] {
+				continue
+			}
+			seen[cm.slug] = true
 			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
-				Name:  cm.displayName,
+				Name:  formatCompanyName(cm.slug),
 				Value: cm.slug,
 			})
 		}
@@ -825,46 +896,83 @@ type RestartRequest struct {
 	ChannelID string
 	Success   bool
 	Message   string
+	// Printer is the language of the messages sent once the restart is done
+	Printer *i18n.Printer
 }
 
 type Handler struct {
-	problemsData     *data.ProblemsByCompany
-	prefix           string
-	reconnectChan    chan RestartRequest
-	disabled         bool
-	session          *discordgo.Session
-	sessionMutex     sync.RWMutex
-	enabledChannels  map[string]bool // tracks which channels leetbot is enabled in
-	channelsMutex    sync.RWMutex    // protects enabledChannels map
+	problemsData  *data.ProblemsByCompany
+	previousData  *data.ProblemsByCompany // snapshot compared against by /dataset changelog
+	reconnectChan chan RestartRequest
+	reloadConfig  ConfigReloader
+	disabled      bool
+	session       *discordgo.Session
+	sessionMutex  sync.RWMutex
+	// state holds what a config reload or !init changes. It is only ever
+	// replaced, under stateMutex, so a command sees one consistent version.
+	state      atomic.Pointer[handlerState]
+	stateMutex sync.Mutex
+	limiter    *commandLimiter
 }
 
-const (
-	// admin user ID (nyumat)
-	adminUserID = "700444827287945316"
-)
-
-// pre-initialized channels for the specific server and test channel
-var preInitializedChannels = []string{
-	"947389742859812884",  // production channel 1
-	"1395661511950729308", // production channel 2
-	"1242309460689424504", // production channel 3
-	"971974276859170886",  // production channel 4
-	"905854653571420190",  // production channel 5
-	"1431649138084155403",          // test channel
+// handlerState is the prefix, settings, enabled channels and rate limits a
+// command is handled with. It must not be modified once stored.
+type handlerState struct {
+	prefix   string
+	settings *config.BotConfig
+	channels map[string]bool // channels leetbot is enabled in
+	limits   *commandLimits
 }
 
 func NewHandler(problemsData *data.ProblemsByCompany, prefix string) *Handler {
-	// initialize the enabled channels map with pre-initialized channels
-	enabledChannels := make(map[string]bool)
-	for _, channelID := range preInitializedChannels {
-		enabledChannels[channelID] = true
-	}
+	settings := currentSettings()
+	// initialize the enabled channels with the configured channels
+	channels := make(map[string]bool)
+	for _, channelID := range settings.Channels {
+		channels[channelID] = true
+	}
+
+	h := &Handler{
+		problemsData: problemsData,
+		limiter:      newCommandLimiter(),
+	}
+	h.state.Store(&handlerState{
+		prefix:   prefix,
+		settings: settings,
+		channels: channels,
+		limits:   newCommandLimits(ratelimit.DefaultCommandLimits()),
+	})
+	return h
+}
 
-	return &Handler{
-		problemsData:    problemsData,
-		prefix:          prefix,
-		enabledChannels: enabledChannels,
-	}
+// currentState returns the state to handle a command with
+func (h *Handler) currentState() *handlerState {
+	return h.state.Load()
+}
+
+// updateState stores a copy of the state changed by update. The channels
+// map is copied too, so update may modify it.
+func (h *Handler) updateState(update func(state *handlerState)) {
+	h.stateMutex.Lock()
+	defer h.stateMutex.Unlock()
+
+	next := *h.state.Load()
+	next.channels = maps.Clone(next.channels)
+	update(&next)
+	h.state.Store(&next)
+}
+
+// SetRateLimits replaces the command rate limits, resetting every bucket
+func (h *Handler) SetRateLimits(limits ratelimit.CommandLimits) {
+	fresh := newCommandLimits(limits)
+	h.updateState(func(state *handlerState) {
+		state.limits = fresh
+	})
+}
+
+// currentPrefix returns the prefix of text commands
+func (h *Handler) currentPrefix() string {
+	return h.currentState().prefix
 }
 
 func (h *Handler) SetReconnectChannel(ch chan RestartRequest) {
@@ -885,33 +993,45 @@ func (h *Handler) GetSession() *discordgo.Session {
 
 // isChannelEnabled checks if leetbot is enabled in the given channel
 func (h *Handler) isChannelEnabled(channelID string) bool {
-	h.channelsMutex.RLock()
-	defer h.channelsMutex.RUnlock()
-	return h.enabledChannels[channelID]
+	return h.currentState().channels[channelID]
 }
 
 // enableChannel enables leetbot in the given channel
 func (h *Handler) enableChannel(channelID string) {
-	h.channelsMutex.Lock()
-	defer h.channelsMutex.Unlock()
-	h.enabledChannels[channelID] = true
+	h.updateState(func(state *handlerState) {
+		state.channels[channelID] = true
+	})
+}
+
+// EnableChannel enables leetbot in a channel without going through !init,
+// for harnesses like the demo REPL
+func (h *Handler) EnableChannel(channelID string) {
+	h.enableChannel(channelID)
 }
 
 // disableChannel disables leetbot in the given channel
 func (h *Handler) disableChannel(channelID string) {
-	h.channelsMutex.Lock()
-	defer h.channelsMutex.Unlock()
-	delete(h.enabledChannels, channelID)
+	h.updateState(func(state *handlerState) {
+		delete(state.channels, channelID)
+	})
+}
+
+// AdminUserID returns the ID of the user allowed to run admin commands,
+// empty when there's none
+func AdminUserID() string {
+	return currentSettings().AdminID
 }
 
-// isAdmin checks if the user is the admin (nyumat)
+// isAdmin checks if the user is the configured admin
 func isAdmin(userID string) bool {
-	return userID == adminUserID
+	adminID := currentSettings().AdminID
+	return adminID != "" && userID == adminID
 }
 
 // HandleSlashCommand routes slash commands to appropriate handlers
 func (h *Handler) HandleSlashCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	commandName := i.ApplicationCommandData().Name
+	state := h.currentState()
 
 	// if bot is disabled, only allow help command
 	if h.disabled && commandName != "help" {
@@ -919,21 +1039,59 @@ func (h *Handler) HandleSlashCommand(s *discordgo.Session, i *discordgo.Interact
 		return
 	}
 
+	// global commands can't be hidden from a single guild
+	if commandDisabled(state.settings, i.GuildID, commandName) {
+		// a refused command has no outcome, so its failure isn't kept for one
+		defer failures.Delete(i.ID)
+		err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: interactionPrinter(i.Interaction).T("error.command_disabled"),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		if err != nil {
+			interactionFailed(i.Interaction, "responding to interaction failed", err)
+		}
+		return
+	}
+
+	key, ok := h.admitSlash(s, i, state)
+	if !ok {
+		return
+	}
+	defer h.limiter.done(key)
+
+	start := time.Now()
+	defer func() {
+		commandsTotal.Inc(commandName, "slash", takeOutcome(i.ID))
+		commandDuration.ObserveDuration(time.Since(start), commandName)
+		interactionLogger(i.Interaction).Info("slash command handled", logging.Latency(start))
+	}()
+
 	switch commandName {
 	case "problems":
 		h.handleProblemsSlash(s, i)
+	case "company":
+		h.handleCompanySlash(s, i)
+	case "export":
+		h.handleExportSlash(s, i)
+	case "dataset":
+		h.handleDatasetSlash(s, i)
+	case "search":
+		h.handleSearchSlash(s, i)
 	case "help":
 		h.handleHelpSlash(s, i)
 	default:
 		err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: fmt.Sprintf("Unknown command: %s", commandName),
+				Content: interactionPrinter(i.Interaction).T("error.unknown_slash_command", commandName),
 				Flags:   discordgo.MessageFlagsEphemeral,
 			},
 		})
 		if err != nil {
-			fmt.Printf("Error responding to interaction: %v\n", err)
+			interactionFailed(i.Interaction, "responding to interaction failed", err)
 		}
 	}
 }
@@ -949,10 +1107,12 @@ func (h *Handler) HandleMessage(s *discordgo.Session, m *discordgo.MessageCreate
 		return
 	}
 
-	if !strings.HasPrefix(m.Content, h.prefix) {
+	state := h.currentState()
+	prefix := state.prefix
+	if !strings.HasPrefix(m.Content, prefix) {
 		return
 	}
-	content := strings.TrimPrefix(m.Content, h.prefix)
+	content := strings.TrimPrefix(m.Content, prefix)
 	content = strings.TrimSpace(content)
 
 	if content == "" {
@@ -1002,7 +1162,7 @@ func (h *Handler) HandleMessage(s *discordgo.Session, m *discordgo.MessageCreate
 		if suggestion != "" {
 			// we have a suggestion - reconstruct the command with args
 			var exampleCommand strings.Builder
-			exampleCommand.WriteString(h.prefix)
+			exampleCommand.WriteString(prefix)
 			exampleCommand.WriteString(suggestion)
 			if len(args) > 0 {
 				exampleCommand.WriteString(" ")
@@ -1010,12 +1170,10 @@ func (h *Handler) HandleMessage(s *discordgo.Session, m *discordgo.MessageCreate
 			}
 
 			h.sendErrorMessage(s, m.ChannelID,
-				fmt.Sprintf("Unknown command '%s%s'. Did you mean `%s`?",
-					h.prefix, command, exampleCommand.String()))
+				messagePrinter(s, m).T("error.unknown_command_suggestion", prefix+command, exampleCommand.String()))
 		} else {
 			h.sendErrorMessage(s, m.ChannelID,
-				fmt.Sprintf("Unknown command '%s'. Use `%shelp` for available commands.",
-					command, h.prefix))
+				messagePrinter(s, m).T("error.unknown_command", command, prefix))
 		}
 		return
 	}
@@ -1025,20 +1183,32 @@ func (h *Handler) HandleMessage(s *discordgo.Session, m *discordgo.MessageCreate
 
 	// check if channel is enabled (init and help are always allowed)
 	if command != "init" && command != "help" {
-		if !h.isChannelEnabled(m.ChannelID) {
+		if !state.channels[m.ChannelID] {
 			// silently ignore commands in non-initialized channels
 			return
 		}
 	}
 
-	// check if Leetbot is disabled (but allow shutdown, startup, help, and init commands)
+	// check if Leetbot is disabled (but allow shutdown, startup, reload, help, and init commands)
 	if h.disabled {
-		// only allow shutdown, startup, help, and init commands when disabled
-		if command != "shutdown" && command != "startup" && command != "help" && command != "init" {
+		// only allow shutdown, startup, reload, help, and init commands when disabled
+		if command != "shutdown" && command != "startup" && command != "reload" && command != "help" && command != "init" {
 			return // silently ignore all other commands
 		}
 	}
 
+	key, ok := h.admitMessage(s, m, command, state)
+	if !ok {
+		return
+	}
+	defer h.limiter.done(key)
+
+	start := time.Now()
+	defer func() {
+		commandsTotal.Inc(command, "text", takeOutcome(m.ID))
+		messageLogger(m, command).Info("text command handled", logging.Latency(start))
+	}()
+
 	switch command {
 	case "problems":
 		h.handleProblemsCommand(s, m, args)
@@ -1050,14 +1220,17 @@ func (h *Handler) HandleMessage(s *discordgo.Session, m *discordgo.MessageCreate
 		h.handleStartupMessage(s, m, args)
 	case "init":
 		h.handleInitCommand(s, m, args)
+	case "reload":
+		h.handleReloadMessage(s, m, args)
 	default:
-		h.sendErrorMessage(s, m.ChannelID, fmt.Sprintf("Unknown command '%s'. Use `%shelp` for available commands.", command, h.prefix))
+		h.sendErrorMessage(s, m.ChannelID, messagePrinter(s, m).T("error.unknown_command", command, prefix))
 	}
 }
 
 func (h *Handler) handleProblemsCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	tr := messagePrinter(s, m)
 	if len(args) == 0 {
-		h.sendErrorMessage(s, m.ChannelID, "Please specify a company. Usage: !problems <company> [timeframe]")
+		h.sendErrorMessage(s, m.ChannelID, tr.T("problems.usage"))
 		return
 	}
 
@@ -1070,17 +1243,10 @@ func (h *Handler) handleProblemsCommand(s *discordgo.Session, m *discordgo.Messa
 	// use enhanced fuzzy matching with suggestions
 	company, companyFound, companySuggestions := findCompanyWithSuggestion(cleanedCompanyInput, h.problemsData)
 	if !companyFound {
-		var errorMsg strings.Builder
-		errorMsg.WriteString(fmt.Sprintf("Could not find company matching '%s'.", cleanedCompanyInput))
-		if len(companySuggestions) > 0 {
-			errorMsg.WriteString("\n\nDid you mean:")
-			for _, suggestion := range companySuggestions {
-				errorMsg.WriteString(fmt.Sprintf("\n• %s", formatCompanyName(suggestion)))
-			}
-		}
-		h.sendErrorMessage(s, m.ChannelID, errorMsg.String())
+		h.sendErrorMessage(s, m.ChannelID, formatCompanyNotFound(tr, cleanedCompanyInput, companySuggestions))
 		return
 	}
+	companyQueries.Inc(company)
 
 	var problems []data.Problem
 	var timeframe string
@@ -1099,28 +1265,20 @@ func (h *Handler) handleProblemsCommand(s *discordgo.Session, m *discordgo.Messa
 		availableTimeframes := h.problemsData.GetAvailableTimeframes(company)
 		if len(availableTimeframes) > 0 && timeframeArg != "" {
 
-			suggestion := h.formatAvailableTimeframesSuggestion(company, timeframe, availableTimeframes)
+			suggestion := h.formatAvailableTimeframesSuggestion(tr, company, timeframe, availableTimeframes)
 			h.sendMessage(s, m.ChannelID, suggestion)
 		} else {
 
-			h.sendMessage(s, m.ChannelID, fmt.Sprintf("No data found for company '%s'", formatCompanyName(company)))
+			h.sendMessage(s, m.ChannelID, tr.T("problems.no_data_company", formatCompanyName(company)))
 		}
 		return
 	}
 
-	if shouldUsePagination(len(problems)) {
-		err := sendPaginatedProblemsMessage(s, m.ChannelID, company, timeframe, problems)
-		if err != nil {
-			fmt.Printf("Error sending paginated message: %v\n", err)
-
-			response := h.formatProblemsResponse(company, timeframe, problems)
-			h.sendMessage(s, m.ChannelID, response)
-		}
-		return
+	list := h.newProblemList(tr, company, timeframe, problems)
+	if err := h.sendProblems(s, m.ChannelID, list); err != nil {
+		messageFailed(m, "problems", "sending problems failed", err)
+		h.sendMessage(s, m.ChannelID, list.text())
 	}
-
-	response := h.formatProblemsResponse(company, timeframe, problems)
-	h.sendMessage(s, m.ChannelID, response)
 }
 
 func (h *Handler) isTimeframeKeyword(s string) bool {
@@ -1166,57 +1324,10 @@ func (h *Handler) NormalizeTimeframe(timeframe string) string {
 	}
 }
 
-func (h *Handler) formatProblemsResponse(company, timeframe string, problems []data.Problem) string {
-	if len(problems) == 0 {
-		return fmt.Sprintf("No problems found for %s (%s)", formatCompanyName(company), h.formatTimeframeDisplay(timeframe))
-	}
-
-	displayTimeframe := h.formatTimeframeDisplay(timeframe)
-
-	title := fmt.Sprintf("Most Popular Problems for %s (%s):", formatCompanyName(company), displayTimeframe)
-
+func (h *Handler) formatAvailableTimeframesSuggestion(tr *i18n.Printer, company, requestedTimeframe string, availableTimeframes []string) string {
 	var message strings.Builder
-	message.WriteString(title + "\n")
-	maxProblems := 20
-	if len(problems) < maxProblems {
-		maxProblems = len(problems)
-	}
-
-	for i := 0; i < maxProblems; i++ {
-		problem := problems[i]
-		difficultyIndicator := getDifficultyIndicator(problem.Difficulty)
-		problemLine := fmt.Sprintf("%s %s (%.0f%%): %s\n",
-			difficultyIndicator, problem.Title, problem.Frequency, problem.URL)
-		message.WriteString(problemLine)
-	}
-
-	return message.String()
-}
-
-func (h *Handler) formatTimeframeDisplay(timeframe string) string {
-	switch timeframe {
-	case "all":
-		return "all"
-	case "thirty-days":
-		return "last 30 days"
-	case "three-months":
-		return "last 3 months"
-	case "six-months":
-		return "last 6 months"
-	case "more-than-six-months":
-		return "more than 6 months"
-	default:
-		return strings.ToLower(strings.ReplaceAll(timeframe, "-", " "))
-	}
-}
-
-func (h *Handler) formatAvailableTimeframesSuggestion(company, requestedTimeframe string, availableTimeframes []string) string {
-	var message strings.Builder
-	message.WriteString(fmt.Sprintf("No data found for %s (%s).\n\n",
-		formatCompanyName(company),
-		h.formatTimeframeDisplay(requestedTimeframe)))
-
-	message.WriteString(fmt.Sprintf("Available timeframes for %s:\n", formatCompanyName(company)))
+	message.WriteString(tr.T("timeframes.no_data", formatCompanyName(company), formatTimeframeDisplay(tr, requestedTimeframe)) + "\n\n")
+	message.WriteString(tr.T("timeframes.available", formatCompanyName(company)) + "\n")
 
 	priorityOrder := map[string]int{
 		"thirty-days":          1,
@@ -1251,10 +1362,10 @@ func (h *Handler) formatAvailableTimeframesSuggestion(company, requestedTimefram
 	for _, tf := range sortedTimeframes {
 
 		shortAlias := h.getTimeframeShortAlias(tf.name)
-		message.WriteString(fmt.Sprintf("• **%s** (%s)\n", shortAlias, h.formatTimeframeDisplay(tf.name)))
+		message.WriteString(fmt.Sprintf("• **%s** (%s)\n", shortAlias, formatTimeframeDisplay(tr, tf.name)))
 	}
 
-	message.WriteString(fmt.Sprintf("\nTry: `%sproblems %s <timeframe>`", h.prefix, company))
+	message.WriteString("\n" + tr.T("timeframes.try_text", h.currentPrefix(), company))
 
 	return message.String()
 }
@@ -1279,12 +1390,12 @@ func (h *Handler) getTimeframeShortAlias(timeframe string) string {
 func (h *Handler) sendMessage(s *discordgo.Session, channelID, message string) {
 	session := h.GetSession()
 	if session == nil {
-		fmt.Printf("[TEST] Would send to %s: %s\n", channelID, message)
+		slog.Debug("no session, message not sent", logging.KeyChannel, channelID, "content", message)
 		return
 	}
 
 	if session.Token == "" {
-		fmt.Printf("[TEST] Would send to %s: %s\n", channelID, message)
+		slog.Debug("no session, message not sent", logging.KeyChannel, channelID, "content", message)
 		return
 	}
 
@@ -1299,54 +1410,32 @@ func (h *Handler) sendMessage(s *discordgo.Session, channelID, message string) {
 		Flags:   discordgo.MessageFlagsSuppressEmbeds,
 	})
 	if err != nil {
-		fmt.Printf("Error sending message: %v\n", err)
+		slog.Error("sending message failed", logging.KeyChannel, channelID, logging.Err(err))
 	}
 }
 
-func (h *Handler) createHelpPaginator(isAdmin bool) *Paginator {
+func (h *Handler) createHelpPaginator(tr *i18n.Printer, isAdmin bool) *Paginator {
 	return &Paginator{
 		PageFunc: func(page int, embed *discordgo.MessageEmbed) {
 			switch page {
 			case 0:
 				// Page 1: Basic Commands
-				embed.Title = "Basic Commands"
+				embed.Title = tr.T("help.basic.title")
 				embed.Color = 0x5865F2
-				embed.Description = fmt.Sprintf(`**Text Commands (prefix: %s):**
-• **%sproblems <company> [timeframe]** - Show interview problems
-
-**Slash Commands:**
-• **/problems** - Show interview problems (with dropdown options)
-• **/help** - Show this help message`, h.prefix, h.prefix)
+				embed.Description = tr.T("help.basic.description", h.currentPrefix())
 
 				embed.Footer = &discordgo.MessageEmbedFooter{
-					Text: "Page 1/2 • Use the buttons below to navigate",
+					Text: tr.T("help.footer", 1, 2),
 				}
 
 			case 1:
 				// Page 2: Problems Command Usage
-				embed.Title = "Problems Command & Timeframe Options"
+				embed.Title = tr.T("help.problems.title")
 				embed.Color = 0x5865F2
-				embed.Description = `**Problems Command Usage:**
-• *company*: Company name (e.g., airbnb, amazon, google)
-• *timeframe*: Optional timeframe filter (if not specified, uses smart priority system)
-
-**Timeframe Options:**
-• **all** - All time
-• **30d** or **thirty-days** - Last 30 days
-• **3mo** or **three-months** - Last 3 months
-• **6mo** or **six-months** - Last 6 months
-• **>6mo** or **more-than-six-months** - More than 6 months ago
-
-**Smart Priority System:**
-When no timeframe is specified, Leetbot automatically tries:
-1. Last 30 days (most recent)
-2. Last 3 months (if 30d has no data)
-3. Last 6 months (if 3mo has no data)
-4. More than 6 months (if 6mo has no data)
-5. All time (fallback)`
+				embed.Description = tr.T("help.problems.description")
 
 				embed.Footer = &discordgo.MessageEmbedFooter{
-					Text: "Page 2/2 • Use the buttons below to navigate",
+					Text: tr.T("help.footer", 2, 2),
 				}
 			}
 
@@ -1357,9 +1446,10 @@ When no timeframe is specified, Leetbot automatically tries:
 }
 
 func (h *Handler) handleHelpCommand(s *discordgo.Session, m *discordgo.MessageCreate) {
+	tr := messagePrinter(s, m)
 	// if bot is disabled, send short offline message
 	if h.disabled {
-		h.sendMessage(s, m.ChannelID, "Leetbot is currently offline. Please try again later.")
+		h.sendMessage(s, m.ChannelID, tr.T("bot.offline"))
 		return
 	}
 
@@ -1367,18 +1457,19 @@ func (h *Handler) handleHelpCommand(s *discordgo.Session, m *discordgo.MessageCr
 	isAdminUser := isAdmin(m.Author.ID)
 
 	// create help paginator
-	pg := h.createHelpPaginator(isAdminUser)
+	pg := h.createHelpPaginator(tr, isAdminUser)
 
 	// send paginated help
 	err := PaginatorManager.CreateMessage(s, m.ChannelID, pg)
 	if err != nil {
-		fmt.Printf("Error creating help paginator: %v\n", err)
+		messageFailed(m, "help", "creating help paginator failed", err)
 		// fallback to simple message
-		h.sendMessage(s, m.ChannelID, "Error displaying help. Please try again.")
+		h.sendMessage(s, m.ChannelID, tr.T("help.error"))
 	}
 }
 
 func (h *Handler) handleProblemsSlash(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	tr := interactionPrinter(i.Interaction)
 	options := i.ApplicationCommandData().Options
 	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
 	for _, opt := range options {
@@ -1390,16 +1481,29 @@ func (h *Handler) handleProblemsSlash(s *discordgo.Session, i *discordgo.Interac
 		err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: "Company is required!",
+				Content: tr.T("error.company_required"),
+				Flags:   discordgo.MessageFlagsEphemeral | discordgo.MessageFlagsSuppressEmbeds,
+			},
+		})
+		if err != nil {
+			interactionFailed(i.Interaction, "responding to interaction failed", err)
+		}
+		return
+	}
+	company, found, suggestions := findCompanyWithSuggestion(cleanCompanyInput(companyOpt.StringValue()), h.problemsData)
+	if !found {
+		err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: formatCompanyNotFound(tr, companyOpt.StringValue(), suggestions),
 				Flags:   discordgo.MessageFlagsEphemeral | discordgo.MessageFlagsSuppressEmbeds,
 			},
 		})
 		if err != nil {
-			fmt.Printf("Error responding to interaction: %v\n", err)
+			interactionFailed(i.Interaction, "responding to interaction failed", err)
 		}
 		return
 	}
-	company := strings.ToLower(companyOpt.StringValue())
 
 	var problems []data.Problem
 	var timeframe string
@@ -1418,12 +1522,12 @@ func (h *Handler) handleProblemsSlash(s *discordgo.Session, i *discordgo.Interac
 		if len(availableTimeframes) > 0 {
 			_, specifiedTimeframe := optionMap["timeframe"]
 			if specifiedTimeframe {
-				responseContent = h.formatAvailableTimeframesSuggestionSlash(company, timeframe, availableTimeframes)
+				responseContent = h.formatAvailableTimeframesSuggestionSlash(tr, company, timeframe, availableTimeframes)
 			} else {
-				responseContent = fmt.Sprintf("No data found for %s", formatCompanyName(company))
+				responseContent = tr.T("problems.no_data", formatCompanyName(company))
 			}
 		} else {
-			responseContent = fmt.Sprintf("No data found for %s", formatCompanyName(company))
+			responseContent = tr.T("problems.no_data", formatCompanyName(company))
 		}
 
 		err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
@@ -1434,46 +1538,31 @@ func (h *Handler) handleProblemsSlash(s *discordgo.Session, i *discordgo.Interac
 			},
 		})
 		if err != nil {
-			fmt.Printf("Error responding to interaction: %v\n", err)
+			interactionFailed(i.Interaction, "responding to interaction failed", err)
 		}
 		return
 	}
+	companyQueries.Inc(company)
 
-	if shouldUsePagination(len(problems)) {
-		err := sendPaginatedProblems(s, i, company, timeframe, problems)
-		if err != nil {
-			fmt.Printf("Error sending paginated response: %v\n", err)
-			// don't try to respond again - the interaction is already acknowledged
-		}
-		return
-	}
-
-	response := h.formatProblemsResponse(company, timeframe, problems)
-
-	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Content: response,
-			Flags:   discordgo.MessageFlagsSuppressEmbeds,
-		},
-	})
-	if err != nil {
-		fmt.Printf("Error responding to interaction: %v\n", err)
+	if err := h.respondProblems(s, i, h.newProblemList(tr, company, timeframe, problems)); err != nil {
+		interactionFailed(i.Interaction, "responding with problems failed", err)
+		// don't try to respond again - the interaction may already be acknowledged
 	}
 }
 
 func (h *Handler) handleHelpSlash(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	tr := interactionPrinter(i.Interaction)
 	// if bot is disabled, send short offline message
 	if h.disabled {
 		err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: "Leetbot is currently offline. Please try again later.",
+				Content: tr.T("bot.offline"),
 				Flags:   discordgo.MessageFlagsEphemeral,
 			},
 		})
 		if err != nil {
-			fmt.Printf("Error responding to interaction: %v\n", err)
+			interactionFailed(i.Interaction, "responding to interaction failed", err)
 		}
 		return
 	}
@@ -1485,47 +1574,40 @@ func (h *Handler) handleHelpSlash(s *discordgo.Session, i *discordgo.Interaction
 	}
 
 	// create help paginator
-	pg := h.createHelpPaginator(isAdminUser)
+	pg := h.createHelpPaginator(tr, isAdminUser)
 
 	// send paginated help
 	err := PaginatorManager.CreateInteraction(s, i.Interaction, pg, false)
 	if err != nil {
-		fmt.Printf("Error creating help paginator: %v\n", err)
+		interactionFailed(i.Interaction, "creating help paginator failed", err)
 		// fallback to simple message
 		err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: "Error displaying help. Please try again.",
+				Content: tr.T("help.error"),
 				Flags:   discordgo.MessageFlagsEphemeral,
 			},
 		})
 		if err != nil {
-			fmt.Printf("Error responding to interaction: %v\n", err)
+			interactionFailed(i.Interaction, "responding to interaction failed", err)
 		}
 	}
 }
 
-func formatTimeframeDisplay(timeframe string) string {
-	switch timeframe {
-	case "all":
-		return "all"
-	case "thirty-days":
-		return "last 30 days"
-	case "three-months":
-		return "last 3 months"
-	case "six-months":
-		return "last 6 months"
-	case "more-than-six-months":
-		return "more than 6 months"
-	default:
-		return strings.ToLower(strings.ReplaceAll(timeframe, "-", " "))
+// formatTimeframeDisplay returns the lowercase label of a timeframe, as in
+// "Most Popular Problems for Airbnb (last 30 days)"
+func formatTimeframeDisplay(tr *i18n.Printer, timeframe string) string {
+	if key := "timeframe." + timeframe; i18n.Has(key) {
+		return tr.T(key)
 	}
+	return strings.ToLower(strings.ReplaceAll(timeframe, "-", " "))
 }
 
 func (h *Handler) handleShutdownMessage(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	tr := messagePrinter(s, m)
 	// check if the user is authorized (admin only)
 	if !isAdmin(m.Author.ID) {
-		h.sendErrorMessage(s, m.ChannelID, "Only the owner of Leetbot can use this command.")
+		h.sendErrorMessage(s, m.ChannelID, tr.T("admin.owner_only"))
 		return
 	}
 
@@ -1535,11 +1617,11 @@ func (h *Handler) handleShutdownMessage(s *discordgo.Session, m *discordgo.Messa
 		h.disabled = true
 
 		// unregister all slash commands except help
-		h.sendMessage(s, m.ChannelID, "Unregistering commands...")
-		err := h.unregisterCommandsExceptHelp(s)
+		h.sendMessage(s, m.ChannelID, tr.T("admin.unregistering"))
+		err := h.syncCommands(s, true, nil)
 		if err != nil {
-			fmt.Printf("Error unregistering commands: %v\n", err)
-			h.sendErrorMessage(s, m.ChannelID, fmt.Sprintf("Failed to unregister commands: %v", err))
+			messageFailed(m, "shutdown", "unregistering commands failed", err)
+			h.sendErrorMessage(s, m.ChannelID, tr.T("admin.unregister_failed", err))
 			h.disabled = false // revert disabled state on error
 			return
 		}
@@ -1549,16 +1631,16 @@ func (h *Handler) handleShutdownMessage(s *discordgo.Session, m *discordgo.Messa
 			Status: "invisible",
 		})
 		if err != nil {
-			fmt.Printf("Error setting Leetbot status to invisible: %v\n", err)
+			messageFailed(m, "shutdown", "setting Leetbot status to invisible failed", err)
 		}
 
-		h.sendMessage(s, m.ChannelID, "Leetbot is now disabled indefinitely. Use `!startup` to re-enable.")
+		h.sendMessage(s, m.ChannelID, tr.T("admin.disabled_indefinitely"))
 		return
 	}
 
 	// regular shutdown - exit the process
 	// send confirmation message first
-	h.sendMessage(s, m.ChannelID, "Leetbot is now shutting down...")
+	h.sendMessage(s, m.ChannelID, tr.T("admin.shutting_down"))
 
 	// close the session to disconnect from Discord
 	// use a goroutine with a small delay to ensure the message is sent
@@ -1566,7 +1648,7 @@ func (h *Handler) handleShutdownMessage(s *discordgo.Session, m *discordgo.Messa
 		time.Sleep(100 * time.Millisecond)
 		err := s.Close()
 		if err != nil {
-			fmt.Printf("Error closing Discord session: %v\n", err)
+			messageFailed(m, "shutdown", "closing Discord session failed", err)
 		}
 		// exit the program
 		os.Exit(0)
@@ -1574,20 +1656,21 @@ func (h *Handler) handleShutdownMessage(s *discordgo.Session, m *discordgo.Messa
 }
 
 func (h *Handler) handleStartupMessage(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	tr := messagePrinter(s, m)
 	// check if the user is authorized (admin only)
 	if !isAdmin(m.Author.ID) {
-		h.sendErrorMessage(s, m.ChannelID, "Only the owner of Leetbot can use this command.")
+		h.sendErrorMessage(s, m.ChannelID, tr.T("admin.owner_only"))
 		return
 	}
 
 	// check if Leetbot is disabled
 	if h.disabled {
 		// re-register slash commands
-		h.sendMessage(s, m.ChannelID, "Re-registering commands...")
-		err := h.registerAllCommands(s)
+		h.sendMessage(s, m.ChannelID, tr.T("admin.registering"))
+		err := h.syncCommands(s, false, nil)
 		if err != nil {
-			fmt.Printf("Error re-registering commands: %v\n", err)
-			h.sendErrorMessage(s, m.ChannelID, fmt.Sprintf("Failed to re-register commands: %v", err))
+			messageFailed(m, "startup", "re-registering commands failed", err)
+			h.sendErrorMessage(s, m.ChannelID, tr.T("admin.register_failed", err))
 			return
 		}
 
@@ -1599,10 +1682,10 @@ func (h *Handler) handleStartupMessage(s *discordgo.Session, m *discordgo.Messag
 			Status: "online",
 		})
 		if err != nil {
-			fmt.Printf("Error setting Leetbot status to online: %v\n", err)
+			messageFailed(m, "startup", "setting Leetbot status to online failed", err)
 		}
 
-		h.sendMessage(s, m.ChannelID, "Leetbot is now back online.")
+		h.sendMessage(s, m.ChannelID, tr.T("admin.back_online"))
 		return
 	}
 
@@ -1612,23 +1695,70 @@ func (h *Handler) handleStartupMessage(s *discordgo.Session, m *discordgo.Messag
 		case h.reconnectChan <- RestartRequest{
 			ChannelID: m.ChannelID,
 			Success:   false,
-			Message:   "Leetbot is restarting...",
+			Message:   tr.T("admin.restarting"),
+			Printer:   tr,
 		}:
 			// Signal sent successfully
 		default:
 			// Channel full, send error message
-			h.sendErrorMessage(s, m.ChannelID, "Restart already in progress, please wait.")
+			h.sendErrorMessage(s, m.ChannelID, tr.T("admin.restart_in_progress"))
 		}
 	} else {
 		// No channel configured, send error
-		h.sendErrorMessage(s, m.ChannelID, "Restart mechanism not available.")
+		h.sendErrorMessage(s, m.ChannelID, tr.T("admin.restart_unavailable"))
+	}
+}
+
+func (h *Handler) handleReloadMessage(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	tr := messagePrinter(s, m)
+	// check if the user is authorized (admin only)
+	if !isAdmin(m.Author.ID) {
+		h.sendErrorMessage(s, m.ChannelID, tr.T("admin.owner_only"))
+		return
+	}
+
+	if len(args) != 1 || args[0] != "config" {
+		h.sendErrorMessage(s, m.ChannelID, tr.T("admin.reload_usage", h.currentPrefix()))
+		return
+	}
+	if h.reloadConfig == nil {
+		h.sendErrorMessage(s, m.ChannelID, tr.T("admin.reload_unavailable"))
+		return
+	}
+
+	changes, err := h.reloadConfig()
+	if err != nil {
+		h.sendErrorMessage(s, m.ChannelID, tr.T("admin.reload_failed", err))
+		return
 	}
+	h.sendMessage(s, m.ChannelID, formatConfigChanges(tr, changes))
+}
+
+// formatConfigChanges summarizes a reload for Discord, which caps messages
+// at 2000 characters
+func formatConfigChanges(tr *i18n.Printer, changes []config.Change) string {
+	if len(changes) == 0 {
+		return tr.T("admin.reload_unchanged")
+	}
+
+	var b strings.Builder
+	b.WriteString(tr.T("admin.reload_changed", len(changes)))
+	for i, change := range changes {
+		line := fmt.Sprintf("\n• `%s`: %s → %s", change.Key, change.Old, change.New)
+		if b.Len()+len(line) > 1900 {
+			b.WriteString("\n" + tr.T("admin.reload_more", len(changes)-i))
+			break
+		}
+		b.WriteString(line)
+	}
+	return b.String()
 }
 
 func (h *Handler) handleInitCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	tr := messagePrinter(s, m)
 	// check if the user is authorized (nyumat's user ID)
 	if !isAdmin(m.Author.ID) {
-		h.sendErrorMessage(s, m.ChannelID, "Only the admin can initialize channels.")
+		h.sendErrorMessage(s, m.ChannelID, tr.T("admin.init_admin_only"))
 		return
 	}
 
@@ -1636,7 +1766,7 @@ func (h *Handler) handleInitCommand(s *discordgo.Session, m *discordgo.MessageCr
 	if len(args) == 0 {
 		// enable the current channel
 		h.enableChannel(m.ChannelID)
-		h.sendMessage(s, m.ChannelID, "✓ Leetbot is now enabled in this channel.")
+		h.sendMessage(s, m.ChannelID, tr.T("admin.channel_enabled"))
 		return
 	}
 
@@ -1644,28 +1774,25 @@ func (h *Handler) handleInitCommand(s *discordgo.Session, m *discordgo.MessageCr
 	switch subcommand {
 	case "enable":
 		h.enableChannel(m.ChannelID)
-		h.sendMessage(s, m.ChannelID, "✓ Leetbot is now enabled in this channel.")
+		h.sendMessage(s, m.ChannelID, tr.T("admin.channel_enabled"))
 	case "disable":
 		h.disableChannel(m.ChannelID)
-		h.sendMessage(s, m.ChannelID, "✓ Leetbot is now disabled in this channel.")
+		h.sendMessage(s, m.ChannelID, tr.T("admin.channel_disabled"))
 	case "status":
 		if h.isChannelEnabled(m.ChannelID) {
-			h.sendMessage(s, m.ChannelID, "✓ Leetbot is enabled in this channel.")
+			h.sendMessage(s, m.ChannelID, tr.T("admin.channel_status_enabled"))
 		} else {
-			h.sendMessage(s, m.ChannelID, "✗ Leetbot is not enabled in this channel.")
+			h.sendMessage(s, m.ChannelID, tr.T("admin.channel_status_disabled"))
 		}
 	default:
-		h.sendErrorMessage(s, m.ChannelID, fmt.Sprintf("Unknown subcommand '%s'. Usage: !init [enable|disable|status]", subcommand))
+		h.sendErrorMessage(s, m.ChannelID, tr.T("admin.init_usage", subcommand))
 	}
 }
 
-func (h *Handler) formatAvailableTimeframesSuggestionSlash(company, requestedTimeframe string, availableTimeframes []string) string {
+func (h *Handler) formatAvailableTimeframesSuggestionSlash(tr *i18n.Printer, company, requestedTimeframe string, availableTimeframes []string) string {
 	var message strings.Builder
-	message.WriteString(fmt.Sprintf("No data found for %s (%s).\n\n",
-		formatCompanyName(company),
-		formatTimeframeDisplay(requestedTimeframe)))
-
-	message.WriteString(fmt.Sprintf("Available timeframes for %s:\n", formatCompanyName(company)))
+	message.WriteString(tr.T("timeframes.no_data", formatCompanyName(company), formatTimeframeDisplay(tr, requestedTimeframe)) + "\n\n")
+	message.WriteString(tr.T("timeframes.available", formatCompanyName(company)) + "\n")
 
 	priorityOrder := map[string]int{
 		"thirty-days":          1,
@@ -1698,10 +1825,10 @@ func (h *Handler) formatAvailableTimeframesSuggestionSlash(company, requestedTim
 	}
 
 	for _, tf := range sortedTimeframes {
-		message.WriteString(fmt.Sprintf("• **%s** (%s)\n", tf.name, formatTimeframeDisplay(tf.name)))
+		message.WriteString(fmt.Sprintf("• **%s** (%s)\n", tf.name, formatTimeframeDisplay(tr, tf.name)))
 	}
 
-	message.WriteString(fmt.Sprintf("\nTry: `/problems company:%s timeframe:<option>`", company))
+	message.WriteString("\n" + tr.T("timeframes.try_slash", company))
 
 	return message.String()
 }
@@ -1710,55 +1837,3 @@ func (h *Handler) sendErrorMessage(s *discordgo.Session, channelID, message stri
 
 	h.sendMessage(s, channelID, message)
 }
-
-// unregisterCommandsExceptHelp removes all slash commands except the help command
-func (h *Handler) unregisterCommandsExceptHelp(s *discordgo.Session) error {
-	// get all currently registered commands
-	registeredCommands, err := s.ApplicationCommands(s.State.User.ID, "")
-	if err != nil {
-		return fmt.Errorf("failed to get registered commands: %w", err)
-	}
-
-	// delete all commands except help
-	for _, cmd := range registeredCommands {
-		if cmd.Name != "help" {
-			fmt.Printf("Unregistering command: /%s\n", cmd.Name)
-			err := s.ApplicationCommandDelete(s.State.User.ID, "", cmd.ID)
-			if err != nil {
-				return fmt.Errorf("failed to delete command '%s': %w", cmd.Name, err)
-			}
-		}
-	}
-
-	return nil
-}
-
-// registerAllCommands registers all slash commands
-func (h *Handler) registerAllCommands(s *discordgo.Session) error {
-	commands := GetSlashCommands(h.problemsData)
-
-	// get currently registered commands to avoid duplicates
-	registeredCommands, err := s.ApplicationCommands(s.State.User.ID, "")
-	if err != nil {
-		return fmt.Errorf("failed to get registered commands: %w", err)
-	}
-
-	// create a map of registered command names
-	registeredMap := make(map[string]bool)
-	for _, cmd := range registeredCommands {
-		registeredMap[cmd.Name] = true
-	}
-
-	// register commands that aren't already registered
-	for _, cmd := range commands {
-		if !registeredMap[cmd.Name] {
-			fmt.Printf("Registering command: /%s\n", cmd.Name)
-			_, err := s.ApplicationCommandCreate(s.State.User.ID, "", cmd)
-			if err != nil {
-				return fmt.Errorf("failed to create command '%s': %w", cmd.Name, err)
-			}
-		}
-	}
-
-	return nil
-}
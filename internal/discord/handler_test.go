@@ -1,12 +1,20 @@
 package discord
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/whotypes/leetbot/internal/config"
 	"github.com/whotypes/leetbot/internal/data"
+	"github.com/whotypes/leetbot/internal/discord/discordtest"
+	"github.com/whotypes/leetbot/internal/export"
+	"github.com/whotypes/leetbot/internal/i18n"
+	"github.com/whotypes/leetbot/internal/ratelimit"
 )
 
 func createTestProblemsData() *data.ProblemsByCompany {
@@ -66,8 +74,8 @@ func TestNewHandler(t *testing.T) {
 		t.Error("NewHandler() should set problemsData")
 	}
 
-	if handler.prefix != "!" {
-		t.Errorf("NewHandler() prefix = %v, want %v", handler.prefix, "!")
+	if handler.currentPrefix() != "!" {
+		t.Errorf("NewHandler() prefix = %v, want %v", handler.currentPrefix(), "!")
 	}
 }
 
@@ -111,8 +119,6 @@ func TestNormalizeTimeframe(t *testing.T) {
 }
 
 func TestFormatTimeframeDisplay(t *testing.T) {
-	handler := NewHandler(createTestProblemsData(), "!")
-
 	tests := []struct {
 		input    string
 		expected string
@@ -126,7 +132,7 @@ func TestFormatTimeframeDisplay(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		result := handler.formatTimeframeDisplay(tt.input)
+		result := formatTimeframeDisplay(i18n.Default(), tt.input)
 		if result != tt.expected {
 			t.Errorf("formatTimeframeDisplay(%q) = %q, want %q", tt.input, result, tt.expected)
 		}
@@ -155,32 +161,32 @@ func TestFormatProblemsResponse(t *testing.T) {
 		},
 	}
 
-	result := handler.formatProblemsResponse("airbnb", "all", problems)
+	result := handler.newProblemList(i18n.Default(), "airbnb", "all", problems).text()
 
 	if !contains(result, "Most Popular Problems for Airbnb (all):") {
-		t.Error("formatProblemsResponse() should contain title")
+		t.Error("text() should contain title")
 	}
 
 	if !contains(result, "Two Sum (100%)") {
-		t.Error("formatProblemsResponse() should contain first problem")
+		t.Error("text() should contain first problem")
 	}
 
 	if !contains(result, "Add Two Numbers (75%)") {
-		t.Error("formatProblemsResponse() should contain second problem")
+		t.Error("text() should contain second problem")
 	}
 
 	if len(result) > 2000 {
-		t.Errorf("formatProblemsResponse() result too long: %d characters", len(result))
+		t.Errorf("text() result too long: %d characters", len(result))
 	}
 }
 
 func TestFormatProblemsResponse_Empty(t *testing.T) {
 	handler := NewHandler(createTestProblemsData(), "!")
 
-	result := handler.formatProblemsResponse("airbnb", "all", []data.Problem{})
+	result := handler.newProblemList(i18n.Default(), "airbnb", "all", []data.Problem{}).text()
 
 	if !contains(result, "No problems found") {
-		t.Error("formatProblemsResponse() should handle empty problems list")
+		t.Error("text() should handle empty problems list")
 	}
 }
 
@@ -375,6 +381,38 @@ func TestGetCompanyAutocompleteChoices(t *testing.T) {
 	}
 }
 
+func TestCompanyRegistryNames(t *testing.T) {
+	problemsData := data.NewTestProblemsByCompany(map[string]map[string][]data.Problem{
+		"facebook": {
+			"all": []data.Problem{{ID: 1, Title: "Test", Difficulty: "Easy", Frequency: 100.0}},
+		},
+		"jpmorgan": {
+			"all": []data.Problem{{ID: 2, Title: "Test", Difficulty: "Medium", Frequency: 90.0}},
+		},
+		"jane-street": {
+			"all": []data.Problem{{ID: 3, Title: "Test", Difficulty: "Hard", Frequency: 85.0}},
+		},
+	})
+
+	names := map[string]string{"facebook": "Meta", "jpmorgan": "JPMorgan Chase", "jane-street": "Jane Street"}
+	for slug, want := range names {
+		if got := formatCompanyName(slug); got != want {
+			t.Errorf("formatCompanyName(%q) = %q, want %q", slug, got, want)
+		}
+	}
+
+	for _, input := range []string{"JPM", "chase", "J.P. Morgan"} {
+		if company, found := findCompanyByFuzzySearch(input, problemsData); !found || company != "jpmorgan" {
+			t.Errorf("findCompanyByFuzzySearch(%q) = %q, %v, want jpmorgan", input, company, found)
+		}
+	}
+
+	choices := getCompanyAutocompleteChoices("fb", problemsData)
+	if len(choices) == 0 || choices[0].Value != "facebook" || choices[0].Name != "Meta" {
+		t.Errorf("getCompanyAutocompleteChoices(fb) = %+v, want Meta first", choices)
+	}
+}
+
 func TestIsTimeframeKeyword(t *testing.T) {
 	handler := NewHandler(createTestProblemsData(), "!")
 
@@ -409,7 +447,7 @@ func TestFormatAvailableTimeframesSuggestion(t *testing.T) {
 	handler := NewHandler(createTestProblemsData(), "!")
 
 	availableTimeframes := []string{"all", "six-months"}
-	result := handler.formatAvailableTimeframesSuggestion("starbucks", "thirty-days", availableTimeframes)
+	result := handler.formatAvailableTimeframesSuggestion(i18n.Default(), "starbucks", "thirty-days", availableTimeframes)
 
 	if !contains(result, "No data found for Starbucks") {
 		t.Error("Suggestion should mention no data found for company")
@@ -466,10 +504,10 @@ func TestFormatProblemsResponse_LongList(t *testing.T) {
 		})
 	}
 
-	result := handler.formatProblemsResponse("test-company", "all", longList)
+	result := handler.newProblemList(i18n.Default(), "test-company", "all", longList).text()
 
 	if len(result) > 2000 {
-		t.Errorf("formatProblemsResponse() length = %d, exceeds Discord limit of 2000", len(result))
+		t.Errorf("text() length = %d, exceeds Discord limit of 2000", len(result))
 	}
 }
 
@@ -544,16 +582,16 @@ func TestFormatProblemsResponse_DifferentDifficulties(t *testing.T) {
 		{ID: 3, Title: "Hard Problem", Difficulty: "Hard", Frequency: 80.0},
 	}
 
-	result := handler.formatProblemsResponse("test-company", "all", problems)
+	result := handler.newProblemList(i18n.Default(), "test-company", "all", problems).text()
 
 	if !contains(result, "🟢") {
-		t.Error("formatProblemsResponse() should contain 🟢 for Easy")
+		t.Error("text() should contain 🟢 for Easy")
 	}
 	if !contains(result, "🟡") {
-		t.Error("formatProblemsResponse() should contain 🟡 for Medium")
+		t.Error("text() should contain 🟡 for Medium")
 	}
 	if !contains(result, "🔴") {
-		t.Error("formatProblemsResponse() should contain 🔴 for Hard")
+		t.Error("text() should contain 🔴 for Hard")
 	}
 }
 
@@ -1045,8 +1083,8 @@ func TestShouldUsePagination(t *testing.T) {
 	}
 }
 
-// Test createProblemsPaginator
-func TestCreateProblemsPaginator(t *testing.T) {
+// Test problemList.paginator
+func TestProblemListPaginator(t *testing.T) {
 	problems := make([]data.Problem, 25)
 	for i := 0; i < 25; i++ {
 		problems[i] = data.Problem{
@@ -1119,12 +1157,12 @@ func TestCreateProblemsPaginator(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			pg := createProblemsPaginator(tt.company, tt.timeframe, tt.problems)
+			pg := problemList{company: tt.company, timeframe: tt.timeframe, problems: tt.problems}.paginator()
 			if pg.MaxPages != tt.expectedPages {
-				t.Errorf("createProblemsPaginator() MaxPages = %d, want %d", pg.MaxPages, tt.expectedPages)
+				t.Errorf("paginator() MaxPages = %d, want %d", pg.MaxPages, tt.expectedPages)
 			}
 			if pg.PageFunc == nil {
-				t.Error("createProblemsPaginator() PageFunc should not be nil")
+				t.Error("paginator() PageFunc should not be nil")
 			}
 			if tt.checkPageFunc != nil {
 				for page := 0; page < tt.expectedPages; page++ {
@@ -1136,7 +1174,7 @@ func TestCreateProblemsPaginator(t *testing.T) {
 }
 
 // Test paginator PageFunc with boundary conditions
-func TestCreateProblemsPaginator_BoundaryConditions(t *testing.T) {
+func TestProblemListPaginator_BoundaryConditions(t *testing.T) {
 	problems := make([]data.Problem, 15)
 	for i := 0; i < 15; i++ {
 		problems[i] = data.Problem{
@@ -1149,7 +1187,7 @@ func TestCreateProblemsPaginator_BoundaryConditions(t *testing.T) {
 		}
 	}
 
-	pg := createProblemsPaginator("test", "all", problems)
+	pg := problemList{company: "test", timeframe: "all", problems: problems}.paginator()
 
 	tests := []struct {
 		name          string
@@ -1292,3 +1330,611 @@ func TestManagerCreateButtons(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateCompanySummaryEmbed(t *testing.T) {
+	problemsData := createTestProblemsData()
+	summary := problemsData.GetCompanySummary("airbnb", summaryTopProblems)
+	if summary == nil {
+		t.Fatal("GetCompanySummary() returned nil for airbnb")
+	}
+
+	embed := createCompanySummaryEmbed(i18n.Default(), summary)
+
+	if embed.Title != "Airbnb Overview" {
+		t.Errorf("embed title = %q, want %q", embed.Title, "Airbnb Overview")
+	}
+
+	// thirty-days and all as inline fields, plus the evergreen field
+	if len(embed.Fields) != 2 {
+		t.Fatalf("embed fields = %d, want 2 (no evergreen problems in test data)", len(embed.Fields))
+	}
+
+	if embed.Fields[0].Name != "Last 30 Days" || !embed.Fields[0].Inline {
+		t.Errorf("first field = %q (inline %v), want inline %q", embed.Fields[0].Name, embed.Fields[0].Inline, "Last 30 Days")
+	}
+
+	if !contains(embed.Fields[1].Value, "Two Sum") || !contains(embed.Fields[1].Value, "**2** problems") {
+		t.Errorf("all time field missing expected content: %s", embed.Fields[1].Value)
+	}
+
+	for _, field := range embed.Fields {
+		if len(field.Value) > embedFieldValueLimit {
+			t.Errorf("field %q value too long: %d characters", field.Name, len(field.Value))
+		}
+	}
+}
+
+func TestCreateCompanySummaryEmbed_Evergreen(t *testing.T) {
+	problem := data.Problem{ID: 1, URL: "https://leetcode.com/problems/two-sum", Title: "Two Sum", Difficulty: "Easy", Frequency: 100.0}
+	problemsData := data.NewTestProblemsByCompany(map[string]map[string][]data.Problem{
+		"google": {
+			"all":         []data.Problem{problem},
+			"thirty-days": []data.Problem{problem},
+			"six-months":  []data.Problem{problem},
+		},
+	})
+
+	embed := createCompanySummaryEmbed(i18n.Default(), problemsData.GetCompanySummary("google", summaryTopProblems))
+
+	last := embed.Fields[len(embed.Fields)-1]
+	if !contains(last.Name, "Evergreen") || !contains(last.Value, "Two Sum") {
+		t.Errorf("expected evergreen field with Two Sum, got %q: %s", last.Name, last.Value)
+	}
+}
+
+func TestFormatTimeframeHeading(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"all", "All Time"},
+		{"thirty-days", "Last 30 Days"},
+		{"more-than-six-months", "More than 6 Months"},
+		{"custom-range", "Custom Range"},
+	}
+
+	for _, tt := range tests {
+		if result := formatTimeframeHeading(i18n.Default(), tt.input); result != tt.expected {
+			t.Errorf("formatTimeframeHeading(%q) = %q, want %q", tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestBuildExportFile(t *testing.T) {
+	problemsData := createTestProblemsData()
+	problems := problemsData.GetProblems("airbnb", "all")
+
+	file, err := buildExportFile(i18n.Default(), "airbnb", "all", export.FormatMarkdown, problems)
+	if err != nil {
+		t.Fatalf("buildExportFile() error = %v", err)
+	}
+
+	if file.Name != "airbnb-all.md" {
+		t.Errorf("file name = %q, want %q", file.Name, "airbnb-all.md")
+	}
+
+	content, err := io.ReadAll(file.Reader)
+	if err != nil {
+		t.Fatalf("failed to read export: %v", err)
+	}
+
+	if !contains(string(content), "# Most Popular Problems for Airbnb (all)") {
+		t.Errorf("export should contain title, got: %s", content)
+	}
+	if !contains(string(content), "- [ ] [Two Sum]") {
+		t.Errorf("export should contain checklist items, got: %s", content)
+	}
+}
+
+func TestCreateChangelogEmbed(t *testing.T) {
+	previous := data.NewTestProblemsByCompany(map[string]map[string][]data.Problem{
+		"airbnb": {
+			"all": []data.Problem{{ID: 3, Title: "Longest Substring", Difficulty: "Medium", Frequency: 50.0}},
+		},
+	})
+	current := createTestProblemsData()
+
+	embed := createChangelogEmbed(i18n.Default(), data.Diff(previous, current, 10))
+
+	if !contains(embed.Title, previous.Version().Version) || !contains(embed.Title, current.Version().Version) {
+		t.Errorf("title should mention both versions, got %q", embed.Title)
+	}
+	if !contains(embed.Description, "**Added companies (1):** Amazon") {
+		t.Errorf("description should list added companies, got: %s", embed.Description)
+	}
+	if !contains(embed.Description, "• Airbnb (all): +2 -1") {
+		t.Errorf("description should summarize airbnb changes, got: %s", embed.Description)
+	}
+	if len(embed.Description) > embedDescriptionLimit {
+		t.Errorf("description too long: %d characters", len(embed.Description))
+	}
+
+	unchanged := createChangelogEmbed(i18n.Default(), data.Diff(current, current, 10))
+	if unchanged.Description != "No changes." {
+		t.Errorf("identical datasets description = %q, want %q", unchanged.Description, "No changes.")
+	}
+}
+
+func TestFormatProblemsResponse_SourceLabel(t *testing.T) {
+	handler := NewHandler(createTestProblemsData(), "!")
+
+	problems := []data.Problem{
+		{ID: 1, URL: "https://leetcode.com/problems/two-sum", Title: "Two Sum", Difficulty: "Easy", Frequency: 100.0, Source: data.DefaultSourceName},
+		{ID: 2, URL: "https://leetcode.com/problems/add-two-numbers", Title: "Add Two Numbers", Difficulty: "Medium", Frequency: 75.0, Source: "interview-notes"},
+	}
+
+	result := handler.newProblemList(i18n.Default(), "airbnb", "all", problems).text()
+
+	if !contains(result, "Two Sum (100%): ") {
+		t.Errorf("public dataset problems should not be labeled, got: %s", result)
+	}
+	if !contains(result, "Add Two Numbers (75%) `interview-notes`: ") {
+		t.Errorf("overlay problems should be labeled with their source, got: %s", result)
+	}
+}
+
+func TestGetSearchAutocompleteChoices(t *testing.T) {
+	problemsData := createTestProblemsData()
+
+	choices := getSearchAutocompleteChoices("two", problemsData)
+	if len(choices) == 0 {
+		t.Fatal("expected choices for 'two'")
+	}
+	if choices[0].Name != "1. Two Sum (Easy)" || choices[0].Value != "1" {
+		t.Errorf("first choice = %q/%v, want \"1. Two Sum (Easy)\"/\"1\"", choices[0].Name, choices[0].Value)
+	}
+	for _, choice := range choices {
+		if len(choice.Name) > choiceNameLimit {
+			t.Errorf("choice name too long: %q", choice.Name)
+		}
+	}
+
+	if choices := getSearchAutocompleteChoices("  ", problemsData); len(choices) != 0 {
+		t.Errorf("empty input should return no choices, got %d", len(choices))
+	}
+}
+
+func TestCreateSearchEmbeds(t *testing.T) {
+	problemsData := createTestProblemsData()
+
+	results := problemsData.Search("two sum", searchResultLimit)
+	if len(results) == 0 {
+		t.Fatal("expected results for 'two sum'")
+	}
+
+	embed := createSearchResultsEmbed(i18n.Default(), "two sum", results)
+	if !contains(embed.Description, "[1. Two Sum](<https://leetcode.com/problems/two-sum>)") {
+		t.Errorf("results embed should link the problem, got: %s", embed.Description)
+	}
+
+	problem := createProblemEmbed(i18n.Default(), results[0])
+	if problem.Title != "1. Two Sum" || problem.URL == "" {
+		t.Errorf("problem embed title/url = %q/%q", problem.Title, problem.URL)
+	}
+	if len(problem.Fields) != 4 || !contains(problem.Fields[3].Value, "Airbnb") {
+		t.Errorf("problem embed should list the companies that ask it, got %+v", problem.Fields)
+	}
+}
+
+func TestPaginatorWithFakeSession(t *testing.T) {
+	var problems []data.Problem
+	for id := 1; id <= 25; id++ {
+		problems = append(problems, data.Problem{
+			ID:         id,
+			URL:        fmt.Sprintf("https://leetcode.com/problems/problem-%d", id),
+			Title:      fmt.Sprintf("Problem %d", id),
+			Difficulty: "Medium",
+			Frequency:  float64(100 - id),
+		})
+	}
+	problemsData := data.NewTestProblemsByCompany(map[string]map[string][]data.Problem{
+		"google": {"all": problems},
+	})
+	handler := NewHandler(problemsData, "!")
+	session := discordtest.New()
+	user := &discordgo.User{ID: "42", Username: "tester"}
+
+	handler.HandleSlashCommand(session.Session, session.SlashCommand("1", user, discordgo.ApplicationCommandInteractionData{
+		Name: "problems",
+		Options: []*discordgo.ApplicationCommandInteractionDataOption{
+			{Name: "company", Type: discordgo.ApplicationCommandOptionString, Value: "google"},
+			{Name: "timeframe", Type: discordgo.ApplicationCommandOptionString, Value: "all"},
+		},
+	}))
+
+	events := session.Events()
+	if len(events) == 0 || events[0].Type != discordtest.EventMessageCreate {
+		t.Fatalf("expected the paginator message to be created, got %+v", events)
+	}
+	msg := events[len(events)-1].Message
+	if len(msg.Embeds) != 1 || !contains(msg.Embeds[0].Footer.Text, "Page 1/3") {
+		t.Fatalf("expected page 1 of 3, got %+v", msg.Embeds)
+	}
+
+	next := fmt.Sprintf("paginator:%s:next", msg.ID)
+	click, err := session.ComponentClick(msg.ID, user, next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	PaginatorManager.OnInteractionCreate(session.Session, click)
+
+	updated, _ := session.Message(msg.ID)
+	if !contains(updated.Embeds[0].Footer.Text, "Page 2/3") {
+		t.Errorf("expected page 2 after clicking next, got %q", updated.Embeds[0].Footer.Text)
+	}
+	if !contains(updated.Embeds[0].Description, "Problem 11") {
+		t.Errorf("page 2 should start at problem 11, got %q", updated.Embeds[0].Description)
+	}
+}
+
+func TestHandleMessageWithFakeSession(t *testing.T) {
+	handler := NewHandler(createTestProblemsData(), "!")
+	session := discordtest.New()
+	handler.SetSession(session.Session)
+	handler.EnableChannel("1")
+	user := &discordgo.User{ID: "42", Username: "tester"}
+
+	handler.HandleMessage(session.Session, session.MessageCreate("1", user, "!problems airbnb"))
+
+	events := session.Events()
+	if len(events) != 1 {
+		t.Fatalf("expected one message, got %d", len(events))
+	}
+	if msg := events[0].Message; len(msg.Embeds) != 1 || !contains(msg.Embeds[0].Description, "Text Justification") {
+		t.Errorf("expected an embed of airbnb's problems, got %+v", msg)
+	}
+
+	// channels that weren't initialized are ignored
+	handler.HandleMessage(session.Session, session.MessageCreate("2", user, "!problems airbnb"))
+	if events := session.Events(); len(events) != 0 {
+		t.Errorf("expected no reply in a disabled channel, got %d messages", len(events))
+	}
+}
+
+func TestProblemsSlashResolvesCompany(t *testing.T) {
+	problemsData := data.NewTestProblemsByCompany(map[string]map[string][]data.Problem{
+		"facebook": {"all": []data.Problem{{ID: 1, Title: "Two Sum", Difficulty: "Easy", Frequency: 100.0}}},
+	})
+	handler := NewHandler(problemsData, "!")
+	session := discordtest.New()
+	user := &discordgo.User{ID: "42", Username: "tester"}
+
+	slash := func(company string) *discordgo.InteractionCreate {
+		return session.SlashCommand("1", user, discordgo.ApplicationCommandInteractionData{
+			Name: "problems",
+			Options: []*discordgo.ApplicationCommandInteractionDataOption{
+				{Name: "company", Type: discordgo.ApplicationCommandOptionString, Value: company},
+			},
+		})
+	}
+
+	handler.HandleSlashCommand(session.Session, slash("meta"))
+	events := session.Events()
+	if len(events) != 1 || len(events[0].Message.Embeds) != 1 || !contains(events[0].Message.Embeds[0].Description, "Two Sum") {
+		t.Fatalf("expected Meta to resolve to facebook's problems, got %+v", events)
+	}
+
+	handler.HandleSlashCommand(session.Session, slash("notacompany"))
+	events = session.Events()
+	if len(events) != 1 || !events[0].Ephemeral || !contains(events[0].Message.Content, "notacompany") {
+		t.Errorf("expected an ephemeral not found reply, got %+v", events)
+	}
+}
+
+func TestCommandAndPaginatorMetrics(t *testing.T) {
+	var problems []data.Problem
+	for id := 1; id <= 15; id++ {
+		problems = append(problems, data.Problem{ID: id, Title: fmt.Sprintf("Problem %d", id), Difficulty: "Easy"})
+	}
+	problemsData := data.NewTestProblemsByCompany(map[string]map[string][]data.Problem{
+		"stripe": {"all": problems},
+	})
+	handler := NewHandler(problemsData, "!")
+	session := discordtest.New()
+	user := &discordgo.User{ID: "42", Username: "tester"}
+
+	commandsBefore := commandsTotal.Value("problems", "slash", "ok")
+	durationsBefore := commandDuration.Count("problems")
+	queriesBefore := companyQueries.Value("stripe")
+	handler.HandleSlashCommand(session.Session, session.SlashCommand("1", user, discordgo.ApplicationCommandInteractionData{
+		Name: "problems",
+		Options: []*discordgo.ApplicationCommandInteractionDataOption{
+			{Name: "company", Type: discordgo.ApplicationCommandOptionString, Value: "stripe"},
+		},
+	}))
+	if got := commandsTotal.Value("problems", "slash", "ok") - commandsBefore; got != 1 {
+		t.Errorf("expected 1 successful problems command, got %v", got)
+	}
+	if got := commandDuration.Count("problems") - durationsBefore; got != 1 {
+		t.Errorf("expected 1 latency observation, got %d", got)
+	}
+	if got := companyQueries.Value("stripe") - queriesBefore; got != 1 {
+		t.Errorf("expected 1 query for stripe, got %v", got)
+	}
+
+	events := session.Events()
+	msg := events[len(events)-1].Message
+
+	clicksBefore := paginatorClicks.Value("next")
+	click, err := session.ComponentClick(msg.ID, user, fmt.Sprintf("paginator:%s:next", msg.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	PaginatorManager.OnInteractionCreate(session.Session, click)
+	if got := paginatorClicks.Value("next") - clicksBefore; got != 1 {
+		t.Errorf("expected 1 next click, got %v", got)
+	}
+	session.Events()
+
+	// an idle paginator expires and its buttons say so
+	PaginatorManager.mu.Lock()
+	PaginatorManager.paginators[msg.ID].lastUsed = time.Now().Add(-2 * paginatorIdleTimeout)
+	PaginatorManager.mu.Unlock()
+
+	evictionsBefore := paginatorEvictions.Value()
+	click, err = session.ComponentClick(msg.ID, user, fmt.Sprintf("paginator:%s:prev", msg.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	PaginatorManager.OnInteractionCreate(session.Session, click)
+	if got := paginatorEvictions.Value() - evictionsBefore; got != 1 {
+		t.Errorf("expected 1 eviction, got %v", got)
+	}
+
+	events = session.Events()
+	if len(events) != 1 || !events[0].Ephemeral || events[0].Message.Content != i18n.Default().T("paginator.expired") {
+		t.Errorf("expected an ephemeral expiry notice, got %+v", events)
+	}
+}
+
+func TestManagerEvictsLeastRecentlyUsed(t *testing.T) {
+	m := &Manager{paginators: make(map[string]*paginatorState)}
+	now := time.Now()
+	for i := 0; i < maxPaginators; i++ {
+		id := fmt.Sprintf("msg-%d", i)
+		m.paginators[id] = &paginatorState{messageID: id, lastUsed: now.Add(time.Duration(i) * time.Second)}
+	}
+
+	m.register(&paginatorState{messageID: "new"})
+
+	if m.Len() != maxPaginators {
+		t.Errorf("expected %d paginators, got %d", maxPaginators, m.Len())
+	}
+	if _, ok := m.paginators["msg-0"]; ok {
+		t.Error("the least recently used paginator should have been evicted")
+	}
+	if _, ok := m.paginators["new"]; !ok {
+		t.Error("the new paginator should be registered")
+	}
+}
+
+func TestDiscordRoute(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/api/v9/channels/123456789/messages", "/channels/{id}/messages"},
+		{"/api/v9/channels/123456789/messages/987654321", "/channels/{id}/messages/{id}"},
+		{"/api/v9/interactions/123456789/aW50ZXJhY3Rpb24/callback", "/interactions/{id}/{token}/callback"},
+		{"/api/v9/webhooks/123456789/aW50ZXJhY3Rpb24/messages/@original", "/webhooks/{id}/{token}/messages/@original"},
+		{"/api/v9/applications/123456789/commands", "/applications/{id}/commands"},
+		{"/api/v9/gateway/bot", "/gateway/bot"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := discordRoute(tt.path); got != tt.expected {
+				t.Errorf("discordRoute(%q) = %q, want %q", tt.path, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestInstrumentSession(t *testing.T) {
+	session := discordtest.New()
+	InstrumentSession(session.Session)
+
+	before := discordAPIRequests.Value("POST", "/channels/{id}/messages", "200")
+	if _, err := session.ChannelMessageSend("123", "hello"); err != nil {
+		t.Fatal(err)
+	}
+	if got := discordAPIRequests.Value("POST", "/channels/{id}/messages", "200") - before; got != 1 {
+		t.Errorf("expected 1 counted request, got %v", got)
+	}
+}
+
+func TestSlashCommandRateLimited(t *testing.T) {
+	handler := NewHandler(createTestProblemsData(), "!")
+	limits, _ := ratelimit.ParseCommandLimits("query.user=1/1m")
+	handler.SetRateLimits(limits)
+	session := discordtest.New()
+	user := &discordgo.User{ID: "42", Username: "tester"}
+
+	company := func(id string) *discordgo.InteractionCreate {
+		return session.SlashCommand(id, user, discordgo.ApplicationCommandInteractionData{
+			Name: "company",
+			Options: []*discordgo.ApplicationCommandInteractionDataOption{
+				{Name: "name", Type: discordgo.ApplicationCommandOptionString, Value: "airbnb"},
+			},
+		})
+	}
+
+	handler.HandleSlashCommand(session.Session, company("1"))
+	if events := session.Events(); len(events) != 1 || events[0].Ephemeral {
+		t.Fatalf("the first command should be answered, got %+v", events)
+	}
+
+	limitedBefore := rateLimited.Value("query", "user")
+	handler.HandleSlashCommand(session.Session, company("2"))
+	events := session.Events()
+	if len(events) != 1 || !events[0].Ephemeral || !contains(events[0].Message.Content, "Slow down") {
+		t.Fatalf("the second command should get an ephemeral slow down, got %+v", events)
+	}
+	if got := rateLimited.Value("query", "user") - limitedBefore; got != 1 {
+		t.Errorf("expected 1 limited command, got %v", got)
+	}
+
+	// other users and other classes have their own buckets
+	handler.HandleSlashCommand(session.Session, session.SlashCommand("3", user, discordgo.ApplicationCommandInteractionData{Name: "help"}))
+	if events := session.Events(); len(events) == 0 || contains(events[0].Message.Content, "Slow down") {
+		t.Errorf("help shouldn't share the query bucket, got %+v", events)
+	}
+}
+
+func TestChannelLimitDoesNotChargeUser(t *testing.T) {
+	limits, _ := ratelimit.ParseCommandLimits("query.user=2/1m,query.channel=1/1m")
+	l := newCommandLimits(limits)
+
+	if scope, _ := l.allow("", ratelimit.ClassQuery, "42", "", "1"); scope != "" {
+		t.Fatalf("the first query should be allowed, got scope %q", scope)
+	}
+	if scope, _ := l.allow("", ratelimit.ClassQuery, "42", "", "1"); scope != "channel" {
+		t.Fatalf("the second query in the channel should be limited by the channel, got scope %q", scope)
+	}
+	// the refused query didn't use the user's second token
+	if scope, _ := l.allow("", ratelimit.ClassQuery, "42", "", "2"); scope != "" {
+		t.Errorf("the user should still have a token for another channel, got scope %q", scope)
+	}
+}
+
+func TestDuplicateRequestsDropped(t *testing.T) {
+	handler := NewHandler(createTestProblemsData(), "!")
+	session := discordtest.New()
+	handler.SetSession(session.Session)
+	handler.EnableChannel("1")
+	user := &discordgo.User{ID: "42", Username: "tester"}
+
+	m := session.MessageCreate("1", user, "!problems airbnb")
+	key := fmt.Sprintf("text:%s:%s:%s", user.ID, m.ChannelID, m.Content)
+
+	// an identical message is still being answered
+	if !handler.limiter.begin(key) {
+		t.Fatal("nothing should be in flight yet")
+	}
+	handler.HandleMessage(session.Session, m)
+	if events := session.Events(); len(events) != 0 {
+		t.Errorf("the duplicate should be dropped, got %+v", events)
+	}
+
+	handler.limiter.done(key)
+	handler.HandleMessage(session.Session, m)
+	if events := session.Events(); len(events) == 0 {
+		t.Error("the message should be answered once the first one is done")
+	}
+}
+
+func TestSlowDownMessage(t *testing.T) {
+	tests := []struct {
+		wait     time.Duration
+		expected string
+	}{
+		{0, "Slow down! You can try again in 1 second."},
+		{1500 * time.Millisecond, "Slow down! You can try again in 2 seconds."},
+		{30 * time.Second, "Slow down! You can try again in 30 seconds."},
+	}
+
+	for _, tt := range tests {
+		if got := slowDownMessage(i18n.Default(), tt.wait); got != tt.expected {
+			t.Errorf("slowDownMessage(%s) = %q, want %q", tt.wait, got, tt.expected)
+		}
+	}
+}
+
+func TestReconfigure(t *testing.T) {
+	defaults := config.Defaults().Bot
+	t.Cleanup(func() { Configure(defaults) })
+
+	next := defaults
+	next.Channels = []string{"111111111111111111"}
+	next.RateLimits = "query.user=1/1m"
+	next.ProblemsPerPage = 5
+	next.PaginationThreshold = 5
+
+	handler := NewHandler(createTestProblemsData(), "!")
+	handler.EnableChannel("222222222222222222")
+	before := handler.currentState()
+	if err := handler.Reconfigure("?", next); err != nil {
+		t.Fatalf("Reconfigure() error = %v", err)
+	}
+
+	// a command that started before the reload keeps seeing the old state
+	if before.prefix != "!" || !before.channels[defaults.Channels[0]] || before.channels["111111111111111111"] || before.settings.RateLimits != defaults.RateLimits {
+		t.Error("the state a command started with shouldn't change under it")
+	}
+
+	if handler.currentPrefix() != "?" {
+		t.Errorf("prefix = %q, want ?", handler.currentPrefix())
+	}
+	if handler.isChannelEnabled(defaults.Channels[0]) || !handler.isChannelEnabled("111111111111111111") {
+		t.Error("channels dropped from the configuration should be disabled and new ones enabled")
+	}
+	if !handler.isChannelEnabled("222222222222222222") {
+		t.Error("channels enabled with !init should stay enabled")
+	}
+	if scope, _ := handler.currentState().limits.allow("", ratelimit.ClassQuery, "42", "", "1"); scope != "" {
+		t.Fatal("the first query should be allowed")
+	}
+	if scope, _ := handler.currentState().limits.allow("", ratelimit.ClassQuery, "42", "", "1"); scope != "user" {
+		t.Errorf("the reloaded limit should apply, got scope %q", scope)
+	}
+	if !shouldUsePagination(6) {
+		t.Error("the reloaded pagination threshold should apply")
+	}
+
+	bad := next
+	bad.RateLimits = "query.user=fast"
+	bad.ProblemsPerPage = 7
+	if err := handler.Reconfigure("$", bad); err == nil {
+		t.Fatal("Reconfigure() should reject bad rate limits")
+	}
+	if handler.currentPrefix() != "?" || currentSettings().ProblemsPerPage != 5 {
+		t.Error("a rejected reload shouldn't change anything")
+	}
+}
+
+func TestReloadCommand(t *testing.T) {
+	handler := NewHandler(createTestProblemsData(), "!")
+	session := discordtest.New()
+	handler.SetSession(session.Session)
+	admin := &discordgo.User{ID: AdminUserID(), Username: "admin"}
+	user := &discordgo.User{ID: "42", Username: "tester"}
+
+	var reloads int
+	var reloadErr error
+	handler.SetConfigReloader(func() ([]config.Change, error) {
+		reloads++
+		return []config.Change{{Key: "discord.prefix", Old: `"!"`, New: `"?"`}}, reloadErr
+	})
+
+	tests := []struct {
+		name    string
+		author  *discordgo.User
+		content string
+		err     error
+		want    string
+		reloads int
+	}{
+		{"not the admin", user, "!reload config", nil, "Only the owner", 0},
+		{"missing argument", admin, "!reload", nil, "Usage: `!reload config`", 0},
+		{"reloaded", admin, "!reload config", nil, "1 changed:\n• `discord.prefix`: \"!\" → \"?\"", 1},
+		{"invalid", admin, "!reload config", errors.New("invalid configuration"), "running settings are unchanged", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reloads, reloadErr = 0, tt.err
+			handler.HandleMessage(session.Session, session.MessageCreate(defaultChannel(), tt.author, tt.content))
+			events := session.Events()
+			if len(events) != 1 || !contains(events[0].Message.Content, tt.want) {
+				t.Errorf("expected a reply containing %q, got %+v", tt.want, events)
+			}
+			if reloads != tt.reloads {
+				t.Errorf("reloaded %d times, want %d", reloads, tt.reloads)
+			}
+		})
+	}
+}
+
+// defaultChannel is a channel enabled by the default configuration
+func defaultChannel() string {
+	return config.Defaults().Bot.Channels[0]
+}
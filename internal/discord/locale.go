@@ -0,0 +1,77 @@
+package discord
+
+import (
+	"github.com/bwmarrin/discordgo"
+	"github.com/whotypes/leetbot/internal/i18n"
+)
+
+// interactionPrinter picks the language of a reply to an interaction: the
+// guild's configured locale, then the user's, then the guild's, then
+// bot.locale
+func interactionPrinter(i *discordgo.Interaction) *i18n.Printer {
+	settings := currentSettings()
+	var guildLocale string
+	if i.GuildLocale != nil {
+		guildLocale = string(*i.GuildLocale)
+	}
+	return i18n.For(settings.GuildLocales[i.GuildID], string(i.Locale), guildLocale, settings.Locale)
+}
+
+// messagePrinter picks the language of a reply to a text command. Messages
+// don't say the author's locale, so it's the guild's configured locale, then
+// the guild's preferred one if it's cached, then bot.locale.
+func messagePrinter(s *discordgo.Session, m *discordgo.MessageCreate) *i18n.Printer {
+	settings := currentSettings()
+	var guildLocale string
+	if s != nil && s.State != nil && m.GuildID != "" {
+		if guild, err := s.State.Guild(m.GuildID); err == nil {
+			guildLocale = guild.PreferredLocale
+		}
+	}
+	return i18n.For(settings.GuildLocales[m.GuildID], guildLocale, settings.Locale)
+}
+
+// localizations returns the translations of a message for command and
+// option names and descriptions, nil when there are none
+func localizations(key string) map[discordgo.Locale]string {
+	translations := i18n.Translations(key)
+	if len(translations) == 0 {
+		return nil
+	}
+	localized := make(map[discordgo.Locale]string, len(translations))
+	for locale, message := range translations {
+		localized[discordgo.Locale(locale)] = message
+	}
+	return localized
+}
+
+// localizedChoice is a slash command choice named by a message
+func localizedChoice(key string, value any) *discordgo.ApplicationCommandOptionChoice {
+	return &discordgo.ApplicationCommandOptionChoice{
+		Name:              i18n.Default().T(key),
+		NameLocalizations: localizations(key),
+		Value:             value,
+	}
+}
+
+// localizeCommand fills in the description and the translated names and
+// descriptions of a command and its options. They come from the messages
+// command.<name>.name and .description, and option.<name>.name and
+// .description.
+func localizeCommand(cmd *discordgo.ApplicationCommand) {
+	name := localizations("command." + cmd.Name + ".name")
+	description := localizations("command." + cmd.Name + ".description")
+	cmd.Description = i18n.Default().T("command." + cmd.Name + ".description")
+	cmd.NameLocalizations = &name
+	cmd.DescriptionLocalizations = &description
+	localizeOptions(cmd.Options)
+}
+
+func localizeOptions(options []*discordgo.ApplicationCommandOption) {
+	for _, opt := range options {
+		opt.Description = i18n.Default().T("option." + opt.Name + ".description")
+		opt.NameLocalizations = localizations("option." + opt.Name + ".name")
+		opt.DescriptionLocalizations = localizations("option." + opt.Name + ".description")
+		localizeOptions(opt.Options)
+	}
+}
@@ -0,0 +1,157 @@
+package discord
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/whotypes/leetbot/internal/config"
+	"github.com/whotypes/leetbot/internal/discord/discordtest"
+	"github.com/whotypes/leetbot/internal/i18n"
+)
+
+// commandName is what discord accepts as the name of a slash command or option
+var commandName = regexp.MustCompile(`^[-_\p{L}\p{N}]{1,32}$`)
+
+func TestSlashCommandsAreLocalized(t *testing.T) {
+	locales := i18n.Locales()[1:]
+	if len(locales) == 0 {
+		t.Fatal("no translations to check")
+	}
+
+	checkName := func(what, locale, name string) {
+		if !commandName.MatchString(name) || strings.ToLower(name) != name {
+			t.Errorf("%s has the invalid %s name %q", what, locale, name)
+		}
+	}
+	checkDescription := func(what, locale, description string) {
+		if description == "" || len([]rune(description)) > 100 {
+			t.Errorf("%s has the %s description %q, want 1 to 100 characters", what, locale, description)
+		}
+	}
+
+	var checkOptions func(what string, options []*discordgo.ApplicationCommandOption)
+	checkOptions = func(what string, options []*discordgo.ApplicationCommandOption) {
+		for _, locale := range locales {
+			seen := make(map[string]bool)
+			for _, opt := range options {
+				name := opt.NameLocalizations[discordgo.Locale(locale)]
+				if seen[name] {
+					t.Errorf("%s has two options named %q in %s", what, name, locale)
+				}
+				seen[name] = true
+			}
+		}
+		for _, opt := range options {
+			optWhat := what + " " + opt.Name
+			checkDescription(optWhat, i18n.DefaultLocale, opt.Description)
+			for _, locale := range locales {
+				checkName(optWhat, locale, opt.NameLocalizations[discordgo.Locale(locale)])
+				checkDescription(optWhat, locale, opt.DescriptionLocalizations[discordgo.Locale(locale)])
+			}
+			for _, choice := range opt.Choices {
+				// names like CSV read the same in every language
+				if choice.NameLocalizations == nil {
+					continue
+				}
+				for _, locale := range locales {
+					if name := choice.NameLocalizations[discordgo.Locale(locale)]; name == "" || len([]rune(name)) > 100 {
+						t.Errorf("%s choice %v has the %s name %q", optWhat, choice.Value, locale, name)
+					}
+				}
+			}
+			checkOptions(optWhat, opt.Options)
+		}
+	}
+
+	for _, cmd := range GetSlashCommands(createTestProblemsData()) {
+		what := "/" + cmd.Name
+		checkDescription(what, i18n.DefaultLocale, cmd.Description)
+		if cmd.NameLocalizations == nil || cmd.DescriptionLocalizations == nil {
+			t.Fatalf("%s has no localizations", what)
+		}
+		for _, locale := range locales {
+			checkName(what, locale, (*cmd.NameLocalizations)[discordgo.Locale(locale)])
+			checkDescription(what, locale, (*cmd.DescriptionLocalizations)[discordgo.Locale(locale)])
+		}
+		checkOptions(what, cmd.Options)
+	}
+}
+
+func TestPrinterSelection(t *testing.T) {
+	defaults := config.Defaults().Bot
+	t.Cleanup(func() { Configure(defaults) })
+
+	settings := defaults
+	settings.Locale = "de"
+	settings.GuildLocales = map[string]string{"111111111111111111": "pt-BR"}
+	Configure(settings)
+
+	french := discordgo.Locale("fr")
+	tests := []struct {
+		name        string
+		guildID     string
+		locale      discordgo.Locale
+		guildLocale *discordgo.Locale
+		want        string
+	}{
+		{"configured guild", "111111111111111111", discordgo.SpanishES, &french, "pt-BR"},
+		{"user locale", "222222222222222222", discordgo.SpanishES, &french, "es-ES"},
+		{"guild locale", "222222222222222222", discordgo.Japanese, &french, "fr"},
+		{"bot locale", "222222222222222222", discordgo.Japanese, nil, "de"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := &discordgo.Interaction{GuildID: tt.guildID, Locale: tt.locale, GuildLocale: tt.guildLocale}
+			if got := interactionPrinter(i).Locale(); got != tt.want {
+				t.Errorf("interactionPrinter() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	session := discordtest.New()
+	if err := session.State.GuildAdd(&discordgo.Guild{ID: "333333333333333333", PreferredLocale: "fr"}); err != nil {
+		t.Fatal(err)
+	}
+	messages := []struct {
+		guildID string
+		want    string
+	}{
+		{"111111111111111111", "pt-BR"},
+		{"333333333333333333", "fr"},
+		{"", "de"},
+	}
+	for _, tt := range messages {
+		m := &discordgo.MessageCreate{Message: &discordgo.Message{GuildID: tt.guildID}}
+		if got := messagePrinter(session.Session, m).Locale(); got != tt.want {
+			t.Errorf("messagePrinter() in guild %q = %s, want %s", tt.guildID, got, tt.want)
+		}
+	}
+}
+
+func TestLocalizedReply(t *testing.T) {
+	handler := NewHandler(createTestProblemsData(), "!")
+	session := discordtest.New()
+	handler.SetSession(session.Session)
+	handler.EnableChannel("1")
+	user := &discordgo.User{ID: "42", Username: "tester"}
+
+	i := session.SlashCommand("1", user, discordgo.ApplicationCommandInteractionData{
+		Name: "problems",
+		Options: []*discordgo.ApplicationCommandInteractionDataOption{
+			{Name: "company", Type: discordgo.ApplicationCommandOptionString, Value: "airbnb"},
+			{Name: "timeframe", Type: discordgo.ApplicationCommandOptionString, Value: "thirty-days"},
+		},
+	})
+	i.Locale = discordgo.French
+	handler.HandleSlashCommand(session.Session, i)
+
+	events := session.Events()
+	if len(events) != 1 || len(events[0].Message.Embeds) != 1 {
+		t.Fatalf("expected one embed, got %+v", events)
+	}
+	if title := events[0].Message.Embeds[0].Title; title != "Problèmes les plus populaires chez Airbnb (30 derniers jours)" {
+		t.Errorf("Title = %q, want it in French", title)
+	}
+}
@@ -0,0 +1,63 @@
+package discord
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/whotypes/leetbot/internal/logging"
+)
+
+// interactionUserID returns the ID of the user who triggered an interaction,
+// whether it came from a guild or a DM
+func interactionUserID(i *discordgo.Interaction) string {
+	if i.Member != nil && i.Member.User != nil {
+		return i.Member.User.ID
+	}
+	if i.User != nil {
+		return i.User.ID
+	}
+	return ""
+}
+
+// interactionLogger returns a logger carrying the guild, channel, user and
+// command of an interaction
+func interactionLogger(i *discordgo.Interaction) *slog.Logger {
+	return slog.Default().With(
+		logging.KeyGuild, i.GuildID,
+		logging.KeyChannel, i.ChannelID,
+		logging.KeyUser, interactionUserID(i),
+		logging.KeyCommand, interactionCommand(i),
+	)
+}
+
+// interactionCommand names what an interaction ran: the slash command, or
+// "paginator" for paginator buttons
+func interactionCommand(i *discordgo.Interaction) string {
+	switch d := i.Data.(type) {
+	case discordgo.ApplicationCommandInteractionData:
+		return d.Name
+	case discordgo.MessageComponentInteractionData:
+		if strings.HasPrefix(d.CustomID, "paginator:") {
+			return "paginator"
+		}
+		return d.CustomID
+	}
+	return ""
+}
+
+// messageLogger returns a logger carrying the guild, channel and user of a
+// text command
+func messageLogger(m *discordgo.MessageCreate, command string) *slog.Logger {
+	var userID string
+	if m.Author != nil {
+		userID = m.Author.ID
+	}
+
+	return slog.Default().With(
+		logging.KeyGuild, m.GuildID,
+		logging.KeyChannel, m.ChannelID,
+		logging.KeyUser, userID,
+		logging.KeyCommand, command,
+	)
+}
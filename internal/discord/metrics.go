@@ -0,0 +1,135 @@
+package discord
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/whotypes/leetbot/internal/logging"
+	"github.com/whotypes/leetbot/internal/metrics"
+)
+
+var (
+	commandsTotal = metrics.NewCounterVec("leetbot_commands_total",
+		"Commands handled, by command, kind (slash or text) and outcome (ok or error).",
+		"command", "kind", "outcome")
+	commandDuration = metrics.NewHistogramVec("leetbot_command_duration_seconds",
+		"Time to handle a slash command.",
+		metrics.DefaultBuckets, "command")
+	companyQueries = metrics.NewCounterVec("leetbot_company_queries_total",
+		"Problem lists and overviews shown, by company.",
+		"company")
+	failedInteractions = metrics.NewCounterVec("leetbot_failed_interactions_total",
+		"Interactions and text commands the bot failed to answer, by command.",
+		"command")
+	paginatorClicks = metrics.NewCounterVec("leetbot_paginator_clicks_total",
+		"Paginator button clicks, by action.",
+		"action")
+	paginatorEvictions = metrics.NewCounter("leetbot_paginator_evictions_total",
+		"Paginators forgotten because they expired or too many were open.")
+	rateLimited = metrics.NewCounterVec("leetbot_rate_limited_total",
+		"Commands and clicks dropped by the rate limiter, by command class and scope (user, channel or duplicate).",
+		"class", "scope")
+	discordAPIRequests = metrics.NewCounterVec("leetbot_discord_api_requests_total",
+		"Discord REST API requests, by method, route and status code.",
+		"method", "route", "status")
+	discordAPIErrors = metrics.NewCounterVec("leetbot_discord_api_errors_total",
+		"Discord REST API requests that failed or returned an error status, by route and status.",
+		"route", "status")
+)
+
+func init() {
+	metrics.NewGaugeFunc("leetbot_paginators_active", "Paginators that can still be clicked.", func() float64 {
+		return float64(PaginatorManager.Len())
+	})
+}
+
+// failures remembers which in-flight interactions and messages hit an error,
+// keyed by interaction or message ID, so the command gets the right outcome
+var failures sync.Map
+
+// interactionFailed logs an error answering an interaction and counts it
+func interactionFailed(i *discordgo.Interaction, msg string, err error) {
+	interactionLogger(i).Error(msg, logging.Err(err))
+	failedInteractions.Inc(interactionCommand(i))
+	failures.Store(i.ID, true)
+}
+
+// messageFailed logs an error answering a text command and counts it
+func messageFailed(m *discordgo.MessageCreate, command, msg string, err error) {
+	messageLogger(m, command).Error(msg, logging.Err(err))
+	failedInteractions.Inc(command)
+	failures.Store(m.ID, true)
+}
+
+// takeOutcome returns the outcome of a finished interaction or message
+func takeOutcome(id string) string {
+	if _, failed := failures.LoadAndDelete(id); failed {
+		return "error"
+	}
+	return "ok"
+}
+
+// InstrumentSession counts the session's Discord REST API calls by route and
+// status. Call it once, before the session is used.
+func InstrumentSession(s *discordgo.Session) {
+	transport := s.Client.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	s.Client.Transport = &instrumentedTransport{next: transport}
+}
+
+type instrumentedTransport struct {
+	next http.RoundTripper
+}
+
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	route := discordRoute(req.URL.Path)
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		discordAPIRequests.Inc(req.Method, route, "error")
+		discordAPIErrors.Inc(route, "error")
+		return nil, err
+	}
+
+	status := strconv.Itoa(resp.StatusCode)
+	discordAPIRequests.Inc(req.Method, route, status)
+	if resp.StatusCode >= http.StatusBadRequest {
+		discordAPIErrors.Inc(route, status)
+	}
+	return resp, nil
+}
+
+// discordRoute turns an API path into a low-cardinality route by replacing
+// IDs and interaction tokens, e.g. /api/v9/channels/123/messages becomes
+// /channels/{id}/messages
+func discordRoute(path string) string {
+	path = strings.TrimPrefix(path, "/api/v"+discordgo.APIVersion)
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	for i, part := range parts {
+		switch {
+		case isSnowflake(part):
+			parts[i] = "{id}"
+		case i == 2 && (parts[0] == "interactions" || parts[0] == "webhooks"):
+			// interactions/{id}/{token} and webhooks/{id}/{token}
+			parts[i] = "{token}"
+		}
+	}
+	return "/" + strings.Join(parts, "/")
+}
+
+func isSnowflake(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
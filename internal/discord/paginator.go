@@ -2,17 +2,20 @@ package discord
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"sync"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
-	"github.com/whotypes/leetbot/internal/data"
+	"github.com/whotypes/leetbot/internal/logging"
 )
 
 const (
-	problemsPerPage     = 10
-	paginationThreshold = 10
+	// paginators idle for longer are forgotten, their buttons answer with
+	// the paginator.expired message
+	paginatorIdleTimeout = 30 * time.Minute
+	// maxPaginators caps memory, the least recently used paginator goes first
+	maxPaginators = 500
 )
 
 type Paginator struct {
@@ -26,6 +29,7 @@ type paginatorState struct {
 	messageID string
 	channelID string
 	currentPage int
+	lastUsed    time.Time
 }
 
 type Manager struct {
@@ -44,54 +48,7 @@ func init() {
 }
 
 func shouldUsePagination(problemCount int) bool {
-	return problemCount > paginationThreshold
-}
-
-func createProblemsPaginator(company, timeframe string, problems []data.Problem) *Paginator {
-	totalPages := (len(problems) + problemsPerPage - 1) / problemsPerPage
-
-	return &Paginator{
-		PageFunc: func(page int, embed *discordgo.MessageEmbed) {
-			if page < 0 {
-				page = 0
-			}
-			if page >= totalPages && totalPages > 0 {
-				page = totalPages - 1
-			}
-
-			start := page * problemsPerPage
-			end := start + problemsPerPage
-			if end > len(problems) {
-				end = len(problems)
-			}
-
-			pageProblems := problems[start:end]
-
-			embed.Title = fmt.Sprintf("Most Popular Problems for %s (%s)",
-				formatCompanyName(company),
-				formatTimeframeDisplay(timeframe))
-			embed.Color = 0x5865F2
-			embed.Footer = &discordgo.MessageEmbedFooter{
-				Text: fmt.Sprintf("Page %d/%d • Total: %d problems", page+1, totalPages, len(problems)),
-			}
-			embed.Timestamp = time.Now().Format(time.RFC3339)
-
-			description := ""
-			for i, problem := range pageProblems {
-				problemNumber := start + i + 1
-				difficultyIndicator := getDifficultyIndicator(problem.Difficulty)
-				description += fmt.Sprintf("**%d.** %s [%s](<%s>) `%.0f%%`\n",
-					problemNumber,
-					difficultyIndicator,
-					problem.Title,
-					problem.URL,
-					problem.Frequency)
-			}
-
-			embed.Description = description
-		},
-		MaxPages: totalPages,
-	}
+	return problemCount > currentSettings().PaginationThreshold
 }
 
 func (m *Manager) createButtons(messageID string, page, maxPages int) []discordgo.MessageComponent {
@@ -128,14 +85,67 @@ func (m *Manager) createButtons(messageID string, page, maxPages int) []discordg
 	return components
 }
 
+// register starts tracking a paginator, evicting idle ones first
+func (m *Manager) register(state *paginatorState) {
+	now := time.Now()
+	state.lastUsed = now
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.evictLocked(now)
+	m.paginators[state.messageID] = state
+}
+
+// evictLocked forgets paginators idle since before the timeout, then the
+// least recently used ones until there is room for one more
+func (m *Manager) evictLocked(now time.Time) {
+	var oldestID string
+	var oldest time.Time
+	for id, state := range m.paginators {
+		if now.Sub(state.lastUsed) > paginatorIdleTimeout {
+			delete(m.paginators, id)
+			paginatorEvictions.Inc()
+			continue
+		}
+		if oldestID == "" || state.lastUsed.Before(oldest) {
+			oldestID, oldest = id, state.lastUsed
+		}
+	}
+
+	for len(m.paginators) >= maxPaginators {
+		delete(m.paginators, oldestID)
+		paginatorEvictions.Inc()
+
+		oldestID = ""
+		for id, state := range m.paginators {
+			if oldestID == "" || state.lastUsed.Before(oldest) {
+				oldestID, oldest = id, state.lastUsed
+			}
+		}
+	}
+}
+
+// Len returns how many paginators can still be clicked
+func (m *Manager) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.paginators)
+}
+
+// paginatorLogger tags log events with the paginator component
+func paginatorLogger(logger *slog.Logger) *slog.Logger {
+	return logger.With(logging.KeyComponent, "paginator")
+}
+
 func (m *Manager) updateMessage(s *discordgo.Session, state *paginatorState) error {
-	log.Printf("[PAGINATOR] Updating message %s to page %d/%d", state.messageID, state.currentPage+1, state.paginator.MaxPages)
+	logger := paginatorLogger(slog.Default()).With(logging.KeyChannel, state.channelID, "message", state.messageID)
+	logger.Debug("updating page", "page", state.currentPage+1, "pages", state.paginator.MaxPages)
 
 	embed := &discordgo.MessageEmbed{}
 
 	defer func() {
 		if r := recover(); r != nil {
-			log.Printf("[PAGINATOR] PANIC in PageFunc for message %s: %v", state.messageID, r)
+			logger.Error("page function panicked", "panic", r)
 		}
 	}()
 
@@ -152,35 +162,28 @@ func (m *Manager) updateMessage(s *discordgo.Session, state *paginatorState) err
 	})
 
 	if err != nil {
-		log.Printf("[PAGINATOR] ERROR updating message %s: %v (channel: %s, page: %d/%d)",
-			state.messageID, err, state.channelID, state.currentPage+1, state.paginator.MaxPages)
+		logger.Error("updating page failed", "page", state.currentPage+1, "pages", state.paginator.MaxPages, logging.Err(err))
 		return fmt.Errorf("failed to update message %s: %w", state.messageID, err)
 	}
 
-	log.Printf("[PAGINATOR] Successfully updated message %s to page %d/%d", state.messageID, state.currentPage+1, state.paginator.MaxPages)
 	return nil
 }
 
 func (m *Manager) CreateInteraction(s *discordgo.Session, i *discordgo.Interaction, pg *Paginator, ephemeral bool) error {
-	log.Printf("[PAGINATOR] Creating interaction paginator (maxPages: %d, ephemeral: %v)", pg.MaxPages, ephemeral)
+	logger := paginatorLogger(interactionLogger(i))
+	logger.Debug("creating paginator", "pages", pg.MaxPages, "ephemeral", ephemeral)
 
 	embed := &discordgo.MessageEmbed{}
 
 	defer func() {
 		if r := recover(); r != nil {
-			log.Printf("[PAGINATOR] PANIC in PageFunc during CreateInteraction: %v", r)
+			logger.Error("page function panicked", "panic", r)
 		}
 	}()
 
 	pg.PageFunc(0, embed)
 
-	var userID string
-	if i.Member != nil {
-		userID = i.Member.User.ID
-	} else if i.User != nil {
-		userID = i.User.ID
-	}
-	log.Printf("[PAGINATOR] User ID: %s", userID)
+	userID := interactionUserID(i)
 
 	var flags discordgo.MessageFlags
 	if ephemeral {
@@ -199,16 +202,15 @@ func (m *Manager) CreateInteraction(s *discordgo.Session, i *discordgo.Interacti
 		},
 	})
 	if err != nil {
-		log.Printf("[PAGINATOR] ERROR responding to interaction: %v", err)
+		logger.Error("responding to interaction failed", logging.Err(err))
 		return fmt.Errorf("failed to respond to interaction: %w", err)
 	}
 
 	msg, err := s.InteractionResponse(i)
 	if err != nil {
-		log.Printf("[PAGINATOR] ERROR getting interaction response: %v", err)
+		logger.Error("getting interaction response failed", logging.Err(err))
 		return fmt.Errorf("failed to get interaction response: %w", err)
 	}
-	log.Printf("[PAGINATOR] Created message %s in channel %s", msg.ID, msg.ChannelID)
 
 	components = m.createButtons(msg.ID, 0, pg.MaxPages)
 	_, err = s.ChannelMessageEditComplex(&discordgo.MessageEdit{
@@ -217,33 +219,32 @@ func (m *Manager) CreateInteraction(s *discordgo.Session, i *discordgo.Interacti
 		Components: &components,
 	})
 	if err != nil {
-		log.Printf("[PAGINATOR] ERROR updating components for message %s: %v", msg.ID, err)
+		logger.Error("updating paginator buttons failed", "message", msg.ID, logging.Err(err))
 		return fmt.Errorf("failed to update message components: %w", err)
 	}
 
-	m.mu.Lock()
-	m.paginators[msg.ID] = &paginatorState{
+	m.register(&paginatorState{
 		paginator:   pg,
 		userID:      userID,
 		messageID:   msg.ID,
 		channelID:   msg.ChannelID,
 		currentPage: 0,
-	}
-	m.mu.Unlock()
+	})
 
-	log.Printf("[PAGINATOR] Registered paginator for message %s (user: %s, pages: %d)", msg.ID, userID, pg.MaxPages)
+	logger.Debug("registered paginator", "message", msg.ID, "pages", pg.MaxPages)
 
 	return nil
 }
 
 func (m *Manager) CreateMessage(s *discordgo.Session, channelID string, pg *Paginator) error {
-	log.Printf("[PAGINATOR] Creating message paginator in channel %s (maxPages: %d)", channelID, pg.MaxPages)
+	logger := paginatorLogger(slog.Default()).With(logging.KeyChannel, channelID)
+	logger.Debug("creating paginator", "pages", pg.MaxPages)
 
 	embed := &discordgo.MessageEmbed{}
 
 	defer func() {
 		if r := recover(); r != nil {
-			log.Printf("[PAGINATOR] PANIC in PageFunc during CreateMessage: %v", r)
+			logger.Error("page function panicked", "panic", r)
 		}
 	}()
 
@@ -257,10 +258,9 @@ func (m *Manager) CreateMessage(s *discordgo.Session, channelID string, pg *Pagi
 		Components: components,
 	})
 	if err != nil {
-		log.Printf("[PAGINATOR] ERROR sending message to channel %s: %v", channelID, err)
+		logger.Error("sending paginator failed", logging.Err(err))
 		return fmt.Errorf("failed to send message: %w", err)
 	}
-	log.Printf("[PAGINATOR] Created message %s in channel %s", msg.ID, channelID)
 
 	components = m.createButtons(msg.ID, 0, pg.MaxPages)
 	_, err = s.ChannelMessageEditComplex(&discordgo.MessageEdit{
@@ -269,21 +269,19 @@ func (m *Manager) CreateMessage(s *discordgo.Session, channelID string, pg *Pagi
 		Components: &components,
 	})
 	if err != nil {
-		log.Printf("[PAGINATOR] ERROR updating components for message %s: %v", msg.ID, err)
+		logger.Error("updating paginator buttons failed", "message", msg.ID, logging.Err(err))
 		return fmt.Errorf("failed to update message components: %w", err)
 	}
 
-	m.mu.Lock()
-	m.paginators[msg.ID] = &paginatorState{
+	m.register(&paginatorState{
 		paginator:   pg,
 		userID:      "",
 		messageID:   msg.ID,
 		channelID:   channelID,
 		currentPage: 0,
-	}
-	m.mu.Unlock()
+	})
 
-	log.Printf("[PAGINATOR] Registered paginator for message %s (pages: %d)", msg.ID, pg.MaxPages)
+	logger.Debug("registered paginator", "message", msg.ID, "pages", pg.MaxPages)
 
 	return nil
 }
@@ -295,45 +293,41 @@ func (m *Manager) OnInteractionCreate(s *discordgo.Session, i *discordgo.Interac
 
 	customID := i.MessageComponentData().CustomID
 
-	var username string
-	if i.Member != nil {
-		username = i.Member.User.Username
-	} else if i.User != nil {
-		username = i.User.Username
-	}
-
-	log.Printf("[PAGINATOR] Received interaction: customID=%s, messageID=%s, username=%s", customID, i.Message.ID, username)
-
 	if len(customID) < 10 || customID[:10] != "paginator:" {
-		log.Printf("[PAGINATOR] Ignoring non-paginator interaction: %s", customID)
 		return
 	}
 
-	m.mu.RLock()
-	state, exists := m.paginators[i.Message.ID]
-	m.mu.RUnlock()
+	start := time.Now()
+	messageID := i.Message.ID
+	logger := paginatorLogger(interactionLogger(i.Interaction)).With("message", messageID)
+
+	m.mu.Lock()
+	state, exists := m.paginators[messageID]
+	if exists && start.Sub(state.lastUsed) > paginatorIdleTimeout {
+		delete(m.paginators, messageID)
+		paginatorEvictions.Inc()
+		exists = false
+	}
+	m.mu.Unlock()
 
 	if !exists {
-		log.Printf("[PAGINATOR] WARNING: No paginator state found for message %s (customID: %s). Available paginators: %d",
-			i.Message.ID, customID, len(m.paginators))
-		m.mu.RLock()
-		for msgID := range m.paginators {
-			log.Printf("[PAGINATOR]   - Registered message ID: %s", msgID)
+		// the bot restarted or the paginator was evicted
+		paginatorClicks.Inc("expired")
+		logger.Info("click on an expired paginator")
+		err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: interactionPrinter(i.Interaction).T("paginator.expired"),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		if err != nil {
+			interactionFailed(i.Interaction, "responding to expired paginator failed", err)
+			failures.Delete(i.ID)
 		}
-		m.mu.RUnlock()
 		return
 	}
 
-	var userID string
-	if i.Member != nil {
-		userID = i.Member.User.ID
-	} else if i.User != nil {
-		userID = i.User.ID
-	}
-	log.Printf("[PAGINATOR] Processing interaction: messageID=%s, userID=%s, username=%s, stateUserID=%s, currentPage=%d/%d",
-		i.Message.ID, userID, username, state.userID, state.currentPage+1, state.paginator.MaxPages)
-
-	messageID := i.Message.ID
 	var newPage int
 	var action string
 	var currentPage int
@@ -363,47 +357,31 @@ func (m *Manager) OnInteractionCreate(s *discordgo.Session, i *discordgo.Interac
 		newPage = maxPages - 1
 		action = "last"
 	default:
-		log.Printf("[PAGINATOR] WARNING: Unknown customID format: %s (expected format: paginator:%s:{action})", customID, messageID)
-		log.Printf("[PAGINATOR]   Expected IDs: first=%s, back=%s, next=%s, last=%s",
-			fmt.Sprintf("paginator:%s:first", messageID),
-			fmt.Sprintf("paginator:%s:back", messageID),
-			fmt.Sprintf("paginator:%s:next", messageID),
-			fmt.Sprintf("paginator:%s:last", messageID))
+		logger.Warn("unknown paginator button")
 		return
 	}
 
-	log.Printf("[PAGINATOR] Action: %s, moving from page %d to %d", action, currentPage+1, newPage+1)
-
 	m.mu.Lock()
 	state.currentPage = newPage
+	state.lastUsed = start
 	m.mu.Unlock()
+	paginatorClicks.Inc(action)
 
 	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseDeferredMessageUpdate,
 	})
 	if err != nil {
-		log.Printf("[PAGINATOR] ERROR responding to interaction (DeferredMessageUpdate): %v (messageID: %s, customID: %s)",
-			err, messageID, customID)
-		log.Printf("[PAGINATOR]   This error will cause 'This interaction failed' message")
-		log.Printf("[PAGINATOR]   Interaction details: Type=%d, Token=%s", i.Type, i.Token)
+		// the user sees "This interaction failed"
+		logger.Error("deferring page update failed", "action", action, logging.Err(err))
+		failedInteractions.Inc("paginator")
 		return
 	}
-	log.Printf("[PAGINATOR] Successfully deferred message update for message %s", messageID)
 
 	err = m.updateMessage(s, state)
 	if err != nil {
-		log.Printf("[PAGINATOR] ERROR updating message after deferred response: %v (messageID: %s)", err, messageID)
+		failedInteractions.Inc("paginator")
+		return
 	}
-}
-
-func sendPaginatedProblems(s *discordgo.Session, i *discordgo.InteractionCreate, company, timeframe string, problems []data.Problem) error {
-	pg := createProblemsPaginator(company, timeframe, problems)
-
-	return PaginatorManager.CreateInteraction(s, i.Interaction, pg, false)
-}
-
-func sendPaginatedProblemsMessage(s *discordgo.Session, channelID, company, timeframe string, problems []data.Problem) error {
-	pg := createProblemsPaginator(company, timeframe, problems)
 
-	return PaginatorManager.CreateMessage(s, channelID, pg)
+	logger.Info("paginator click handled", "action", action, "page", newPage+1, "pages", maxPages, logging.Latency(start))
 }
@@ -0,0 +1,218 @@
+package discord
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/whotypes/leetbot/internal/i18n"
+	"github.com/whotypes/leetbot/internal/logging"
+	"github.com/whotypes/leetbot/internal/ratelimit"
+)
+
+// commandClass returns the class a slash or text command belongs to
+func commandClass(command string) ratelimit.CommandClass {
+	switch command {
+	case "problems", "company", "export", "search", "dataset":
+		return ratelimit.ClassQuery
+	case "paginator":
+		return ratelimit.ClassPaginator
+	default:
+		return ratelimit.ClassBasic
+	}
+}
+
+// commandLimits rate limits commands by user and by channel. A reload
+// replaces it with empty buckets.
+type commandLimits struct {
+	user    map[ratelimit.CommandClass]*ratelimit.Limiter
+	channel map[ratelimit.CommandClass]*ratelimit.Limiter
+}
+
+func newCommandLimits(limits ratelimit.CommandLimits) *commandLimits {
+	l := &commandLimits{
+		user:    make(map[ratelimit.CommandClass]*ratelimit.Limiter),
+		channel: make(map[ratelimit.CommandClass]*ratelimit.Limiter),
+	}
+	for _, class := range ratelimit.CommandClasses {
+		l.user[class] = ratelimit.New(limits[class].User)
+		l.channel[class] = ratelimit.New(limits[class].Channel)
+	}
+	return l
+}
+
+// allow takes a token from the user's and the channel's bucket for the class.
+// When either is empty it returns the scope that was limited and how long
+// until the next token. The admin is never limited.
+func (l *commandLimits) allow(adminID string, class ratelimit.CommandClass, userID, guildID, channelID string) (string, time.Duration) {
+	if adminID != "" && userID == adminID {
+		return "", 0
+	}
+	if ok, wait := l.user[class].Allow(userID); !ok {
+		return "user", wait
+	}
+	if ok, wait := l.channel[class].Allow(guildID + "/" + channelID); !ok {
+		// a command the channel limit refuses doesn't count against the user
+		l.user[class].Refund(userID)
+		return "channel", wait
+	}
+	return "", 0
+}
+
+// commandLimiter drops requests identical to one that is still being answered
+type commandLimiter struct {
+	mu       sync.Mutex
+	inflight map[string]struct{}
+}
+
+func newCommandLimiter() *commandLimiter {
+	return &commandLimiter{inflight: make(map[string]struct{})}
+}
+
+// begin marks a request as in flight, returning false when an identical one
+// already is. Every successful begin must be followed by done.
+func (l *commandLimiter) begin(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, busy := l.inflight[key]; busy {
+		return false
+	}
+	l.inflight[key] = struct{}{}
+	return true
+}
+
+func (l *commandLimiter) done(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.inflight, key)
+}
+
+// slowDownMessage tells a limited user when to try again
+func slowDownMessage(tr *i18n.Printer, wait time.Duration) string {
+	seconds := int(math.Ceil(wait.Seconds()))
+	if seconds <= 1 {
+		return tr.T("ratelimit.slow_down_one", 1)
+	}
+	return tr.T("ratelimit.slow_down", seconds)
+}
+
+// slashRequestKey identifies a slash command by who ran it, where and with
+// which options, so double submissions can be dropped
+func slashRequestKey(i *discordgo.Interaction) string {
+	data := i.ApplicationCommandData()
+	var b strings.Builder
+	fmt.Fprintf(&b, "slash:%s:%s:%s", interactionUserID(i), i.ChannelID, data.Name)
+	writeOptionsKey(&b, data.Options)
+	return b.String()
+}
+
+func writeOptionsKey(b *strings.Builder, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	sorted := append([]*discordgo.ApplicationCommandInteractionDataOption(nil), options...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	for _, opt := range sorted {
+		fmt.Fprintf(b, ":%s=%v", opt.Name, opt.Value)
+		writeOptionsKey(b, opt.Options)
+	}
+}
+
+// admitSlash applies deduplication and rate limits to a slash command,
+// answering the user itself when the command won't run. The returned key
+// must be passed to done once the command has been handled.
+func (h *Handler) admitSlash(s *discordgo.Session, i *discordgo.InteractionCreate, state *handlerState) (string, bool) {
+	key := slashRequestKey(i.Interaction)
+	command := i.ApplicationCommandData().Name
+	class := commandClass(command)
+
+	if !h.limiter.begin(key) {
+		rateLimited.Inc(string(class), "duplicate")
+		h.respondLimited(s, i, interactionPrinter(i.Interaction).T("ratelimit.duplicate"))
+		return "", false
+	}
+
+	scope, wait := state.limits.allow(state.settings.AdminID, class, interactionUserID(i.Interaction), i.GuildID, i.ChannelID)
+	if scope != "" {
+		h.limiter.done(key)
+		rateLimited.Inc(string(class), scope)
+		interactionLogger(i.Interaction).Info("command rate limited", "scope", scope, "retry_after", wait)
+		h.respondLimited(s, i, slowDownMessage(interactionPrinter(i.Interaction), wait))
+		return "", false
+	}
+	return key, true
+}
+
+// admitMessage applies deduplication and rate limits to a text command.
+// Duplicates are dropped silently and limited messages get a ⏳ reaction,
+// since text commands can't be answered ephemerally.
+func (h *Handler) admitMessage(s *discordgo.Session, m *discordgo.MessageCreate, command string, state *handlerState) (string, bool) {
+	key := fmt.Sprintf("text:%s:%s:%s", m.Author.ID, m.ChannelID, strings.TrimSpace(m.Content))
+	class := commandClass(command)
+
+	if !h.limiter.begin(key) {
+		rateLimited.Inc(string(class), "duplicate")
+		return "", false
+	}
+
+	scope, wait := state.limits.allow(state.settings.AdminID, class, m.Author.ID, m.GuildID, m.ChannelID)
+	if scope != "" {
+		h.limiter.done(key)
+		rateLimited.Inc(string(class), scope)
+		messageLogger(m, command).Info("command rate limited", "scope", scope, "retry_after", wait)
+		if err := s.MessageReactionAdd(m.ChannelID, m.ID, "⏳"); err != nil {
+			messageLogger(m, command).Debug("reacting to rate limited message failed", logging.Err(err))
+		}
+		return "", false
+	}
+	return key, true
+}
+
+// HandleComponent rate limits button clicks before passing them on to the
+// paginator. A click identical to one still being handled is acknowledged
+// without doing anything, which is what double clicks usually are.
+func (h *Handler) HandleComponent(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	userID := interactionUserID(i.Interaction)
+	key := fmt.Sprintf("component:%s:%s", userID, i.MessageComponentData().CustomID)
+
+	if !h.limiter.begin(key) {
+		rateLimited.Inc(string(ratelimit.ClassPaginator), "duplicate")
+		err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseDeferredMessageUpdate,
+		})
+		if err != nil {
+			interactionLogger(i.Interaction).Warn("acknowledging duplicate click failed", logging.Err(err))
+			failedInteractions.Inc("paginator")
+		}
+		return
+	}
+	defer h.limiter.done(key)
+
+	state := h.currentState()
+	scope, wait := state.limits.allow(state.settings.AdminID, ratelimit.ClassPaginator, userID, i.GuildID, i.ChannelID)
+	if scope != "" {
+		rateLimited.Inc(string(ratelimit.ClassPaginator), scope)
+		interactionLogger(i.Interaction).Info("click rate limited", "scope", scope, "retry_after", wait)
+		h.respondLimited(s, i, slowDownMessage(interactionPrinter(i.Interaction), wait))
+		return
+	}
+
+	PaginatorManager.OnInteractionCreate(s, i)
+}
+
+// respondLimited answers an interaction that won't run with an ephemeral
+// message. Limited interactions aren't commands, so they have no outcome.
+func (h *Handler) respondLimited(s *discordgo.Session, i *discordgo.InteractionCreate, content string) {
+	defer failures.Delete(i.ID)
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if err != nil {
+		interactionFailed(i.Interaction, "responding to limited interaction failed", err)
+	}
+}
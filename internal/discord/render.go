@@ -0,0 +1,237 @@
+package discord
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/whotypes/leetbot/internal/data"
+	"github.com/whotypes/leetbot/internal/i18n"
+)
+
+const (
+	// maxProblemsPerEmbed is how many problems fit on a page without the
+	// description outgrowing an embed, the same limit as problems_per_page
+	maxProblemsPerEmbed = 25
+	// maxTextProblems is how many problems the plain text fallback lists
+	maxTextProblems = 20
+	// messageContentLimit is discord's maximum length for message content
+	messageContentLimit = 2000
+)
+
+// problemList renders the problems of a company and timeframe, as embeds where
+// the bot may post them and as compact text where it can't
+type problemList struct {
+	// tr is the language of the list
+	tr        *i18n.Printer
+	company   string
+	timeframe string
+	problems  []data.Problem
+	// version labels the dataset the list came from, left out when empty
+	version string
+}
+
+func (h *Handler) newProblemList(tr *i18n.Printer, company, timeframe string, problems []data.Problem) problemList {
+	l := problemList{tr: tr, company: company, timeframe: timeframe, problems: problems}
+	if h.problemsData != nil {
+		l.version = h.problemsData.Version().Version
+	}
+	return l
+}
+
+// pageSize is how many problems each page shows, lists up to the pagination
+// threshold fit on a single page
+func (l problemList) pageSize() int {
+	if n := len(l.problems); !shouldUsePagination(n) && n <= maxProblemsPerEmbed {
+		return max(n, 1)
+	}
+	return currentSettings().ProblemsPerPage
+}
+
+func (l problemList) pageCount() int {
+	size := l.pageSize()
+	return (len(l.problems) + size - 1) / size
+}
+
+func (l problemList) title() string {
+	return l.tr.T("problems.title", formatCompanyName(l.company), formatTimeframeDisplay(l.tr, l.timeframe))
+}
+
+// difficulties counts the problems of each difficulty, in the order they're shown
+func (l problemList) difficulties() []int {
+	counts := make([]int, len(difficulties))
+	for _, p := range l.problems {
+		for i, difficulty := range difficulties {
+			if strings.EqualFold(p.Difficulty, difficulty) {
+				counts[i]++
+			}
+		}
+	}
+	return counts
+}
+
+var difficulties = []string{"easy", "medium", "hard"}
+
+// renderPage fills in one page of the list, clamping page to the pages there are
+func (l problemList) renderPage(page int, embed *discordgo.MessageEmbed) {
+	size := l.pageSize()
+	pages := l.pageCount()
+	page = min(page, pages-1)
+	page = max(page, 0)
+
+	embed.Title = l.title()
+	embed.Color = 0x5865F2
+	embed.Timestamp = time.Now().Format(time.RFC3339)
+
+	footer := []string{l.tr.T("problems.count", len(l.problems))}
+	if pages > 1 {
+		footer = append([]string{l.tr.T("problems.page", page+1, pages)}, footer...)
+	}
+	if l.version != "" {
+		footer = append(footer, l.tr.T("problems.dataset", l.version))
+	}
+	embed.Footer = &discordgo.MessageEmbedFooter{Text: strings.Join(footer, " • ")}
+
+	if len(l.problems) == 0 {
+		embed.Description = l.tr.T("problems.none")
+		embed.Fields = nil
+		return
+	}
+
+	start := page * size
+	end := min(start+size, len(l.problems))
+	var description strings.Builder
+	for i, problem := range l.problems[start:end] {
+		fmt.Fprintf(&description, "**%d.** %s [%s](<%s>) `%.0f%%`%s\n",
+			start+i+1,
+			getDifficultyIndicator(problem.Difficulty),
+			problem.Title,
+			problem.URL,
+			problem.Frequency,
+			getSourceLabel(problem.Source))
+	}
+	embed.Description = description.String()
+
+	// the breakdown covers the whole list, so it reads the same on every page
+	embed.Fields = nil
+	for i, count := range l.difficulties() {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   getDifficultyIndicator(difficulties[i]) + " " + formatDifficulty(l.tr, difficulties[i]),
+			Value:  fmt.Sprint(count),
+			Inline: true,
+		})
+	}
+}
+
+func (l problemList) embed() *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{}
+	l.renderPage(0, embed)
+	return embed
+}
+
+func (l problemList) paginator() *Paginator {
+	return &Paginator{
+		PageFunc: l.renderPage,
+		MaxPages: l.pageCount(),
+	}
+}
+
+// text is the fallback for channels the bot can't post embeds in, it lists
+// the first problems and stays within a single message
+func (l problemList) text() string {
+	if len(l.problems) == 0 {
+		return l.tr.T("problems.not_found", formatCompanyName(l.company), formatTimeframeDisplay(l.tr, l.timeframe))
+	}
+
+	var message strings.Builder
+	message.WriteString(l.title() + ":\n")
+
+	var breakdown []string
+	for i, count := range l.difficulties() {
+		breakdown = append(breakdown, fmt.Sprintf("%s %d %s", getDifficultyIndicator(difficulties[i]), count, formatDifficulty(l.tr, difficulties[i])))
+	}
+	message.WriteString(strings.Join(breakdown, " • ") + "\n")
+
+	shown := 0
+	for _, problem := range l.problems[:min(len(l.problems), maxTextProblems)] {
+		line := fmt.Sprintf("%s %s (%.0f%%)%s: %s\n",
+			getDifficultyIndicator(problem.Difficulty), problem.Title, problem.Frequency, getSourceLabel(problem.Source), problem.URL)
+		// leave room for the footer line
+		if message.Len()+len(line) > messageContentLimit-100 {
+			break
+		}
+		message.WriteString(line)
+		shown++
+	}
+
+	var footer []string
+	if more := len(l.problems) - shown; more > 0 {
+		footer = append(footer, l.tr.T("list.more", more))
+	}
+	if l.version != "" {
+		footer = append(footer, l.tr.T("problems.dataset", l.version))
+	}
+	if len(footer) > 0 {
+		message.WriteString(strings.Join(footer, " • ") + "\n")
+	}
+	return message.String()
+}
+
+// canEmbed reports whether the bot may post embeds in a channel. Permissions
+// come from the state cache, channels missing from it (like DMs) are assumed
+// to allow embeds rather than calling the API on every message.
+func canEmbed(s *discordgo.Session, channelID string) bool {
+	if s == nil || s.State == nil || s.State.User == nil {
+		return true
+	}
+	perms, err := s.State.UserChannelPermissions(s.State.User.ID, channelID)
+	if err != nil {
+		return true
+	}
+	return perms&discordgo.PermissionEmbedLinks != 0
+}
+
+// interactionCanEmbed reports whether the bot may post embeds in response to
+// an interaction, discord sends the bot's permissions along with it
+func interactionCanEmbed(i *discordgo.Interaction) bool {
+	return i.AppPermissions == 0 || i.AppPermissions&discordgo.PermissionEmbedLinks != 0
+}
+
+// sendProblems posts a problem list to a channel, as text where the bot can't
+// post embeds and with page buttons when it has more than one page
+func (h *Handler) sendProblems(s *discordgo.Session, channelID string, l problemList) error {
+	if !canEmbed(s, channelID) {
+		h.sendMessage(s, channelID, l.text())
+		return nil
+	}
+	if l.pageCount() > 1 {
+		return PaginatorManager.CreateMessage(s, channelID, l.paginator())
+	}
+	_, err := s.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+		Embeds: []*discordgo.MessageEmbed{l.embed()},
+	})
+	return err
+}
+
+// respondProblems answers an interaction with a problem list, like sendProblems
+func (h *Handler) respondProblems(s *discordgo.Session, i *discordgo.InteractionCreate, l problemList) error {
+	if !interactionCanEmbed(i.Interaction) {
+		return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: l.text(),
+				Flags:   discordgo.MessageFlagsSuppressEmbeds,
+			},
+		})
+	}
+	if l.pageCount() > 1 {
+		return PaginatorManager.CreateInteraction(s, i.Interaction, l.paginator(), false)
+	}
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{l.embed()},
+		},
+	})
+}
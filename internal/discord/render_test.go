@@ -0,0 +1,162 @@
+package discord
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/whotypes/leetbot/internal/data"
+	"github.com/whotypes/leetbot/internal/discord/discordtest"
+)
+
+func testProblems(n int) []data.Problem {
+	difficulties := []string{"Easy", "Medium", "Hard"}
+	problems := make([]data.Problem, n)
+	for i := range problems {
+		problems[i] = data.Problem{
+			ID:         i + 1,
+			URL:        fmt.Sprintf("https://leetcode.com/problems/test-%d", i+1),
+			Title:      fmt.Sprintf("Test Problem %d", i+1),
+			Difficulty: difficulties[i%3],
+			Frequency:  100.0,
+		}
+	}
+	return problems
+}
+
+func TestProblemListEmbed(t *testing.T) {
+	tests := []struct {
+		name       string
+		problems   int
+		pages      int
+		footer     string
+		difficulty []string
+	}{
+		{"short list", 4, 1, "4 problems • Dataset v1", []string{"2", "1", "1"}},
+		{"single problem", 1, 1, "1 problems • Dataset v1", []string{"1", "0", "0"}},
+		{"long list", 25, 3, "Page 1/3 • 25 problems • Dataset v1", []string{"9", "8", "8"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := problemList{company: "airbnb", timeframe: "all", problems: testProblems(tt.problems), version: "v1"}
+			if got := l.pageCount(); got != tt.pages {
+				t.Errorf("pageCount() = %d, want %d", got, tt.pages)
+			}
+
+			embed := l.embed()
+			if embed.Title != "Most Popular Problems for Airbnb (all)" {
+				t.Errorf("Title = %q", embed.Title)
+			}
+			if embed.Footer == nil || embed.Footer.Text != tt.footer {
+				t.Errorf("Footer = %+v, want %q", embed.Footer, tt.footer)
+			}
+			if len(embed.Fields) != len(tt.difficulty) {
+				t.Fatalf("Fields = %+v, want a difficulty breakdown", embed.Fields)
+			}
+			for i, field := range embed.Fields {
+				if !field.Inline || field.Value != tt.difficulty[i] {
+					t.Errorf("field %q = %q, want %q", field.Name, field.Value, tt.difficulty[i])
+				}
+			}
+			if !strings.Contains(embed.Description, "**1.** 🟢 [Test Problem 1]") {
+				t.Errorf("Description = %q", embed.Description)
+			}
+		})
+	}
+
+	empty := problemList{company: "airbnb", timeframe: "all"}.embed()
+	if empty.Description != "No problems found." || len(empty.Fields) != 0 {
+		t.Errorf("empty list embed = %+v", empty)
+	}
+}
+
+func TestProblemListText(t *testing.T) {
+	l := problemList{company: "airbnb", timeframe: "thirty-days", problems: testProblems(30), version: "v1"}
+	text := l.text()
+
+	for _, want := range []string{
+		"Most Popular Problems for Airbnb (last 30 days):\n",
+		"🟢 10 Easy • 🟡 10 Medium • 🔴 10 Hard\n",
+		"🟢 Test Problem 1 (100%): https://leetcode.com/problems/test-1\n",
+		"…and 10 more • Dataset v1\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text() = %q, want it to contain %q", text, want)
+		}
+	}
+	if strings.Contains(text, "Test Problem 21 ") {
+		t.Error("text() should list at most 20 problems")
+	}
+
+	// long titles are cut short rather than going over the message limit
+	long := testProblems(20)
+	for i := range long {
+		long[i].Title = strings.Repeat("x", 200)
+	}
+	text = problemList{company: "airbnb", timeframe: "all", problems: long}.text()
+	if len(text) > messageContentLimit {
+		t.Errorf("text() length = %d, want at most %d", len(text), messageContentLimit)
+	}
+	if !strings.Contains(text, "more") {
+		t.Errorf("text() should say how many problems it left out, got %q", text)
+	}
+}
+
+func TestProblemsWithoutEmbedLinks(t *testing.T) {
+	handler := NewHandler(createTestProblemsData(), "!")
+	session := discordtest.New()
+	handler.SetSession(session.Session)
+	handler.EnableChannel("1")
+	handler.EnableChannel("2")
+	session.SetBotPermissions("1", discordgo.PermissionViewChannel|discordgo.PermissionSendMessages)
+	session.SetBotPermissions("2", discordgo.PermissionViewChannel|discordgo.PermissionSendMessages|discordgo.PermissionEmbedLinks)
+	user := &discordgo.User{ID: "42", Username: "tester"}
+
+	slash := func(channelID string) *discordgo.InteractionCreate {
+		return session.SlashCommand(channelID, user, discordgo.ApplicationCommandInteractionData{
+			Name: "problems",
+			Options: []*discordgo.ApplicationCommandInteractionDataOption{
+				{Name: "company", Type: discordgo.ApplicationCommandOptionString, Value: "airbnb"},
+				{Name: "timeframe", Type: discordgo.ApplicationCommandOptionString, Value: "all"},
+			},
+		})
+	}
+
+	tests := []struct {
+		name      string
+		channelID string
+		run       func(channelID string)
+		embed     bool
+	}{
+		{"text command without embed links", "1", func(id string) {
+			handler.HandleMessage(session.Session, session.MessageCreate(id, user, "!problems airbnb all"))
+		}, false},
+		{"slash command without embed links", "1", func(id string) { handler.HandleSlashCommand(session.Session, slash(id)) }, false},
+		{"text command with embed links", "2", func(id string) {
+			handler.HandleMessage(session.Session, session.MessageCreate(id, user, "!problems airbnb all"))
+		}, true},
+		{"slash command with embed links", "2", func(id string) { handler.HandleSlashCommand(session.Session, slash(id)) }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(tt.channelID)
+			events := session.Events()
+			if len(events) != 1 {
+				t.Fatalf("expected one message, got %d", len(events))
+			}
+			msg := events[0].Message
+			if tt.embed {
+				if len(msg.Embeds) != 1 || msg.Content != "" {
+					t.Errorf("expected an embed, got %+v", msg)
+				}
+				return
+			}
+			if len(msg.Embeds) != 0 || !strings.Contains(msg.Content, "🟢 Two Sum (100%)") {
+				t.Errorf("expected the plain text list, got %+v", msg)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package discord
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/whotypes/leetbot/internal/data"
+	"github.com/whotypes/leetbot/internal/i18n"
+)
+
+const (
+	// searchResultLimit is how many results /search shows
+	searchResultLimit = 10
+	// autocompleteChoiceLimit is discord's maximum number of autocomplete choices
+	autocompleteChoiceLimit = 25
+	// choiceNameLimit is discord's maximum length for a choice name
+	choiceNameLimit = 100
+)
+
+func getSearchAutocompleteChoices(input string, problemsData *data.ProblemsByCompany) []*discordgo.ApplicationCommandOptionChoice {
+	if strings.TrimSpace(input) == "" {
+		return nil
+	}
+
+	results := problemsData.Search(input, autocompleteChoiceLimit)
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, len(results))
+	for _, result := range results {
+		name := fmt.Sprintf("%d. %s (%s)", result.Problem.ID, result.Problem.Title, result.Problem.Difficulty)
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name: truncateTitle(name, choiceNameLimit),
+			// picking a choice submits the problem ID, which search resolves exactly
+			Value: strconv.Itoa(result.Problem.ID),
+		})
+	}
+	return choices
+}
+
+// formatTopCompanies lists the companies that ask a problem most often
+func formatTopCompanies(tr *i18n.Printer, result data.SearchResult) string {
+	names := make([]string, 0, len(result.TopCompanies))
+	for _, company := range result.TopCompanies {
+		names = append(names, formatCompanyName(company))
+	}
+	text := strings.Join(names, ", ")
+	if more := result.Companies - len(result.TopCompanies); more > 0 {
+		text += " " + tr.T("list.and_more", more)
+	}
+	return text
+}
+
+// createSearchResultsEmbed lists search results, one line per problem
+func createSearchResultsEmbed(tr *i18n.Printer, query string, results []data.SearchResult) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title:     tr.T("search.title", truncateTitle(query, 64)),
+		Color:     0x5865F2,
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+
+	var description strings.Builder
+	for i, result := range results {
+		line := fmt.Sprintf("**%d.** %s [%d. %s](<%s>) • %s%s\n",
+			i+1,
+			getDifficultyIndicator(result.Problem.Difficulty),
+			result.Problem.ID,
+			result.Problem.Title,
+			result.Problem.URL,
+			tr.T("search.companies", result.Companies),
+			getSourceLabel(result.Problem.Source))
+		if description.Len()+len(line) > embedDescriptionLimit {
+			break
+		}
+		description.WriteString(line)
+	}
+	embed.Description = description.String()
+
+	embed.Footer = &discordgo.MessageEmbedFooter{
+		Text: tr.T("search.footer"),
+	}
+
+	return embed
+}
+
+// createProblemEmbed shows a single problem with the companies that ask it
+func createProblemEmbed(tr *i18n.Printer, result data.SearchResult) *discordgo.MessageEmbed {
+	problem := result.Problem
+	embed := &discordgo.MessageEmbed{
+		Title:     fmt.Sprintf("%d. %s", problem.ID, problem.Title),
+		URL:       problem.URL,
+		Color:     0x5865F2,
+		Timestamp: time.Now().Format(time.RFC3339),
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:   tr.T("search.difficulty"),
+				Value:  strings.TrimSpace(getDifficultyIndicator(problem.Difficulty) + " " + formatDifficulty(tr, problem.Difficulty)),
+				Inline: true,
+			},
+			{
+				Name:   tr.T("search.acceptance"),
+				Value:  fmt.Sprintf("%.1f%%", problem.Acceptance),
+				Inline: true,
+			},
+			{
+				Name:   tr.T("search.companies_field"),
+				Value:  strconv.Itoa(result.Companies),
+				Inline: true,
+			},
+		},
+	}
+
+	if len(result.TopCompanies) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  tr.T("search.top_companies"),
+			Value: formatTopCompanies(tr, result),
+		})
+	}
+
+	if getSourceLabel(problem.Source) != "" {
+		embed.Footer = &discordgo.MessageEmbedFooter{Text: tr.T("search.source", problem.Source)}
+	}
+
+	return embed
+}
+
+func (h *Handler) handleSearchSlash(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	tr := interactionPrinter(i.Interaction)
+	var query string
+	for _, opt := range i.ApplicationCommandData().Options {
+		if opt.Name == "query" {
+			query = strings.TrimSpace(opt.StringValue())
+		}
+	}
+
+	respond := func(data *discordgo.InteractionResponseData) {
+		err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: data,
+		})
+		if err != nil {
+			interactionFailed(i.Interaction, "responding to interaction failed", err)
+		}
+	}
+
+	if query == "" {
+		respond(&discordgo.InteractionResponseData{
+			Content: tr.T("search.query_required"),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		})
+		return
+	}
+
+	// autocomplete choices submit a problem ID
+	if id, err := strconv.Atoi(query); err == nil {
+		if result, ok := h.problemsData.SearchIndex().Get(id); ok {
+			respond(&discordgo.InteractionResponseData{
+				Embeds: []*discordgo.MessageEmbed{createProblemEmbed(tr, result)},
+			})
+			return
+		}
+	}
+
+	results := h.problemsData.Search(query, searchResultLimit)
+	if len(results) == 0 {
+		respond(&discordgo.InteractionResponseData{
+			Content: tr.T("search.not_found", query),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		})
+		return
+	}
+
+	respond(&discordgo.InteractionResponseData{
+		Embeds: []*discordgo.MessageEmbed{createSearchResultsEmbed(tr, query, results)},
+	})
+}
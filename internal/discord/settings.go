@@ -0,0 +1,91 @@
+package discord
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync/atomic"
+
+	"github.com/whotypes/leetbot/internal/config"
+	"github.com/whotypes/leetbot/internal/logging"
+	"github.com/whotypes/leetbot/internal/ratelimit"
+)
+
+// settings holds the configurable parts of the bot, swapped as a whole when
+// the configuration is reloaded
+var settings atomic.Pointer[config.BotConfig]
+
+func init() {
+	Configure(config.Defaults().Bot)
+}
+
+// Configure applies the bot section of the configuration. Call it before
+// NewHandler, which reads the channels enabled without !init.
+func Configure(c config.BotConfig) {
+	settings.Store(&c)
+	configureEnricher(c)
+}
+
+// currentSettings returns the bot settings, which must not be modified
+func currentSettings() *config.BotConfig {
+	return settings.Load()
+}
+
+// ConfigReloader re-reads the configuration and applies it, returning what
+// changed
+type ConfigReloader func() ([]config.Change, error)
+
+// SetConfigReloader enables the !reload config admin command
+func (h *Handler) SetConfigReloader(reload ConfigReloader) {
+	h.reloadConfig = reload
+}
+
+// Reconfigure applies reloaded settings without reconnecting. The rate
+// limits are checked first, so a bad value leaves everything as it was.
+// Channels dropped from bot.channels are disabled; channels enabled with
+// !init stay enabled.
+func (h *Handler) Reconfigure(prefix string, c config.BotConfig) error {
+	limits, err := ratelimit.ParseCommandLimits(c.RateLimits)
+	if err != nil {
+		return fmt.Errorf("bot.rate_limits: %w", err)
+	}
+	fresh := newCommandLimits(limits)
+
+	// everything a command reads is swapped at once, so no command sees the
+	// new prefix with the old channels or limits
+	var previous *config.BotConfig
+	h.updateState(func(state *handlerState) {
+		previous = state.settings
+		for _, channelID := range previous.Channels {
+			if !slices.Contains(c.Channels, channelID) {
+				delete(state.channels, channelID)
+			}
+		}
+		for _, channelID := range c.Channels {
+			state.channels[channelID] = true
+		}
+		// changing the limits resets every bucket, so unchanged limits are kept
+		if c.RateLimits != previous.RateLimits {
+			state.limits = fresh
+		}
+		state.prefix = prefix
+		state.settings = &c
+	})
+	Configure(c)
+
+	// registering is a REST call per guild, so only when where commands go changed
+	if !slices.Equal(c.CommandGuilds, previous.CommandGuilds) || !slices.Equal(c.DisabledCommands, previous.DisabledCommands) {
+		if s := h.GetSession(); s != nil && s.State != nil && s.State.User != nil {
+			var dropped []string
+			for _, guildID := range previous.CommandGuilds {
+				if !slices.Contains(c.CommandGuilds, guildID) {
+					dropped = append(dropped, guildID)
+				}
+			}
+			if err := h.syncCommands(s, h.disabled, dropped); err != nil {
+				slog.Error("syncing slash commands failed", logging.Err(err))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package discord
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/whotypes/leetbot/internal/data"
+	"github.com/whotypes/leetbot/internal/i18n"
+)
+
+const (
+	// summaryTopProblems is how many problems are listed per timeframe in a summary
+	summaryTopProblems = 5
+	// summaryMaxEvergreen caps the evergreen list so the field stays under discord's limit
+	summaryMaxEvergreen = 10
+	// embedFieldValueLimit is discord's maximum length for an embed field value
+	embedFieldValueLimit = 1024
+)
+
+// formatTimeframeHeading returns a title-cased timeframe label for embed headings
+func formatTimeframeHeading(tr *i18n.Printer, timeframe string) string {
+	if key := "timeframe.heading." + timeframe; i18n.Has(key) {
+		return tr.T(key)
+	}
+	return formatCompanyName(timeframe)
+}
+
+// truncateTitle shortens long problem titles so side by side fields stay readable
+func truncateTitle(title string, max int) string {
+	runes := []rune(title)
+	if len(runes) <= max {
+		return title
+	}
+	return string(runes[:max-1]) + "…"
+}
+
+// createCompanySummaryEmbed renders a company overview with one inline field per timeframe
+func createCompanySummaryEmbed(tr *i18n.Printer, summary *data.CompanySummary) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title:     tr.T("summary.title", formatCompanyName(summary.Company)),
+		Color:     0x5865F2,
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+
+	if len(summary.Timeframes) == 0 {
+		embed.Description = tr.T("problems.no_data", formatCompanyName(summary.Company))
+		return embed
+	}
+
+	embed.Description = tr.T("summary.description", len(summary.Timeframes))
+
+	for _, tf := range summary.Timeframes {
+		var value strings.Builder
+		value.WriteString(tr.T("summary.count", tf.Count) + "\n")
+		value.WriteString(fmt.Sprintf("%s %d • %s %d • %s %d\n",
+			getDifficultyIndicator("easy"), tf.Difficulty.Easy,
+			getDifficultyIndicator("medium"), tf.Difficulty.Medium,
+			getDifficultyIndicator("hard"), tf.Difficulty.Hard))
+
+		for i, problem := range tf.Top {
+			line := fmt.Sprintf("\n%d. [%s](<%s>)%s", i+1, truncateTitle(problem.Title, 28), problem.URL, getSourceLabel(problem.Source))
+			if value.Len()+len(line) > embedFieldValueLimit {
+				break
+			}
+			value.WriteString(line)
+		}
+
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   formatTimeframeHeading(tr, tf.Timeframe),
+			Value:  value.String(),
+			Inline: true,
+		})
+	}
+
+	if len(summary.Evergreen) > 0 {
+		var value strings.Builder
+		shown := 0
+		for _, problem := range summary.Evergreen {
+			if shown >= summaryMaxEvergreen {
+				break
+			}
+			line := fmt.Sprintf("%s [%s](<%s>)\n", getDifficultyIndicator(problem.Difficulty), problem.Title, problem.URL)
+			if value.Len()+len(line) > embedFieldValueLimit-32 {
+				break
+			}
+			value.WriteString(line)
+			shown++
+		}
+		if remaining := len(summary.Evergreen) - shown; remaining > 0 {
+			value.WriteString(tr.T("list.more", remaining))
+		}
+
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  tr.T("summary.evergreen", len(summary.Evergreen)),
+			Value: value.String(),
+		})
+	}
+
+	embed.Footer = &discordgo.MessageEmbedFooter{
+		Text: tr.T("summary.footer"),
+	}
+
+	return embed
+}
+
+func (h *Handler) handleCompanySlash(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	tr := interactionPrinter(i.Interaction)
+	var input string
+	for _, opt := range i.ApplicationCommandData().Options {
+		if opt.Name == "name" {
+			input = opt.StringValue()
+		}
+	}
+
+	respondEphemeral := func(content string) {
+		err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: content,
+				Flags:   discordgo.MessageFlagsEphemeral | discordgo.MessageFlagsSuppressEmbeds,
+			},
+		})
+		if err != nil {
+			interactionFailed(i.Interaction, "responding to interaction failed", err)
+		}
+	}
+
+	if strings.TrimSpace(input) == "" {
+		respondEphemeral(tr.T("error.company_required"))
+		return
+	}
+
+	company, found, suggestions := findCompanyWithSuggestion(cleanCompanyInput(input), h.problemsData)
+	if !found {
+		respondEphemeral(formatCompanyNotFound(tr, input, suggestions))
+		return
+	}
+	companyQueries.Inc(company)
+
+	summary := h.problemsData.GetCompanySummary(company, summaryTopProblems)
+	if summary == nil || len(summary.Timeframes) == 0 {
+		respondEphemeral(tr.T("problems.no_data", formatCompanyName(company)))
+		return
+	}
+
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{createCompanySummaryEmbed(tr, summary)},
+		},
+	})
+	if err != nil {
+		interactionFailed(i.Interaction, "responding to interaction failed", err)
+	}
+}
@@ -0,0 +1,244 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"strings"
+
+	"github.com/whotypes/leetbot/internal/data"
+)
+
+// Format identifies an export file format
+type Format string
+
+const (
+	FormatCSV      Format = "csv"
+	FormatJSON     Format = "json"
+	FormatMarkdown Format = "markdown"
+	FormatAnki     Format = "anki"
+)
+
+// Formats lists every supported export format
+var Formats = []Format{FormatCSV, FormatJSON, FormatMarkdown, FormatAnki}
+
+var ErrUnknownFormat = errors.New("unknown export format")
+
+// ParseFormat resolves a user supplied format name or file extension
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(strings.TrimPrefix(s, "."))) {
+	case "csv":
+		return FormatCSV, nil
+	case "json":
+		return FormatJSON, nil
+	case "markdown", "md":
+		return FormatMarkdown, nil
+	case "anki", "tsv":
+		return FormatAnki, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnknownFormat, s)
+}
+
+// FormatFromAccept picks an export format from an HTTP Accept header.
+// JSON is intentionally not matched since it is the default API response type.
+func FormatFromAccept(accept string) (Format, bool) {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		switch mediaType {
+		case "text/csv":
+			return FormatCSV, true
+		case "text/markdown":
+			return FormatMarkdown, true
+		case "text/tab-separated-values":
+			return FormatAnki, true
+		}
+	}
+	return "", false
+}
+
+// ContentType returns the MIME type used when serving the format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatJSON:
+		return "application/json"
+	case FormatMarkdown:
+		return "text/markdown; charset=utf-8"
+	case FormatAnki:
+		return "text/tab-separated-values; charset=utf-8"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// Extension returns the file extension for the format, without the dot
+func (f Format) Extension() string {
+	switch f {
+	case FormatMarkdown:
+		return "md"
+	case FormatAnki:
+		return "txt"
+	default:
+		return string(f)
+	}
+}
+
+// List is a rendered problem query
+type List struct {
+	Company   string
+	Timeframe string
+	// Title is an optional human readable heading, defaults to "company (timeframe)"
+	Title    string
+	Problems []data.Problem
+}
+
+func (l List) title() string {
+	if l.Title != "" {
+		return l.Title
+	}
+	return fmt.Sprintf("%s (%s)", l.Company, l.Timeframe)
+}
+
+// Filename returns a download file name for the list, e.g. "google-thirty-days.csv"
+func Filename(l List, f Format) string {
+	name := l.Company
+	if l.Timeframe != "" {
+		name += "-" + l.Timeframe
+	}
+	if name == "" {
+		name = "problems"
+	}
+	return fmt.Sprintf("%s.%s", name, f.Extension())
+}
+
+// Render writes the list to w in the given format
+func Render(w io.Writer, f Format, l List) error {
+	switch f {
+	case FormatCSV:
+		return renderCSV(w, l)
+	case FormatJSON:
+		return renderJSON(w, l)
+	case FormatMarkdown:
+		return renderMarkdown(w, l)
+	case FormatAnki:
+		return renderAnki(w, l)
+	}
+	return fmt.Errorf("%w: %q", ErrUnknownFormat, string(f))
+}
+
+// renderCSV uses the same column layout as the files in data/
+func renderCSV(w io.Writer, l List) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"ID", "URL", "Title", "Difficulty", "Acceptance %", "Frequency %"}); err != nil {
+		return err
+	}
+	for _, p := range l.Problems {
+		record := []string{
+			fmt.Sprintf("%d", p.ID),
+			p.URL,
+			p.Title,
+			p.Difficulty,
+			fmt.Sprintf("%.1f%%", p.Acceptance),
+			fmt.Sprintf("%.1f%%", p.Frequency),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+type jsonProblem struct {
+	ID         int     `json:"id"`
+	URL        string  `json:"url"`
+	Title      string  `json:"title"`
+	Difficulty string  `json:"difficulty"`
+	Acceptance float64 `json:"acceptance"`
+	Frequency  float64 `json:"frequency"`
+}
+
+type jsonList struct {
+	Company   string        `json:"company"`
+	Timeframe string        `json:"timeframe"`
+	Count     int           `json:"count"`
+	Problems  []jsonProblem `json:"problems"`
+}
+
+func renderJSON(w io.Writer, l List) error {
+	out := jsonList{
+		Company:   l.Company,
+		Timeframe: l.Timeframe,
+		Count:     len(l.Problems),
+		Problems:  make([]jsonProblem, len(l.Problems)),
+	}
+	for i, p := range l.Problems {
+		out.Problems[i] = jsonProblem{
+			ID:         p.ID,
+			URL:        p.URL,
+			Title:      p.Title,
+			Difficulty: p.Difficulty,
+			Acceptance: p.Acceptance,
+			Frequency:  p.Frequency,
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
+
+// renderMarkdown writes a GitHub flavored task list
+func renderMarkdown(w io.Writer, l List) error {
+	if _, err := fmt.Fprintf(w, "# %s\n\n", l.title()); err != nil {
+		return err
+	}
+	for _, p := range l.Problems {
+		title := strings.NewReplacer("[", "\\[", "]", "\\]").Replace(p.Title)
+		_, err := fmt.Fprintf(w, "- [ ] [%s](%s) - %s, %.0f%% frequency\n", title, p.URL, p.Difficulty, p.Frequency)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderAnki writes a tab separated deck with file headers understood by Anki 2.1.54+.
+// Columns are front, back and tags.
+func renderAnki(w io.Writer, l List) error {
+	headers := "#separator:tab\n#html:true\n#tags column:3\n"
+	if _, err := io.WriteString(w, headers); err != nil {
+		return err
+	}
+
+	clean := strings.NewReplacer("\t", " ", "\n", " ", "\r", " ")
+	baseTags := []string{"leetbot"}
+	if l.Company != "" {
+		baseTags = append(baseTags, "company::"+l.Company)
+	}
+	if l.Timeframe != "" {
+		baseTags = append(baseTags, "timeframe::"+l.Timeframe)
+	}
+
+	// the deck is read as HTML, so text from the data is escaped
+	for _, p := range l.Problems {
+		front := fmt.Sprintf("%d. %s", p.ID, html.EscapeString(clean.Replace(p.Title)))
+		url := html.EscapeString(clean.Replace(p.URL))
+		back := fmt.Sprintf(`<a href="%s">%s</a><br>%s • %.1f%% acceptance • %.0f%% frequency`,
+			url, url, p.Difficulty, p.Acceptance, p.Frequency)
+		tags := append(append([]string{}, baseTags...), "difficulty::"+strings.ToLower(p.Difficulty))
+
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\n", front, back, strings.Join(tags, " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
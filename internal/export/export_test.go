@@ -0,0 +1,193 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/whotypes/leetbot/internal/data"
+)
+
+func testList() List {
+	return List{
+		Company:   "google",
+		Timeframe: "thirty-days",
+		Title:     "Google (last 30 days)",
+		Problems: []data.Problem{
+			{ID: 1, URL: "https://leetcode.com/problems/two-sum", Title: "Two Sum", Difficulty: "Easy", Acceptance: 55.9, Frequency: 100.0},
+			{ID: 56, URL: "https://leetcode.com/problems/merge-intervals", Title: "Merge Intervals, Again", Difficulty: "Medium", Acceptance: 48.4, Frequency: 75.0},
+		},
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Format
+		wantErr  bool
+	}{
+		{"csv", FormatCSV, false},
+		{"JSON", FormatJSON, false},
+		{"md", FormatMarkdown, false},
+		{".markdown", FormatMarkdown, false},
+		{"anki", FormatAnki, false},
+		{"tsv", FormatAnki, false},
+		{"pdf", "", true},
+	}
+
+	for _, tt := range tests {
+		result, err := ParseFormat(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseFormat(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr && !errors.Is(err, ErrUnknownFormat) {
+			t.Errorf("ParseFormat(%q) error = %v, want ErrUnknownFormat", tt.input, err)
+		}
+		if result != tt.expected {
+			t.Errorf("ParseFormat(%q) = %q, want %q", tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestFormatFromAccept(t *testing.T) {
+	tests := []struct {
+		accept   string
+		expected Format
+		ok       bool
+	}{
+		{"text/csv", FormatCSV, true},
+		{"text/markdown; charset=utf-8", FormatMarkdown, true},
+		{"application/json", "", false},
+		{"text/html, text/tab-separated-values;q=0.9", FormatAnki, true},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		result, ok := FormatFromAccept(tt.accept)
+		if ok != tt.ok || result != tt.expected {
+			t.Errorf("FormatFromAccept(%q) = (%q, %v), want (%q, %v)", tt.accept, result, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+func TestRenderCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Render(&buf, FormatCSV, testList()); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("rendered CSV is not parseable: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("CSV rows = %d, want 3", len(records))
+	}
+	if records[2][2] != "Merge Intervals, Again" {
+		t.Errorf("title with comma not preserved: %q", records[2][2])
+	}
+	if records[1][5] != "100.0%" {
+		t.Errorf("frequency = %q, want %q", records[1][5], "100.0%")
+	}
+}
+
+func TestRenderJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Render(&buf, FormatJSON, testList()); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	var decoded struct {
+		Company  string `json:"company"`
+		Count    int    `json:"count"`
+		Problems []struct {
+			ID int `json:"id"`
+		} `json:"problems"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("rendered JSON is not parseable: %v", err)
+	}
+	if decoded.Company != "google" || decoded.Count != 2 || decoded.Problems[1].ID != 56 {
+		t.Errorf("unexpected JSON output: %s", buf.String())
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Render(&buf, FormatMarkdown, testList()); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	output := buf.String()
+	if !strings.HasPrefix(output, "# Google (last 30 days)\n") {
+		t.Errorf("markdown should start with the title, got: %q", output)
+	}
+	if !strings.Contains(output, "- [ ] [Two Sum](https://leetcode.com/problems/two-sum)") {
+		t.Errorf("markdown should contain a checklist item, got: %q", output)
+	}
+}
+
+func TestRenderAnki(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Render(&buf, FormatAnki, testList()); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("anki lines = %d, want 3 headers and 2 notes", len(lines))
+	}
+	if lines[0] != "#separator:tab" {
+		t.Errorf("first header = %q, want #separator:tab", lines[0])
+	}
+
+	columns := strings.Split(lines[3], "\t")
+	if len(columns) != 3 {
+		t.Fatalf("anki note columns = %d, want 3", len(columns))
+	}
+	if !strings.Contains(columns[2], "company::google") || !strings.Contains(columns[2], "difficulty::easy") {
+		t.Errorf("anki tags missing company or difficulty: %q", columns[2])
+	}
+}
+
+func TestRenderAnkiEscapesHTML(t *testing.T) {
+	list := List{Company: "google", Problems: []data.Problem{{
+		ID:         1,
+		URL:        "https://leetcode.com/problems/a?x=1&y=2",
+		Title:      "Sum <b> & Difference",
+		Difficulty: "Easy",
+	}}}
+	var buf bytes.Buffer
+	if err := Render(&buf, FormatAnki, list); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	columns := strings.Split(lines[3], "\t")
+	if columns[0] != "1. Sum &lt;b&gt; &amp; Difference" {
+		t.Errorf("front = %q, want the title escaped", columns[0])
+	}
+	if want := `<a href="https://leetcode.com/problems/a?x=1&amp;y=2">https://leetcode.com/problems/a?x=1&amp;y=2</a>`; !strings.HasPrefix(columns[1], want) {
+		t.Errorf("back = %q, want the URL escaped", columns[1])
+	}
+}
+
+func TestFilename(t *testing.T) {
+	if name := Filename(testList(), FormatMarkdown); name != "google-thirty-days.md" {
+		t.Errorf("Filename() = %q, want %q", name, "google-thirty-days.md")
+	}
+	if name := Filename(List{}, FormatCSV); name != "problems.csv" {
+		t.Errorf("Filename() = %q, want %q", name, "problems.csv")
+	}
+}
+
+func TestRender_UnknownFormat(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Render(&buf, Format("pdf"), testList()); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("Render() error = %v, want ErrUnknownFormat", err)
+	}
+}
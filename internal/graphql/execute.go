@@ -0,0 +1,844 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Request is a query with its variables, as sent over HTTP
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+// Response holds the result of a query. Data is nil when the query didn't
+// run because it was invalid or too expensive.
+type Response struct {
+	Data   any      `json:"data,omitempty"`
+	Errors []*Error `json:"errors,omitempty"`
+}
+
+// Error is a query or field error
+type Error struct {
+	Message   string     `json:"message"`
+	Locations []Location `json:"locations,omitempty"`
+	// Path is the response keys and list indexes of the field that failed
+	Path []any `json:"path,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if len(e.Locations) > 0 {
+		return fmt.Sprintf("%s (line %d, column %d)", e.Message, e.Locations[0].Line, e.Locations[0].Column)
+	}
+	return e.Message
+}
+
+// Execute validates and runs a query
+func (s *Schema) Execute(ctx context.Context, req Request) *Response {
+	doc, err := Parse(req.Query)
+	if err != nil {
+		return &Response{Errors: []*Error{asError(err)}}
+	}
+
+	op, err := selectOperation(doc, req.OperationName)
+	if err != nil {
+		return &Response{Errors: []*Error{asError(err)}}
+	}
+
+	e := &executor{ctx: ctx, schema: s, doc: doc}
+	e.coerceVariables(op, req.Variables)
+	if len(e.errors) > 0 {
+		return &Response{Errors: e.errors}
+	}
+
+	e.checkFragmentCycles()
+	if len(e.errors) > 0 {
+		return &Response{Errors: e.errors}
+	}
+
+	cost := e.check(s.query, op.Selections, 1)
+	if len(e.errors) > 0 {
+		return &Response{Errors: e.errors}
+	}
+	if s.limits.MaxComplexity > 0 && cost > s.limits.MaxComplexity {
+		return &Response{Errors: []*Error{{
+			Message:   fmt.Sprintf("query costs %d, more than the limit of %d; request fewer items with first", cost, s.limits.MaxComplexity),
+			Locations: []Location{op.Loc},
+		}}}
+	}
+
+	data, ok := e.executeSelections(s.query, nil, op.Selections, nil)
+	resp := &Response{Errors: e.errors}
+	if ok {
+		resp.Data = data
+	}
+	return resp
+}
+
+func selectOperation(doc *Document, name string) (*Operation, error) {
+	var op *Operation
+	switch {
+	case name != "":
+		for _, candidate := range doc.Operations {
+			if candidate.Name == name {
+				op = candidate
+			}
+		}
+		if op == nil {
+			return nil, &Error{Message: fmt.Sprintf("unknown operation %s", name)}
+		}
+	case len(doc.Operations) == 1:
+		op = doc.Operations[0]
+	default:
+		return nil, &Error{Message: "operationName is required when the document has several operations"}
+	}
+
+	if op.Type != "query" {
+		return nil, &Error{Message: "only queries are supported", Locations: []Location{op.Loc}}
+	}
+	return op, nil
+}
+
+func asError(err error) *Error {
+	var gqlErr *Error
+	if errors.As(err, &gqlErr) {
+		return gqlErr
+	}
+	return &Error{Message: err.Error()}
+}
+
+type executor struct {
+	ctx    context.Context
+	schema *Schema
+	doc    *Document
+	// vars holds the variables that were provided or have defaults
+	vars    map[string]any
+	defined map[string]bool
+	errors  []*Error
+	// fragments memoizes the fields each named fragment collects, so a
+	// fragment spread many times is only expanded once
+	fragments map[string][]*collected
+	// expansions counts the fields, fragment spreads and inline fragments
+	// collected so far, checked against Limits.MaxExpansions
+	expansions int
+	overBudget bool
+}
+
+func (e *executor) addError(loc Location, path []any, format string, args ...any) {
+	err := &Error{Message: fmt.Sprintf(format, args...), Locations: []Location{loc}}
+	if path != nil {
+		err.Path = append([]any(nil), path...)
+	}
+	e.errors = append(e.errors, err)
+}
+
+func (e *executor) coerceVariables(op *Operation, provided map[string]any) {
+	e.vars = make(map[string]any)
+	e.defined = make(map[string]bool)
+
+	for _, def := range op.Variables {
+		if e.defined[def.Name] {
+			e.addError(def.Loc, nil, "variable $%s is defined more than once", def.Name)
+			continue
+		}
+		e.defined[def.Name] = true
+		if !scalars[def.Type.named()] {
+			e.addError(def.Loc, nil, "variable $%s must be a scalar or list of scalars", def.Name)
+			continue
+		}
+
+		if value, ok := provided[def.Name]; ok {
+			coerced, err := coerceInput(value, def.Type)
+			if err != nil {
+				e.addError(def.Loc, nil, "variable $%s: %v", def.Name, err)
+				continue
+			}
+			e.vars[def.Name] = coerced
+		} else if def.Default != nil {
+			coerced, _, err := e.valueFromAST(def.Default, def.Type)
+			if err != nil {
+				e.addError(def.Loc, nil, "variable $%s default: %v", def.Name, err)
+				continue
+			}
+			e.vars[def.Name] = coerced
+		} else if def.Type.NonNull {
+			e.addError(def.Loc, nil, "variable $%s of type %s was not provided", def.Name, def.Type)
+		}
+	}
+}
+
+// collected is a response key and the fields selected under it
+type collected struct {
+	key    string
+	fields []*Field
+}
+
+// collectFields flattens fragments and drops skipped fields, merging fields
+// with the same response key
+func (e *executor) collectFields(obj *Object, selections []Selection, visiting map[string]bool, out []*collected) []*collected {
+	for _, selection := range selections {
+		switch sel := selection.(type) {
+		case *Field:
+			e.expansions++
+			if !e.included(sel.Directives) {
+				continue
+			}
+			out = mergeField(out, sel.ResponseKey(), sel)
+
+		case *FragmentSpread:
+			e.expansions++
+			if !e.included(sel.Directives) {
+				continue
+			}
+			fragment, ok := e.doc.Fragments[sel.Name]
+			if !ok {
+				e.addError(sel.Loc, nil, "unknown fragment %s", sel.Name)
+				continue
+			}
+			if visiting[sel.Name] {
+				continue
+			}
+			if fragment.TypeCondition != obj.Name {
+				e.addError(sel.Loc, nil, "fragment %s on %s can't be spread on %s", sel.Name, fragment.TypeCondition, obj.Name)
+				continue
+			}
+			fields, ok := e.fragments[sel.Name]
+			if !ok {
+				visiting[sel.Name] = true
+				fields = e.collectFields(obj, fragment.Selections, visiting, nil)
+				delete(visiting, sel.Name)
+				if e.fragments == nil {
+					e.fragments = make(map[string][]*collected)
+				}
+				e.fragments[sel.Name] = fields
+			}
+			for _, c := range fields {
+				e.expansions += len(c.fields)
+				for _, field := range c.fields {
+					out = mergeField(out, c.key, field)
+				}
+			}
+
+		case *InlineFragment:
+			e.expansions++
+			if !e.included(sel.Directives) {
+				continue
+			}
+			if sel.TypeCondition != "" && sel.TypeCondition != obj.Name {
+				e.addError(sel.Loc, nil, "fragment on %s can't be spread on %s", sel.TypeCondition, obj.Name)
+				continue
+			}
+			out = e.collectFields(obj, sel.Selections, visiting, out)
+		}
+	}
+	return out
+}
+
+// mergeField adds field under key, skipping a field that's already there
+// because the same fragment was spread twice
+func mergeField(out []*collected, key string, field *Field) []*collected {
+	for _, c := range out {
+		if c.key != key {
+			continue
+		}
+		for _, existing := range c.fields {
+			if existing == field {
+				return out
+			}
+		}
+		c.fields = append(c.fields, field)
+		return out
+	}
+	return append(out, &collected{key: key, fields: []*Field{field}})
+}
+
+// included evaluates @include and @skip
+func (e *executor) included(directives []*Directive) bool {
+	include := true
+	for _, directive := range directives {
+		if directive.Name != "include" && directive.Name != "skip" {
+			e.addError(directive.Loc, nil, "unknown directive @%s", directive.Name)
+			continue
+		}
+		if len(directive.Arguments) != 1 || directive.Arguments[0].Name != "if" {
+			e.addError(directive.Loc, nil, "@%s takes a single argument if", directive.Name)
+			continue
+		}
+		value, _, err := e.valueFromAST(directive.Arguments[0].Value, &Type{Name: "Boolean", NonNull: true})
+		if err != nil {
+			e.addError(directive.Loc, nil, "@%s(if:): %v", directive.Name, err)
+			continue
+		}
+		if value.(bool) == (directive.Name == "skip") {
+			include = false
+		}
+	}
+	return include
+}
+
+// checkFragmentCycles reports fragments that spread themselves, directly
+// or through nested fields and other fragments
+func (e *executor) checkFragmentCycles() {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int, len(e.doc.Fragments))
+
+	var visit func(name string, selections []Selection)
+	visit = func(name string, selections []Selection) {
+		for _, selection := range selections {
+			switch sel := selection.(type) {
+			case *Field:
+				visit(name, sel.Selections)
+			case *InlineFragment:
+				visit(name, sel.Selections)
+			case *FragmentSpread:
+				fragment, ok := e.doc.Fragments[sel.Name]
+				if !ok {
+					continue
+				}
+				switch state[sel.Name] {
+				case visiting:
+					e.addError(sel.Loc, nil, "fragment %s spreads itself", sel.Name)
+				case 0:
+					state[sel.Name] = visiting
+					visit(sel.Name, fragment.Selections)
+					state[sel.Name] = done
+				}
+			}
+		}
+	}
+
+	for name, fragment := range e.doc.Fragments {
+		if state[name] == 0 {
+			state[name] = visiting
+			visit(name, fragment.Selections)
+			state[name] = done
+		}
+	}
+}
+
+// check validates selections against obj and returns their estimated cost
+func (e *executor) check(obj *Object, selections []Selection, depth int) int {
+	if limit := e.schema.limits.MaxDepth; limit > 0 && depth > limit {
+		e.addError(selections[0].location(), nil, "query is nested more than %d levels deep", limit)
+		return 0
+	}
+	if e.overBudget {
+		return 0
+	}
+
+	fields := e.collectFields(obj, selections, make(map[string]bool), nil)
+	if limit := e.schema.limits.MaxExpansions; limit > 0 && e.expansions > limit {
+		e.addError(selections[0].location(), nil, "query expands to more than %d selections", limit)
+		e.overBudget = true
+		return 0
+	}
+
+	cost := 0
+	for _, c := range fields {
+		first := c.fields[0]
+		for _, field := range c.fields[1:] {
+			if field.Name != first.Name {
+				e.addError(field.Loc, nil, "%s selects both %s and %s", c.key, first.Name, field.Name)
+			}
+		}
+
+		if first.Name == "__typename" {
+			if len(first.Arguments) > 0 || len(first.Selections) > 0 {
+				e.addError(first.Loc, nil, "__typename takes no arguments or selections")
+			}
+			continue
+		}
+
+		def, ok := obj.fields[first.Name]
+		if !ok {
+			e.addError(first.Loc, nil, "type %s has no field %s", obj.Name, first.Name)
+			continue
+		}
+
+		var args map[string]any
+		for _, field := range c.fields {
+			var err error
+			if args, err = e.coerceArgs(def, field.Arguments); err != nil {
+				e.addError(field.Loc, nil, "%s: %v", field.Name, err)
+			}
+		}
+
+		var subselections []Selection
+		for _, field := range c.fields {
+			subselections = append(subselections, field.Selections...)
+		}
+
+		fieldCost := 1
+		if child, isObject := e.schema.objects[def.typ.named()]; isObject {
+			if len(subselections) == 0 {
+				e.addError(first.Loc, nil, "field %s of type %s needs a selection of subfields", first.Name, def.Type)
+				continue
+			}
+			childCost := e.check(child, subselections, depth+1)
+			fieldCost = saturatingAdd(1, saturatingMul(listSize(def, args), childCost))
+		} else if len(subselections) > 0 {
+			e.addError(first.Loc, nil, "field %s of type %s has no subfields", first.Name, def.Type)
+			continue
+		}
+		cost = saturatingAdd(cost, fieldCost)
+	}
+	return cost
+}
+
+// listSize is how many items a field is expected to return, 1 for fields
+// that aren't lists
+func listSize(def *FieldDef, args map[string]any) int {
+	if def.typ.Elem == nil {
+		return 1
+	}
+	if first, ok := args["first"].(int); ok {
+		return max(first, 0)
+	}
+	return def.ListSize
+}
+
+func saturatingAdd(a, b int) int {
+	if a > math.MaxInt32-b {
+		return math.MaxInt32
+	}
+	return a + b
+}
+
+func saturatingMul(a, b int) int {
+	if a != 0 && b > math.MaxInt32/a {
+		return math.MaxInt32
+	}
+	return a * b
+}
+
+// errOmitted is returned by valueFromAST for variables that are defined but
+// weren't provided and have no default
+var errOmitted = errors.New("omitted")
+
+func (e *executor) coerceArgs(def *FieldDef, args []*Argument) (map[string]any, error) {
+	values := make(map[string]any, len(def.Args))
+	seen := make(map[string]bool, len(args))
+
+	for _, arg := range args {
+		if seen[arg.Name] {
+			return nil, fmt.Errorf("argument %s is given more than once", arg.Name)
+		}
+		seen[arg.Name] = true
+
+		var argDef *ArgDef
+		for _, candidate := range def.Args {
+			if candidate.Name == arg.Name {
+				argDef = candidate
+			}
+		}
+		if argDef == nil {
+			return nil, fmt.Errorf("unknown argument %s", arg.Name)
+		}
+
+		value, omitted, err := e.valueFromAST(arg.Value, argDef.typ)
+		if err != nil {
+			return nil, fmt.Errorf("argument %s: %w", arg.Name, err)
+		}
+		if !omitted {
+			values[arg.Name] = value
+		}
+	}
+
+	for _, argDef := range def.Args {
+		if _, ok := values[argDef.Name]; ok {
+			continue
+		}
+		if argDef.Default != nil {
+			values[argDef.Name], _ = coerceInput(argDef.Default, argDef.typ)
+		} else if argDef.typ.NonNull {
+			return nil, fmt.Errorf("argument %s of type %s is required", argDef.Name, argDef.Type)
+		}
+	}
+	return values, nil
+}
+
+// valueFromAST coerces a literal or variable to t. omitted is true for
+// variables that weren't provided.
+func (e *executor) valueFromAST(v *Value, t *Type) (value any, omitted bool, err error) {
+	if v.Kind == VariableValue {
+		if !e.defined[v.Raw] {
+			return nil, false, fmt.Errorf("variable $%s is not defined", v.Raw)
+		}
+		value, ok := e.vars[v.Raw]
+		if !ok {
+			if t.NonNull {
+				return nil, false, fmt.Errorf("variable $%s is required", v.Raw)
+			}
+			return nil, true, nil
+		}
+		value, err := coerceInput(value, t)
+		return value, false, err
+	}
+
+	if v.Kind == NullValue {
+		if t.NonNull {
+			return nil, false, fmt.Errorf("expected %s, got null", t)
+		}
+		return nil, false, nil
+	}
+
+	if t.Elem != nil {
+		items := v.List
+		if v.Kind != ListValue {
+			items = []*Value{v}
+		}
+		list := make([]any, 0, len(items))
+		for _, item := range items {
+			value, _, err := e.valueFromAST(item, t.Elem)
+			if err != nil {
+				return nil, false, err
+			}
+			list = append(list, value)
+		}
+		return list, false, nil
+	}
+
+	switch t.Name {
+	case "Int":
+		if v.Kind == IntValue {
+			n, err := strconv.ParseInt(v.Raw, 10, 32)
+			if err == nil {
+				return int(n), false, nil
+			}
+		}
+	case "Float":
+		if v.Kind == IntValue || v.Kind == FloatValue {
+			f, err := strconv.ParseFloat(v.Raw, 64)
+			if err == nil {
+				return f, false, nil
+			}
+		}
+	case "String":
+		if v.Kind == StringValue {
+			return v.Raw, false, nil
+		}
+	case "ID":
+		if v.Kind == StringValue || v.Kind == IntValue {
+			return v.Raw, false, nil
+		}
+	case "Boolean":
+		if v.Kind == BooleanValue {
+			return v.Raw == "true", false, nil
+		}
+	}
+	return nil, false, fmt.Errorf("expected %s", t)
+}
+
+// coerceInput coerces a JSON decoded value or argument default to t
+func coerceInput(v any, t *Type) (any, error) {
+	if v == nil {
+		if t.NonNull {
+			return nil, fmt.Errorf("expected %s, got null", t)
+		}
+		return nil, nil
+	}
+
+	if t.Elem != nil {
+		items, ok := v.([]any)
+		if !ok {
+			items = []any{v}
+		}
+		list := make([]any, 0, len(items))
+		for _, item := range items {
+			value, err := coerceInput(item, t.Elem)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		return list, nil
+	}
+
+	switch t.Name {
+	case "Int":
+		if n, ok := toInt(v); ok {
+			return n, nil
+		}
+	case "Float":
+		if f, ok := toFloat(v); ok {
+			return f, nil
+		}
+	case "String":
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+	case "ID":
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+		if n, ok := toInt(v); ok {
+			return strconv.Itoa(n), nil
+		}
+	case "Boolean":
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+	}
+	return nil, fmt.Errorf("expected %s, got %v", t, v)
+}
+
+func toInt(v any) (int, bool) {
+	var n float64
+	switch v := v.(type) {
+	case int:
+		n = float64(v)
+	case int64:
+		n = float64(v)
+	case float64:
+		n = v
+	default:
+		return 0, false
+	}
+	if n != math.Trunc(n) || n < math.MinInt32 || n > math.MaxInt32 {
+		return 0, false
+	}
+	return int(n), true
+}
+
+func toFloat(v any) (float64, bool) {
+	switch v := v.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// orderedMap is an object in the response, with keys in selection order
+type orderedMap []keyValue
+
+type keyValue struct {
+	key   string
+	value any
+}
+
+func (m orderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, kv := range m {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(kv.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(kv.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// executeSelections resolves the selections of obj against source. ok is
+// false when a non-null field failed and the object must become null.
+func (e *executor) executeSelections(obj *Object, source any, selections []Selection, path []any) (orderedMap, bool) {
+	fields := e.collectFields(obj, selections, make(map[string]bool), nil)
+	result := make(orderedMap, 0, len(fields))
+
+	for _, c := range fields {
+		field := c.fields[0]
+		fieldPath := append(path[:len(path):len(path)], c.key)
+
+		if field.Name == "__typename" {
+			result = append(result, keyValue{c.key, obj.Name})
+			continue
+		}
+
+		def := obj.fields[field.Name]
+		value, ok := e.executeField(def, c.fields, source, fieldPath)
+		if !ok {
+			return nil, false
+		}
+		result = append(result, keyValue{c.key, value})
+	}
+	return result, true
+}
+
+func (e *executor) executeField(def *FieldDef, fields []*Field, source any, path []any) (any, bool) {
+	field := fields[0]
+	args, err := e.coerceArgs(def, field.Arguments)
+	if err == nil {
+		if err = e.ctx.Err(); err == nil {
+			var value any
+			if def.Resolve != nil {
+				value, err = def.Resolve(ResolveParams{Context: e.ctx, Source: source, Args: args})
+			} else {
+				value, err = defaultResolve(source, def.Name)
+			}
+			if err == nil {
+				return e.completeValue(def.typ, fields, value, path)
+			}
+		}
+	}
+
+	e.addError(field.Loc, path, "%v", err)
+	return nil, !def.typ.NonNull
+}
+
+// completeValue converts a resolved value to its response form. ok is false
+// when the value is null but mustn't be, and the parent must become null.
+func (e *executor) completeValue(t *Type, fields []*Field, value any, path []any) (any, bool) {
+	nullable := *t
+	nullable.NonNull = false
+
+	completed, ok := e.completeNullable(&nullable, fields, value, path)
+	if !ok {
+		return nil, !t.NonNull
+	}
+	if completed == nil && t.NonNull {
+		e.addError(fields[0].Loc, path, "%s can't be null", fields[0].Name)
+		return nil, false
+	}
+	return completed, true
+}
+
+func (e *executor) completeNullable(t *Type, fields []*Field, value any, path []any) (any, bool) {
+	if isNil(value) {
+		return nil, true
+	}
+
+	if t.Elem != nil {
+		list := reflect.ValueOf(value)
+		if list.Kind() != reflect.Slice && list.Kind() != reflect.Array {
+			e.addError(fields[0].Loc, path, "%s resolved to %T instead of a list", fields[0].Name, value)
+			return nil, true
+		}
+		items := make([]any, list.Len())
+		for i := range items {
+			item, ok := e.completeValue(t.Elem, fields, list.Index(i).Interface(), append(path[:len(path):len(path)], i))
+			if !ok {
+				return nil, false
+			}
+			items[i] = item
+		}
+		return items, true
+	}
+
+	if obj, ok := e.schema.objects[t.Name]; ok {
+		var selections []Selection
+		for _, field := range fields {
+			selections = append(selections, field.Selections...)
+		}
+		result, ok := e.executeSelections(obj, value, selections, path)
+		if !ok {
+			return nil, false
+		}
+		return result, true
+	}
+
+	serialized, err := serialize(t.Name, value)
+	if err != nil {
+		e.addError(fields[0].Loc, path, "%s: %v", fields[0].Name, err)
+		return nil, true
+	}
+	return serialized, true
+}
+
+func serialize(scalar string, value any) (any, error) {
+	v := reflect.ValueOf(value)
+	switch scalar {
+	case "Int":
+		switch {
+		case v.CanInt() && v.Int() >= math.MinInt32 && v.Int() <= math.MaxInt32:
+			return v.Int(), nil
+		case v.CanUint() && v.Uint() <= math.MaxInt32:
+			return v.Uint(), nil
+		}
+	case "Float":
+		switch {
+		case v.CanFloat() && !math.IsNaN(v.Float()) && !math.IsInf(v.Float(), 0):
+			return v.Float(), nil
+		case v.CanInt():
+			return float64(v.Int()), nil
+		}
+	case "String":
+		if v.Kind() == reflect.String {
+			return v.String(), nil
+		}
+	case "ID":
+		if v.Kind() == reflect.String {
+			return v.String(), nil
+		}
+		if v.CanInt() {
+			return strconv.FormatInt(v.Int(), 10), nil
+		}
+	case "Boolean":
+		if v.Kind() == reflect.Bool {
+			return v.Bool(), nil
+		}
+	}
+	return nil, fmt.Errorf("can't serialize %T as %s", value, scalar)
+}
+
+// isNil reports values that complete as null. Nil slices are empty lists,
+// so resolvers can return them for non-null list fields.
+func isNil(value any) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
+
+// defaultResolve reads a field from a struct or map source
+func defaultResolve(source any, name string) (any, error) {
+	v := reflect.ValueOf(source)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		r, size := utf8.DecodeRuneInString(name)
+		field := v.FieldByName(string(unicode.ToUpper(r)) + name[size:])
+		if !field.IsValid() {
+			// initialisms, like id for ID
+			field = v.FieldByNameFunc(func(fieldName string) bool { return strings.EqualFold(fieldName, name) })
+		}
+		if field.IsValid() && field.CanInterface() {
+			return field.Interface(), nil
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() == reflect.String {
+			if value := v.MapIndex(reflect.ValueOf(name)); value.IsValid() {
+				return value.Interface(), nil
+			}
+			return nil, nil
+		}
+	}
+	return nil, fmt.Errorf("no resolver for %s on %s", name, strings.TrimPrefix(fmt.Sprintf("%T", source), "*"))
+}
@@ -0,0 +1,332 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testItem struct {
+	ID   int
+	Name string
+	Tags []string
+}
+
+func testSchema(t *testing.T, limits Limits) *Schema {
+	t.Helper()
+	items := []*testItem{{1, "one", []string{"a"}}, {2, "two", nil}, {3, "three", []string{"b", "c"}}}
+
+	item := &Object{
+		Name: "Item",
+		Fields: []*FieldDef{
+			{Name: "id", Type: "Int!"},
+			{Name: "name", Type: "String!"},
+			{Name: "tags", Type: "[String!]"},
+			{Name: "broken", Type: "String!", Resolve: func(p ResolveParams) (any, error) { return nil, nil }},
+			{Name: "next", Type: "Item", Resolve: func(p ResolveParams) (any, error) {
+				id := p.Source.(*testItem).ID
+				if id < len(items) {
+					return items[id], nil
+				}
+				return nil, nil
+			}},
+		},
+	}
+	query := &Object{
+		Name: "Query",
+		Fields: []*FieldDef{
+			{Name: "hello", Type: "String!", Description: "Greets someone",
+				Args: []*ArgDef{{Name: "name", Type: "String", Default: "world"}},
+				Resolve: func(p ResolveParams) (any, error) {
+					return "hello " + p.Args["name"].(string), nil
+				}},
+			{Name: "item", Type: "Item",
+				Args: []*ArgDef{{Name: "id", Type: "Int!"}},
+				Resolve: func(p ResolveParams) (any, error) {
+					for _, item := range items {
+						if item.ID == p.Args["id"].(int) {
+							return item, nil
+						}
+					}
+					return nil, nil
+				}},
+			{Name: "items", Type: "[Item!]!",
+				Args: []*ArgDef{{Name: "first", Type: "Int", Default: 2}, {Name: "names", Type: "[String!]"}},
+				Resolve: func(p ResolveParams) (any, error) {
+					if names, ok := p.Args["names"].([]any); ok {
+						var matched []*testItem
+						for _, item := range items {
+							for _, name := range names {
+								if item.Name == name {
+									matched = append(matched, item)
+								}
+							}
+						}
+						return matched, nil
+					}
+					return items[:min(p.Args["first"].(int), len(items))], nil
+				}},
+			{Name: "failing", Type: "String", Resolve: func(p ResolveParams) (any, error) {
+				return nil, errors.New("it failed")
+			}},
+		},
+	}
+
+	schema, err := NewSchema(query, limits, item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return schema
+}
+
+func TestExecute(t *testing.T) {
+	schema := testSchema(t, Limits{})
+
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]any
+		want      string
+		errors    []string
+	}{
+		{
+			name:  "defaults and aliases",
+			query: `{ hello greeting: hello(name: "you") }`,
+			want:  `{"hello":"hello world","greeting":"hello you"}`,
+		},
+		{
+			name:  "nested objects and lists",
+			query: `query { items { id name tags next { name } } }`,
+			want:  `{"items":[{"id":1,"name":"one","tags":["a"],"next":{"name":"two"}},{"id":2,"name":"two","tags":[],"next":{"name":"three"}}]}`,
+		},
+		{
+			name:      "variables",
+			query:     `query Pick($id: Int!, $names: [String!] = ["three"]) { item(id: $id) { name } items(names: $names) { id } }`,
+			variables: map[string]any{"id": float64(2)},
+			want:      `{"item":{"name":"two"},"items":[{"id":3}]}`,
+		},
+		{
+			name:  "single value for a list argument",
+			query: `{ items(names: "one") { id } }`,
+			want:  `{"items":[{"id":1}]}`,
+		},
+		{
+			name:  "fragments, typename and directives",
+			query: `query($skip: Boolean = true) { item(id: 1) { ...Names ... on Item { id } tags @skip(if: $skip) __typename } } fragment Names on Item { name }`,
+			want:  `{"item":{"name":"one","id":1,"__typename":"Item"}}`,
+		},
+		{
+			name:   "resolver errors null the field",
+			query:  `{ failing hello }`,
+			want:   `{"failing":null,"hello":"hello world"}`,
+			errors: []string{"it failed"},
+		},
+		{
+			name:   "null in a non-null field nulls the parent",
+			query:  `{ item(id: 1) { name broken } }`,
+			want:   `{"item":null}`,
+			errors: []string{"broken can't be null"},
+		},
+		{
+			name:   "null in a non-null list item nulls the list and its non-null parent",
+			query:  `{ items { broken } }`,
+			errors: []string{"broken can't be null"},
+		},
+		{name: "unknown field", query: `{ nope }`, errors: []string{"type Query has no field nope"}},
+		{name: "missing selection", query: `{ item(id: 1) }`, errors: []string{"needs a selection of subfields"}},
+		{name: "selection on a scalar", query: `{ hello { x } }`, errors: []string{"has no subfields"}},
+		{name: "missing argument", query: `{ item { id } }`, errors: []string{"argument id of type Int! is required"}},
+		{name: "unknown argument", query: `{ hello(nope: 1) }`, errors: []string{"unknown argument nope"}},
+		{name: "wrong argument type", query: `{ item(id: "1") { id } }`, errors: []string{"argument id: expected Int!"}},
+		{name: "undefined variable", query: `{ item(id: $id) { id } }`, errors: []string{"variable $id is not defined"}},
+		{name: "missing variable", query: `query($id: Int!) { item(id: $id) { id } }`, errors: []string{"variable $id of type Int! was not provided"}},
+		{name: "unknown fragment", query: `{ item(id: 1) { ...Nope } }`, errors: []string{"unknown fragment Nope"}},
+		{name: "fragment cycle", query: `{ item(id: 1) { ...A } } fragment A on Item { next { ...A } }`, errors: []string{"fragment A spreads itself"}},
+		{name: "conflicting fields", query: `{ x: hello x: failing }`, errors: []string{"x selects both hello and failing"}},
+		{name: "mutation", query: `mutation { hello }`, errors: []string{"only queries are supported"}},
+		{name: "syntax error", query: `{ hello(name: "x) }`, errors: []string{"syntax error: unterminated string"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := schema.Execute(context.Background(), Request{Query: tt.query, Variables: tt.variables})
+
+			if len(resp.Errors) != len(tt.errors) {
+				t.Fatalf("expected errors %v, got %v", tt.errors, resp.Errors)
+			}
+			for i, want := range tt.errors {
+				if !strings.Contains(resp.Errors[i].Message, want) {
+					t.Errorf("error %q doesn't mention %q", resp.Errors[i].Message, want)
+				}
+			}
+
+			if tt.want == "" {
+				if resp.Data != nil {
+					t.Errorf("expected no data, got %v", resp.Data)
+				}
+				return
+			}
+			got, err := json.Marshal(resp.Data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorPathAndLocation(t *testing.T) {
+	schema := testSchema(t, Limits{})
+
+	resp := schema.Execute(context.Background(), Request{Query: "{\n  items { broken }\n}"})
+	if len(resp.Errors) != 1 {
+		t.Fatalf("expected 1 error, got %v", resp.Errors)
+	}
+	err := resp.Errors[0]
+	if path, _ := json.Marshal(err.Path); string(path) != `["items",0,"broken"]` {
+		t.Errorf("unexpected path %s", path)
+	}
+	if err.Locations[0] != (Location{Line: 2, Column: 11}) {
+		t.Errorf("unexpected location %+v", err.Locations[0])
+	}
+}
+
+func TestLimits(t *testing.T) {
+	schema := testSchema(t, Limits{MaxDepth: 3, MaxComplexity: 50})
+
+	tests := []struct {
+		query string
+		err   string
+	}{
+		{`{ item(id: 1) { next { next { name } } } }`, "nested more than 3 levels"},
+		// 1 + 2 * (1 + 1 + 1) = 7
+		{`{ items { id next { id } } }`, ""},
+		// 1 + 20 * (1 + 1 + 1) = 61
+		{`{ items(first: 20) { id next { id } } }`, "query costs 61, more than the limit of 50"},
+		{`query($n: Int) { items(first: $n) { id } }`, ""},
+		{`{ items(first: 2000000000) { next { id } } }`, "more than the limit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			resp := schema.Execute(context.Background(), Request{Query: tt.query})
+			switch {
+			case tt.err == "" && len(resp.Errors) > 0:
+				t.Errorf("unexpected errors %v", resp.Errors)
+			case tt.err != "" && (len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0].Message, tt.err)):
+				t.Errorf("expected an error mentioning %q, got %v", tt.err, resp.Errors)
+			}
+		})
+	}
+}
+
+// TestFragmentExpansion builds fragments that each spread the next one
+// twice, which would take 2^levels steps to expand naively
+func TestFragmentExpansion(t *testing.T) {
+	const levels = 40
+	var query strings.Builder
+	query.WriteString("{ item(id: 1) { ...F0 } }\n")
+	for i := range levels {
+		fmt.Fprintf(&query, "fragment F%d on Item { id ...F%d ...F%d }\n", i, i+1, i+1)
+	}
+	fmt.Fprintf(&query, "fragment F%d on Item { name }\n", levels)
+
+	done := make(chan *Response, 1)
+	go func() {
+		done <- testSchema(t, Limits{MaxExpansions: 10000}).Execute(context.Background(), Request{Query: query.String()})
+	}()
+	select {
+	case resp := <-done:
+		if len(resp.Errors) > 0 {
+			t.Fatalf("unexpected errors %v", resp.Errors)
+		}
+		got, _ := json.Marshal(resp.Data)
+		if want := `{"item":{"id":1,"name":"one"}}`; string(got) != want {
+			t.Errorf("expected %s, got %s", want, got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expanding the fragments took too long")
+	}
+
+	// Aliases at every level still fan out, so the budget rejects them
+	var wide strings.Builder
+	wide.WriteString("{ item(id: 1) { ...W0 } }\n")
+	for i := range 8 {
+		fmt.Fprintf(&wide, "fragment W%d on Item { a: next { ...W%d } b: next { ...W%d } c: next { ...W%d } }\n", i, i+1, i+1, i+1)
+	}
+	wide.WriteString("fragment W8 on Item { id }\n")
+	resp := testSchema(t, Limits{MaxExpansions: 1000}).Execute(context.Background(), Request{Query: wide.String()})
+	if len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0].Message, "more than 1000 selections") {
+		t.Errorf("expected an expansion error, got %v", resp.Errors)
+	}
+}
+
+func TestNewSchemaErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		field *FieldDef
+	}{
+		{"unknown type", &FieldDef{Name: "x", Type: "Nope"}},
+		{"bad type", &FieldDef{Name: "x", Type: "[Int"}},
+		{"object argument", &FieldDef{Name: "x", Type: "Int", Args: []*ArgDef{{Name: "q", Type: "Query"}}}},
+		{"bad default", &FieldDef{Name: "x", Type: "Int", Args: []*ArgDef{{Name: "n", Type: "Int", Default: "ten"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewSchema(&Object{Name: "Query", Fields: []*FieldDef{tt.field}}, Limits{}); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestHandler(t *testing.T) {
+	handler := testSchema(t, Limits{}).Handler()
+
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		contentType string
+		body        string
+		status      int
+		contains    string
+	}{
+		{"schema", http.MethodGet, "/graphql", "", "", http.StatusOK, `hello(name: String = "world"): String!`},
+		{"get", http.MethodGet, `/graphql?query={item(id:$id){name}}&variables={"id":3}`, "", "", http.StatusBadRequest, "variable $id is not defined"},
+		{"get with variables", http.MethodGet, `/graphql?query=query($id:Int!){item(id:$id){name}}&variables={"id":3}`, "", "", http.StatusOK, `{"data":{"item":{"name":"three"}}}`},
+		{"post", http.MethodPost, "/graphql", "application/json; charset=utf-8", `{"query":"query($id: Int!) { item(id: $id) { name } }","variables":{"id":1}}`, http.StatusOK, `{"data":{"item":{"name":"one"}}}`},
+		{"field error", http.MethodPost, "/graphql", "application/json", `{"query":"{ failing }"}`, http.StatusOK, `"path":["failing"]`},
+		{"invalid query", http.MethodPost, "/graphql", "application/json", `{"query":"{ nope }"}`, http.StatusBadRequest, "no field nope"},
+		{"bad body", http.MethodPost, "/graphql", "application/json", `{"query":`, http.StatusBadRequest, "invalid request body"},
+		{"form body", http.MethodPost, "/graphql", "application/x-www-form-urlencoded", `query={hello}`, http.StatusUnsupportedMediaType, "application/json"},
+		{"method", http.MethodDelete, "/graphql", "", "", http.StatusMethodNotAllowed, "use GET or POST"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := strings.NewReplacer("{", "%7B", "}", "%7D", `"`, "%22", "$", "%24").Replace(tt.target)
+			req := httptest.NewRequest(tt.method, target, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Errorf("expected status %d, got %d: %s", tt.status, rec.Code, rec.Body.String())
+			}
+			if !strings.Contains(rec.Body.String(), tt.contains) {
+				t.Errorf("expected the body to contain %q, got %s", tt.contains, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package graphql
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+
+	"github.com/whotypes/leetbot/internal/logging"
+)
+
+// maxRequestBytes bounds POST bodies
+const maxRequestBytes = 64 << 10
+
+// Handler serves queries as POST requests with a JSON body, or as GET
+// requests with query, operationName and variables parameters. A GET without
+// a query returns the schema in SDL. Invalid or too expensive queries get a
+// 400; queries that ran get a 200, with any field errors in errors.
+func (s *Schema) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Request
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			query := r.URL.Query()
+			if query.Get("query") == "" {
+				w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+				_, _ = io.WriteString(w, s.SDL())
+				return
+			}
+			req.Query = query.Get("query")
+			req.OperationName = query.Get("operationName")
+			if variables := query.Get("variables"); variables != "" {
+				if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+					writeResponse(w, http.StatusBadRequest, &Response{Errors: []*Error{{Message: "variables must be a JSON object"}}})
+					return
+				}
+			}
+
+		case http.MethodPost:
+			if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+				writeResponse(w, http.StatusUnsupportedMediaType, &Response{Errors: []*Error{{Message: "the body must be application/json"}}})
+				return
+			}
+			decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes))
+			decoder.UseNumber()
+			if err := decoder.Decode(&req); err != nil {
+				writeResponse(w, http.StatusBadRequest, &Response{Errors: []*Error{{Message: "invalid request body: " + err.Error()}}})
+				return
+			}
+			req.Variables = normalizeNumbers(req.Variables).(map[string]any)
+
+		default:
+			w.Header().Set("Allow", "GET, HEAD, POST")
+			writeResponse(w, http.StatusMethodNotAllowed, &Response{Errors: []*Error{{Message: "use GET or POST"}}})
+			return
+		}
+
+		resp := s.Execute(r.Context(), req)
+		status := http.StatusOK
+		if resp.Data == nil {
+			status = http.StatusBadRequest
+		}
+		writeResponse(w, status, resp)
+	})
+}
+
+// normalizeNumbers turns json.Number into int64 or float64, so integers
+// beyond float64 precision aren't silently rounded into range
+func normalizeNumbers(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]any:
+		for key, value := range v {
+			v[key] = normalizeNumbers(value)
+		}
+		return v
+	case []any:
+		for i, value := range v {
+			v[i] = normalizeNumbers(value)
+		}
+		return v
+	}
+	return v
+}
+
+func writeResponse(w http.ResponseWriter, status int, resp *Response) {
+	w.Header().Set("Content-Type", "application/graphql-response+json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Error("encoding graphql response failed", logging.Err(err))
+	}
+}
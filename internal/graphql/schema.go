@@ -0,0 +1,195 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// scalars are the built in leaf types
+var scalars = map[string]bool{"Int": true, "Float": true, "String": true, "Boolean": true, "ID": true}
+
+// Object is an object type and its fields
+type Object struct {
+	Name        string
+	Description string
+	Fields      []*FieldDef
+
+	fields map[string]*FieldDef
+}
+
+// FieldDef is a field of an object type
+type FieldDef struct {
+	Name        string
+	Description string
+	// Type is a type reference like [Problem!]!
+	Type string
+	Args []*ArgDef
+	// Resolve returns the field's value. Without one the field is read from
+	// the source struct's exported field with the capitalized name, so
+	// uniqueProblems reads UniqueProblems, or failing that the field with
+	// the same name ignoring case, so id reads ID.
+	Resolve ResolveFunc
+	// ListSize is the expected length of a list field without a first
+	// argument, used to estimate query cost. It defaults to 10.
+	ListSize int
+
+	typ *Type
+}
+
+// ArgDef is an argument of a field. Only scalar and list of scalar
+// arguments are supported.
+type ArgDef struct {
+	Name        string
+	Description string
+	Type        string
+	// Default is used when the argument is omitted, nil for none
+	Default any
+
+	typ *Type
+}
+
+// ResolveFunc computes a field from its parent's value
+type ResolveFunc func(p ResolveParams) (any, error)
+
+type ResolveParams struct {
+	Context context.Context
+	// Source is the value of the parent object, nil for Query fields
+	Source any
+	// Args holds the coerced arguments: int, float64, string, bool, []any or
+	// nil, with defaults filled in
+	Args map[string]any
+}
+
+// Limits bounds the queries a schema will run. Zero values disable a limit.
+type Limits struct {
+	// MaxDepth is how deeply selections can nest
+	MaxDepth int
+	// MaxComplexity caps the estimated number of resolved fields. Each field
+	// costs 1, and list fields multiply the cost of their selections by their
+	// first argument or ListSize.
+	MaxComplexity int
+	// MaxExpansions caps how many fields, fragment spreads and inline
+	// fragments validation walks. A fragment counts its fields again every
+	// time it's spread, so reusing fragments can't hide a huge query.
+	MaxExpansions int
+}
+
+// Schema is a set of object types rooted at a Query type
+type Schema struct {
+	query   *Object
+	objects map[string]*Object
+	order   []*Object
+	limits  Limits
+}
+
+const defaultListSize = 10
+
+// NewSchema checks that every type referenced by query and objects exists
+func NewSchema(query *Object, limits Limits, objects ...*Object) (*Schema, error) {
+	s := &Schema{
+		query:   query,
+		objects: make(map[string]*Object),
+		limits:  limits,
+	}
+
+	for _, obj := range append([]*Object{query}, objects...) {
+		if _, exists := s.objects[obj.Name]; exists || scalars[obj.Name] {
+			return nil, fmt.Errorf("graphql: type %s is defined more than once", obj.Name)
+		}
+		s.objects[obj.Name] = obj
+		s.order = append(s.order, obj)
+	}
+
+	for _, obj := range s.order {
+		obj.fields = make(map[string]*FieldDef, len(obj.Fields))
+		for _, field := range obj.Fields {
+			if err := s.initField(obj, field); err != nil {
+				return nil, err
+			}
+			obj.fields[field.Name] = field
+		}
+	}
+	return s, nil
+}
+
+func (s *Schema) initField(obj *Object, field *FieldDef) error {
+	if _, exists := obj.fields[field.Name]; exists || strings.HasPrefix(field.Name, "__") {
+		return fmt.Errorf("graphql: field %s.%s is invalid or defined more than once", obj.Name, field.Name)
+	}
+
+	typ, err := ParseType(field.Type)
+	if err != nil {
+		return fmt.Errorf("graphql: field %s.%s: %w", obj.Name, field.Name, err)
+	}
+	if named := typ.named(); !scalars[named] && s.objects[named] == nil {
+		return fmt.Errorf("graphql: field %s.%s has unknown type %s", obj.Name, field.Name, named)
+	}
+	field.typ = typ
+	if field.ListSize == 0 {
+		field.ListSize = defaultListSize
+	}
+
+	for _, arg := range field.Args {
+		typ, err := ParseType(arg.Type)
+		if err != nil {
+			return fmt.Errorf("graphql: argument %s.%s(%s): %w", obj.Name, field.Name, arg.Name, err)
+		}
+		if !scalars[typ.named()] {
+			return fmt.Errorf("graphql: argument %s.%s(%s) must be a scalar", obj.Name, field.Name, arg.Name)
+		}
+		arg.typ = typ
+		if arg.Default != nil {
+			if _, err := coerceInput(arg.Default, typ); err != nil {
+				return fmt.Errorf("graphql: argument %s.%s(%s) default: %w", obj.Name, field.Name, arg.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// SDL describes the schema in the GraphQL schema definition language
+func (s *Schema) SDL() string {
+	var b strings.Builder
+	for i, obj := range s.order {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		writeDescription(&b, "", obj.Description)
+		fmt.Fprintf(&b, "type %s {\n", obj.Name)
+		for _, field := range obj.Fields {
+			writeDescription(&b, "  ", field.Description)
+			b.WriteString("  " + field.Name)
+			if len(field.Args) > 0 {
+				args := make([]string, len(field.Args))
+				for i, arg := range field.Args {
+					args[i] = arg.Name + ": " + arg.Type
+					if arg.Default != nil {
+						args[i] += " = " + literal(arg.Default)
+					}
+				}
+				b.WriteString("(" + strings.Join(args, ", ") + ")")
+			}
+			b.WriteString(": " + field.Type + "\n")
+		}
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
+
+func writeDescription(b *strings.Builder, indent, description string) {
+	if description != "" {
+		b.WriteString(indent + literal(description) + "\n")
+	}
+}
+
+// literal formats a Go value as a GraphQL literal, which for the values
+// arguments take is the same as JSON
+func literal(v any) string {
+	out, err := json.Marshal(v)
+	if err != nil {
+		return "null"
+	}
+	return string(out)
+}
@@ -0,0 +1,134 @@
+// Package health serves the liveness, readiness and info endpoints shared by
+// the bot and the server.
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/whotypes/leetbot/internal/buildinfo"
+	"github.com/whotypes/leetbot/internal/data"
+	"github.com/whotypes/leetbot/pkg/api"
+)
+
+// Check reports why a dependency isn't ready, or nil when it is
+type Check func() error
+
+// Checker runs named readiness checks
+type Checker struct {
+	mu     sync.RWMutex
+	names  []string
+	checks map[string]Check
+}
+
+func NewChecker() *Checker {
+	return &Checker{checks: make(map[string]Check)}
+}
+
+// Add registers a readiness check, replacing any check with the same name
+func (c *Checker) Add(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.checks[name]; !exists {
+		c.names = append(c.names, name)
+	}
+	c.checks[name] = check
+}
+
+// Status is the body of /healthz and /readyz
+type Status struct {
+	Status string `json:"status"`
+	// Checks maps each readiness check to "ok" or the reason it failed
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// Run runs every check and reports whether all of them passed
+func (c *Checker) Run() (Status, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	status := Status{Status: "ready", Checks: make(map[string]string, len(c.names))}
+	ready := true
+	for _, name := range c.names {
+		if err := c.checks[name](); err != nil {
+			status.Checks[name] = err.Error()
+			ready = false
+			continue
+		}
+		status.Checks[name] = "ok"
+	}
+	if !ready {
+		status.Status = "not ready"
+	}
+	return status, ready
+}
+
+// Readyz answers 200 when every check passes and 503 otherwise, so load
+// balancers stop routing to an instance that can't serve
+func (c *Checker) Readyz(w http.ResponseWriter, r *http.Request) {
+	status, ready := c.Run()
+	code := http.StatusOK
+	if !ready {
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, status)
+}
+
+// Healthz answers 200 as long as the process can serve HTTP at all
+func Healthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, Status{Status: "ok"})
+}
+
+// DataLoaded is a readiness check that fails until the dataset has companies
+func DataLoaded(problemsData func() *data.ProblemsByCompany) Check {
+	return func() error {
+		pbc := problemsData()
+		if pbc == nil {
+			return errors.New("problems data not loaded")
+		}
+		if pbc.Version().Companies == 0 {
+			return errors.New("problems data is empty")
+		}
+		return nil
+	}
+}
+
+// startedAt is when the process started, close enough to package init
+var startedAt = time.Now()
+
+// NewInfo describes the running build and its dataset
+func NewInfo(pbc *data.ProblemsByCompany) api.Info {
+	build := buildinfo.Get()
+	info := api.Info{
+		Version:   build.Version,
+		Commit:    build.Commit,
+		GoVersion: build.GoVersion,
+		StartedAt: startedAt,
+	}
+	if pbc != nil {
+		version := pbc.Version()
+		info.DatasetVersion = version.Version
+		info.DatasetHash = version.Hash
+		info.Companies = version.Companies
+		info.TotalProblems = version.Problems
+		info.UniqueProblems = version.UniqueProblems
+	}
+	return info
+}
+
+// InfoHandler serves /api/info in the same envelope as the rest of the API
+func InfoHandler(problemsData func() *data.ProblemsByCompany) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, api.Response[api.Info]{Success: true, Data: NewInfo(problemsData())})
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
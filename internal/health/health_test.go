@@ -0,0 +1,122 @@
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/whotypes/leetbot/internal/data"
+)
+
+func testData() *data.ProblemsByCompany {
+	return data.NewTestProblemsByCompany(map[string]map[string][]data.Problem{
+		"google": {"all": {{ID: 1, Title: "Two Sum"}, {ID: 2, Title: "Add Two Numbers"}}},
+		"meta":   {"all": {{ID: 1, Title: "Two Sum"}}},
+	})
+}
+
+func TestReadyz(t *testing.T) {
+	var pbc *data.ProblemsByCompany
+	connected := false
+
+	checker := NewChecker()
+	checker.Add("data", DataLoaded(func() *data.ProblemsByCompany { return pbc }))
+	checker.Add("gateway", func() error {
+		if !connected {
+			return errors.New("gateway not connected")
+		}
+		return nil
+	})
+
+	tests := []struct {
+		name       string
+		setup      func()
+		wantCode   int
+		wantChecks map[string]string
+	}{
+		{
+			name:       "nothing ready",
+			setup:      func() {},
+			wantCode:   http.StatusServiceUnavailable,
+			wantChecks: map[string]string{"data": "problems data not loaded", "gateway": "gateway not connected"},
+		},
+		{
+			name:       "empty data",
+			setup:      func() { pbc = data.NewTestProblemsByCompany(nil) },
+			wantCode:   http.StatusServiceUnavailable,
+			wantChecks: map[string]string{"data": "problems data is empty", "gateway": "gateway not connected"},
+		},
+		{
+			name:       "ready",
+			setup:      func() { pbc, connected = testData(), true },
+			wantCode:   http.StatusOK,
+			wantChecks: map[string]string{"data": "ok", "gateway": "ok"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			rec := httptest.NewRecorder()
+			checker.Readyz(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("expected status %d, got %d", tt.wantCode, rec.Code)
+			}
+			var status Status
+			if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+				t.Fatal(err)
+			}
+			for name, want := range tt.wantChecks {
+				if status.Checks[name] != want {
+					t.Errorf("check %s: expected %q, got %q", name, want, status.Checks[name])
+				}
+			}
+		})
+	}
+}
+
+func TestHealthz(t *testing.T) {
+	rec := httptest.NewRecorder()
+	Healthz(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Cache-Control"); got != "no-store" {
+		t.Errorf("expected Cache-Control no-store, got %q", got)
+	}
+}
+
+func TestInfoHandler(t *testing.T) {
+	pbc := testData()
+	rec := httptest.NewRecorder()
+	InfoHandler(func() *data.ProblemsByCompany { return pbc })(rec, httptest.NewRequest(http.MethodGet, "/api/info", nil))
+
+	var body struct {
+		Success bool           `json:"success"`
+		Data    map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if !body.Success {
+		t.Error("expected success")
+	}
+	for _, key := range []string{"version", "commit", "go_version", "dataset_version", "started_at"} {
+		if body.Data[key] == "" || body.Data[key] == nil {
+			t.Errorf("expected %s to be set", key)
+		}
+	}
+	if body.Data["companies"] != float64(2) {
+		t.Errorf("expected 2 companies, got %v", body.Data["companies"])
+	}
+	if body.Data["total_problems"] != float64(3) {
+		t.Errorf("expected 3 total problems, got %v", body.Data["total_problems"])
+	}
+	if body.Data["dataset_version"] != pbc.Version().Version {
+		t.Errorf("expected dataset version %s, got %v", pbc.Version().Version, body.Data["dataset_version"])
+	}
+}
@@ -0,0 +1,349 @@
+// Package httpcache serves precomputed responses with strong ETags and
+// pre-compressed gzip and brotli bodies. Responses are computed once per
+// dataset version, so they are only valid while the data doesn't change.
+package httpcache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+)
+
+const (
+	// minCompressSize is the smallest body worth compressing
+	minCompressSize = 1024
+	// brotliLevel compresses an 8MB body about 30x in 200ms; level 11 is
+	// only 10% smaller and takes 20s
+	brotliLevel = 9
+	// maxEntries bounds the cache, several times the responses the whole
+	// dataset has, so a key function that lets variants through can't grow
+	// it without limit
+	maxEntries = 20000
+)
+
+// CacheControl lets browsers and CDNs reuse a response for five minutes and
+// revalidate it with If-None-Match afterwards
+const CacheControl = "public, max-age=300"
+
+// Entry is a precomputed response
+type Entry struct {
+	contentType string
+	etag        string
+	// vary lists the request headers the response depends on besides
+	// Accept-Encoding, as the handler set them
+	vary     []string
+	identity []byte
+	gzip     []byte
+	brotli   []byte
+}
+
+// NewEntry compresses body and derives its ETag from the dataset version and
+// the body's hash
+func NewEntry(version, contentType string, body []byte) (*Entry, error) {
+	sum := sha256.Sum256(body)
+	e := &Entry{
+		contentType: contentType,
+		etag:        version + "-" + hex.EncodeToString(sum[:8]),
+		identity:    body,
+	}
+	if len(body) < minCompressSize {
+		return e, nil
+	}
+
+	var gz bytes.Buffer
+	gw, err := gzip.NewWriterLevel(&gz, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := gw.Write(body); err != nil {
+		return nil, err
+	}
+	if err := gw.Close(); err != nil {
+		return nil, err
+	}
+	e.gzip = gz.Bytes()
+
+	var br bytes.Buffer
+	bw := brotli.NewWriterLevel(&br, brotliLevel)
+	if _, err := bw.Write(body); err != nil {
+		return nil, err
+	}
+	if err := bw.Close(); err != nil {
+		return nil, err
+	}
+	e.brotli = br.Bytes()
+
+	return e, nil
+}
+
+// ETag returns the quoted ETag of the uncompressed body
+func (e *Entry) ETag() string {
+	return `"` + e.etag + `"`
+}
+
+// ServeHTTP writes the entry in the best encoding the client accepts, or 304
+// when the client's copy is current
+func (e *Entry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, encoding := e.identity, ""
+	switch negotiate(r.Header.Get("Accept-Encoding"), e.brotli != nil, e.gzip != nil) {
+	case "br":
+		body, encoding = e.brotli, "br"
+	case "gzip":
+		body, encoding = e.gzip, "gzip"
+	}
+
+	// each encoding is a different representation, so it gets its own strong ETag
+	etag := e.etag
+	if encoding != "" {
+		etag += "-" + encoding
+	}
+
+	h := w.Header()
+	h.Set("ETag", `"`+etag+`"`)
+	h.Set("Cache-Control", CacheControl)
+	h.Add("Vary", "Accept-Encoding")
+	for _, v := range e.vary {
+		h.Add("Vary", v)
+	}
+
+	if e.matches(r.Header.Get("If-None-Match")) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	h.Set("Content-Type", e.contentType)
+	h.Set("Content-Length", strconv.Itoa(len(body)))
+	if encoding != "" {
+		h.Set("Content-Encoding", encoding)
+	}
+	w.WriteHeader(http.StatusOK)
+	if r.Method != http.MethodHead {
+		_, _ = w.Write(body)
+	}
+}
+
+// matches reports whether If-None-Match names any encoding of the entry.
+// If-None-Match uses weak comparison, so W/ prefixes are ignored.
+func (e *Entry) matches(ifNoneMatch string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return true
+		}
+		tag = strings.Trim(strings.TrimPrefix(tag, "W/"), `"`)
+		if tag == e.etag || tag == e.etag+"-gzip" || tag == e.etag+"-br" {
+			return true
+		}
+	}
+	return false
+}
+
+// negotiate picks br, gzip or "" (identity) from an Accept-Encoding header,
+// preferring brotli when the client accepts both equally
+func negotiate(acceptEncoding string, haveBrotli, haveGzip bool) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+
+		switch {
+		case (name == "br" || name == "*") && haveBrotli:
+			if q > bestQ || (q == bestQ && best != "br") {
+				best, bestQ = "br", q
+			}
+		case (name == "gzip" || name == "*") && haveGzip:
+			if q > bestQ {
+				best, bestQ = "gzip", q
+			}
+		}
+	}
+	return best
+}
+
+// Cache holds the entries for one dataset version
+type Cache struct {
+	version string
+
+	mu      sync.RWMutex
+	entries map[string]*Entry
+	limit   int
+}
+
+func New(version string) *Cache {
+	return &Cache{version: version, entries: make(map[string]*Entry), limit: maxEntries}
+}
+
+// Version returns the dataset version the entries were computed from
+func (c *Cache) Version() string {
+	return c.version
+}
+
+// Get returns the entry for key
+func (c *Cache) Get(key string) (*Entry, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	e, ok := c.entries[key]
+	return e, ok
+}
+
+// Put stores an entry for key, unless the cache is full
+func (c *Cache) Put(key string, e *Entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.limit {
+		return
+	}
+	c.entries[key] = e
+}
+
+// Len returns how many entries are cached
+func (c *Cache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.entries)
+}
+
+// Keep decides whether a response is worth caching
+type Keep func(status int, body []byte) bool
+
+// Key names the entry a request is served from, or reports false when the
+// request should pass through, like one the response to would differ for
+// despite having the same key
+type Key func(r *http.Request) (string, bool)
+
+// PathKey keys every request on its path
+func PathKey(r *http.Request) (string, bool) {
+	return r.URL.Path, true
+}
+
+// Middleware serves GET and HEAD requests without a query string from the
+// cache. The first request for a key runs next and caches the response if
+// keep accepts it and its Cache-Control allows it; anything else passes
+// straight through with the handler's headers.
+func (c *Cache) Middleware(key Key, keep Keep) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if (r.Method != http.MethodGet && r.Method != http.MethodHead) || r.URL.RawQuery != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key, ok := key(r)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if e, ok := c.Get(key); ok {
+				e.ServeHTTP(w, r)
+				return
+			}
+
+			rec := &recorder{header: make(http.Header)}
+			get := r.Clone(r.Context())
+			get.Method = http.MethodGet
+			next.ServeHTTP(rec, get)
+
+			if rec.status == 0 {
+				rec.status = http.StatusOK
+			}
+			if !keep(rec.status, rec.body.Bytes()) || !shareable(rec.header) {
+				rec.replay(w, r)
+				return
+			}
+
+			e, err := NewEntry(c.version, rec.header.Get("Content-Type"), rec.body.Bytes())
+			if err != nil {
+				rec.replay(w, r)
+				return
+			}
+			e.vary = rec.header.Values("Vary")
+			c.Put(key, e)
+			e.ServeHTTP(w, r)
+		})
+	}
+}
+
+// shareable reports whether a response may be cached and served to anyone,
+// which handlers opt out of with Cache-Control no-store or private
+func shareable(header http.Header) bool {
+	for _, value := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			name, _, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			if name = strings.ToLower(name); name == "no-store" || name == "private" {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Warm requests paths from a handler that uses the cache's middleware, so
+// their entries are computed before the first client asks for them
+func Warm(handler http.Handler, paths ...string) {
+	for _, path := range paths {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		handler.ServeHTTP(discard{}, req)
+	}
+}
+
+// recorder buffers a response so it can be cached
+type recorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *recorder) Header() http.Header { return r.header }
+
+func (r *recorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.body.Write(b)
+}
+
+// replay writes the buffered response unchanged
+func (r *recorder) replay(w http.ResponseWriter, req *http.Request) {
+	for key, values := range r.header {
+		w.Header()[key] = values
+	}
+	w.WriteHeader(r.status)
+	if req.Method != http.MethodHead {
+		_, _ = w.Write(r.body.Bytes())
+	}
+}
+
+// discard is a ResponseWriter that drops everything, for warming
+type discard struct{}
+
+func (discard) Header() http.Header         { return make(http.Header) }
+func (discard) WriteHeader(int)             {}
+func (discard) Write(b []byte) (int, error) { return len(b), nil }
@@ -0,0 +1,239 @@
+package httpcache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		acceptEncoding string
+		expected       string
+	}{
+		{"", ""},
+		{"identity", ""},
+		{"gzip", "gzip"},
+		{"gzip, deflate, br", "br"},
+		{"br;q=0.5, gzip", "gzip"},
+		{"br;q=0, gzip;q=0", ""},
+		{"*", "br"},
+		{"GZIP", "gzip"},
+		{"gzip;q=bogus", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.acceptEncoding, func(t *testing.T) {
+			if got := negotiate(tt.acceptEncoding, true, true); got != tt.expected {
+				t.Errorf("negotiate(%q) = %q, want %q", tt.acceptEncoding, got, tt.expected)
+			}
+		})
+	}
+
+	// a wildcard takes gzip when there's no brotli body
+	if got := negotiate("*", false, true); got != "gzip" {
+		t.Errorf("negotiate(%q) without brotli = %q, want gzip", "*", got)
+	}
+}
+
+func TestEntryServeHTTP(t *testing.T) {
+	body := []byte(strings.Repeat(`{"success":true}`, 200))
+	e, err := NewEntry("v1", "application/json", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoders := map[string]func(io.Reader) (io.Reader, error){
+		"":     func(r io.Reader) (io.Reader, error) { return r, nil },
+		"gzip": func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+		"br":   func(r io.Reader) (io.Reader, error) { return brotli.NewReader(r), nil },
+	}
+
+	for encoding, decode := range decoders {
+		t.Run("encoding "+encoding, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept-Encoding", encoding)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get("Content-Encoding"); got != encoding {
+				t.Errorf("expected Content-Encoding %q, got %q", encoding, got)
+			}
+			if rec.Header().Get("Cache-Control") != CacheControl {
+				t.Errorf("expected Cache-Control %q, got %q", CacheControl, rec.Header().Get("Cache-Control"))
+			}
+			r, err := decode(rec.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			decoded, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(decoded, body) {
+				t.Error("decoded body doesn't match the original")
+			}
+
+			// the ETag of any encoding revalidates
+			revalidate := httptest.NewRequest(http.MethodGet, "/", nil)
+			revalidate.Header.Set("If-None-Match", rec.Header().Get("ETag"))
+			rec = httptest.NewRecorder()
+			e.ServeHTTP(rec, revalidate)
+			if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+				t.Errorf("expected an empty 304, got %d with %d bytes", rec.Code, rec.Body.Len())
+			}
+		})
+	}
+}
+
+func TestEntryMatches(t *testing.T) {
+	e, _ := NewEntry("v1", "application/json", []byte("{}"))
+	tag := strings.Trim(e.ETag(), `"`)
+
+	tests := []struct {
+		ifNoneMatch string
+		expected    bool
+	}{
+		{"", false},
+		{"*", true},
+		{e.ETag(), true},
+		{`W/` + e.ETag(), true},
+		{`"other", "` + tag + `-gzip"`, true},
+		{`"v0-` + tag[3:] + `"`, false},
+	}
+
+	for _, tt := range tests {
+		if got := e.matches(tt.ifNoneMatch); got != tt.expected {
+			t.Errorf("matches(%q) = %v, want %v", tt.ifNoneMatch, got, tt.expected)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	calls := 0
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/missing" {
+			_, _ = w.Write([]byte(`{"success":false}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"success":true}`))
+	})
+	keep := func(status int, body []byte) bool {
+		return status == http.StatusOK && bytes.HasPrefix(body, []byte(`{"success":true`))
+	}
+
+	cache := New("v1")
+	cached := cache.Middleware(PathKey, keep)(handler)
+	get := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		cached.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec
+	}
+
+	Warm(cached, "/found")
+	if calls != 1 || cache.Len() != 1 {
+		t.Fatalf("warming should run the handler once and cache it, got %d calls and %d entries", calls, cache.Len())
+	}
+	if rec := get("/found"); rec.Header().Get("ETag") == "" || rec.Body.String() != `{"success":true}` {
+		t.Errorf("expected the cached response, got %q with ETag %q", rec.Body.String(), rec.Header().Get("ETag"))
+	}
+	if calls != 1 {
+		t.Errorf("a cached path shouldn't run the handler, got %d calls", calls)
+	}
+
+	// responses keep rejects and requests with a query pass through
+	for _, target := range []string{"/missing", "/missing", "/found?limit=1"} {
+		rec := get(target)
+		if rec.Header().Get("ETag") != "" {
+			t.Errorf("%s shouldn't be cached", target)
+		}
+	}
+	if calls != 4 || cache.Len() != 1 {
+		t.Errorf("expected 4 calls and 1 entry, got %d and %d", calls, cache.Len())
+	}
+
+	// a full cache answers new keys without storing them
+	cache.limit = 1
+	get("/other")
+	if calls != 5 || cache.Len() != 1 {
+		t.Errorf("a full cache shouldn't grow, got %d calls and %d entries", calls, cache.Len())
+	}
+}
+
+func TestMiddlewareNoStore(t *testing.T) {
+	calls := 0
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Cache-Control", "no-store")
+		_, _ = w.Write([]byte(`{"success":true}`))
+	})
+	keep := func(status int, body []byte) bool { return true }
+
+	cache := New("v1")
+	cached := cache.Middleware(PathKey, keep)(handler)
+	for range 2 {
+		rec := httptest.NewRecorder()
+		cached.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/info", nil))
+		if got := rec.Header().Get("Cache-Control"); got != "no-store" {
+			t.Errorf("Cache-Control = %q, want the handler's no-store", got)
+		}
+		if rec.Header().Get("ETag") != "" {
+			t.Error("a no-store response shouldn't get an ETag")
+		}
+	}
+	if calls != 2 || cache.Len() != 0 {
+		t.Errorf("no-store responses shouldn't be cached, got %d calls and %d entries", calls, cache.Len())
+	}
+
+	for _, value := range []string{"private", "Private, max-age=60", "public, no-store"} {
+		if shareable(http.Header{"Cache-Control": {value}}) {
+			t.Errorf("shareable(%q) = true, want false", value)
+		}
+	}
+	if !shareable(http.Header{"Cache-Control": {CacheControl}}) {
+		t.Errorf("shareable(%q) = false, want true", CacheControl)
+	}
+}
+
+func TestMiddlewareKey(t *testing.T) {
+	calls := 0
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Add("Vary", "Accept")
+		_, _ = w.Write([]byte(`{"success":true}`))
+	})
+	key := func(r *http.Request) (string, bool) {
+		return strings.ToLower(r.URL.Path), r.Header.Get("Accept") == ""
+	}
+	keep := func(status int, body []byte) bool { return true }
+
+	cache := New("v1")
+	cached := cache.Middleware(key, keep)(handler)
+	for _, target := range []string{"/found", "/FOUND", "/Found"} {
+		rec := httptest.NewRecorder()
+		cached.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if vary := rec.Header().Values("Vary"); !slices.Contains(vary, "Accept") {
+			t.Errorf("%s: Vary = %v, want the handler's Accept kept", target, vary)
+		}
+	}
+	if calls != 1 || cache.Len() != 1 {
+		t.Errorf("requests with the same key should share an entry, got %d calls and %d entries", calls, cache.Len())
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/found", nil)
+	req.Header.Set("Accept", "text/csv")
+	rec := httptest.NewRecorder()
+	cached.ServeHTTP(rec, req)
+	if calls != 2 || rec.Header().Get("ETag") != "" {
+		t.Errorf("a request the key rejects should pass through, got %d calls", calls)
+	}
+}
@@ -0,0 +1,204 @@
+// Package i18n translates the messages of the bot and the API.
+//
+// Messages live in locales/<locale>.json, one file per Discord locale, as an
+// object of keys to fmt format strings:
+//
+//	"problems.not_found": "No problems found for %s (%s)"
+//
+// en-US is the source every other locale translates, and the fallback for
+// keys a locale lacks. Translations may reorder arguments with explicit
+// indexes like %[2]s.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/language"
+)
+
+// DefaultLocale is the locale of the source messages
+const DefaultLocale = "en-US"
+
+//go:embed locales/*.json
+var files embed.FS
+
+// catalog holds the messages of every locale
+type catalog struct {
+	// locales lists the supported locales, DefaultLocale first
+	locales  []string
+	messages map[string]map[string]string
+	matcher  language.Matcher
+}
+
+var loadCatalog = sync.OnceValue(func() *catalog {
+	c, err := parseCatalog(files)
+	if err != nil {
+		// the locale files are checked by the tests
+		panic(err)
+	}
+	return c
+})
+
+// parseCatalog reads every locales/*.json of fsys
+func parseCatalog(fsys fs.FS) (*catalog, error) {
+	entries, err := fs.ReadDir(fsys, "locales")
+	if err != nil {
+		return nil, err
+	}
+
+	c := &catalog{messages: make(map[string]map[string]string)}
+	for _, entry := range entries {
+		locale := strings.TrimSuffix(entry.Name(), ".json")
+		content, err := fs.ReadFile(fsys, path.Join("locales", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(content, &messages); err != nil {
+			return nil, fmt.Errorf("error parsing %s: %w", entry.Name(), err)
+		}
+		if _, err := language.Parse(locale); err != nil {
+			return nil, fmt.Errorf("%s isn't named after a locale: %w", entry.Name(), err)
+		}
+		c.messages[locale] = messages
+		c.locales = append(c.locales, locale)
+	}
+	if c.messages[DefaultLocale] == nil {
+		return nil, fmt.Errorf("locales/%s.json is missing", DefaultLocale)
+	}
+
+	sort.Slice(c.locales, func(i, j int) bool {
+		if (c.locales[i] == DefaultLocale) != (c.locales[j] == DefaultLocale) {
+			return c.locales[i] == DefaultLocale
+		}
+		return c.locales[i] < c.locales[j]
+	})
+	tags := make([]language.Tag, len(c.locales))
+	for i, locale := range c.locales {
+		tags[i] = language.MustParse(locale)
+	}
+	c.matcher = language.NewMatcher(tags)
+	return c, nil
+}
+
+// match finds the supported locale closest to locale, like es-ES for es-419
+func (c *catalog) match(locale string) (string, bool) {
+	if locale == "" {
+		return "", false
+	}
+	if _, ok := c.messages[locale]; ok {
+		return locale, true
+	}
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return "", false
+	}
+	// the returned tag can carry extensions, the index is what was matched
+	_, index, confidence := c.matcher.Match(tag)
+	if confidence < language.High {
+		return "", false
+	}
+	return c.locales[index], true
+}
+
+// Locales returns the supported locales, DefaultLocale first
+func Locales() []string {
+	return append([]string(nil), loadCatalog().locales...)
+}
+
+// Match returns the supported locale used for locale, if there is one
+func Match(locale string) (string, bool) {
+	return loadCatalog().match(locale)
+}
+
+// Printer formats messages in one locale. The nil Printer uses DefaultLocale.
+type Printer struct {
+	locale   string
+	messages map[string]string
+}
+
+var defaultPrinter = sync.OnceValue(func() *Printer {
+	return &Printer{locale: DefaultLocale, messages: loadCatalog().messages[DefaultLocale]}
+})
+
+// Default returns the printer of DefaultLocale
+func Default() *Printer {
+	return defaultPrinter()
+}
+
+// For returns the printer of the first locale that is supported, trying
+// each in order, or of DefaultLocale when none is. Empty locales are skipped,
+// so optional sources can be passed as they are.
+func For(locales ...string) *Printer {
+	c := loadCatalog()
+	for _, locale := range locales {
+		if matched, ok := c.match(locale); ok {
+			return &Printer{locale: matched, messages: c.messages[matched]}
+		}
+	}
+	return Default()
+}
+
+// FromAcceptLanguage returns the printer for an HTTP Accept-Language header
+func FromAcceptLanguage(header string) *Printer {
+	tags, _, err := language.ParseAcceptLanguage(header)
+	if err != nil {
+		return Default()
+	}
+	locales := make([]string, len(tags))
+	for i, tag := range tags {
+		locales[i] = tag.String()
+	}
+	return For(locales...)
+}
+
+// Locale returns the locale the printer formats messages in
+func (p *Printer) Locale() string {
+	if p == nil {
+		return DefaultLocale
+	}
+	return p.locale
+}
+
+// T formats the message key with args. Keys the locale doesn't translate
+// fall back to DefaultLocale, and unknown keys are returned as they are.
+func (p *Printer) T(key string, args ...any) string {
+	if p == nil {
+		p = Default()
+	}
+	format, ok := p.messages[key]
+	if !ok {
+		format, ok = loadCatalog().messages[DefaultLocale][key]
+	}
+	if !ok {
+		return key
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Has reports whether key is a message of DefaultLocale
+func Has(key string) bool {
+	_, ok := loadCatalog().messages[DefaultLocale][key]
+	return ok
+}
+
+// Translations returns the message key in every supported locale but
+// DefaultLocale, for APIs that take the translations of a string up front.
+// The messages aren't formatted, so they shouldn't have arguments.
+func Translations(key string) map[string]string {
+	c := loadCatalog()
+	translations := make(map[string]string, len(c.locales)-1)
+	for _, locale := range c.locales[1:] {
+		if message, ok := c.messages[locale][key]; ok {
+			translations[locale] = message
+		}
+	}
+	return translations
+}
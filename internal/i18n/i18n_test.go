@@ -0,0 +1,285 @@
+package i18n
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// verbs maps each argument index of a format string to the verbs it's
+// formatted with, so translations that reorder arguments still compare equal
+func verbs(format string) map[int]string {
+	found := make(map[int]string)
+	arg := 1
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		// flags, width and precision
+		for i < len(format) && strings.IndexByte("+-# 0123456789.", format[i]) >= 0 {
+			i++
+		}
+		if i < len(format) && format[i] == '[' {
+			end := strings.IndexByte(format[i:], ']')
+			if end < 0 {
+				break
+			}
+			if n, err := strconv.Atoi(format[i+1 : i+end]); err == nil {
+				arg = n
+			}
+			i += end + 1
+		}
+		if i >= len(format) {
+			break
+		}
+		if format[i] == '%' {
+			continue
+		}
+		if !strings.ContainsRune(found[arg], rune(format[i])) {
+			found[arg] += string(format[i])
+		}
+		arg++
+	}
+	return found
+}
+
+func TestVerbs(t *testing.T) {
+	tests := []struct {
+		format string
+		want   map[int]string
+	}{
+		{"no arguments, 100%%", map[int]string{}},
+		{"%d problems for %s", map[int]string{1: "d", 2: "s"}},
+		{"%[2]s a %[1]d", map[int]string{1: "d", 2: "s"}},
+		{"%[1]s and %[1]s again", map[int]string{1: "s"}},
+		{"%[1]s and %[1]d", map[int]string{1: "sd"}},
+		{"%.0f%%", map[int]string{1: "f"}},
+	}
+	for _, tt := range tests {
+		got := verbs(tt.format)
+		if len(got) != len(tt.want) {
+			t.Errorf("verbs(%q) = %v, want %v", tt.format, got, tt.want)
+			continue
+		}
+		for arg, verb := range tt.want {
+			if got[arg] != verb {
+				t.Errorf("verbs(%q) = %v, want %v", tt.format, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestLocalesTranslateEveryMessage(t *testing.T) {
+	c := loadCatalog()
+	source := c.messages[DefaultLocale]
+	if len(source) == 0 {
+		t.Fatal("the default locale has no messages")
+	}
+
+	for _, locale := range c.locales[1:] {
+		messages := c.messages[locale]
+		for key, format := range source {
+			translated, ok := messages[key]
+			if !ok {
+				t.Errorf("%s is missing %s", locale, key)
+				continue
+			}
+			want, got := verbs(format), verbs(translated)
+			if len(want) != len(got) {
+				t.Errorf("%s %s uses arguments %v, want %v", locale, key, got, want)
+				continue
+			}
+			for arg, verb := range want {
+				if got[arg] != verb {
+					t.Errorf("%s %s uses arguments %v, want %v", locale, key, got, want)
+					break
+				}
+			}
+		}
+		for key := range messages {
+			if _, ok := source[key]; !ok {
+				t.Errorf("%s has %s, which %s doesn't", locale, key, DefaultLocale)
+			}
+		}
+	}
+}
+
+// TestCodeUsesKnownMessages fails for message keys the code formats that the
+// catalog doesn't have, which would be shown to users as the bare key
+func TestCodeUsesKnownMessages(t *testing.T) {
+	root := filepath.Join("..", "..")
+	fset := token.NewFileSet()
+	used := 0
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			switch d.Name() {
+			case ".git", "node_modules", "testdata":
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 {
+				return true
+			}
+			var name string
+			switch fun := call.Fun.(type) {
+			case *ast.SelectorExpr:
+				name = fun.Sel.Name
+			case *ast.Ident:
+				name = fun.Name
+			}
+			if name != "T" && name != "localizedChoice" {
+				return true
+			}
+			lit, ok := call.Args[0].(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				return true
+			}
+			key, err := strconv.Unquote(lit.Value)
+			if err != nil {
+				return true
+			}
+			used++
+			if !Has(key) {
+				t.Errorf("%s: message %q isn't in locales/%s.json", fset.Position(lit.Pos()), key, DefaultLocale)
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if used == 0 {
+		t.Fatal("found no messages in the code, the walk is broken")
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		locale string
+		want   string
+		ok     bool
+	}{
+		{"en-US", "en-US", true},
+		{"en-GB", "en-US", true},
+		{"es-ES", "es-ES", true},
+		{"es-419", "es-ES", true},
+		{"fr", "fr", true},
+		{"pt-BR", "pt-BR", true},
+		{"de", "de", true},
+		{"ja", "", false},
+		{"not a locale", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := Match(tt.locale)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Match(%q) = %q, %v, want %q, %v", tt.locale, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestPrinter(t *testing.T) {
+	if got := For("", "ja", "fr", "de").Locale(); got != "fr" {
+		t.Errorf("For() picked %s, want the first supported locale fr", got)
+	}
+	if got := For("ja").Locale(); got != DefaultLocale {
+		t.Errorf("For() with no supported locale = %s, want %s", got, DefaultLocale)
+	}
+	if got := FromAcceptLanguage("ja, de;q=0.8, fr;q=0.9").Locale(); got != "fr" {
+		t.Errorf("FromAcceptLanguage() = %s, want fr", got)
+	}
+	if got := FromAcceptLanguage("").Locale(); got != DefaultLocale {
+		t.Errorf("FromAcceptLanguage(\"\") = %s, want %s", got, DefaultLocale)
+	}
+
+	if got := Default().T("problems.count", 3); got != "3 problems" {
+		t.Errorf("T() = %q", got)
+	}
+	if got := For("de").T("problems.count", 3); got != "3 Aufgaben" {
+		t.Errorf("T() = %q", got)
+	}
+	var nilPrinter *Printer
+	if got := nilPrinter.T("problems.count", 3); got != "3 problems" {
+		t.Errorf("nil Printer T() = %q, want the default locale", got)
+	}
+	if got := Default().T("no.such.key"); got != "no.such.key" {
+		t.Errorf("T() of an unknown key = %q, want the key", got)
+	}
+
+	missing := &Printer{locale: "xx", messages: map[string]string{}}
+	if got := missing.T("problems.count", 3); got != "3 problems" {
+		t.Errorf("T() of an untranslated key = %q, want the %s message", got, DefaultLocale)
+	}
+
+	translations := Translations("difficulty.easy")
+	if _, ok := translations[DefaultLocale]; ok {
+		t.Error("Translations() should leave out the default locale")
+	}
+	if translations["fr"] != "Facile" {
+		t.Errorf("Translations()[fr] = %q", translations["fr"])
+	}
+}
+
+func TestParseCatalog(t *testing.T) {
+	tests := []struct {
+		name  string
+		files fstest.MapFS
+		err   string
+	}{
+		{"valid", fstest.MapFS{
+			"locales/en-US.json": {Data: []byte(`{"a": "A"}`)},
+			"locales/fr.json":    {Data: []byte(`{"a": "à"}`)},
+		}, ""},
+		{"no default locale", fstest.MapFS{
+			"locales/fr.json": {Data: []byte(`{"a": "à"}`)},
+		}, "en-US.json is missing"},
+		{"invalid json", fstest.MapFS{
+			"locales/en-US.json": {Data: []byte(`{"a": 1}`)},
+		}, "error parsing en-US.json"},
+		{"not a locale", fstest.MapFS{
+			"locales/en-US.json":   {Data: []byte(`{}`)},
+			"locales/english.json": {Data: []byte(`{}`)},
+		}, "english.json isn't named after a locale"},
+		{"no locales", fstest.MapFS{}, "locales"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := parseCatalog(tt.files)
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("parseCatalog() error = %v", err)
+				}
+				if len(c.locales) != 2 || c.locales[0] != DefaultLocale {
+					t.Errorf("locales = %v, want %s first", c.locales, DefaultLocale)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("parseCatalog() error = %v, want it to mention %q", err, tt.err)
+			}
+		})
+	}
+}
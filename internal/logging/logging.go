@@ -0,0 +1,180 @@
+// Package logging configures log/slog for the bot and the server and defines
+// the field names every log event uses, so one user's interaction or one HTTP
+// request can be followed through the logs.
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+)
+
+// field keys shared by every log event
+const (
+	KeyGuild     = "guild"
+	KeyChannel   = "channel"
+	KeyUser      = "user"
+	KeyCommand   = "command"
+	KeyLatency   = "latency"
+	KeyError     = "error"
+	KeyComponent = "component"
+	KeyRequestID = "request_id"
+	KeyMethod    = "method"
+	KeyPath      = "path"
+	KeyStatus    = "status"
+	KeyDuration  = "duration"
+)
+
+// Format is how log lines are written
+type Format string
+
+const (
+	FormatText Format = "text"
+	FormatJSON Format = "json"
+)
+
+// Options configures the default logger
+type Options struct {
+	Level  slog.Level
+	Format Format
+	Output io.Writer
+}
+
+// level is shared by every logger created by Setup so it can change at runtime
+var level = new(slog.LevelVar)
+
+// ParseLevel parses debug, info, warn or error
+func ParseLevel(s string) (slog.Level, error) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(strings.TrimSpace(s))); err != nil {
+		return slog.LevelInfo, fmt.Errorf("unknown log level %q, expected debug, info, warn or error", s)
+	}
+	return l, nil
+}
+
+// ParseFormat parses text or json
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "text":
+		return FormatText, nil
+	case "json":
+		return FormatJSON, nil
+	}
+	return "", fmt.Errorf("unknown log format %q, expected text or json", s)
+}
+
+// OptionsFromEnv reads LOG_LEVEL and LOG_FORMAT, defaulting to info and text
+func OptionsFromEnv() (Options, error) {
+	opts := Options{Level: slog.LevelInfo, Format: FormatText, Output: os.Stderr}
+
+	if s := os.Getenv("LOG_LEVEL"); s != "" {
+		l, err := ParseLevel(s)
+		if err != nil {
+			return opts, err
+		}
+		opts.Level = l
+	}
+
+	format, err := ParseFormat(os.Getenv("LOG_FORMAT"))
+	if err != nil {
+		return opts, err
+	}
+	opts.Format = format
+
+	return opts, nil
+}
+
+// New returns a logger writing in the configured format. Durations are
+// written in milliseconds so latencies can be compared and aggregated.
+func New(opts Options) *slog.Logger {
+	output := opts.Output
+	if output == nil {
+		output = os.Stderr
+	}
+
+	handlerOpts := &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Value.Kind() == slog.KindDuration {
+				return slog.Float64(a.Key, float64(a.Value.Duration().Microseconds())/1000)
+			}
+			return a
+		},
+	}
+
+	level.Set(opts.Level)
+	if opts.Format == FormatJSON {
+		return slog.New(slog.NewJSONHandler(output, handlerOpts))
+	}
+	return slog.New(slog.NewTextHandler(output, handlerOpts))
+}
+
+// Setup installs the logger as slog's default. The standard log package
+// writes through it too, at info level.
+func Setup(opts Options) *slog.Logger {
+	logger := New(opts)
+	slog.SetDefault(logger)
+	return logger
+}
+
+// SetupFromEnv configures the default logger from LOG_LEVEL and LOG_FORMAT.
+// Invalid settings fall back to the defaults and are reported as a warning.
+func SetupFromEnv() *slog.Logger {
+	opts, err := OptionsFromEnv()
+	logger := Setup(opts)
+	if err != nil {
+		logger.Warn("invalid logging configuration, using defaults", Err(err))
+	}
+	return logger
+}
+
+// SetLevel changes the level of every logger created by New
+func SetLevel(l slog.Level) {
+	level.Set(l)
+}
+
+// Err is the attribute for an error
+func Err(err error) slog.Attr {
+	return slog.Any(KeyError, err)
+}
+
+// Latency is the attribute for the time spent since start
+func Latency(start time.Time) slog.Attr {
+	return slog.Duration(KeyLatency, time.Since(start))
+}
+
+type requestIDKey struct{}
+
+// WithRequestID returns a context carrying a request ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID stored in ctx, if any
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// NewRequestID returns a random 16 character hex ID
+func NewRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%016x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// FromContext returns the default logger with the context's request ID attached
+func FromContext(ctx context.Context) *slog.Logger {
+	if id := RequestID(ctx); id != "" {
+		return slog.Default().With(KeyRequestID, id)
+	}
+	return slog.Default()
+}
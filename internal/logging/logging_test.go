@@ -0,0 +1,105 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    slog.Level
+		wantErr bool
+	}{
+		{"debug", slog.LevelDebug, false},
+		{"INFO", slog.LevelInfo, false},
+		{" warn ", slog.LevelWarn, false},
+		{"error", slog.LevelError, false},
+		{"verbose", slog.LevelInfo, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseLevel(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLevel(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseLevel(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOptionsFromEnv(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "debug")
+	t.Setenv("LOG_FORMAT", "json")
+
+	opts, err := OptionsFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.Level != slog.LevelDebug || opts.Format != FormatJSON {
+		t.Errorf("OptionsFromEnv() = %+v, want debug/json", opts)
+	}
+
+	t.Setenv("LOG_FORMAT", "xml")
+	if _, err := OptionsFromEnv(); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestNewJSON(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(Options{Level: slog.LevelInfo, Format: FormatJSON, Output: &buf})
+
+	logger.Debug("hidden")
+	logger.Info("handled", KeyCommand, "problems", slog.Duration(KeyLatency, 1500*time.Microsecond), Err(errors.New("boom")))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected the debug line to be filtered, got %d lines", len(lines))
+	}
+
+	var event map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &event); err != nil {
+		t.Fatalf("invalid JSON %q: %v", lines[0], err)
+	}
+	if event[KeyCommand] != "problems" || event[KeyError] != "boom" {
+		t.Errorf("unexpected fields: %v", event)
+	}
+	if event[KeyLatency] != 1.5 {
+		t.Errorf("latency = %v, want 1.5 (milliseconds)", event[KeyLatency])
+	}
+
+	SetLevel(slog.LevelDebug)
+	defer SetLevel(slog.LevelInfo)
+	logger.Debug("shown")
+	if !strings.Contains(buf.String(), "shown") {
+		t.Error("SetLevel should change the level of existing loggers")
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	id := NewRequestID()
+	if len(id) != 16 {
+		t.Errorf("NewRequestID() = %q, want 16 characters", id)
+	}
+	if id == NewRequestID() {
+		t.Error("request IDs should be unique")
+	}
+
+	ctx := WithRequestID(context.Background(), id)
+	if got := RequestID(ctx); got != id {
+		t.Errorf("RequestID() = %q, want %q", got, id)
+	}
+	if got := RequestID(context.Background()); got != "" {
+		t.Errorf("RequestID() without an ID = %q, want empty", got)
+	}
+}
@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteText(t *testing.T) {
+	r := NewRegistry()
+	commands := r.NewCounterVec("test_commands_total", "Commands handled.", "command", "outcome")
+	clicks := r.NewCounter("test_clicks_total", "Paginator clicks.")
+	r.NewGaugeFunc("test_active", "Active things.", func() float64 { return 3 })
+	latency := r.NewHistogramVec("test_latency_seconds", "Latency.", []float64{0.1, 1}, "command")
+
+	commands.Inc("problems", "ok")
+	commands.Inc("problems", "ok")
+	commands.Inc("search", `we"ird`)
+	latency.Observe(0.05, "problems")
+	latency.ObserveDuration(500*time.Millisecond, "problems")
+	latency.Observe(3, "problems")
+
+	var out strings.Builder
+	if err := r.WriteText(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `# HELP test_active Active things.
+# TYPE test_active gauge
+test_active 3
+# HELP test_clicks_total Paginator clicks.
+# TYPE test_clicks_total counter
+test_clicks_total 0
+# HELP test_commands_total Commands handled.
+# TYPE test_commands_total counter
+test_commands_total{command="problems",outcome="ok"} 2
+test_commands_total{command="search",outcome="we\"ird"} 1
+# HELP test_latency_seconds Latency.
+# TYPE test_latency_seconds histogram
+test_latency_seconds_bucket{command="problems",le="0.1"} 1
+test_latency_seconds_bucket{command="problems",le="1"} 2
+test_latency_seconds_bucket{command="problems",le="+Inf"} 3
+test_latency_seconds_sum{command="problems"} 3.55
+test_latency_seconds_count{command="problems"} 3
+`
+	if out.String() != want {
+		t.Errorf("WriteText() =\n%s\nwant\n%s", out.String(), want)
+	}
+
+	clicks.Inc()
+	if clicks.Value() != 1 || commands.Value("problems", "ok") != 2 || latency.Count("problems") != 3 {
+		t.Error("Value/Count should report the recorded values")
+	}
+}
+
+func TestRegistryPanics(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(r *Registry)
+	}{
+		{"duplicate name", func(r *Registry) {
+			r.NewCounter("dup_total", "")
+			r.NewCounter("dup_total", "")
+		}},
+		{"wrong label count", func(r *Registry) {
+			r.NewCounterVec("labels_total", "", "a", "b").Inc("only-one")
+		}},
+		{"negative add", func(r *Registry) {
+			r.NewCounter("neg_total", "").Add(-1)
+		}},
+		{"unsorted buckets", func(r *Registry) {
+			r.NewHistogramVec("h", "", []float64{1, 0.5})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected a panic")
+				}
+			}()
+			tt.fn(NewRegistry())
+		})
+	}
+}
+
+func TestHandler(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("served_total", "Served.").Inc()
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if rec.Header().Get("Content-Type") != ContentType {
+		t.Errorf("Content-Type = %q, want %q", rec.Header().Get("Content-Type"), ContentType)
+	}
+	if !strings.Contains(rec.Body.String(), "served_total 1\n") {
+		t.Errorf("body = %q, want served_total 1", rec.Body.String())
+	}
+}
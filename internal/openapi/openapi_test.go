@@ -0,0 +1,101 @@
+package openapi
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testEnvelope[T any] struct {
+	OK   bool `json:"ok"`
+	Data T    `json:"data,omitempty"`
+}
+
+type testBase struct {
+	ID int `json:"id"`
+}
+
+type testItem struct {
+	testBase
+	Name    string            `json:"name"`
+	Tags    []string          `json:"tags"`
+	Counts  map[string]int    `json:"counts,omitempty"`
+	Created time.Time         `json:"created"`
+	Parent  *testItem         `json:"parent,omitempty"`
+	Extra   map[string]string `json:"-"`
+	hidden  string
+}
+
+func TestSchemaFor(t *testing.T) {
+	doc := NewDocument("test", "1", "")
+	schema := doc.SchemaFor(testEnvelope[testItem]{})
+
+	// generic types are inlined, named structs referenced
+	if schema.Ref != "" || schema.Properties["data"].Ref != "#/components/schemas/testItem" {
+		t.Fatalf("unexpected envelope schema %+v", schema)
+	}
+
+	item := doc.Components.Schemas["testItem"]
+	if item == nil {
+		t.Fatal("testItem should be registered as a component")
+	}
+	var names []string
+	for name := range item.Properties {
+		names = append(names, name)
+	}
+	if len(names) != 6 {
+		t.Errorf("expected id, name, tags, counts, created and parent, got %v", names)
+	}
+	if got := strings.Join(item.Required, ","); got != "created,id,name,tags" {
+		t.Errorf("required = %s, want created,id,name,tags", got)
+	}
+	if item.Properties["created"].Format != "date-time" {
+		t.Error("time.Time should be a date-time string")
+	}
+	if !item.Properties["tags"].Nullable {
+		t.Error("slices should be nullable since nil slices encode as null")
+	}
+	if item.Properties["parent"].Ref != "#/components/schemas/testItem" {
+		t.Error("recursive types should reference themselves")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	doc := NewDocument("test", "1", "")
+	schema := doc.SchemaFor(testEnvelope[[]testItem]{})
+
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{"valid", `{"ok":true,"data":[{"id":1,"name":"a","tags":null,"created":"2024-01-02T03:04:05Z","counts":{"x":1}}]}`, ""},
+		{"omitted data", `{"ok":false}`, ""},
+		{"missing required", `{"ok":true,"data":[{"id":1,"tags":[],"created":"2024-01-02T03:04:05Z"}]}`, "$.data[0]: missing required property name"},
+		{"wrong type", `{"ok":"yes"}`, "$.ok: expected boolean, got string"},
+		{"fractional integer", `{"ok":true,"data":[{"id":1.5,"name":"a","tags":[],"created":"2024-01-02T03:04:05Z"}]}`, "$.data[0].id: expected integer"},
+		{"bad date", `{"ok":true,"data":[{"id":1,"name":"a","tags":[],"created":"yesterday"}]}`, "isn't a date-time"},
+		{"bad map value", `{"ok":true,"data":[{"id":1,"name":"a","tags":[],"created":"2024-01-02T03:04:05Z","counts":{"x":"1"}}]}`, "$.data[0].counts.x: expected integer"},
+		{"undocumented property", `{"ok":true,"surprise":1}`, "$.surprise: property isn't in the schema"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var value any
+			if err := json.Unmarshal([]byte(tt.body), &value); err != nil {
+				t.Fatal(err)
+			}
+			err := doc.Validate(schema, value)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
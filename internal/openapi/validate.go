@@ -0,0 +1,132 @@
+package openapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Validate checks a value decoded by encoding/json into an any against a
+// schema, resolving references in the document. The error names the path
+// of the first mismatch.
+func (d *Document) Validate(schema *Schema, value any) error {
+	return d.validate(schema, value, "$")
+}
+
+func (d *Document) validate(schema *Schema, value any, path string) error {
+	if schema.Ref != "" {
+		name := strings.TrimPrefix(schema.Ref, "#/components/schemas/")
+		resolved, ok := d.Components.Schemas[name]
+		if !ok {
+			return fmt.Errorf("%s: unknown schema %s", path, schema.Ref)
+		}
+		return d.validate(resolved, value, path)
+	}
+
+	if value == nil {
+		if schema.Nullable || schema.Type == "" {
+			return nil
+		}
+		return fmt.Errorf("%s: null isn't a %s", path, schema.Type)
+	}
+
+	switch schema.Type {
+	case "":
+		return nil
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return mismatch(path, schema, value)
+		}
+	case "integer":
+		n, ok := value.(float64)
+		if !ok || n != float64(int64(n)) {
+			return mismatch(path, schema, value)
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return mismatch(path, schema, value)
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return mismatch(path, schema, value)
+		}
+		if schema.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, s); err != nil {
+				return fmt.Errorf("%s: %q isn't a date-time", path, s)
+			}
+		}
+		if len(schema.Enum) > 0 && !contains(schema.Enum, s) {
+			return fmt.Errorf("%s: %q isn't one of %v", path, s, schema.Enum)
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			return mismatch(path, schema, value)
+		}
+		for i, item := range items {
+			if err := d.validate(schema.Items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case "object":
+		return d.validateObject(schema, value, path)
+	default:
+		return fmt.Errorf("%s: unsupported schema type %s", path, schema.Type)
+	}
+	return nil
+}
+
+func (d *Document) validateObject(schema *Schema, value any, path string) error {
+	object, ok := value.(map[string]any)
+	if !ok {
+		return mismatch(path, schema, value)
+	}
+
+	for _, name := range schema.Required {
+		if _, ok := object[name]; !ok {
+			return fmt.Errorf("%s: missing required property %s", path, name)
+		}
+	}
+
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		propertyPath := path + "." + key
+		if property, ok := schema.Properties[key]; ok {
+			if err := d.validate(property, object[key], propertyPath); err != nil {
+				return err
+			}
+			continue
+		}
+		switch additional := schema.AdditionalProperties.(type) {
+		case *Schema:
+			if err := d.validate(additional, object[key], propertyPath); err != nil {
+				return err
+			}
+		case bool:
+			if !additional {
+				return fmt.Errorf("%s: property isn't in the schema", propertyPath)
+			}
+		}
+	}
+	return nil
+}
+
+func mismatch(path string, schema *Schema, value any) error {
+	return fmt.Errorf("%s: expected %s, got %T", path, schema.Type, value)
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
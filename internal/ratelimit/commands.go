@@ -0,0 +1,103 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CommandClass groups bot commands that cost about the same to answer, so
+// each group gets its own limits
+type CommandClass string
+
+const (
+	// ClassQuery is for commands that look up and render problem lists
+	ClassQuery CommandClass = "query"
+	// ClassBasic is for help and the admin commands
+	ClassBasic CommandClass = "basic"
+	// ClassPaginator is for paginator button clicks
+	ClassPaginator CommandClass = "paginator"
+)
+
+// CommandClasses are the classes in the order they're documented
+var CommandClasses = []CommandClass{ClassQuery, ClassBasic, ClassPaginator}
+
+// ClassLimits limits a command class per user and per channel
+type ClassLimits struct {
+	User    Limit
+	Channel Limit
+}
+
+// CommandLimits holds the limits for each command class
+type CommandLimits map[CommandClass]ClassLimits
+
+// DefaultCommandLimits are generous enough for normal use and stop a user or
+// a busy channel from flooding the bot
+func DefaultCommandLimits() CommandLimits {
+	return CommandLimits{
+		ClassQuery: {
+			User:    Limit{Events: 5, Per: 30 * time.Second},
+			Channel: Limit{Events: 20, Per: 30 * time.Second},
+		},
+		ClassBasic: {
+			User:    Limit{Events: 10, Per: 30 * time.Second},
+			Channel: Limit{Events: 30, Per: 30 * time.Second},
+		},
+		ClassPaginator: {
+			User:    Limit{Events: 30, Per: 30 * time.Second},
+			Channel: Limit{Events: 90, Per: 30 * time.Second},
+		},
+	}
+}
+
+// ParseCommandLimits overrides the default limits with a comma separated
+// list of class.scope=limit entries, e.g. "query.user=3/30s,paginator.channel=off"
+func ParseCommandLimits(spec string) (CommandLimits, error) {
+	limits := DefaultCommandLimits()
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("rate limit %q must look like query.user=5/30s", entry)
+		}
+		class, scope, ok := strings.Cut(key, ".")
+		if !ok {
+			return nil, fmt.Errorf("rate limit %q must name a class and a scope, e.g. query.user", entry)
+		}
+		classLimits, known := limits[CommandClass(class)]
+		if !known {
+			return nil, fmt.Errorf("unknown command class %q in rate limit %q", class, entry)
+		}
+		limit, err := ParseLimit(value)
+		if err != nil {
+			return nil, err
+		}
+
+		switch scope {
+		case "user":
+			classLimits.User = limit
+		case "channel":
+			classLimits.Channel = limit
+		default:
+			return nil, fmt.Errorf("unknown scope %q in rate limit %q, use user or channel", scope, entry)
+		}
+		limits[CommandClass(class)] = classLimits
+	}
+	return limits, nil
+}
+
+// String lists the limits in the format ParseCommandLimits accepts
+func (c CommandLimits) String() string {
+	var entries []string
+	for _, class := range CommandClasses {
+		limits := c[class]
+		entries = append(entries,
+			fmt.Sprintf("%s.user=%s", class, limits.User),
+			fmt.Sprintf("%s.channel=%s", class, limits.Channel))
+	}
+	return strings.Join(entries, ",")
+}
@@ -0,0 +1,161 @@
+// Package ratelimit implements keyed token buckets for limiting how often
+// users, channels or clients can do something.
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limit allows Events in any Per window, and bursts of up to Events at once.
+// The zero Limit allows everything.
+type Limit struct {
+	Events int
+	Per    time.Duration
+}
+
+// ParseLimit parses limits like "5/30s" or "100/1m"; "off" and "0" disable limiting
+func ParseLimit(s string) (Limit, error) {
+	s = strings.TrimSpace(s)
+	if s == "off" || s == "0" {
+		return Limit{}, nil
+	}
+
+	events, per, ok := strings.Cut(s, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("limit %q must look like 5/30s", s)
+	}
+	n, err := strconv.Atoi(events)
+	if err != nil || n < 0 {
+		return Limit{}, fmt.Errorf("limit %q has an invalid event count", s)
+	}
+	// a zero count would read as blocking everything but turns limiting off
+	if n == 0 {
+		return Limit{}, fmt.Errorf("limit %q allows no events, use off to disable limiting", s)
+	}
+	d, err := time.ParseDuration(per)
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("limit %q has an invalid window", s)
+	}
+	return Limit{Events: n, Per: d}, nil
+}
+
+// Unlimited reports whether the limit allows everything
+func (l Limit) Unlimited() bool {
+	return l.Events <= 0 || l.Per <= 0
+}
+
+func (l Limit) String() string {
+	if l.Unlimited() {
+		return "off"
+	}
+	return fmt.Sprintf("%d/%s", l.Events, l.Per)
+}
+
+// interval is how long one token takes to refill
+func (l Limit) interval() time.Duration {
+	return l.Per / time.Duration(l.Events)
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Limiter keeps a token bucket per key
+type Limiter struct {
+	limit Limit
+	now   func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func New(limit Limit) *Limiter {
+	return &Limiter{limit: limit, now: time.Now, buckets: make(map[string]*bucket)}
+}
+
+// SetClock replaces time.Now, for tests
+func (l *Limiter) SetClock(now func() time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.now = now
+}
+
+// Limit returns the limit the limiter enforces
+func (l *Limiter) Limit() Limit {
+	return l.limit
+}
+
+// Allow takes a token for key. When none is left it returns false and how
+// long until the next one.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	if l.limit.Unlimited() {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.limit.Events), last: now}
+		l.buckets[key] = b
+	}
+
+	// refill for the time since the last call, up to a full bucket
+	elapsed := now.Sub(b.last)
+	b.tokens = math.Min(float64(l.limit.Events), b.tokens+elapsed.Seconds()/l.limit.interval().Seconds())
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - b.tokens) * float64(l.limit.interval()))
+	return false, wait
+}
+
+// Refund gives back a token Allow took for key, for a request that another
+// limit refused after all
+func (l *Limiter) Refund(key string) {
+	if l.limit.Unlimited() {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if b, ok := l.buckets[key]; ok {
+		b.tokens = math.Min(float64(l.limit.Events), b.tokens+1)
+	}
+}
+
+// sweep forgets buckets that have refilled completely, at most once per
+// window, so the map doesn't grow with every key ever seen
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.limit.Per {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= l.limit.Per {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// Len returns how many keys are being tracked
+func (l *Limiter) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.buckets)
+}
@@ -0,0 +1,144 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Limit
+		wantErr  bool
+	}{
+		{"5/30s", Limit{Events: 5, Per: 30 * time.Second}, false},
+		{" 100/1m ", Limit{Events: 100, Per: time.Minute}, false},
+		{"off", Limit{}, false},
+		{"0", Limit{}, false},
+		{"5", Limit{}, true},
+		{"five/30s", Limit{}, true},
+		{"5/soon", Limit{}, true},
+		{"5/-1s", Limit{}, true},
+		{"0/30s", Limit{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseLimit(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLimit(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("ParseLimit(%q) = %+v, want %+v", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLimiterAllow(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := New(Limit{Events: 3, Per: 3 * time.Second})
+	l.SetClock(func() time.Time { return now })
+
+	// the full burst is allowed, then the bucket is empty
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.Allow("user"); !ok {
+			t.Fatalf("request %d should be allowed", i+1)
+		}
+	}
+	ok, wait := l.Allow("user")
+	if ok {
+		t.Fatal("the fourth request should be limited")
+	}
+	if wait != time.Second {
+		t.Errorf("expected to wait 1s, got %s", wait)
+	}
+
+	// other keys have their own bucket
+	if ok, _ := l.Allow("someone else"); !ok {
+		t.Error("a different key should be allowed")
+	}
+
+	// one token refills per second
+	now = now.Add(time.Second)
+	if ok, _ := l.Allow("user"); !ok {
+		t.Error("a token should have refilled after 1s")
+	}
+	if ok, _ := l.Allow("user"); ok {
+		t.Error("only one token should have refilled")
+	}
+}
+
+func TestLimiterRefund(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := New(Limit{Events: 1, Per: time.Minute})
+	l.SetClock(func() time.Time { return now })
+
+	if ok, _ := l.Allow("user"); !ok {
+		t.Fatal("the first request should be allowed")
+	}
+	l.Refund("user")
+	if ok, _ := l.Allow("user"); !ok {
+		t.Error("a refunded token should be usable again")
+	}
+
+	// refunds never fill a bucket past its burst
+	l.Refund("user")
+	l.Refund("user")
+	l.Allow("user")
+	if ok, _ := l.Allow("user"); ok {
+		t.Error("refunds shouldn't add more than the burst")
+	}
+}
+
+func TestLimiterSweep(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := New(Limit{Events: 1, Per: time.Second})
+	l.SetClock(func() time.Time { return now })
+
+	l.Allow("a")
+	l.Allow("b")
+	if l.Len() != 2 {
+		t.Fatalf("expected 2 buckets, got %d", l.Len())
+	}
+
+	now = now.Add(2 * time.Second)
+	l.Allow("c")
+	if l.Len() != 1 {
+		t.Errorf("idle buckets should be swept, got %d", l.Len())
+	}
+}
+
+func TestUnlimited(t *testing.T) {
+	l := New(Limit{})
+	for i := 0; i < 100; i++ {
+		if ok, _ := l.Allow("user"); !ok {
+			t.Fatal("the zero limit should allow everything")
+		}
+	}
+	if l.Len() != 0 {
+		t.Error("the zero limit shouldn't track keys")
+	}
+}
+
+func TestParseCommandLimits(t *testing.T) {
+	limits, err := ParseCommandLimits("query.user=2/10s, paginator.channel=off")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := limits[ClassQuery].User; got != (Limit{Events: 2, Per: 10 * time.Second}) {
+		t.Errorf("query.user = %s, want 2/10s", got)
+	}
+	if !limits[ClassPaginator].Channel.Unlimited() {
+		t.Errorf("paginator.channel should be off, got %s", limits[ClassPaginator].Channel)
+	}
+	if limits[ClassBasic] != DefaultCommandLimits()[ClassBasic] {
+		t.Error("classes that aren't mentioned should keep their defaults")
+	}
+
+	for _, spec := range []string{"query=5/30s", "search.user=5/30s", "query.guild=5/30s", "query.user=fast"} {
+		if _, err := ParseCommandLimits(spec); err == nil {
+			t.Errorf("ParseCommandLimits(%q) should fail", spec)
+		}
+	}
+}
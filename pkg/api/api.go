@@ -0,0 +1,101 @@
+// Package api defines the JSON bodies of the leetbot HTTP API. The server
+// encodes them, the OpenAPI document at /api/openapi.json is generated from
+// them, and pkg/client decodes them.
+package api
+
+import "time"
+
+// Response is the envelope every JSON endpoint answers with. Data is set
+// when Success is true and Error otherwise.
+type Response[T any] struct {
+	Success bool   `json:"success"`
+	Data    T      `json:"data,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Problem is a LeetCode problem as asked by a company
+type Problem struct {
+	ID         int     `json:"id"`
+	URL        string  `json:"url"`
+	Title      string  `json:"title"`
+	Difficulty string  `json:"difficulty"`
+	Acceptance float64 `json:"acceptance"`
+	Frequency  float64 `json:"frequency"`
+	// Source names the overlay the problem came from, empty for the bundled data
+	Source string `json:"source,omitempty"`
+}
+
+type CompaniesList struct {
+	Companies []string `json:"companies"`
+}
+
+type TimeframesList struct {
+	Timeframes []string `json:"timeframes"`
+}
+
+// ProblemsList is a company's problems for one timeframe
+type ProblemsList struct {
+	Company   string    `json:"company"`
+	Timeframe string    `json:"timeframe"`
+	Problems  []Problem `json:"problems"`
+	Count     int       `json:"count"`
+}
+
+// AllProblems maps company to timeframe to problems
+type AllProblems map[string]map[string][]Problem
+
+type DatasetVersion struct {
+	Hash           string `json:"hash"`
+	Version        string `json:"version"`
+	Companies      int    `json:"companies"`
+	Problems       int    `json:"problems"`
+	UniqueProblems int    `json:"unique_problems"`
+}
+
+type DifficultyBreakdown struct {
+	Easy   int `json:"easy"`
+	Medium int `json:"medium"`
+	Hard   int `json:"hard"`
+}
+
+type TimeframeSummary struct {
+	Timeframe  string              `json:"timeframe"`
+	Count      int                 `json:"count"`
+	Difficulty DifficultyBreakdown `json:"difficulty"`
+	Top        []Problem           `json:"top"`
+}
+
+// CompanySummary is a company's overview across timeframes
+type CompanySummary struct {
+	Company    string             `json:"company"`
+	Timeframes []TimeframeSummary `json:"timeframes"`
+	// Evergreen problems are asked in every timeframe other than all time
+	Evergreen []Problem `json:"evergreen"`
+}
+
+type SearchResult struct {
+	Problem
+	Score float64 `json:"score"`
+	// Companies is how many companies ask the problem
+	Companies    int      `json:"companies"`
+	TopCompanies []string `json:"top_companies"`
+}
+
+type SearchResults struct {
+	Query   string         `json:"query"`
+	Results []SearchResult `json:"results"`
+	Count   int            `json:"count"`
+}
+
+// Info describes the running build and its dataset
+type Info struct {
+	Version        string    `json:"version"`
+	Commit         string    `json:"commit"`
+	GoVersion      string    `json:"go_version"`
+	DatasetVersion string    `json:"dataset_version"`
+	DatasetHash    string    `json:"dataset_hash"`
+	Companies      int       `json:"companies"`
+	TotalProblems  int       `json:"total_problems"`
+	UniqueProblems int       `json:"unique_problems"`
+	StartedAt      time.Time `json:"started_at"`
+}
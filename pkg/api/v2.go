@@ -0,0 +1,91 @@
+package api
+
+// The types below are the bodies of /api/v2. Unlike v1, lookups that find
+// nothing answer 404, and problem lists say which timeframe they came from.
+
+// CompanyNames are the names data/companies.json knows a company by.
+// Companies it doesn't list only have a name, their slug title cased.
+type CompanyNames struct {
+	// Name is how the company is shown, e.g. Meta for facebook
+	Name        string   `json:"name"`
+	Aliases     []string `json:"aliases,omitempty"`
+	Tickers     []string `json:"tickers,omitempty"`
+	FormerNames []string `json:"former_names,omitempty"`
+}
+
+// CompanyListing is a company in the v2 company list
+type CompanyListing struct {
+	Slug string `json:"slug"`
+	CompanyNames
+	// Timeframes are ordered from most to least recent
+	Timeframes     []string `json:"timeframes"`
+	UniqueProblems int      `json:"unique_problems"`
+}
+
+type CompanyList struct {
+	Companies []CompanyListing `json:"companies"`
+	Count     int              `json:"count"`
+}
+
+// TimeframeCount describes one of a company's timeframes
+type TimeframeCount struct {
+	Timeframe  string              `json:"timeframe"`
+	Count      int                 `json:"count"`
+	Difficulty DifficultyBreakdown `json:"difficulty"`
+}
+
+// Company is a company and the timeframes it has problems for
+type Company struct {
+	Slug string `json:"slug"`
+	CompanyNames
+	Domain  string `json:"domain,omitempty"`
+	LogoURL string `json:"logo_url,omitempty"`
+	// Parent is the slug of the company that owns this one
+	Parent string `json:"parent,omitempty"`
+	// DefaultTimeframe is what /companies/{slug}/problems resolves to
+	// without ?timeframe=, the most recent timeframe with problems
+	DefaultTimeframe string           `json:"default_timeframe"`
+	Timeframes       []TimeframeCount `json:"timeframes"`
+	UniqueProblems   int              `json:"unique_problems"`
+}
+
+// CompanyProblems is a company's problems for one timeframe
+type CompanyProblems struct {
+	Company string `json:"company"`
+	// RequestedTimeframe is the ?timeframe= the client sent, if any
+	RequestedTimeframe string `json:"requested_timeframe,omitempty"`
+	// ResolvedTimeframe is the canonical timeframe the problems are from
+	ResolvedTimeframe string    `json:"resolved_timeframe"`
+	Problems          []Problem `json:"problems"`
+	Count             int       `json:"count"`
+}
+
+// ProblemListing is a problem in the v2 problem list. Problem is the
+// occurrence with the highest frequency across all companies.
+type ProblemListing struct {
+	Problem
+	// Companies is how many companies ask the problem
+	Companies    int      `json:"companies"`
+	TopCompanies []string `json:"top_companies"`
+}
+
+type ProblemList struct {
+	Problems []ProblemListing `json:"problems"`
+	Count    int              `json:"count"`
+	// Total is how many problems matched before offset and limit
+	Total int `json:"total"`
+}
+
+// Occurrence is a company and timeframe that lists a problem
+type Occurrence struct {
+	Company   string  `json:"company"`
+	Timeframe string  `json:"timeframe"`
+	Frequency float64 `json:"frequency"`
+}
+
+// ProblemDetail is a problem and everywhere it's asked
+type ProblemDetail struct {
+	Problem
+	Companies   int          `json:"companies"`
+	Occurrences []Occurrence `json:"occurrences"`
+}
@@ -0,0 +1,270 @@
+// Package client is a Go client for the leetbot HTTP API.
+//
+//	c := client.New("https://leetbot.example.com")
+//	list, err := c.Problems(ctx, "google", &client.ProblemsOptions{Timeframe: "thirty-days", Limit: 10})
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/whotypes/leetbot/pkg/api"
+)
+
+// DefaultTimeout bounds requests made with the default HTTP client
+const DefaultTimeout = 30 * time.Second
+
+// ErrNotFound is wrapped by errors for 404 responses
+var ErrNotFound = errors.New("not found")
+
+// Error is returned when the API answers with success false or an error status
+type Error struct {
+	StatusCode int
+	Message    string
+	// RetryAfter is set when the request was rate limited
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("leetbot api: status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("leetbot api: %s (status %d)", e.Message, e.StatusCode)
+}
+
+// Unwrap makes errors.Is(err, ErrNotFound) work for 404 responses
+func (e *Error) Unwrap() error {
+	if e.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Client calls the API. Its methods are safe for concurrent use.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	userAgent  string
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient replaces the default HTTP client
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithUserAgent identifies your bot or script to the server
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// New returns a client for the server at baseURL, e.g. http://localhost:8080
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: DefaultTimeout},
+		userAgent:  "leetbot-go-client",
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Companies lists the companies with problem data
+func (c *Client) Companies(ctx context.Context) ([]api.CompanyListing, error) {
+	var data api.CompanyList
+	if err := c.get(ctx, "/api/v2/companies", nil, &data); err != nil {
+		return nil, err
+	}
+	return data.Companies, nil
+}
+
+// Company gets a company and its problem counts per timeframe. Unknown
+// companies return an error wrapping ErrNotFound.
+func (c *Client) Company(ctx context.Context, slug string) (*api.Company, error) {
+	var data api.Company
+	if err := c.get(ctx, "/api/v2/companies/"+url.PathEscape(slug), nil, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// ProblemsOptions narrows a problem list
+type ProblemsOptions struct {
+	// Timeframe defaults to the company's most recent timeframe with data.
+	// The response's ResolvedTimeframe says which one was used.
+	Timeframe string
+	// Difficulties keeps only these difficulties, e.g. "easy", "medium"
+	Difficulties []string
+	// Limit caps the number of problems, 0 means all
+	Limit int
+}
+
+func (o *ProblemsOptions) query() url.Values {
+	query := url.Values{}
+	if o == nil {
+		return query
+	}
+	if o.Timeframe != "" {
+		query.Set("timeframe", o.Timeframe)
+	}
+	if len(o.Difficulties) > 0 {
+		query.Set("difficulty", strings.Join(o.Difficulties, ","))
+	}
+	if o.Limit > 0 {
+		query.Set("limit", strconv.Itoa(o.Limit))
+	}
+	return query
+}
+
+// Problems gets a company's problems. Unknown companies, and timeframes the
+// company has no problems for, return an error wrapping ErrNotFound.
+func (c *Client) Problems(ctx context.Context, company string, opts *ProblemsOptions) (*api.CompanyProblems, error) {
+	var data api.CompanyProblems
+	if err := c.get(ctx, "/api/v2/companies/"+url.PathEscape(company)+"/problems", opts.query(), &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// ListOptions pages through every problem
+type ListOptions struct {
+	Difficulties []string
+	Offset       int
+	// Limit caps the page size, 0 means all remaining problems
+	Limit int
+}
+
+// ListProblems lists every problem any company asks, ordered by ID
+func (c *Client) ListProblems(ctx context.Context, opts *ListOptions) (*api.ProblemList, error) {
+	query := url.Values{}
+	if opts != nil {
+		if len(opts.Difficulties) > 0 {
+			query.Set("difficulty", strings.Join(opts.Difficulties, ","))
+		}
+		if opts.Offset > 0 {
+			query.Set("offset", strconv.Itoa(opts.Offset))
+		}
+		if opts.Limit > 0 {
+			query.Set("limit", strconv.Itoa(opts.Limit))
+		}
+	}
+
+	var data api.ProblemList
+	if err := c.get(ctx, "/api/v2/problems", query, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// Problem gets a problem and every company and timeframe that asks it.
+// Unknown problems return an error wrapping ErrNotFound.
+func (c *Client) Problem(ctx context.Context, id int) (*api.ProblemDetail, error) {
+	var data api.ProblemDetail
+	if err := c.get(ctx, "/api/v2/problems/"+strconv.Itoa(id), nil, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// Summary gets a company's overview across timeframes
+func (c *Client) Summary(ctx context.Context, company string) (*api.CompanySummary, error) {
+	var data api.CompanySummary
+	if err := c.get(ctx, "/api/companies/"+url.PathEscape(company)+"/summary", nil, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// DatasetVersion gets the version of the server's dataset
+func (c *Client) DatasetVersion(ctx context.Context) (*api.DatasetVersion, error) {
+	var data api.DatasetVersion
+	if err := c.get(ctx, "/api/dataset/version", nil, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// Search finds problems by title, slug or ID. A limit of 0 uses the server's default.
+func (c *Client) Search(ctx context.Context, query string, limit int) (*api.SearchResults, error) {
+	params := url.Values{"q": {query}}
+	if limit > 0 {
+		params.Set("limit", strconv.Itoa(limit))
+	}
+
+	var data api.SearchResults
+	if err := c.get(ctx, "/api/search", params, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// Info gets the server's version and dataset
+func (c *Client) Info(ctx context.Context) (*api.Info, error) {
+	var data api.Info
+	if err := c.get(ctx, "/api/info", nil, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// get fetches path and decodes the envelope's data into out
+func (c *Client) get(ctx context.Context, path string, query url.Values, out any) error {
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var envelope struct {
+		Success bool            `json:"success"`
+		Data    json.RawMessage `json:"data"`
+		Error   string          `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil && !errors.Is(err, io.EOF) {
+		if resp.StatusCode != http.StatusOK {
+			return responseError(resp, "")
+		}
+		return fmt.Errorf("leetbot api: decoding response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK || !envelope.Success {
+		return responseError(resp, envelope.Error)
+	}
+	return json.Unmarshal(envelope.Data, out)
+}
+
+func responseError(resp *http.Response, message string) *Error {
+	err := &Error{StatusCode: resp.StatusCode, Message: message}
+	if seconds, convErr := strconv.Atoi(resp.Header.Get("Retry-After")); convErr == nil {
+		err.RetryAfter = time.Duration(seconds) * time.Second
+	}
+	return err
+}
@@ -0,0 +1,108 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestProblemsRequest(t *testing.T) {
+	var gotPath, gotQuery, gotAgent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotQuery, gotAgent = r.URL.EscapedPath(), r.URL.RawQuery, r.UserAgent()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"success":true,"data":{"company":"jane street","requested_timeframe":"all","resolved_timeframe":"all","problems":[{"id":1,"url":"u","title":"Two Sum","difficulty":"Easy","acceptance":50,"frequency":100}],"count":1}}`))
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL+"/", WithUserAgent("my-bot"))
+	list, err := c.Problems(context.Background(), "jane street", &ProblemsOptions{
+		Timeframe:    "all",
+		Difficulties: []string{"easy", "medium"},
+		Limit:        5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if gotPath != "/api/v2/companies/jane%20street/problems" {
+		t.Errorf("unexpected path %s", gotPath)
+	}
+	if gotQuery != "difficulty=easy%2Cmedium&limit=5&timeframe=all" {
+		t.Errorf("unexpected query %s", gotQuery)
+	}
+	if gotAgent != "my-bot" {
+		t.Errorf("expected user agent my-bot, got %s", gotAgent)
+	}
+	if list.Count != 1 || list.Problems[0].Title != "Two Sum" || list.ResolvedTimeframe != "all" {
+		t.Errorf("unexpected list %+v", list)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		retryAfter string
+		check      func(t *testing.T, err *Error)
+	}{
+		{
+			name:   "unsuccessful",
+			status: http.StatusOK,
+			body:   `{"success":false,"error":"No problems found for company: nope"}`,
+			check: func(t *testing.T, err *Error) {
+				if err.Message != "No problems found for company: nope" {
+					t.Errorf("unexpected message %q", err.Message)
+				}
+			},
+		},
+		{
+			name:       "rate limited",
+			status:     http.StatusTooManyRequests,
+			body:       `{"success":false,"error":"Too many requests, slow down"}`,
+			retryAfter: "7",
+			check: func(t *testing.T, err *Error) {
+				if err.RetryAfter != 7*time.Second {
+					t.Errorf("expected RetryAfter 7s, got %s", err.RetryAfter)
+				}
+			},
+		},
+		{
+			name:   "not found",
+			status: http.StatusNotFound,
+			body:   `404 page not found`,
+			check: func(t *testing.T, err *Error) {
+				if !errors.Is(err, ErrNotFound) {
+					t.Error("expected ErrNotFound")
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			_, err := New(srv.URL).Companies(context.Background())
+			var apiErr *Error
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("expected an *Error, got %v", err)
+			}
+			if apiErr.StatusCode != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, apiErr.StatusCode)
+			}
+			tt.check(t, apiErr)
+		})
+	}
+}
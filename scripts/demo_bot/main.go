@@ -1,123 +1,200 @@
+// demo_bot is an interactive REPL for the bot. Typed lines run through the
+// real message and slash command handlers against an in-memory Discord, and
+// responses are printed as text.
+//
+// Usage:
+//
+//	go run ./scripts/demo_bot [-prefix !] [-admin] [-v]
 package main
 
 import (
+	"bufio"
+	"flag"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
 	"strings"
 
+	"github.com/bwmarrin/discordgo"
 	"github.com/whotypes/leetbot/internal/data"
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
+	"github.com/whotypes/leetbot/internal/discord"
+	"github.com/whotypes/leetbot/internal/discord/discordtest"
+	"github.com/whotypes/leetbot/internal/logging"
 )
 
-var titleCaser = cases.Title(language.English)
-
-func getDifficultyIndicator(difficulty string) string {
-	switch strings.ToLower(difficulty) {
-	case "easy":
-		return "🟢"
-	case "medium":
-		return "🟡"
-	case "hard":
-		return "🔴"
-	default:
-		return ""
-	}
+const demoChannelID = "300000000000000001"
+
+type repl struct {
+	handler      *discord.Handler
+	session      *discordtest.Session
+	problemsData *data.ProblemsByCompany
+	commands     []*discordgo.ApplicationCommand
+	user         *discordgo.User
+	out          io.Writer
+	// buttons are the clickable buttons from the latest output, numbered from 1
+	buttons []button
 }
 
 func main() {
-	fmt.Println("=== Leetbot Demo ===")
-	fmt.Println()
+	prefix := flag.String("prefix", "!", "text command prefix")
+	admin := flag.Bool("admin", false, "start as the bot admin, for !init, !shutdown and /dataset changelog")
+	verbose := flag.Bool("v", false, "show the bot's internal logs")
+	flag.Parse()
+
+	logOptions := logging.Options{Level: slog.LevelDebug, Format: logging.FormatText}
+	if !*verbose {
+		logOptions.Output = io.Discard
+	}
+	logging.Setup(logOptions)
+
+	// company lookups never leave the machine
+	discord.DisableCompanyEnrich()
 
 	fmt.Println("Loading problems data...")
 	problemsData, err := data.LoadAllProblems()
 	if err != nil {
-		log.Fatalf("Failed to load problems data: %v", err)
+		fmt.Fprintf(os.Stderr, "Failed to load problems data: %v\n", err)
+		os.Exit(1)
+	}
+
+	session := discordtest.New()
+	handler := discord.NewHandler(problemsData, *prefix)
+	handler.SetSession(session.Session)
+	handler.EnableChannel(demoChannelID)
+
+	r := &repl{
+		handler:      handler,
+		session:      session,
+		problemsData: problemsData,
+		commands:     discord.GetSlashCommands(problemsData),
+		user:         &discordgo.User{ID: "400000000000000001", Username: "demo"},
+		out:          os.Stdout,
+	}
+	if *admin {
+		r.user.ID = discord.AdminUserID()
 	}
 
-	companies := problemsData.GetAvailableCompanies()
-	fmt.Printf("Available companies: %v\n", companies)
-	fmt.Println()
-
-	fmt.Println("=== Command Examples ===")
-	fmt.Println()
-
-	fmt.Println("Command: !problems airbnb")
-	demoProblemsResponse(problemsData, "airbnb", "all")
-	fmt.Println()
-
-	fmt.Println("Command: !problems amazon thirty-days")
-	demoProblemsResponse(problemsData, "amazon", "thirty-days")
-	fmt.Println()
-
-	fmt.Println("Command: !problems google three-months")
-	demoProblemsResponse(problemsData, "google", "three-months")
-	fmt.Println()
-
-	fmt.Println("=== Help Command ===")
-	fmt.Println("Command: !help")
-	fmt.Println("Response would show:")
-	fmt.Println("**Leetbot Commands:**")
-	fmt.Println("**!problems <company> [timeframe]** - Show popular coding interview problems by company")
-	fmt.Println("• company: Company name (e.g., airbnb, amazon, google)")
-	fmt.Println("• timeframe: Optional timeframe filter")
-	fmt.Println("  - all (default) - All time")
-	fmt.Println("  - thirty-days - Last 30 days")
-	fmt.Println("  - three-months - Last 3 months")
-	fmt.Println("  - six-months - Last 6 months")
-	fmt.Println("  - more-than-six-months - More than 6 months ago")
-	fmt.Println()
-	fmt.Println("**Examples:**")
-	fmt.Println("• !problems airbnb")
-	fmt.Println("• !problems amazon thirty-days")
-	fmt.Println("• !problems google three-months")
+	fmt.Printf("Leetbot demo: %d companies loaded. Type .help for usage, .quit to exit.\n", len(problemsData.GetAvailableCompanies()))
+	r.run(os.Stdin)
 }
 
-func demoProblemsResponse(problemsData *data.ProblemsByCompany, company, timeframe string) {
-	problems := problemsData.GetProblems(company, timeframe)
-	if problems == nil {
-		fmt.Printf("❌ No data found for company '%s' and timeframe '%s'\n", company, timeframe)
-		return
+func (r *repl) run(in io.Reader) {
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(r.out, "> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(r.out)
+			return
+		}
+		if quit := r.exec(strings.TrimSpace(scanner.Text())); quit {
+			return
+		}
 	}
+}
 
-	companyName := titleCaser.String(company)
-	displayTimeframe := formatTimeframeDisplay(timeframe)
-	title := fmt.Sprintf("Most Popular Problems for %s (%s):", companyName, displayTimeframe)
-
-	fmt.Println(title)
-	fmt.Println()
+// exec runs one line of input and reports whether the REPL should exit
+func (r *repl) exec(line string) bool {
+	switch {
+	case line == "":
+		return false
 
-	const maxLength = 2000
-	currentLength := len(title) + 2
+	case strings.HasPrefix(line, "."):
+		return r.meta(line)
 
-	for i, problem := range problems {
-		difficultyIndicator := getDifficultyIndicator(problem.Difficulty)
-		problemLine := fmt.Sprintf("%.1f%%: %s %s - %s",
-			problem.Frequency, difficultyIndicator, problem.Title, problem.URL)
+	case strings.HasPrefix(line, "/"):
+		i, autocomplete, err := r.parseSlash(line)
+		if err != nil {
+			fmt.Fprintf(r.out, "error: %v\n", err)
+			return false
+		}
+		if autocomplete {
+			discord.HandleAutocomplete(r.session.Session, i, r.problemsData)
+		} else {
+			r.handler.HandleSlashCommand(r.session.Session, i)
+		}
 
-		if currentLength+len(problemLine) > maxLength {
-			fmt.Printf("\n... and %d more problems\n", len(problems)-i)
-			break
+	case isNumber(line):
+		n, _ := strconv.Atoi(line)
+		if err := r.click(n); err != nil {
+			fmt.Fprintf(r.out, "error: %v\n", err)
+			return false
 		}
 
-		fmt.Println(problemLine)
-		currentLength += len(problemLine) + 1
+	default:
+		r.handler.HandleMessage(r.session.Session, r.session.MessageCreate(demoChannelID, r.user, line))
 	}
+
+	r.render(r.session.Events())
+	return false
 }
 
-func formatTimeframeDisplay(timeframe string) string {
-	switch timeframe {
-	case "all":
-		return "All Time"
-	case "thirty-days":
-		return "Last 30 Days"
-	case "three-months":
-		return "Last 3 Months"
-	case "six-months":
-		return "Last 6 Months"
-	case "more-than-six-months":
-		return "More than 6 Months"
+// meta handles the REPL's own dot commands
+func (r *repl) meta(line string) bool {
+	fields := strings.Fields(line)
+	switch fields[0] {
+	case ".quit", ".exit", ".q":
+		return true
+	case ".help", ".h":
+		r.printHelp()
+	case ".admin":
+		if r.user.ID == discord.AdminUserID() {
+			r.user.ID = "400000000000000001"
+			fmt.Fprintln(r.out, "You are now a regular user.")
+		} else {
+			r.user.ID = discord.AdminUserID()
+			fmt.Fprintln(r.out, "You are now the bot admin.")
+		}
+	case ".commands":
+		for _, cmd := range r.commands {
+			fmt.Fprintf(r.out, "/%s %s- %s\n", cmd.Name, optionUsage(cmd.Options), cmd.Description)
+		}
 	default:
-		return titleCaser.String(strings.ReplaceAll(timeframe, "-", " "))
+		fmt.Fprintf(r.out, "unknown command %s, try .help\n", fields[0])
+	}
+	return false
+}
+
+func (r *repl) printHelp() {
+	fmt.Fprintln(r.out, `Input:
+  !problems google 30d           text commands go through HandleMessage
+  /problems company:google       slash commands, option:value pairs
+  /company name:"jane street"    quote values with spaces
+  /dataset changelog threshold:5 subcommands come first
+  /search query:lru?             a trailing ? shows autocomplete choices
+  3                              click button 3 of the latest output
+
+REPL commands:
+  .commands   list slash commands and their options
+  .admin      toggle acting as the bot admin
+  .help       show this help
+  .quit       exit`)
+}
+
+// click presses a numbered button from the latest output
+func (r *repl) click(n int) error {
+	if n < 1 || n > len(r.buttons) {
+		if len(r.buttons) == 0 {
+			return fmt.Errorf("there are no buttons to click")
+		}
+		return fmt.Errorf("pick a button between 1 and %d", len(r.buttons))
 	}
+
+	b := r.buttons[n-1]
+	if b.disabled {
+		return fmt.Errorf("button %d is disabled", n)
+	}
+
+	i, err := r.session.ComponentClick(b.messageID, r.user, b.customID)
+	if err != nil {
+		return err
+	}
+	r.handler.HandleComponent(r.session.Session, i)
+	return nil
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.Atoi(s)
+	return err == nil
 }
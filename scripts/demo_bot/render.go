@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/whotypes/leetbot/internal/discord/discordtest"
+)
+
+// button is a clickable button on a rendered message
+type button struct {
+	messageID string
+	customID  string
+	disabled  bool
+}
+
+// markdownLink matches [text](url) and [text](<url>)
+var markdownLink = regexp.MustCompile(`\[([^\]]+)\]\(<?([^)>]+)>?\)`)
+
+// render prints a batch of events. A message created and then edited in the
+// same batch, like a paginator getting its real button IDs, prints once.
+func (r *repl) render(events []discordtest.Event) {
+	type rendered struct {
+		event   discordtest.Event
+		created bool
+	}
+
+	var order []string
+	latest := make(map[string]*rendered)
+	for _, event := range events {
+		if event.Type == discordtest.EventAutocomplete {
+			r.renderChoices(event.Choices)
+			continue
+		}
+
+		id := event.Message.ID
+		if current, ok := latest[id]; ok {
+			current.event.Message = event.Message
+			current.event.Files = append(current.event.Files, event.Files...)
+			if event.Type == discordtest.EventMessageDelete {
+				current.event.Type = event.Type
+			}
+			continue
+		}
+		order = append(order, id)
+		latest[id] = &rendered{event: event, created: event.Type == discordtest.EventMessageCreate}
+	}
+
+	var buttons []button
+	for _, id := range order {
+		item := latest[id]
+		header := "bot"
+		switch {
+		case item.event.Type == discordtest.EventMessageDelete:
+			fmt.Fprintln(r.out, "── bot deleted a message ──")
+			continue
+		case !item.created:
+			header = "bot edited"
+		}
+		if item.event.Ephemeral {
+			header += " (only you can see this)"
+		}
+		fmt.Fprintf(r.out, "── %s ──\n", header)
+
+		buttons = append(buttons, r.renderMessage(item.event.Message, item.event.Files, len(buttons))...)
+	}
+
+	// keep the previous buttons clickable when nothing new has any
+	if len(buttons) > 0 {
+		r.buttons = buttons
+	}
+}
+
+// renderMessage prints a message and returns its buttons, numbered after offset
+func (r *repl) renderMessage(msg *discordgo.Message, files []discordtest.File, offset int) []button {
+	if msg.Content != "" {
+		fmt.Fprintln(r.out, plainText(msg.Content))
+	}
+
+	for _, embed := range msg.Embeds {
+		r.renderEmbed(embed)
+	}
+
+	for _, file := range files {
+		fmt.Fprintf(r.out, "📎 %s (%d bytes)\n", file.Name, len(file.Data))
+	}
+
+	var buttons []button
+	for _, component := range msg.Components {
+		row, ok := component.(*discordgo.ActionsRow)
+		if !ok {
+			continue
+		}
+		var labels []string
+		for _, c := range row.Components {
+			b, ok := c.(*discordgo.Button)
+			if !ok {
+				continue
+			}
+			buttons = append(buttons, button{messageID: msg.ID, customID: b.CustomID, disabled: b.Disabled})
+			label := b.Label
+			if b.Emoji != nil && b.Emoji.Name != "" {
+				label = strings.TrimSpace(b.Emoji.Name + " " + label)
+			}
+			if b.Disabled {
+				labels = append(labels, fmt.Sprintf("(%d %s)", offset+len(buttons), label))
+			} else {
+				labels = append(labels, fmt.Sprintf("[%d %s]", offset+len(buttons), label))
+			}
+		}
+		if len(labels) > 0 {
+			fmt.Fprintln(r.out, strings.Join(labels, " "))
+		}
+	}
+
+	return buttons
+}
+
+func (r *repl) renderEmbed(embed *discordgo.MessageEmbed) {
+	var lines []string
+	if embed.Title != "" {
+		lines = append(lines, "**"+embed.Title+"**")
+	}
+	if embed.Description != "" {
+		lines = append(lines, strings.Split(strings.TrimRight(embed.Description, "\n"), "\n")...)
+	}
+	for _, field := range embed.Fields {
+		lines = append(lines, "", field.Name)
+		lines = append(lines, strings.Split(strings.TrimRight(field.Value, "\n"), "\n")...)
+	}
+	if embed.Footer != nil && embed.Footer.Text != "" {
+		lines = append(lines, "", embed.Footer.Text)
+	}
+
+	for i, line := range lines {
+		prefix := "│ "
+		switch {
+		case i == 0:
+			prefix = "┌ "
+		case i == len(lines)-1:
+			prefix = "└ "
+		}
+		fmt.Fprintln(r.out, strings.TrimRight(prefix+plainText(line), " "))
+	}
+}
+
+func (r *repl) renderChoices(choices []*discordgo.ApplicationCommandOptionChoice) {
+	if len(choices) == 0 {
+		fmt.Fprintln(r.out, "(no suggestions)")
+		return
+	}
+	for _, choice := range choices {
+		value := fmt.Sprint(choice.Value)
+		if value == choice.Name {
+			fmt.Fprintf(r.out, "  %s\n", choice.Name)
+		} else {
+			fmt.Fprintf(r.out, "  %s  →  %s\n", choice.Name, value)
+		}
+	}
+}
+
+// plainText rewrites markdown links for a terminal, where they aren't clickable
+func plainText(s string) string {
+	return markdownLink.ReplaceAllString(s, "$1 <$2>")
+}
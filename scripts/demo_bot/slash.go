@@ -0,0 +1,222 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// parseSlash turns "/name [subcommand] option:value ..." into an interaction,
+// checking options against the command definitions. A value ending in "?"
+// is the focused option of an autocomplete request.
+func (r *repl) parseSlash(line string) (*discordgo.InteractionCreate, bool, error) {
+	words, err := splitWords(strings.TrimPrefix(line, "/"))
+	if err != nil {
+		return nil, false, err
+	}
+	if len(words) == 0 {
+		return nil, false, fmt.Errorf("missing command name, try .commands")
+	}
+
+	var cmd *discordgo.ApplicationCommand
+	for _, c := range r.commands {
+		if c.Name == strings.ToLower(words[0]) {
+			cmd = c
+			break
+		}
+	}
+	if cmd == nil {
+		return nil, false, fmt.Errorf("unknown slash command /%s, try .commands", words[0])
+	}
+
+	definitions := cmd.Options
+	var subcommand *discordgo.ApplicationCommandInteractionDataOption
+	args := words[1:]
+	if len(args) > 0 && !strings.Contains(args[0], ":") {
+		for _, def := range definitions {
+			if def.Type == discordgo.ApplicationCommandOptionSubCommand && def.Name == strings.ToLower(args[0]) {
+				subcommand = &discordgo.ApplicationCommandInteractionDataOption{Name: def.Name, Type: def.Type}
+				definitions = def.Options
+				args = args[1:]
+				break
+			}
+		}
+		if subcommand == nil {
+			return nil, false, fmt.Errorf("unknown subcommand %q for /%s", args[0], cmd.Name)
+		}
+	}
+
+	options, focused, err := parseOptions(args, definitions)
+	if err != nil {
+		return nil, false, fmt.Errorf("/%s: %w", cmd.Name, err)
+	}
+	if subcommand != nil {
+		subcommand.Options = options
+		options = []*discordgo.ApplicationCommandInteractionDataOption{subcommand}
+	}
+
+	commandData := discordgo.ApplicationCommandInteractionData{
+		ID:          cmd.Name,
+		Name:        cmd.Name,
+		CommandType: discordgo.ChatApplicationCommand,
+		Options:     options,
+	}
+	if focused {
+		return r.session.Autocomplete(demoChannelID, r.user, commandData), true, nil
+	}
+	return r.session.SlashCommand(demoChannelID, r.user, commandData), false, nil
+}
+
+func parseOptions(args []string, definitions []*discordgo.ApplicationCommandOption) ([]*discordgo.ApplicationCommandInteractionDataOption, bool, error) {
+	var options []*discordgo.ApplicationCommandInteractionDataOption
+	focused := false
+
+	for _, arg := range args {
+		name, value, ok := strings.Cut(arg, ":")
+		if !ok {
+			return nil, false, fmt.Errorf("expected option:value, got %q", arg)
+		}
+
+		var def *discordgo.ApplicationCommandOption
+		for _, d := range definitions {
+			if d.Name == strings.ToLower(name) {
+				def = d
+				break
+			}
+		}
+		if def == nil {
+			return nil, false, fmt.Errorf("unknown option %q, expected one of: %s", name, optionUsage(definitions))
+		}
+
+		option := &discordgo.ApplicationCommandInteractionDataOption{Name: def.Name, Type: def.Type}
+		if strings.HasSuffix(value, "?") {
+			if !def.Autocomplete {
+				return nil, false, fmt.Errorf("option %q has no autocomplete", def.Name)
+			}
+			// autocomplete sends the partial input as a string whatever the option type
+			option.Value = strings.TrimSuffix(value, "?")
+			option.Focused = true
+			focused = true
+			options = append(options, option)
+			continue
+		}
+
+		parsed, err := parseOptionValue(def, value)
+		if err != nil {
+			return nil, false, err
+		}
+		option.Value = parsed
+		options = append(options, option)
+	}
+
+	if !focused {
+		for _, def := range definitions {
+			if def.Required && !hasOption(options, def.Name) {
+				return nil, false, fmt.Errorf("missing required option %s", def.Name)
+			}
+		}
+	}
+
+	return options, focused, nil
+}
+
+// parseOptionValue converts a value like Discord's JSON payload would, where
+// every number is a float64
+func parseOptionValue(def *discordgo.ApplicationCommandOption, value string) (interface{}, error) {
+	switch def.Type {
+	case discordgo.ApplicationCommandOptionInteger:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("option %s needs a whole number, got %q", def.Name, value)
+		}
+		return float64(n), nil
+	case discordgo.ApplicationCommandOptionNumber:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("option %s needs a number, got %q", def.Name, value)
+		}
+		return n, nil
+	case discordgo.ApplicationCommandOptionBoolean:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("option %s needs true or false, got %q", def.Name, value)
+		}
+		return b, nil
+	}
+
+	if len(def.Choices) > 0 {
+		for _, choice := range def.Choices {
+			if fmt.Sprint(choice.Value) == value || strings.EqualFold(choice.Name, value) {
+				return choice.Value, nil
+			}
+		}
+		names := make([]string, 0, len(def.Choices))
+		for _, choice := range def.Choices {
+			names = append(names, fmt.Sprint(choice.Value))
+		}
+		return nil, fmt.Errorf("option %s must be one of: %s", def.Name, strings.Join(names, ", "))
+	}
+
+	return value, nil
+}
+
+func hasOption(options []*discordgo.ApplicationCommandInteractionDataOption, name string) bool {
+	for _, option := range options {
+		if option.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// optionUsage lists options as "name:<type>", subcommands by name
+func optionUsage(definitions []*discordgo.ApplicationCommandOption) string {
+	var usage strings.Builder
+	for _, def := range definitions {
+		if def.Type == discordgo.ApplicationCommandOptionSubCommand {
+			fmt.Fprintf(&usage, "%s|", def.Name)
+			continue
+		}
+		if def.Required {
+			fmt.Fprintf(&usage, "%s:<%s> ", def.Name, strings.ToLower(def.Type.String()))
+		} else {
+			fmt.Fprintf(&usage, "[%s:<%s>] ", def.Name, strings.ToLower(def.Type.String()))
+		}
+	}
+	return strings.TrimSuffix(usage.String(), "|") + " "
+}
+
+// splitWords splits on spaces, keeping double-quoted text together so
+// company:"jane street" is one word
+func splitWords(s string) ([]string, error) {
+	var words []string
+	var current strings.Builder
+	inQuotes, inWord := false, false
+
+	for _, r := range s {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			inWord = true
+		case r == ' ' && !inQuotes:
+			if inWord {
+				words = append(words, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if inWord {
+		words = append(words, current.String())
+	}
+
+	return words, nil
+}
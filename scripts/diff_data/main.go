@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/whotypes/leetbot/internal/data"
+)
+
+func main() {
+	threshold := flag.Float64("threshold", 10, "minimum frequency change in percentage points to report")
+	jsonOutput := flag.Bool("json", false, "print the changelog as JSON")
+	snapshotsDir := flag.String("snapshots", "", "compare against the latest snapshot in this directory instead of <old-directory>")
+	save := flag.Bool("save", false, "save <new-directory> as a snapshot in the -snapshots directory after diffing")
+	flag.Usage = func() {
+		fmt.Println("Usage: go run scripts/diff_data/main.go [flags] <old-directory> <new-directory>")
+		fmt.Println("       go run scripts/diff_data/main.go -snapshots <snapshots-directory> [-save] [flags] <new-directory>")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	args := flag.Args()
+	if (*snapshotsDir == "" && len(args) != 2) || (*snapshotsDir != "" && len(args) != 1) {
+		flag.Usage()
+		os.Exit(1)
+	}
+	if *save && *snapshotsDir == "" {
+		fmt.Println("-save requires -snapshots")
+		os.Exit(1)
+	}
+
+	newDir := args[len(args)-1]
+	newData, err := data.LoadSnapshot(newDir)
+	if err != nil {
+		fmt.Printf("Error loading %s: %v\n", newDir, err)
+		os.Exit(1)
+	}
+
+	var oldData *data.ProblemsByCompany
+	if *snapshotsDir != "" {
+		oldData, err = data.LatestSnapshotBefore(*snapshotsDir, newData.Version())
+		if err != nil && !os.IsNotExist(err) {
+			fmt.Printf("Error loading snapshots from %s: %v\n", *snapshotsDir, err)
+			os.Exit(1)
+		}
+	} else {
+		oldData, err = data.LoadSnapshot(args[0])
+		if err != nil {
+			fmt.Printf("Error loading %s: %v\n", args[0], err)
+			os.Exit(1)
+		}
+	}
+
+	if oldData == nil {
+		fmt.Printf("No previous snapshot in %s, nothing to compare against\n", *snapshotsDir)
+	} else {
+		changelog := data.Diff(oldData, newData, *threshold)
+		if *jsonOutput {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(changelog); err != nil {
+				fmt.Printf("Error encoding changelog: %v\n", err)
+				os.Exit(1)
+			}
+		} else {
+			printChangelog(changelog, *threshold)
+		}
+	}
+
+	if *save {
+		dir, err := data.SaveSnapshot(newData, *snapshotsDir, time.Now())
+		if err != nil {
+			fmt.Printf("Error saving snapshot: %v\n", err)
+			os.Exit(1)
+		}
+		if !*jsonOutput {
+			fmt.Printf("\nSaved snapshot %s\n", dir)
+		}
+	}
+}
+
+func printChangelog(changelog *data.Changelog, threshold float64) {
+	fmt.Printf("Dataset changes %s → %s\n", changelog.From.Version, changelog.To.Version)
+	fmt.Printf("  Companies: %d → %d\n", changelog.From.Companies, changelog.To.Companies)
+	fmt.Printf("  Unique problems: %d → %d\n", changelog.From.UniqueProblems, changelog.To.UniqueProblems)
+
+	if changelog.Empty() {
+		fmt.Println("\n✅ No changes")
+		return
+	}
+
+	if len(changelog.AddedCompanies) > 0 {
+		fmt.Printf("\nAdded companies (%d):\n", len(changelog.AddedCompanies))
+		for _, company := range changelog.AddedCompanies {
+			fmt.Printf("  + %s\n", company)
+		}
+	}
+
+	if len(changelog.RemovedCompanies) > 0 {
+		fmt.Printf("\nRemoved companies (%d):\n", len(changelog.RemovedCompanies))
+		for _, company := range changelog.RemovedCompanies {
+			fmt.Printf("  - %s\n", company)
+		}
+	}
+
+	if len(changelog.Timeframes) > 0 {
+		fmt.Printf("\nChanged timeframes (%d, frequency threshold %.1f):\n", len(changelog.Timeframes), threshold)
+	}
+	for _, tf := range changelog.Timeframes {
+		fmt.Printf("\n%s/%s\n", tf.Company, tf.Timeframe)
+		for _, p := range tf.Added {
+			fmt.Printf("  + %d. %s (%.1f%%)\n", p.ID, p.Title, p.Frequency)
+		}
+		for _, p := range tf.Removed {
+			fmt.Printf("  - %d. %s (%.1f%%)\n", p.ID, p.Title, p.Frequency)
+		}
+		for _, change := range tf.FrequencyChanges {
+			fmt.Printf("  ~ %d. %s %.1f%% → %.1f%% (%+.1f)\n",
+				change.Problem.ID, change.Problem.Title, change.Old, change.New, change.Delta())
+		}
+	}
+}
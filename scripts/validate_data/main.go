@@ -1,177 +1,189 @@
 package main
 
 import (
-	"encoding/csv"
+	"encoding/json"
+	"flag"
 	"fmt"
-	"io/fs"
 	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
+
+	"github.com/whotypes/leetbot/internal/data"
 )
 
-type Problem struct {
-	ID         int     `csv:"ID"`
-	URL        string  `csv:"URL"`
-	Title      string  `csv:"Title"`
-	Difficulty string  `csv:"Difficulty"`
-	Acceptance float64 `csv:"Acceptance %"`
-	Frequency  float64 `csv:"Frequency %"`
+type report struct {
+	Files       int               `json:"files"`
+	Errors      int               `json:"errors"`
+	Warnings    int               `json:"warnings"`
+	Fixes       []string          `json:"fixes,omitempty"`
+	Diagnostics []data.Diagnostic `json:"diagnostics"`
 }
 
 func main() {
-	if len(os.Args) != 2 {
-		fmt.Println("Usage: go run scripts/validate_data/main.go <data-directory>")
+	fix := flag.Bool("fix", false, "apply safe automatic fixes before validating")
+	jsonOutput := flag.Bool("json", false, "print the report as JSON for CI")
+	strict := flag.Bool("strict", false, "treat warnings as errors")
+	flag.Usage = func() {
+		fmt.Println("Usage: go run scripts/validate_data/main.go [-fix] [-json] [-strict] <data-directory>")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
 		os.Exit(1)
 	}
 
-	dataDir := os.Args[1]
+	dataDir := flag.Arg(0)
 	if _, err := os.Stat(dataDir); os.IsNotExist(err) {
 		fmt.Printf("Data directory %s does not exist\n", dataDir)
 		os.Exit(1)
 	}
 
-	fmt.Printf("Validating CSV data in %s...\n", dataDir)
-
-	var totalFiles, validFiles, invalidFiles int
-	var totalProblems int
+	var result report
 
-	err := filepath.WalkDir(dataDir, func(path string, d fs.DirEntry, err error) error {
+	if *fix {
+		fixes, err := fixDir(dataDir)
 		if err != nil {
-			return err
-		}
-
-		if !strings.HasSuffix(path, ".csv") || strings.Contains(path, ".git") {
-			return nil
-		}
-
-		totalFiles++
-		fmt.Printf("Validating %s... ", filepath.Base(path))
-
-		if err := validateCSV(path); err != nil {
-			fmt.Printf("❌ INVALID: %v\n", err)
-			invalidFiles++
-			return nil
+			fmt.Printf("Error fixing data: %v\n", err)
+			os.Exit(1)
 		}
+		result.Fixes = fixes
+	}
 
-		fmt.Printf("✅ VALID\n")
-		validFiles++
-		return nil
-	})
-
+	diagnostics, files, err := data.ValidateDir(dataDir)
 	if err != nil {
 		fmt.Printf("Error walking directory: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("\nValidation Summary:\n")
-	fmt.Printf("  Total CSV files: %d\n", totalFiles)
-	fmt.Printf("  Valid files: %d\n", validFiles)
-	fmt.Printf("  Invalid files: %d\n", invalidFiles)
-	fmt.Printf("  Total problems: %d\n", totalProblems)
-
-	if invalidFiles > 0 {
-		fmt.Printf("❌ Validation failed: %d files have errors\n", invalidFiles)
-		os.Exit(1)
+	result.Files = files
+	result.Diagnostics = diagnostics
+	if result.Diagnostics == nil {
+		result.Diagnostics = []data.Diagnostic{}
 	}
-
-	fmt.Printf("✅ All CSV files are valid!\n")
-}
-
-func validateCSV(path string) error {
-	file, err := os.Open(path)
-	if err != nil {
-		return fmt.Errorf("cannot open file: %v", err)
+	for _, d := range diagnostics {
+		if d.Severity == data.SeverityError {
+			result.Errors++
+		} else {
+			result.Warnings++
+		}
 	}
-	defer file.Close()
 
-	reader := csv.NewReader(file)
+	failed := result.Errors > 0 || (*strict && result.Warnings > 0)
 
-	header, err := reader.Read()
-	if err != nil {
-		return fmt.Errorf("cannot read header: %v", err)
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(result); err != nil {
+			fmt.Printf("Error encoding report: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		printReport(dataDir, result, failed)
 	}
-	expectedHeader := []string{"ID", "URL", "Title", "Difficulty", "Acceptance %", "Frequency %"}
-	if len(header) != len(expectedHeader) {
-		return fmt.Errorf("header has %d columns, expected %d", len(header), len(expectedHeader))
+
+	if failed {
+		os.Exit(1)
 	}
+}
+
+func printReport(dataDir string, result report, failed bool) {
+	fmt.Printf("Validating CSV data in %s...\n", dataDir)
 
-	for i, expected := range expectedHeader {
-		if header[i] != expected {
-			return fmt.Errorf("header[%d] = %q, expected %q", i, header[i], expected)
+	if len(result.Fixes) > 0 {
+		fmt.Printf("\nApplied %d fixes:\n", len(result.Fixes))
+		for _, fix := range result.Fixes {
+			fmt.Printf("  🔧 %s\n", fix)
 		}
 	}
 
-	lineNum := 1
-	for {
-		record, err := reader.Read()
-		if err != nil {
-			if err.Error() == "EOF" {
-				break
-			}
-			return fmt.Errorf("error reading line %d: %v", lineNum+1, err)
+	if len(result.Diagnostics) > 0 {
+		fmt.Println()
+	}
+	for _, d := range result.Diagnostics {
+		icon := "⚠️ "
+		if d.Severity == data.SeverityError {
+			icon = "❌"
 		}
+		fmt.Printf("%s %s\n", icon, d)
+	}
 
-		lineNum++
+	fmt.Printf("\nValidation Summary:\n")
+	fmt.Printf("  Total CSV files: %d\n", result.Files)
+	fmt.Printf("  Errors: %d\n", result.Errors)
+	fmt.Printf("  Warnings: %d\n", result.Warnings)
 
-		if err := validateRecord(record); err != nil {
-			return fmt.Errorf("line %d: %v", lineNum, err)
-		}
+	if failed {
+		fmt.Printf("❌ Validation failed\n")
+		return
 	}
 
-	return nil
+	fmt.Printf("✅ All CSV files are valid!\n")
 }
 
-func validateRecord(record []string) error {
-	if len(record) != 6 {
-		return fmt.Errorf("record has %d fields, expected 6", len(record))
-	}
-
-	if record[0] == "" {
-		return fmt.Errorf("ID is empty")
-	}
+// fixDir rewrites every CSV file with data.FixCSV and renames timeframe files
+// that use an alias (e.g. 30d.csv) to their canonical name
+func fixDir(dataDir string) ([]string, error) {
+	var fixes []string
 
-	if record[1] == "" {
-		return fmt.Errorf("URL is empty")
-	}
-	if !strings.HasPrefix(record[1], "https://leetcode.com/") {
-		return fmt.Errorf("URL does not start with https://leetcode.com/")
+	companies, err := os.ReadDir(dataDir)
+	if err != nil {
+		return nil, err
 	}
 
-	if record[2] == "" {
-		return fmt.Errorf("Title is empty")
-	}
+	for _, company := range companies {
+		if !company.IsDir() || strings.HasPrefix(company.Name(), ".") {
+			continue
+		}
+		companyDir := filepath.Join(dataDir, company.Name())
 
-	difficulty := strings.ToLower(record[3])
-	if difficulty != "easy" && difficulty != "medium" && difficulty != "hard" {
-		return fmt.Errorf("Difficulty %q is not valid (must be Easy, Medium, or Hard)", record[3])
-	}
+		entries, err := os.ReadDir(companyDir)
+		if err != nil {
+			return fixes, err
+		}
 
-	if record[4] == "" {
-		return fmt.Errorf("Acceptance %% is empty")
-	}
-	if _, err := parsePercentage(record[4]); err != nil {
-		return fmt.Errorf("Acceptance %% %q is not a valid number: %v", record[4], err)
-	}
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".csv") {
+				continue
+			}
+			path := filepath.Join(companyDir, entry.Name())
 
-	if record[5] == "" {
-		return fmt.Errorf("Frequency %% is empty")
-	}
-	frequency, err := parsePercentage(record[5])
-	if err != nil {
-		return fmt.Errorf("Frequency %% %q is not a valid number", record[5])
-	}
-	if frequency < 0 || frequency > 100 {
-		return fmt.Errorf("Frequency %% %.1f is out of range [0, 100]", frequency)
-	}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return fixes, err
+			}
 
-	return nil
-}
+			fixed, changes, err := data.FixCSV(content)
+			if err != nil {
+				// malformed CSV can't be fixed automatically, validation reports it
+				continue
+			}
+			if len(changes) > 0 {
+				if err := os.WriteFile(path, fixed, 0o644); err != nil {
+					return fixes, err
+				}
+				for _, change := range changes {
+					fixes = append(fixes, fmt.Sprintf("%s: %s", path, change))
+				}
+			}
 
-func parsePercentage(s string) (float64, error) {
-	if !strings.HasSuffix(s, "%") {
-		return 0, fmt.Errorf("percentage must end with %%")
+			timeframe := strings.TrimSuffix(entry.Name(), ".csv")
+			canonical, ok := data.CanonicalTimeframe(timeframe)
+			if !ok || canonical == timeframe {
+				continue
+			}
+			target := filepath.Join(companyDir, canonical+".csv")
+			if _, err := os.Stat(target); err == nil {
+				// both files exist, leave it for a human to merge
+				continue
+			}
+			if err := os.Rename(path, target); err != nil {
+				return fixes, err
+			}
+			fixes = append(fixes, fmt.Sprintf("%s: renamed to %s", path, target))
+		}
 	}
-	s = strings.TrimSuffix(s, "%")
-	return strconv.ParseFloat(s, 64)
+
+	return fixes, nil
 }
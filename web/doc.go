@@ -0,0 +1,3 @@
+// Package web compiles the built frontend in web/dist into binaries built
+// with the embedweb tag. Without it the server reads the files from disk.
+package web
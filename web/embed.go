@@ -0,0 +1,26 @@
+//go:build embedweb
+
+package web
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// the frontend has to be built first, see make build-server-embed
+//
+//go:embed all:dist
+var dist embed.FS
+
+// Embedded reports whether the frontend is compiled into the binary
+const Embedded = true
+
+// Dist returns the contents of web/dist
+func Dist() fs.FS {
+	files, err := fs.Sub(dist, "dist")
+	if err != nil {
+		// only possible for an invalid path
+		panic(err)
+	}
+	return files
+}
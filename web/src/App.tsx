@@ -125,6 +125,11 @@ const fetchProblems = async ({
   return data.data!
 }
 
+// Deep links like /company/google select that company
+const companyFromPath = (): string => {
+  const match = window.location.pathname.match(/^\/company\/([^/]+)\/?$/)
+  return match ? decodeURIComponent(match[1]) : ''
+}
 
 function App() {
   const { theme, toggleTheme } = useTheme()
@@ -135,6 +140,15 @@ function App() {
   const [selectedTimeframe, setSelectedTimeframe] = useLocalStorage<string>('selectedTimeframe', 'all')
   const [previewCompany, setPreviewCompany] = useState<string>('')
 
+  // Select the company in the URL once, on first load
+  useEffect(() => {
+    const linked = companyFromPath()
+    if (linked) {
+      setSelectedCompany(linked)
+    }
+    // eslint-disable-next-line react-hooks/exhaustive-deps
+  }, [])
+
   // Prefetch all problems in the background
   useQuery({
     queryKey: ['all-problems'],
@@ -188,6 +202,7 @@ function App() {
   const handleCompanyChange = (company: string) => {
     setSelectedCompany(company)
     setPreviewCompany('')
+    window.history.pushState(null, '', `/company/${encodeURIComponent(company)}`)
   }
 
   const handleCompanyPreview = (company: string) => {
@@ -5,6 +5,7 @@ export interface Problem {
   difficulty: string
   acceptance: number
   frequency: number
+  source?: string
 }
 
 export interface APIResponse {
@@ -0,0 +1,13 @@
+//go:build !embedweb
+
+package web
+
+import "io/fs"
+
+// Embedded reports whether the frontend is compiled into the binary
+const Embedded = false
+
+// Dist returns nil, the frontend isn't embedded
+func Dist() fs.FS {
+	return nil
+}